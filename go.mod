@@ -2,11 +2,13 @@ module diabeticai-advisor
 
 go 1.24.1
 
-require github.com/firebase/genkit/go v1.2.0
+require (
+	cloud.google.com/go/auth v0.16.2
+	github.com/firebase/genkit/go v1.2.0
+)
 
 require (
 	cloud.google.com/go v0.120.0 // indirect
-	cloud.google.com/go/auth v0.16.2 // indirect
 	cloud.google.com/go/compute/metadata v0.7.0 // indirect
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
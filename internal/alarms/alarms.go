@@ -0,0 +1,229 @@
+// Package alarms raises glucose alarms from users' rules as CGM readings
+// arrive, repeats them after a snooze, and escalates them to emergency
+// contacts when nobody acknowledges them.
+package alarms
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"diabeticai-advisor/internal/email"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+)
+
+// Readings further apart than this break a run, so a gap in CGM data
+// doesn't count as time past the level
+const maxGap = 15 * time.Minute
+
+// A reading older than this is too stale to raise or clear an alarm
+const staleAfter = 15 * time.Minute
+
+// Escalate after this long without an acknowledgement, unless the rule says otherwise
+const defaultEscalateAfter = 15 * time.Minute
+
+// Longest snooze allowed
+const MaxSnooze = 4 * time.Hour
+
+// Reminder types for alarms, which ignore quiet hours and mutes
+const (
+	TypeAlarm      = "glucose_alarm"
+	TypeEscalation = "alarm_escalation"
+)
+
+// Result Struct
+type Result struct {
+	Raised    int
+	Repeated  int
+	Escalated int
+	Cleared   int
+}
+
+// Check every user's alarm rules against their readings at the given time:
+// raise alarms for rules newly met, sound snoozed alarms whose snooze is over,
+// escalate unacknowledged ones and clear those whose readings recovered.
+func Check(ctx context.Context, s *store.Stores, mailer *email.Sender, now time.Time) (Result, error) {
+	var res Result
+	failed := 0
+	for _, userID := range s.AlarmRules.Users() {
+		if err := checkUser(ctx, s, mailer, userID, now, &res); err != nil {
+			log.Printf("Alarm check failed for %s: %v", userID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return res, fmt.Errorf("failed to check alarms for %d users", failed)
+	}
+	return res, nil
+}
+
+// Helper function to check one user's rules
+func checkUser(ctx context.Context, s *store.Stores, mailer *email.Sender, userID string, now time.Time, res *Result) error {
+	settings := s.AlarmRules.Get(userID)
+	prefs, _ := locale.Resolve(s.Preferences.Get(userID))
+	firing := map[string]store.Alarm{}
+	for _, a := range s.Alarms.Active(userID) {
+		firing[a.RuleID] = a
+	}
+
+	var errs int
+	for _, rule := range settings.Rules {
+		cond, err := rule.Condition()
+		if err != nil {
+			errs++
+			continue
+		}
+		latest, since, met := evaluate(s.Readings, userID, cond, now)
+		alarm, ok := firing[rule.ID]
+		delete(firing, rule.ID)
+
+		switch {
+		case !ok && met:
+			alarm = s.Alarms.Put(store.Alarm{
+				UserID:     userID,
+				RuleID:     rule.ID,
+				Rule:       rule.Rule,
+				State:      store.AlarmActive,
+				Value:      latest.Value,
+				Since:      since,
+				RaisedAt:   now,
+				NotifiedAt: now,
+			})
+			notify(ctx, s, alarm, prefs, false)
+			res.Raised++
+		case !ok:
+		case latest.Timestamp.IsZero():
+			// No fresh readings: keep the alarm, since recovery can't be confirmed
+			if escalate(ctx, s, mailer, alarm, rule, settings.Contacts, prefs, now) {
+				res.Escalated++
+			}
+		case !cond.Past(latest.Value):
+			s.Alarms.Clear(alarm.ID)
+			log.Printf("Alarm %s cleared for %s at %s", rule.ID, userID, prefs.Glucose(latest.Value))
+			res.Cleared++
+		default:
+			repeat := false
+			alarm, ok = s.Alarms.Update(alarm.ID, func(a *store.Alarm) {
+				a.Value = latest.Value
+				if a.State == store.AlarmSnoozed && !now.Before(a.SnoozedUntil) {
+					a.State, a.NotifiedAt, a.SnoozedUntil = store.AlarmActive, now, time.Time{}
+					repeat = true
+				}
+			})
+			if !ok {
+				continue
+			}
+			if repeat {
+				notify(ctx, s, alarm, prefs, true)
+				res.Repeated++
+			}
+			if escalate(ctx, s, mailer, alarm, rule, settings.Contacts, prefs, now) {
+				res.Escalated++
+			}
+		}
+	}
+
+	// Alarms whose rule was removed
+	for _, alarm := range firing {
+		s.Alarms.Clear(alarm.ID)
+	}
+	if errs > 0 {
+		return fmt.Errorf("%d invalid rule(s)", errs)
+	}
+	return nil
+}
+
+// Helper function to check a rule against a user's readings. Returns the
+// latest reading when it is fresh, the first reading of the run past the
+// level leading up to it, and whether the run has lasted long enough.
+func evaluate(readings *store.ReadingStore, userID string, cond store.AlarmCondition, now time.Time) (store.GlucoseReading, time.Time, bool) {
+	recent := readings.Range(userID, now.Add(-cond.For-2*maxGap), now.Add(time.Second))
+	if len(recent) == 0 || now.Sub(recent[len(recent)-1].Timestamp) > staleAfter {
+		return store.GlucoseReading{}, time.Time{}, false
+	}
+	latest := recent[len(recent)-1]
+	if !cond.Past(latest.Value) {
+		return latest, time.Time{}, false
+	}
+
+	since := latest.Timestamp
+	for i := len(recent) - 2; i >= 0; i-- {
+		r := recent[i]
+		if !cond.Past(r.Value) || since.Sub(r.Timestamp) > maxGap {
+			break
+		}
+		since = r.Timestamp
+	}
+	return latest, since, latest.Timestamp.Sub(since) >= cond.For
+}
+
+// Helper function to alert the user to an alarm on every channel they use
+func notify(ctx context.Context, s *store.Stores, alarm store.Alarm, prefs locale.Prefs, repeat bool) {
+	channels := s.Notifications.Get(alarm.UserID).Channels
+	if len(channels) == 0 {
+		// Alarms are safety alerts, so they are queued in-app even with reminders off
+		channels = []string{"in_app"}
+	}
+	prefix := "Glucose alarm"
+	if repeat {
+		prefix = "Glucose alarm still sounding"
+	}
+	s.Reminders.Add(ctx, store.Reminder{
+		UserID:   alarm.UserID,
+		Type:     TypeAlarm,
+		Message:  fmt.Sprintf("%s: %s since %s (%s). %s", prefix, prefs.Glucose(alarm.Value), prefs.Clock(alarm.Since), alarm.Rule, advice(alarm)),
+		Channels: channels,
+		DueAt:    alarm.NotifiedAt,
+	})
+}
+
+// Helper function to suggest what to do about an alarm
+func advice(alarm store.Alarm) string {
+	cond, _ := store.AlarmRule{Rule: alarm.Rule}.Condition()
+	if cond.Below {
+		return "Treat with 15 g of fast-acting carbs now and recheck in 15 minutes."
+	}
+	return "Drink water, check ketones if you can, and follow your high blood sugar plan."
+}
+
+// Helper function to alert the emergency contacts once, when an active alarm
+// has gone unanswered for the rule's escalation time. Reports whether it did.
+func escalate(ctx context.Context, s *store.Stores, mailer *email.Sender, alarm store.Alarm, rule store.AlarmRule, contacts []store.EmergencyContact, prefs locale.Prefs, now time.Time) bool {
+	after := defaultEscalateAfter
+	if rule.EscalateAfter > 0 {
+		after = time.Duration(rule.EscalateAfter) * time.Minute
+	}
+	if len(contacts) == 0 || alarm.State != store.AlarmActive || !alarm.EscalatedAt.IsZero() || now.Sub(alarm.NotifiedAt) < after {
+		return false
+	}
+	// Mark first, so a slow mail server doesn't cause a second escalation,
+	// and recheck in case the user answered meanwhile
+	marked := false
+	s.Alarms.Update(alarm.ID, func(a *store.Alarm) {
+		if a.State == store.AlarmActive && a.EscalatedAt.IsZero() {
+			a.EscalatedAt, marked = now, true
+		}
+	})
+	if !marked {
+		return false
+	}
+
+	msg := fmt.Sprintf("%s has not answered a glucose alarm for %d minutes: %s since %s (%s). Please check on them.",
+		alarm.UserID, int(now.Sub(alarm.NotifiedAt).Minutes()), prefs.Glucose(alarm.Value), prefs.Clock(alarm.Since), alarm.Rule)
+	for _, c := range contacts {
+		if c.UserID != "" {
+			s.Reminders.Add(ctx, store.Reminder{UserID: c.UserID, Type: TypeEscalation, Message: msg, Channels: []string{"in_app"}, DueAt: now})
+		}
+		if c.Email != "" && mailer.Enabled() {
+			body := "<p>" + html.EscapeString(msg) + "</p><p>This is an automated alert. If they may be in danger, call emergency services.</p>"
+			if err := mailer.Send(c.Email, "Urgent: unanswered glucose alarm for "+alarm.UserID, body); err != nil {
+				log.Printf("Alarm escalation email to %s failed: %v", c.Name, err)
+			}
+		}
+	}
+	log.Printf("Alarm %s escalated for %s to %d contact(s)", alarm.RuleID, alarm.UserID, len(contacts))
+	return true
+}
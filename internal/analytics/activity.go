@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Window of recent activity that still lowers blood sugar
+const recentActivityWindow = 3 * time.Hour
+
+// Steps in the recent window that count as a stretch of activity
+const activeSteps = 4000
+
+// Activity Struct
+//
+// What a user's wearable reported today and over the last few hours.
+type Activity struct {
+	StepsToday      int `json:"steps_today"`
+	RecentSteps     int `json:"recent_steps" jsonschema:"description=Steps in the last 3 hours"`
+	RecentWorkout   int `json:"recent_workout_minutes" jsonschema:"description=Workout minutes in the last 3 hours"`
+	LatestHeartRate int `json:"latest_heart_rate_bpm,omitempty"`
+	Samples         int `json:"samples"`
+}
+
+// Summarise a user's wearable data for the day containing now
+func RecentActivity(samples *store.LogStore[store.ActivitySample], workouts *store.LogStore[store.WorkoutLog], userID string, now time.Time) Activity {
+	var a Activity
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	recent := now.Add(-recentActivityWindow)
+	for _, s := range samples.Range(userID, start, now.Add(time.Second)) {
+		a.Samples++
+		a.StepsToday += s.Steps
+		if !s.Timestamp.Before(recent) {
+			a.RecentSteps += s.Steps
+		}
+		if s.HeartRate > 0 {
+			a.LatestHeartRate = s.HeartRate
+		}
+	}
+	for _, w := range workouts.Range(userID, recent.Add(-4*time.Hour), now.Add(time.Second)) {
+		// A workout counts while any of it falls in the window
+		if w.Timestamp.Add(time.Duration(w.DurationMinutes) * time.Minute).After(recent) {
+			a.RecentWorkout += w.DurationMinutes
+		}
+	}
+	return a
+}
+
+// Report whether the user has been active enough lately to lower blood sugar
+func (a Activity) Active() bool {
+	return a.RecentSteps >= activeSteps || a.RecentWorkout >= 20
+}
+
+// Helper function to describe recent activity for a prompt
+func (a Activity) PromptSummary() string {
+	if a.Samples == 0 && a.RecentWorkout == 0 {
+		return "Wearable activity: none reported"
+	}
+	parts := []string{fmt.Sprintf("%d steps today, %d in the last 3 hours", a.StepsToday, a.RecentSteps)}
+	if a.RecentWorkout > 0 {
+		parts = append(parts, fmt.Sprintf("%d workout minutes in the last 3 hours", a.RecentWorkout))
+	}
+	if a.LatestHeartRate > 0 {
+		parts = append(parts, fmt.Sprintf("latest heart rate %d bpm", a.LatestHeartRate))
+	}
+	note := ""
+	if a.Active() {
+		note = " (recent activity can lower blood sugar for hours)"
+	}
+	return "Wearable activity: " + strings.Join(parts, "; ") + note
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Percentiles plotted on an Ambulatory Glucose Profile
+var agpPercentiles = []int{10, 25, 50, 75, 90}
+
+// AGP Output Struct
+//
+// Labels and each Series slice are aligned by index, one entry per
+// time-of-day bin. Bins without readings are null so charts show a gap.
+type AGPOutput struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	BinMinutes int                   `json:"bin_minutes"`
+	Labels     []string              `json:"labels"`
+	Counts     []int                 `json:"counts"`
+	Series     map[string][]*float64 `json:"series"`
+	Stats      GlucoseStats          `json:"stats"`
+}
+
+// Build percentile curves by time of day from a set of readings
+func ComputeAGP(readings []store.GlucoseReading, from, to time.Time, binMinutes int) AGPOutput {
+	numBins := 24 * 60 / binMinutes
+	bins := make([][]float64, numBins)
+	for _, r := range readings {
+		minute := r.Timestamp.Hour()*60 + r.Timestamp.Minute()
+		bins[minute/binMinutes] = append(bins[minute/binMinutes], r.Value)
+	}
+
+	out := AGPOutput{
+		From:       from,
+		To:         to,
+		BinMinutes: binMinutes,
+		Labels:     make([]string, numBins),
+		Counts:     make([]int, numBins),
+		Series:     make(map[string][]*float64, len(agpPercentiles)),
+		Stats:      ComputeStats(readings, from, to),
+	}
+	for _, p := range agpPercentiles {
+		out.Series[fmt.Sprintf("p%d", p)] = make([]*float64, numBins)
+	}
+
+	for i, values := range bins {
+		start := i * binMinutes
+		out.Labels[i] = fmt.Sprintf("%02d:%02d", start/60, start%60)
+		out.Counts[i] = len(values)
+		if len(values) == 0 {
+			continue
+		}
+
+		sort.Float64s(values)
+		for _, p := range agpPercentiles {
+			v := percentile(values, float64(p))
+			out.Series[fmt.Sprintf("p%d", p)][i] = &v
+		}
+	}
+
+	return out
+}
+
+// Helper function to compute a percentile of sorted values using linear interpolation
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+
+	v := sorted[lower]*(1-weight) + sorted[upper]*weight
+	return math.Round(v*10) / 10
+}
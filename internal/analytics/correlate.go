@@ -0,0 +1,281 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Windows used to pair meals with readings
+const (
+	BaselineWindow = 60 * time.Minute
+	postMealStart  = 30 * time.Minute
+	PostMealEnd    = 3 * time.Hour
+	spikeRise      = 50.0
+)
+
+// Meal Response Struct
+type MealResponse struct {
+	Meal      store.MealLog `json:"meal"`
+	Baseline  float64       `json:"baseline,omitempty"`
+	Peak      float64       `json:"peak"`
+	PeakAfter int           `json:"peak_after_minutes"`
+	Rise      float64       `json:"rise,omitempty"`
+	Spike     bool          `json:"spike"`
+}
+
+// Food Pattern Struct
+type FoodPattern struct {
+	Food      string  `json:"food"`
+	Meals     int     `json:"meals"`
+	Spikes    int     `json:"spikes"`
+	AvgPeak   float64 `json:"avg_peak"`
+	AvgRise   float64 `json:"avg_rise,omitempty"`
+	SpikeRate float64 `json:"spike_rate"`
+}
+
+// Helper function to define a spike for a prompt, with the cutoffs MealResponses uses
+func SpikeDefinition() string {
+	return fmt.Sprintf("A spike is a peak above %.0f mg/dL or a rise of %.0f mg/dL or more within %.0f hours of eating.",
+		thresholds.Ranges().High, spikeRise, PostMealEnd.Hours())
+}
+
+// Pair each meal with the readings around it.
+// Meals without a reading in the post-meal window are skipped.
+func MealResponses(meals []store.MealLog, readings []store.GlucoseReading) []MealResponse {
+	var out []MealResponse
+	for _, meal := range meals {
+		resp := MealResponse{Meal: meal}
+		var seenPeak, seenBaseline bool
+
+		for _, r := range readings {
+			offset := r.Timestamp.Sub(meal.Timestamp)
+			switch {
+			case offset < 0 && offset >= -BaselineWindow:
+				// Latest reading before the meal wins
+				resp.Baseline = r.Value
+				seenBaseline = true
+			case offset >= postMealStart && offset <= PostMealEnd:
+				if !seenPeak || r.Value > resp.Peak {
+					resp.Peak = r.Value
+					resp.PeakAfter = int(offset.Minutes())
+					seenPeak = true
+				}
+			}
+		}
+		if !seenPeak {
+			continue
+		}
+
+		if seenBaseline {
+			resp.Rise = resp.Peak - resp.Baseline
+		}
+		resp.Spike = resp.Peak > thresholds.Ranges().High || (seenBaseline && resp.Rise >= spikeRise)
+		out = append(out, resp)
+	}
+	return out
+}
+
+// Aggregate meal responses by food item and by meal type, worst first
+func FoodPatterns(responses []MealResponse) []FoodPattern {
+	type acc struct {
+		meals, spikes, rises int
+		peak, rise           float64
+	}
+	byKey := make(map[string]*acc)
+	add := func(key string, resp MealResponse) {
+		a, ok := byKey[key]
+		if !ok {
+			a = &acc{}
+			byKey[key] = a
+		}
+		a.meals++
+		a.peak += resp.Peak
+		if resp.Baseline > 0 {
+			a.rises++
+			a.rise += resp.Rise
+		}
+		if resp.Spike {
+			a.spikes++
+		}
+	}
+
+	for _, resp := range responses {
+		for _, food := range resp.Meal.FoodItems() {
+			add(food, resp)
+		}
+		if resp.Meal.MealType != "" {
+			add("["+strings.ToLower(resp.Meal.MealType)+"]", resp)
+		}
+	}
+
+	var out []FoodPattern
+	for key, a := range byKey {
+		p := FoodPattern{
+			Food:      key,
+			Meals:     a.meals,
+			Spikes:    a.spikes,
+			AvgPeak:   a.peak / float64(a.meals),
+			SpikeRate: float64(a.spikes) / float64(a.meals) * 100,
+		}
+		if a.rises > 0 {
+			p.AvgRise = a.rise / float64(a.rises)
+		}
+		out = append(out, p)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SpikeRate != out[j].SpikeRate {
+			return out[i].SpikeRate > out[j].SpikeRate
+		}
+		if out[i].Meals != out[j].Meals {
+			return out[i].Meals > out[j].Meals
+		}
+		return out[i].Food < out[j].Food
+	})
+	return out
+}
+
+// Helper function to render food patterns as a prompt table
+func FoodPatternsPrompt(patterns []FoodPattern) string {
+	if len(patterns) == 0 {
+		return "No meals with follow-up readings in this period."
+	}
+
+	var b strings.Builder
+	b.WriteString("Food or [meal type] | meals | spikes | spike rate | avg peak | avg rise\n")
+	for _, p := range patterns {
+		fmt.Fprintf(&b, "%s | %d | %d | %.0f%% | %.0f mg/dL | %+.0f mg/dL\n",
+			p.Food, p.Meals, p.Spikes, p.SpikeRate, p.AvgPeak, p.AvgRise)
+	}
+	return b.String()
+}
+
+// How long after a workout ends readings are still attributed to it
+const postWorkoutWindow = 4 * time.Hour
+
+// Workout Response Struct
+type WorkoutResponse struct {
+	Workout    store.WorkoutLog `json:"workout"`
+	Baseline   float64          `json:"baseline"`
+	Nadir      float64          `json:"nadir"`
+	NadirAfter int              `json:"nadir_after_minutes"`
+	Drop       float64          `json:"drop"`
+	WentLow    bool             `json:"went_low"`
+}
+
+// Exercise Pattern Struct
+type ExercisePattern struct {
+	Type     string  `json:"type"`
+	Sessions int     `json:"sessions"`
+	AvgDrop  float64 `json:"avg_drop"`
+	MaxDrop  float64 `json:"max_drop"`
+	AvgNadir float64 `json:"avg_nadir"`
+	Lows     int     `json:"lows"`
+}
+
+// Pair each workout with the readings before it and in the hours after.
+// Workouts without both a baseline and a follow-up reading are skipped.
+func WorkoutResponses(workouts []store.WorkoutLog, readings []store.GlucoseReading) []WorkoutResponse {
+	var out []WorkoutResponse
+	for _, workout := range workouts {
+		end := workout.Timestamp.Add(time.Duration(workout.DurationMinutes)*time.Minute + postWorkoutWindow)
+		resp := WorkoutResponse{Workout: workout}
+		var seenBaseline, seenNadir bool
+
+		for _, r := range readings {
+			offset := r.Timestamp.Sub(workout.Timestamp)
+			switch {
+			case offset < 0 && offset >= -BaselineWindow:
+				resp.Baseline = r.Value
+				seenBaseline = true
+			case offset >= 0 && !r.Timestamp.After(end):
+				if !seenNadir || r.Value < resp.Nadir {
+					resp.Nadir = r.Value
+					resp.NadirAfter = int(offset.Minutes())
+					seenNadir = true
+				}
+			}
+		}
+		if !seenBaseline || !seenNadir {
+			continue
+		}
+
+		resp.Drop = resp.Baseline - resp.Nadir
+		resp.WentLow = resp.Nadir < thresholds.Ranges().Low
+		out = append(out, resp)
+	}
+	return out
+}
+
+// Aggregate workout responses by exercise type, largest drop first
+func ExercisePatterns(responses []WorkoutResponse) []ExercisePattern {
+	byType := make(map[string]*ExercisePattern)
+	for _, resp := range responses {
+		p, ok := byType[resp.Workout.Type]
+		if !ok {
+			p = &ExercisePattern{Type: resp.Workout.Type}
+			byType[resp.Workout.Type] = p
+		}
+		p.Sessions++
+		p.AvgDrop += resp.Drop
+		p.AvgNadir += resp.Nadir
+		if resp.Drop > p.MaxDrop {
+			p.MaxDrop = resp.Drop
+		}
+		if resp.WentLow {
+			p.Lows++
+		}
+	}
+
+	var out []ExercisePattern
+	for _, p := range byType {
+		p.AvgDrop /= float64(p.Sessions)
+		p.AvgNadir /= float64(p.Sessions)
+		out = append(out, *p)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].AvgDrop != out[j].AvgDrop {
+			return out[i].AvgDrop > out[j].AvgDrop
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// Helper function to render exercise patterns as a prompt table
+func ExercisePatternsPrompt(patterns []ExercisePattern) string {
+	if len(patterns) == 0 {
+		return "No workouts with surrounding readings in this period."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exercise type | sessions | avg drop | max drop | avg lowest | went below %.0f\n", thresholds.Ranges().Low)
+	for _, p := range patterns {
+		fmt.Fprintf(&b, "%s | %d | %.0f mg/dL | %.0f mg/dL | %.0f mg/dL | %d\n",
+			p.Type, p.Sessions, p.AvgDrop, p.MaxDrop, p.AvgNadir, p.Lows)
+	}
+	return b.String()
+}
+
+// Helper function to describe a user's past response to an exercise type
+func ExerciseHistoryNote(patterns []ExercisePattern, exerciseType string) string {
+	exerciseType = strings.ToLower(strings.TrimSpace(exerciseType))
+	for _, p := range patterns {
+		if p.Type != exerciseType {
+			continue
+		}
+		note := fmt.Sprintf("Personal history for %s: blood sugar typically drops %.0f mg/dL (up to %.0f) across %d logged sessions",
+			p.Type, p.AvgDrop, p.MaxDrop, p.Sessions)
+		if p.Lows > 0 {
+			note += fmt.Sprintf(", and went below %.0f mg/dL after %d of them", thresholds.Ranges().Low, p.Lows)
+		}
+		return note
+	}
+	return ""
+}
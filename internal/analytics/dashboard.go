@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// How far back the dashboard looks for alerts, and when data counts as stale
+const (
+	dashboardAlertWindow = 24 * time.Hour
+	dashboardStaleAfter  = 12 * time.Hour
+)
+
+// Dashboard Alert Struct
+type DashboardAlert struct {
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Value     float64   `json:"value,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// Dashboard Struct
+type Dashboard struct {
+	PatientID     string                `json:"patient_id"`
+	LatestReading *store.GlucoseReading `json:"latest_reading"`
+	Stats         GlucoseStats          `json:"stats"`
+	Alerts        []DashboardAlert      `json:"alerts"`
+}
+
+// Build the caregiver dashboard for a patient, with alert messages in the viewer's units
+func BuildDashboard(readings *store.ReadingStore, patientID string, now time.Time, prefs locale.Prefs) Dashboard {
+	dash := Dashboard{PatientID: patientID, Alerts: []DashboardAlert{}}
+
+	from := now.AddDate(0, 0, -14)
+	dash.Stats = ComputeStats(readings.Range(patientID, from, now), from, now)
+
+	latest, ok := readings.Latest(patientID)
+	if ok {
+		dash.LatestReading = &latest
+	}
+	if !ok || now.Sub(latest.Timestamp) > dashboardStaleAfter {
+		dash.Alerts = append(dash.Alerts, DashboardAlert{
+			Type:    "no_data",
+			Message: "No blood sugar reading in the last 12 hours",
+		})
+	}
+
+	rng := thresholds.Ranges()
+	for _, r := range readings.Range(patientID, now.Add(-dashboardAlertWindow), now.Add(time.Second)) {
+		alert := DashboardAlert{Value: r.Value, Timestamp: r.Timestamp}
+		switch {
+		case r.Value < rng.VeryLow:
+			alert.Type, alert.Message = "very_low", fmt.Sprintf("Very low reading (below %s)", prefs.Glucose(rng.VeryLow))
+		case r.Value < rng.Low:
+			alert.Type, alert.Message = "low", fmt.Sprintf("Low reading (below %s)", prefs.Glucose(rng.Low))
+		case r.Value > rng.VeryHigh:
+			alert.Type, alert.Message = "very_high", fmt.Sprintf("Very high reading (above %s)", prefs.Glucose(rng.VeryHigh))
+		default:
+			continue
+		}
+		dash.Alerts = append(dash.Alerts, alert)
+	}
+
+	return dash
+}
@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Day Struct
+//
+// What a user logged over one day, for the end-of-day review.
+type Day struct {
+	Date           string       `json:"date"`
+	Stats          GlucoseStats `json:"stats"`
+	Meals          int          `json:"meals"`
+	Carbs          float64      `json:"carbs"`
+	Hypos          int          `json:"hypos"`
+	Steps          int          `json:"steps"`
+	WorkoutMinutes int          `json:"workout_minutes"`
+	InsulinDoses   int          `json:"insulin_doses"`
+	WaterML        float64      `json:"water_ml"`
+	ScheduledDoses int          `json:"scheduled_doses" jsonschema:"description=Doses a day in your confirmed medication schedule"`
+	MissedDoses    []string     `json:"missed_doses,omitempty"`
+	Adherence      *float64     `json:"medication_adherence,omitempty" jsonschema:"description=Percent of scheduled doses taken, when missed doses were reported"`
+}
+
+// Gather a user's logs for the day containing day, in day's location. missed
+// lists the scheduled medications the user reports missing; nil means not reported.
+func BuildDay(s *store.Stores, userID string, day time.Time, missed []string) Day {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	to := from.AddDate(0, 0, 1)
+
+	d := Day{
+		Date:           from.Format("2006-01-02"),
+		Stats:          ComputeStats(s.Readings.Range(userID, from, to), from, to),
+		Hypos:          len(s.Hypos.Range(userID, from, to)),
+		InsulinDoses:   len(s.Insulin.Range(userID, from, to)),
+		ScheduledDoses: len(s.Schedules.Get(userID).Doses),
+		MissedDoses:    missed,
+	}
+	for _, m := range s.Meals.Range(userID, from, to) {
+		d.Meals++
+		d.Carbs += m.Carbs
+	}
+	for _, a := range s.Activity.Range(userID, from, to) {
+		d.Steps += a.Steps
+	}
+	for _, w := range s.Workouts.Range(userID, from, to) {
+		d.WorkoutMinutes += w.DurationMinutes
+	}
+	for _, w := range s.Water.Range(userID, from, to) {
+		d.WaterML += w.Milliliters
+	}
+	if d.ScheduledDoses > 0 && missed != nil {
+		taken := max(d.ScheduledDoses-len(missed), 0)
+		adherence := round1(float64(taken) / float64(d.ScheduledDoses) * 100)
+		d.Adherence = &adherence
+	}
+	return d
+}
+
+// Helper function to describe the day for a prompt
+func (d Day) PromptSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Glucose:\n%s\n", d.Stats.PromptSummary())
+	fmt.Fprintf(&b, "Lows logged: %d\n", d.Hypos)
+	fmt.Fprintf(&b, "Meals logged: %d (%.0f g carbs)\n", d.Meals, d.Carbs)
+	fmt.Fprintf(&b, "Activity: %d steps, %d workout minutes\n", d.Steps, d.WorkoutMinutes)
+	fmt.Fprintf(&b, "Water: %.0f ml\n", d.WaterML)
+	fmt.Fprintf(&b, "Insulin doses logged: %d\n", d.InsulinDoses)
+	switch {
+	case d.ScheduledDoses == 0:
+		b.WriteString("Medications: no confirmed schedule")
+	case d.Adherence == nil:
+		fmt.Fprintf(&b, "Medications: %d scheduled doses, adherence not reported", d.ScheduledDoses)
+	case len(d.MissedDoses) == 0:
+		fmt.Fprintf(&b, "Medications: all %d scheduled doses taken", d.ScheduledDoses)
+	default:
+		fmt.Fprintf(&b, "Medications: %.0f%% of %d scheduled doses taken; missed %s", *d.Adherence, d.ScheduledDoses, strings.Join(d.MissedDoses, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,224 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Windows used to pair boluses with meals and outcome readings
+const (
+	bolusMealWindow  = 30 * time.Minute
+	outcomeStart     = 3 * time.Hour
+	outcomeEnd       = 5 * time.Hour
+	outcomeTarget    = 4 * time.Hour
+	settledRiseLimit = 30.0
+)
+
+// Ratio Estimates Struct
+type RatioEstimates struct {
+	Days             int     `json:"days"`
+	TotalDailyDose   float64 `json:"total_daily_dose,omitempty"`
+	CarbRatioRule    float64 `json:"carb_ratio_rule,omitempty"`
+	CorrectionRule   float64 `json:"correction_factor_rule,omitempty"`
+	CarbRatioObs     float64 `json:"carb_ratio_observed,omitempty"`
+	CorrectionObs    float64 `json:"correction_factor_observed,omitempty"`
+	MealEvents       int     `json:"meal_events"`
+	CorrectionEvents int     `json:"correction_events"`
+	Confidence       string  `json:"confidence"`
+}
+
+// Estimate carb ratio and correction factor from paired meal, bolus and BG data.
+// Rule-of-thumb values (500 and 1800 rules) come from the total daily dose;
+// observed values are medians over clean meal and correction events.
+func EstimateRatios(meals []store.MealLog, doses []store.InsulinDose, readings []store.GlucoseReading, days int) RatioEstimates {
+	est := RatioEstimates{Days: days}
+
+	// Total daily dose over the days that have any logged insulin
+	byDay := make(map[string]float64)
+	for _, d := range doses {
+		byDay[d.Timestamp.Format("2006-01-02")] += d.Units
+	}
+	if len(byDay) > 0 {
+		var total float64
+		for _, units := range byDay {
+			total += units
+		}
+		est.TotalDailyDose = round1(total / float64(len(byDay)))
+		est.CarbRatioRule = round1(500 / est.TotalDailyDose)
+		est.CorrectionRule = round1(1800 / est.TotalDailyDose)
+	}
+
+	var boluses []store.InsulinDose
+	for _, d := range doses {
+		if _, ok := insulinCurves[strings.ToLower(d.InsulinType)]; ok {
+			boluses = append(boluses, d)
+		}
+	}
+
+	correctionMinBG := thresholds.Current().CorrectionMinBG
+	var carbRatios, corrections []float64
+	usedBolus := make(map[int]bool)
+
+	for i, meal := range meals {
+		if meal.Carbs <= 0 || mealBetween(meals, i, meal.Timestamp, meal.Timestamp.Add(outcomeEnd)) {
+			continue
+		}
+		bi := bolusNear(boluses, meal.Timestamp, bolusMealWindow)
+		if bi < 0 {
+			continue
+		}
+		pre, okPre := readingBefore(readings, meal.Timestamp, BaselineWindow)
+		post, okPost := readingAround(readings, meal.Timestamp, outcomeStart, outcomeEnd, outcomeTarget)
+		if !okPre || !okPost {
+			continue
+		}
+		usedBolus[bi] = true
+
+		units := boluses[bi].Units
+		change := post - pre
+		switch {
+		case est.CorrectionRule > 0:
+			needed := units + change/est.CorrectionRule
+			if needed > 0 {
+				carbRatios = append(carbRatios, meal.Carbs/needed)
+			}
+		case math.Abs(change) <= settledRiseLimit:
+			carbRatios = append(carbRatios, meal.Carbs/units)
+		}
+	}
+
+	for i, bolus := range boluses {
+		if usedBolus[i] || mealBetween(meals, -1, bolus.Timestamp.Add(-time.Hour), bolus.Timestamp.Add(outcomeEnd)) {
+			continue
+		}
+		pre, okPre := readingBefore(readings, bolus.Timestamp, BaselineWindow)
+		post, okPost := readingAround(readings, bolus.Timestamp, outcomeStart, outcomeEnd, outcomeTarget)
+		if !okPre || !okPost || pre < correctionMinBG || post >= pre {
+			continue
+		}
+		corrections = append(corrections, (pre-post)/bolus.Units)
+	}
+
+	est.MealEvents = len(carbRatios)
+	est.CorrectionEvents = len(corrections)
+	if len(carbRatios) > 0 {
+		est.CarbRatioObs = round1(median(carbRatios))
+	}
+	if len(corrections) > 0 {
+		est.CorrectionObs = round1(median(corrections))
+	}
+
+	switch events := est.MealEvents + est.CorrectionEvents; {
+	case events >= 10:
+		est.Confidence = "moderate"
+	case events >= 3:
+		est.Confidence = "low"
+	default:
+		est.Confidence = "insufficient data"
+	}
+
+	return est
+}
+
+// Helper function to check for a meal in a window, ignoring meals[skip]
+func mealBetween(meals []store.MealLog, skip int, from, to time.Time) bool {
+	for i, m := range meals {
+		if i == skip {
+			continue
+		}
+		if !m.Timestamp.Before(from) && m.Timestamp.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to find the bolus closest to a time, or -1
+func bolusNear(boluses []store.InsulinDose, at time.Time, window time.Duration) int {
+	best := -1
+	var bestGap time.Duration
+	for i, d := range boluses {
+		gap := d.Timestamp.Sub(at)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= window && (best < 0 || gap < bestGap) {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+// Helper function to find the last reading before a time
+func readingBefore(readings []store.GlucoseReading, at time.Time, window time.Duration) (float64, bool) {
+	value, ok := 0.0, false
+	for _, r := range readings {
+		if r.Timestamp.Before(at) && !r.Timestamp.Before(at.Add(-window)) {
+			value, ok = r.Value, true
+		}
+	}
+	return value, ok
+}
+
+// Helper function to find the reading closest to a target offset within a window
+func readingAround(readings []store.GlucoseReading, at time.Time, start, end, target time.Duration) (float64, bool) {
+	value, ok := 0.0, false
+	var bestGap time.Duration
+	for _, r := range readings {
+		offset := r.Timestamp.Sub(at)
+		if offset < start || offset > end {
+			continue
+		}
+		gap := offset - target
+		if gap < 0 {
+			gap = -gap
+		}
+		if !ok || gap < bestGap {
+			value, ok, bestGap = r.Value, true, gap
+		}
+	}
+	return value, ok
+}
+
+// Helper function to compute the median of a set of values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Helper function to round to one decimal place
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// Helper function to render ratio estimates as a prompt block
+func (e RatioEstimates) PromptSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Analysis window: %d days\n", e.Days)
+	if e.TotalDailyDose > 0 {
+		fmt.Fprintf(&b, "Average total daily insulin: %.1f units\n", e.TotalDailyDose)
+		fmt.Fprintf(&b, "500 rule carb ratio: 1 unit per %.1f g\n", e.CarbRatioRule)
+		fmt.Fprintf(&b, "1800 rule correction factor: 1 unit per %.1f mg/dL\n", e.CorrectionRule)
+	} else {
+		b.WriteString("No insulin doses logged.\n")
+	}
+	if e.CarbRatioObs > 0 {
+		fmt.Fprintf(&b, "Observed carb ratio: 1 unit per %.1f g (median of %d meals)\n", e.CarbRatioObs, e.MealEvents)
+	}
+	if e.CorrectionObs > 0 {
+		fmt.Fprintf(&b, "Observed correction factor: 1 unit per %.1f mg/dL (median of %d corrections)\n", e.CorrectionObs, e.CorrectionEvents)
+	}
+	fmt.Fprintf(&b, "Confidence: %s", e.Confidence)
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"time"
+
+	"diabeticai-advisor/internal/icd10"
+	"diabeticai-advisor/internal/store"
+)
+
+// Note attached to every export that carries ICD-10 codes
+const icd10Note = "ICD-10 codes are automated suggestions for clinician review only. They are not diagnoses and must be confirmed before use."
+
+// Clinician Export Struct
+type ClinicianExport struct {
+	PatientID        string               `json:"patient_id"`
+	GeneratedAt      time.Time            `json:"generated_at"`
+	From             time.Time            `json:"from"`
+	To               time.Time            `json:"to"`
+	Stats            GlucoseStats         `json:"stats"`
+	AGP              AGPOutput            `json:"agp"`
+	InsulinDoses     []store.InsulinDose  `json:"insulin_doses"`
+	SymptomChecks    []store.SymptomCheck `json:"symptom_checks"`
+	ICD10Suggestions []icd10.Code         `json:"icd10_suggestions"`
+	ICD10Note        string               `json:"icd10_note,omitempty"`
+}
+
+// Build the bundle a patient hands to their clinician for a time window
+func BuildClinicianExport(s *store.Stores, patientID string, from, to time.Time) ClinicianExport {
+	readings := s.Readings.Range(patientID, from, to)
+	export := ClinicianExport{
+		PatientID:        patientID,
+		GeneratedAt:      time.Now(),
+		From:             from,
+		To:               to,
+		Stats:            ComputeStats(readings, from, to),
+		AGP:              ComputeAGP(readings, from, to, 60),
+		InsulinDoses:     s.Insulin.Range(patientID, from, to),
+		SymptomChecks:    s.Symptoms.Range(patientID, from, to),
+		ICD10Suggestions: []icd10.Code{},
+	}
+	if export.InsulinDoses == nil {
+		export.InsulinDoses = []store.InsulinDose{}
+	}
+	if export.SymptomChecks == nil {
+		export.SymptomChecks = []store.SymptomCheck{}
+	}
+
+	// Collect each suggested code once across all symptom checks
+	seen := map[string]bool{}
+	for _, check := range export.SymptomChecks {
+		for _, code := range check.ICD10 {
+			if !seen[code.Code] {
+				seen[code.Code] = true
+				export.ICD10Suggestions = append(export.ICD10Suggestions, code)
+			}
+		}
+	}
+	if len(export.ICD10Suggestions) > 0 {
+		export.ICD10Note = icd10Note
+	}
+	return export
+}
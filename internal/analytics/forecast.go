@@ -0,0 +1,261 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Forecast model settings
+const (
+	forecastStep        = 15 * time.Minute
+	MaxForecastHours    = 4
+	trendWindow         = 30 * time.Minute
+	momentumHorizon     = 30 * time.Minute
+	carbAbsorption      = 3 * time.Hour
+	defaultISF          = 50.0
+	defaultCarbRatio    = 10.0
+	forecastFloor       = 40.0
+	forecastCeiling     = 400.0
+	bandBase            = 10.0
+	bandPerHour         = 10.0
+	bandEffectShare     = 0.1
+	bandDefaultRatioMul = 1.5
+)
+
+// Glucose drop per minute of planned activity, in mg/dL, by intensity
+var activityDropPerMinute = map[string]float64{
+	"light":    0.3,
+	"moderate": 0.6,
+	"vigorous": 0.9,
+}
+
+// Planned Activity Struct
+type PlannedActivity struct {
+	Type            string `json:"type,omitempty" jsonschema:"description=Exercise type, e.g. walking, cycling (optional)"`
+	Intensity       string `json:"intensity,omitempty" jsonschema:"description=Intensity: light, moderate, vigorous (default moderate)"`
+	StartInMinutes  int    `json:"start_in_minutes,omitempty" jsonschema:"description=Minutes from now until it starts"`
+	DurationMinutes int    `json:"duration_minutes" jsonschema:"description=How long it lasts in minutes"`
+}
+
+// Forecast Inputs Struct
+//
+// What the projection is computed from. ISF and CarbRatio fall back to the
+// defaults when unset, which widens the band.
+type ForecastInputs struct {
+	Now         time.Time
+	CurrentBG   float64
+	Readings    []store.GlucoseReading
+	Meals       []store.MealLog
+	Doses       []store.InsulinDose
+	Activity    *PlannedActivity
+	ISF         float64
+	CarbRatio   float64
+	RatioSource string
+	Hours       int
+}
+
+// Where forecast ratios come from
+const (
+	RatiosInput     = "input"
+	RatiosEstimated = "estimated"
+	RatiosDefault   = "default"
+)
+
+// Forecast Point Struct
+type ForecastPoint struct {
+	Minutes int       `json:"minutes" jsonschema:"description=Minutes from now"`
+	Time    time.Time `json:"time"`
+	BG      float64   `json:"bg" jsonschema:"description=Projected blood glucose in mg/dL"`
+	Low     float64   `json:"low" jsonschema:"description=Lower edge of the confidence band in mg/dL"`
+	High    float64   `json:"high" jsonschema:"description=Upper edge of the confidence band in mg/dL"`
+}
+
+// Forecast Struct
+type Forecast struct {
+	StartBG        float64         `json:"start_bg"`
+	TrendPerMinute float64         `json:"trend_mgdl_per_min" jsonschema:"description=Recent rate of change from the last 30 minutes of readings"`
+	InsulinOnBoard float64         `json:"insulin_on_board"`
+	CarbsOnBoard   float64         `json:"carbs_on_board" jsonschema:"description=Grams of logged carbs not yet absorbed"`
+	ISF            float64         `json:"isf" jsonschema:"description=Correction factor used, mg/dL per unit"`
+	CarbRatio      float64         `json:"carb_ratio" jsonschema:"description=Carb ratio used, grams per unit"`
+	RatioSource    string          `json:"ratio_source" jsonschema:"description=Where the ratios came from: input, estimated or default"`
+	Points         []ForecastPoint `json:"points"`
+	Lowest         ForecastPoint   `json:"lowest"`
+	Highest        ForecastPoint   `json:"highest"`
+	LowRiskAt      *time.Time      `json:"low_risk_at,omitempty" jsonschema:"description=First time the band dips below the low threshold"`
+	HighRiskAt     *time.Time      `json:"high_risk_at,omitempty" jsonschema:"description=First time the projection rises above the high threshold"`
+}
+
+// Project blood glucose over the next hours from the recent trend, insulin on board,
+// unabsorbed carbs and planned activity. Each effect is added to the current value:
+//   - the trend carries on for 30 minutes, then stops (other effects take over)
+//   - insulin lowers by ISF for every unit absorbed, on the IOB activity curve
+//   - carbs raise by ISF/carb ratio per gram, absorbed evenly over 3 hours
+//   - activity lowers at a fixed rate per minute by intensity
+//
+// The band widens with time and with the size of the effects, and by half
+// again when default ratios are used.
+func ProjectGlucose(in ForecastInputs) Forecast {
+	hours := min(max(in.Hours, 1), MaxForecastHours)
+	fc := Forecast{
+		StartBG:        in.CurrentBG,
+		TrendPerMinute: round1(glucoseTrend(in.Readings, in.Now)),
+		InsulinOnBoard: ComputeIOB(in.Doses, in.Now).Total,
+		ISF:            in.ISF,
+		CarbRatio:      in.CarbRatio,
+		RatioSource:    in.RatioSource,
+	}
+	if fc.RatioSource == "" {
+		fc.RatioSource = RatiosInput
+	}
+	if fc.ISF <= 0 {
+		fc.ISF, fc.RatioSource = defaultISF, RatiosDefault
+	}
+	if fc.CarbRatio <= 0 {
+		fc.CarbRatio, fc.RatioSource = defaultCarbRatio, RatiosDefault
+	}
+	fc.CarbsOnBoard = round1(carbsUnabsorbed(in.Meals, in.Now))
+
+	rng := thresholds.Ranges()
+	for step := 0; step <= hours*int(time.Hour/forecastStep); step++ {
+		elapsed := time.Duration(step) * forecastStep
+		at := in.Now.Add(elapsed)
+
+		trend := fc.TrendPerMinute * min(elapsed, momentumHorizon).Minutes()
+		insulin := -fc.ISF * (fc.InsulinOnBoard - ComputeIOB(in.Doses, at).Total)
+		carbs := fc.ISF / fc.CarbRatio * (carbsUnabsorbed(in.Meals, in.Now) - carbsUnabsorbed(in.Meals, at))
+		activity := -activityDrop(in.Activity, elapsed)
+
+		bg := in.CurrentBG + trend + insulin + carbs + activity
+		band := bandBase + bandPerHour*elapsed.Hours() + bandEffectShare*(math.Abs(insulin)+math.Abs(carbs)+math.Abs(activity))
+		if fc.RatioSource == RatiosDefault {
+			band *= bandDefaultRatioMul
+		}
+		if step == 0 {
+			band = 0
+		}
+
+		p := ForecastPoint{
+			Minutes: int(elapsed.Minutes()),
+			Time:    at,
+			BG:      clampBG(bg),
+			Low:     clampBG(bg - band),
+			High:    clampBG(bg + band),
+		}
+		fc.Points = append(fc.Points, p)
+		if step == 0 || p.BG < fc.Lowest.BG {
+			fc.Lowest = p
+		}
+		if step == 0 || p.BG > fc.Highest.BG {
+			fc.Highest = p
+		}
+		if fc.LowRiskAt == nil && p.Low < rng.Low {
+			fc.LowRiskAt = &p.Time
+		}
+		if fc.HighRiskAt == nil && p.BG > rng.High {
+			fc.HighRiskAt = &p.Time
+		}
+	}
+	return fc
+}
+
+// Helper function to keep a projection within what a meter can read
+func clampBG(v float64) float64 {
+	return math.Round(min(max(v, forecastFloor), forecastCeiling))
+}
+
+// Rate of change in mg/dL per minute: the least-squares slope of the readings
+// in the last 30 minutes. Zero without at least three readings 10 minutes apart.
+func glucoseTrend(readings []store.GlucoseReading, now time.Time) float64 {
+	var xs, ys []float64
+	for _, r := range readings {
+		if r.Timestamp.After(now) || now.Sub(r.Timestamp) > trendWindow {
+			continue
+		}
+		xs = append(xs, r.Timestamp.Sub(now).Minutes())
+		ys = append(ys, r.Value)
+	}
+	if len(xs) < 3 || slicesSpan(xs) < 10 {
+		return 0
+	}
+
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(ys))
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - mx) * (ys[i] - my)
+		den += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// Helper function to measure the spread of a set of values
+func slicesSpan(vs []float64) float64 {
+	lo, hi := vs[0], vs[0]
+	for _, v := range vs {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return hi - lo
+}
+
+// Grams of carbs from logged meals not yet absorbed at a point in time
+func carbsUnabsorbed(meals []store.MealLog, at time.Time) float64 {
+	var total float64
+	for _, m := range meals {
+		elapsed := at.Sub(m.Timestamp)
+		if m.Carbs <= 0 || elapsed < 0 || elapsed >= carbAbsorption {
+			continue
+		}
+		total += m.Carbs * (1 - elapsed.Hours()/carbAbsorption.Hours())
+	}
+	return total
+}
+
+// Glucose lowered by planned activity between now and elapsed
+func activityDrop(a *PlannedActivity, elapsed time.Duration) float64 {
+	if a == nil || a.DurationMinutes <= 0 {
+		return 0
+	}
+	rate, ok := activityDropPerMinute[strings.ToLower(a.Intensity)]
+	if !ok {
+		rate = activityDropPerMinute["moderate"]
+	}
+	start := float64(a.StartInMinutes)
+	active := min(elapsed.Minutes(), start+float64(a.DurationMinutes)) - start
+	return rate * max(active, 0)
+}
+
+// Helper function to render the forecast as a prompt block
+func (fc Forecast) PromptSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Current: %.0f mg/dL, trend %+.1f mg/dL per minute\n", fc.StartBG, fc.TrendPerMinute)
+	fmt.Fprintf(&b, "Insulin on board: %.1f units; carbs still absorbing: %.0f g\n", fc.InsulinOnBoard, fc.CarbsOnBoard)
+	fmt.Fprintf(&b, "Ratios (%s): 1 unit per %.0f mg/dL, 1 unit per %.0f g\n", fc.RatioSource, fc.ISF, fc.CarbRatio)
+	b.WriteString("Projection (mg/dL, with confidence band):\n")
+	for _, p := range fc.Points {
+		if p.Minutes%60 == 0 || p.Minutes == 30 {
+			fmt.Fprintf(&b, "- +%d min: %.0f (%.0f-%.0f)\n", p.Minutes, p.BG, p.Low, p.High)
+		}
+	}
+	fmt.Fprintf(&b, "Lowest: %.0f at +%d min; highest: %.0f at +%d min\n", fc.Lowest.BG, fc.Lowest.Minutes, fc.Highest.BG, fc.Highest.Minutes)
+	if fc.LowRiskAt != nil {
+		fmt.Fprintf(&b, "Low risk: the band dips below %.0f mg/dL from +%.0f min\n", thresholds.Ranges().Low, fc.LowRiskAt.Sub(fc.Points[0].Time).Minutes())
+	}
+	if fc.HighRiskAt != nil {
+		fmt.Fprintf(&b, "High: the projection rises above %.0f mg/dL from +%.0f min\n", thresholds.Ranges().High, fc.HighRiskAt.Sub(fc.Points[0].Time).Minutes())
+	}
+	return strings.TrimSpace(b.String())
+}
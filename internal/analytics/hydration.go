@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Default daily water target
+const DailyWaterTargetML = 2000
+
+// Hours of the day over which the target is spread
+const (
+	hydrationDayStart = 8
+	hydrationDayEnd   = 20
+)
+
+// Hydration Struct
+type Hydration struct {
+	Date       string  `json:"date"`
+	TotalML    float64 `json:"total_ml"`
+	TargetML   float64 `json:"target_ml"`
+	ExpectedML float64 `json:"expected_ml" jsonschema:"description=Intake expected by this time of day to stay on track"`
+	Percent    float64 `json:"percent_of_target"`
+	Entries    int     `json:"entries"`
+}
+
+// Sum a user's water intake for the day containing now, in the user's timezone
+func TodayHydration(water *store.LogStore[store.WaterLog], userID string, now time.Time, loc *time.Location) Hydration {
+	now = now.In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	h := Hydration{Date: start.Format("2006-01-02"), TargetML: DailyWaterTargetML}
+	for _, w := range water.Range(userID, start, now.Add(time.Second)) {
+		h.TotalML += w.Milliliters
+		h.Entries++
+	}
+
+	// Spread the target evenly over the waking day
+	elapsed := now.Sub(start.Add(hydrationDayStart*time.Hour)).Hours() / (hydrationDayEnd - hydrationDayStart)
+	h.ExpectedML = math.Round(h.TargetML * math.Max(0, math.Min(1, elapsed)))
+	h.Percent = math.Round(h.TotalML / h.TargetML * 100)
+	return h
+}
+
+// Report whether intake is well behind where it should be by now
+func (h Hydration) Behind() bool {
+	return h.ExpectedML > 0 && h.TotalML < h.ExpectedML*0.75
+}
+
+// Helper function to describe today's intake for a prompt
+func (h Hydration) PromptSummary() string {
+	if h.Entries == 0 {
+		return "Water logged today: none"
+	}
+	note := ""
+	if h.Behind() {
+		note = " (behind schedule)"
+	}
+	return fmt.Sprintf("Water logged today: %.0f ml of a %.0f ml target%s", h.TotalML, h.TargetML, note)
+}
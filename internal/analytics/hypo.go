@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// How far back to look for a likely trigger of a hypo
+const (
+	hypoInsulinLookback  = 4 * time.Hour
+	hypoMealLookback     = 4 * time.Hour
+	hypoWorkoutLookback  = 12 * time.Hour
+	hypoEpisodeGap       = 30 * time.Minute
+	hypoOvertreatedCarbs = 30
+)
+
+// Hypo Patterns Struct
+type HypoPatterns struct {
+	Events        int            `json:"events"`
+	Severe        int            `json:"severe" jsonschema:"description=Events below 54 mg/dL"`
+	NeededHelp    int            `json:"needed_help"`
+	AverageLowest float64        `json:"average_lowest"`
+	ByCause       map[string]int `json:"by_cause"`
+	ByPeriod      map[string]int `json:"by_period" jsonschema:"description=Events by time of day: overnight, morning, afternoon, evening"`
+	AfterInsulin  int            `json:"after_insulin" jsonschema:"description=Events within 4 hours of a logged insulin dose"`
+	AfterExercise int            `json:"after_exercise" jsonschema:"description=Events within 12 hours of a logged workout"`
+	NoRecentMeal  int            `json:"no_recent_meal" jsonschema:"description=Events with no meal logged in the previous 4 hours"`
+	Overtreated   int            `json:"overtreated" jsonschema:"description=Events treated with more than 30 g of carbs"`
+	UnloggedLows  int            `json:"unlogged_lows" jsonschema:"description=Low reading episodes with no hypo event logged"`
+	Recurring     []string       `json:"recurring" jsonschema:"description=Patterns seen in at least 2 events and a third of them"`
+}
+
+// Helper function to name the time of day
+func dayPeriod(t time.Time) string {
+	switch h := t.Hour(); {
+	case h < 6:
+		return "overnight"
+	case h < 12:
+		return "morning"
+	case h < 18:
+		return "afternoon"
+	default:
+		return "evening"
+	}
+}
+
+// Look for recurring causes and triggers across a user's hypo events.
+// Times of day are read in loc.
+func AnalyzeHypos(s *store.Stores, userID string, from, to time.Time, loc *time.Location) HypoPatterns {
+	p := HypoPatterns{ByCause: map[string]int{}, ByPeriod: map[string]int{}, Recurring: []string{}}
+	events := s.Hypos.Range(userID, from, to)
+	p.Events = len(events)
+
+	for _, e := range events {
+		if e.LowestBG > 0 && e.LowestBG < thresholds.Ranges().VeryLow {
+			p.Severe++
+		}
+		if e.NeededHelp {
+			p.NeededHelp++
+		}
+		if e.TreatedCarbs > hypoOvertreatedCarbs {
+			p.Overtreated++
+		}
+		p.AverageLowest += e.LowestBG
+		cause := e.Cause
+		if cause == "" {
+			cause = "unknown"
+		}
+		p.ByCause[cause]++
+		p.ByPeriod[dayPeriod(e.Timestamp.In(loc))]++
+
+		if len(s.Insulin.Range(userID, e.Timestamp.Add(-hypoInsulinLookback), e.Timestamp)) > 0 {
+			p.AfterInsulin++
+		}
+		if len(s.Workouts.Range(userID, e.Timestamp.Add(-hypoWorkoutLookback), e.Timestamp)) > 0 {
+			p.AfterExercise++
+		}
+		if len(s.Meals.Range(userID, e.Timestamp.Add(-hypoMealLookback), e.Timestamp)) == 0 {
+			p.NoRecentMeal++
+		}
+	}
+	if p.Events > 0 {
+		p.AverageLowest /= float64(p.Events)
+	}
+	p.UnloggedLows = unloggedLows(s.Readings.Range(userID, from, to), events)
+
+	recurring := func(count int, pattern string) {
+		if count >= 2 && count*3 >= p.Events {
+			p.Recurring = append(p.Recurring, fmt.Sprintf("%s (%d of %d)", pattern, count, p.Events))
+		}
+	}
+	for _, cause := range sortedKeys(p.ByCause) {
+		if cause != "unknown" {
+			recurring(p.ByCause[cause], "suspected cause: "+strings.ReplaceAll(cause, "_", " "))
+		}
+	}
+	for _, period := range sortedKeys(p.ByPeriod) {
+		recurring(p.ByPeriod[period], "happens "+period)
+	}
+	recurring(p.AfterInsulin, "within 4 hours of insulin")
+	recurring(p.AfterExercise, "within 12 hours of exercise")
+	recurring(p.NoRecentMeal, "no meal logged in the 4 hours before")
+	recurring(p.Overtreated, "treated with more than 30 g of carbs")
+	return p
+}
+
+// Helper function to count low reading episodes with no hypo event nearby
+func unloggedLows(readings []store.GlucoseReading, events []store.HypoEvent) int {
+	count := 0
+	var episodeEnd time.Time
+	low := thresholds.Ranges().Low
+	for _, r := range readings {
+		if r.Value >= low {
+			continue
+		}
+		inEpisode := !episodeEnd.IsZero() && r.Timestamp.Sub(episodeEnd) <= hypoEpisodeGap
+		episodeEnd = r.Timestamp
+		if inEpisode {
+			continue
+		}
+		logged := false
+		for _, e := range events {
+			if d := e.Timestamp.Sub(r.Timestamp); d > -2*time.Hour && d < 2*time.Hour {
+				logged = true
+				break
+			}
+		}
+		if !logged {
+			count++
+		}
+	}
+	return count
+}
+
+// Helper function to list map keys in order
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Helper function to render the patterns as prompt lines
+func (p HypoPatterns) PromptSummary() string {
+	if p.Events == 0 {
+		return fmt.Sprintf("No hypo events logged. Low reading episodes without a logged event: %d.", p.UnloggedLows)
+	}
+	var causes, periods []string
+	for _, k := range sortedKeys(p.ByCause) {
+		causes = append(causes, fmt.Sprintf("%s %d", k, p.ByCause[k]))
+	}
+	for _, k := range sortedKeys(p.ByPeriod) {
+		periods = append(periods, fmt.Sprintf("%s %d", k, p.ByPeriod[k]))
+	}
+	recurring := "none"
+	if len(p.Recurring) > 0 {
+		recurring = strings.Join(p.Recurring, "; ")
+	}
+	return fmt.Sprintf(`Hypo events: %d (severe below 54 mg/dL: %d, needed help: %d), average lowest %.0f mg/dL
+Suspected causes: %s
+Time of day: %s
+Within 4 h of insulin: %d, within 12 h of exercise: %d, no meal in the 4 h before: %d, treated with over 30 g carbs: %d
+Low reading episodes without a logged event: %d
+Recurring patterns: %s`,
+		p.Events, p.Severe, p.NeededHelp, p.AverageLowest, strings.Join(causes, ", "), strings.Join(periods, ", "),
+		p.AfterInsulin, p.AfterExercise, p.NoRecentMeal, p.Overtreated, p.UnloggedLows, recurring)
+}
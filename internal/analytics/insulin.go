@@ -0,0 +1,155 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Duration-of-action curve for an insulin type
+type InsulinCurve struct {
+	DIA  time.Duration
+	Peak time.Duration
+}
+
+// Default activity curves by insulin type.
+// Long-acting (basal) insulin is left out and never counts towards IOB.
+var insulinCurves = map[string]InsulinCurve{
+	"ultra_rapid": {DIA: 5 * time.Hour, Peak: 55 * time.Minute},
+	"rapid":       {DIA: 5 * time.Hour, Peak: 75 * time.Minute},
+	"short":       {DIA: 6 * time.Hour, Peak: 150 * time.Minute},
+}
+
+// Longest DIA across the configured curves, used to bound dose lookups
+func maxDIA() time.Duration {
+	var longest time.Duration
+	for _, c := range insulinCurves {
+		longest = max(longest, c.DIA)
+	}
+	return longest
+}
+
+// Helper function to override insulin curves from config.
+// Format: "rapid=4.5h:75m,short=6h:150m" (DIA:peak per type).
+func LoadInsulinCurves(spec string) error {
+	if spec == "" {
+		return nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		name, values, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("invalid insulin curve %q", entry)
+		}
+		diaStr, peakStr, ok := strings.Cut(values, ":")
+		if !ok {
+			return fmt.Errorf("invalid insulin curve %q: want DIA:peak", entry)
+		}
+		dia, err := time.ParseDuration(diaStr)
+		if err != nil {
+			return fmt.Errorf("invalid DIA for %s: %w", name, err)
+		}
+		peak, err := time.ParseDuration(peakStr)
+		if err != nil {
+			return fmt.Errorf("invalid peak for %s: %w", name, err)
+		}
+		if peak <= 0 || peak >= dia/2 {
+			return fmt.Errorf("insulin curve %s: peak must be less than half the DIA", name)
+		}
+		insulinCurves[name] = InsulinCurve{DIA: dia, Peak: peak}
+	}
+	return nil
+}
+
+// Fraction of a dose still active after elapsed time, using the
+// exponential insulin activity model (as in oref0 and Loop).
+func (c InsulinCurve) Remaining(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 1
+	}
+	if elapsed >= c.DIA {
+		return 0
+	}
+
+	t := elapsed.Minutes()
+	td := c.DIA.Minutes()
+	tp := c.Peak.Minutes()
+
+	tau := tp * (1 - tp/td) / (1 - 2*tp/td)
+	a := 2 * tau / td
+	s := 1 / (1 - a + (1+a)*math.Exp(-td/tau))
+
+	return 1 - s*(1-a)*((t*t/(tau*td*(1-a))-t/tau-1)*math.Exp(-t/tau)+1)
+}
+
+// Active Dose Struct
+type ActiveDose struct {
+	Dose           store.InsulinDose `json:"dose"`
+	RemainingUnits float64           `json:"remaining_units"`
+}
+
+// IOB Struct
+type InsulinOnBoard struct {
+	AsOf  time.Time    `json:"as_of"`
+	Total float64      `json:"total_units"`
+	Doses []ActiveDose `json:"active_doses"`
+}
+
+// Compute insulin on board at a point in time from logged doses
+func ComputeIOB(doses []store.InsulinDose, at time.Time) InsulinOnBoard {
+	iob := InsulinOnBoard{AsOf: at, Doses: []ActiveDose{}}
+	for _, d := range doses {
+		curve, ok := insulinCurves[strings.ToLower(d.InsulinType)]
+		if !ok || d.Timestamp.After(at) {
+			continue
+		}
+
+		remaining := d.Units * curve.Remaining(at.Sub(d.Timestamp))
+		if remaining < 0.05 {
+			continue
+		}
+		iob.Total += remaining
+		iob.Doses = append(iob.Doses, ActiveDose{Dose: d, RemainingUnits: math.Round(remaining*100) / 100})
+	}
+	iob.Total = math.Round(iob.Total*100) / 100
+	return iob
+}
+
+// Helper function to compute a user's current IOB from the dose log
+func CurrentIOB(doses *store.LogStore[store.InsulinDose], userID string, at time.Time) InsulinOnBoard {
+	return ComputeIOB(RecentDoses(doses, userID, at), at)
+}
+
+// Helper function to fetch the logged doses that may still be active at a point in time
+func RecentDoses(doses *store.LogStore[store.InsulinDose], userID string, at time.Time) []store.InsulinDose {
+	return doses.Range(userID, at.Add(-maxDIA()), at.Add(time.Second))
+}
+
+// Helper function to render IOB as a prompt line
+func (iob InsulinOnBoard) PromptSummary() string {
+	if iob.Total == 0 {
+		return "Insulin on board: none logged in the active window."
+	}
+	return fmt.Sprintf("Insulin on board: %.1f units still active from %d recent dose(s). Do not suggest additional correction insulin while this is active (insulin stacking).",
+		iob.Total, len(iob.Doses))
+}
+
+// Helper function to classify hypoglycemia risk from BG and active insulin
+func HypoRiskLevel(bg, iob float64) string {
+	table := thresholds.Current()
+	risk := table.HypoRisk
+	switch {
+	case bg < table.Range(thresholds.Adult).Low:
+		return "high"
+	case bg < risk.NearLow && iob >= risk.NearLowIOB:
+		return "high"
+	case bg < risk.NearLow, bg < risk.Watch && iob >= risk.WatchIOB:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
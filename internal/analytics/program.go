@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Program levels, easiest first
+const (
+	MinProgramLevel = 1
+	MaxProgramLevel = 5
+)
+
+// Adherence thresholds for stepping a program up or down, in percent
+const (
+	progressAdherence = 80
+	regressAdherence  = 50
+)
+
+// Mark the sessions of a program week completed by logged workouts in that
+// week, then score adherence and the blood sugar response. A workout of the
+// planned type is preferred; otherwise any unused workout counts.
+func TrackWeek(week store.ProgramWeek, workouts []store.WorkoutLog, readings []store.GlucoseReading) store.ProgramWeek {
+	var inWeek []store.WorkoutLog
+	for _, w := range workouts {
+		if !w.Timestamp.Before(week.Start) && w.Timestamp.Before(week.End()) {
+			inWeek = append(inWeek, w)
+		}
+	}
+
+	used := make([]bool, len(inWeek))
+	var matched []store.WorkoutLog
+	sessions := make([]store.ProgramSession, len(week.Sessions))
+	copy(sessions, week.Sessions)
+	for i := range sessions {
+		sessions[i].Completed = nil
+		idx := -1
+		for j, w := range inWeek {
+			if used[j] {
+				continue
+			}
+			if strings.EqualFold(w.Type, sessions[i].Type) {
+				idx = j
+				break
+			}
+			if idx < 0 {
+				idx = j
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+		used[idx] = true
+		ts := inWeek[idx].Timestamp
+		sessions[i].Completed = &ts
+		matched = append(matched, inWeek[idx])
+	}
+	week.Sessions = sessions
+
+	week.Adherence = 0
+	if len(sessions) > 0 {
+		week.Adherence = round1(float64(len(matched)) / float64(len(sessions)) * 100)
+	}
+
+	week.AvgDrop, week.Lows = 0, 0
+	responses := WorkoutResponses(matched, readings)
+	for _, resp := range responses {
+		week.AvgDrop += resp.Drop
+		if resp.WentLow {
+			week.Lows++
+		}
+	}
+	if len(responses) > 0 {
+		week.AvgDrop = round1(week.AvgDrop / float64(len(responses)))
+	}
+	return week
+}
+
+// Choose the next week's level from a tracked week. A low after exercise or
+// poor adherence steps down, high adherence with no lows steps up.
+func NextProgramLevel(week store.ProgramWeek) (int, string) {
+	level := week.Level
+	switch {
+	case week.Lows > 0:
+		level--
+		return clampLevel(level), fmt.Sprintf("Eased off: blood sugar went below %.0f mg/dL after %d session(s) last week", thresholds.Ranges().Low, week.Lows)
+	case week.Adherence < regressAdherence:
+		level--
+		return clampLevel(level), fmt.Sprintf("Eased off: %.0f%% of last week's sessions were completed", week.Adherence)
+	case week.Adherence >= progressAdherence:
+		level++
+		if clampLevel(level) == week.Level {
+			return week.Level, "Held at the top level after a full week"
+		}
+		return clampLevel(level), fmt.Sprintf("Stepped up: %.0f%% of last week's sessions were completed with no lows", week.Adherence)
+	default:
+		return clampLevel(level), fmt.Sprintf("Held steady: %.0f%% of last week's sessions were completed", week.Adherence)
+	}
+}
+
+// Helper function to describe a tracked week for a prompt
+func ProgramWeekPrompt(week store.ProgramWeek) string {
+	done := 0
+	for _, s := range week.Sessions {
+		if s.Completed != nil {
+			done++
+		}
+	}
+	note := fmt.Sprintf("Week %d (level %d): %d of %d sessions completed (%.0f%%)", week.Number, week.Level, done, len(week.Sessions), week.Adherence)
+	if week.AvgDrop != 0 {
+		note += fmt.Sprintf(", blood sugar dropped %.0f mg/dL on average", week.AvgDrop)
+	}
+	if week.Lows > 0 {
+		note += fmt.Sprintf(", %d session(s) were followed by a low", week.Lows)
+	}
+	return note
+}
+
+func clampLevel(level int) int {
+	return min(max(level, MinProgramLevel), MaxProgramLevel)
+}
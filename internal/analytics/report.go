@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Months of A1C estimates shown in a monthly report, ending with the report month
+const reportTrajectoryMonths = 3
+
+// Monthly Report Struct
+type MonthlyReport struct {
+	UserID     string        `json:"user_id"`
+	Month      string        `json:"month" jsonschema:"description=Report month as YYYY-MM"`
+	Stats      GlucoseStats  `json:"stats"`
+	Weeks      []WeekInRange `json:"weeks" jsonschema:"description=Time in range for each week of the month, for charting"`
+	Trajectory []A1CEstimate `json:"a1c_trajectory" jsonschema:"description=Estimated A1C for the report month and the months before it, oldest first"`
+	Insights   []string      `json:"insights,omitempty" jsonschema:"description=Top patterns and suggestions from the trend analysis"`
+}
+
+// Week In Range Struct
+type WeekInRange struct {
+	From        time.Time `json:"from"`
+	Readings    int       `json:"readings"`
+	TimeInRange float64   `json:"time_in_range"`
+	TimeBelow   float64   `json:"time_below_range"`
+	TimeAbove   float64   `json:"time_above_range"`
+}
+
+// A1C Estimate Struct
+type A1CEstimate struct {
+	Month    string  `json:"month"`
+	Readings int     `json:"readings"`
+	GMI      float64 `json:"gmi,omitempty"`
+}
+
+// Build a user's report for the calendar month containing month, in month's location.
+// Weeks start on the 1st, 8th, 15th and 22nd; the last one runs to the end of the month.
+func BuildMonthlyReport(s *store.Stores, userID string, month time.Time) MonthlyReport {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	to := from.AddDate(0, 1, 0)
+	report := MonthlyReport{
+		UserID: userID,
+		Month:  from.Format("2006-01"),
+		Stats:  ComputeStats(s.Readings.Range(userID, from, to), from, to),
+	}
+
+	for i := range 4 {
+		start := from.AddDate(0, 0, 7*i)
+		end := start.AddDate(0, 0, 7)
+		if i == 3 {
+			end = to
+		}
+		week := ComputeStats(s.Readings.Range(userID, start, end), start, end)
+		report.Weeks = append(report.Weeks, WeekInRange{
+			From:        start,
+			Readings:    week.Count,
+			TimeInRange: week.TimeInRange,
+			TimeBelow:   week.TimeBelow,
+			TimeAbove:   week.TimeAbove,
+		})
+	}
+
+	for i := reportTrajectoryMonths - 1; i >= 0; i-- {
+		start := from.AddDate(0, -i, 0)
+		end := start.AddDate(0, 1, 0)
+		stats := ComputeStats(s.Readings.Range(userID, start, end), start, end)
+		report.Trajectory = append(report.Trajectory, A1CEstimate{
+			Month:    start.Format("2006-01"),
+			Readings: stats.Count,
+			GMI:      stats.GMI,
+		})
+	}
+	return report
+}
@@ -0,0 +1,30 @@
+package analytics
+
+import (
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Build a user's rollup for the calendar day containing day
+func BuildDailyRollup(s *store.Stores, userID string, day time.Time) store.DailyRollup {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	to := from.AddDate(0, 0, 1)
+	stats := ComputeStats(s.Readings.Range(userID, from, to), from, to)
+
+	rollup := store.DailyRollup{
+		UserID:      userID,
+		Date:        from.Format("2006-01-02"),
+		Readings:    stats.Count,
+		Mean:        stats.Mean,
+		TimeInRange: stats.TimeInRange,
+		TimeBelow:   stats.TimeBelow,
+		TimeAbove:   stats.TimeAbove,
+		CV:          stats.CV,
+		Timestamp:   from,
+	}
+	for _, w := range s.Water.Range(userID, from, to) {
+		rollup.WaterML += w.Milliliters
+	}
+	return rollup
+}
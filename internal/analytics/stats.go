@@ -0,0 +1,128 @@
+// Package analytics computes glucose, meal, exercise and insulin metrics from stored logs.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Glucose Stats Struct
+type GlucoseStats struct {
+	From          time.Time        `json:"from"`
+	To            time.Time        `json:"to"`
+	Count         int              `json:"count"`
+	Mean          float64          `json:"mean"`
+	SD            float64          `json:"sd"`
+	CV            float64          `json:"cv"`
+	TimeInRange   float64          `json:"time_in_range"`
+	TimeBelow     float64          `json:"time_below_range"`
+	TimeVeryLow   float64          `json:"time_very_low"`
+	TimeAbove     float64          `json:"time_above_range"`
+	TimeVeryHigh  float64          `json:"time_very_high"`
+	GMI           float64          `json:"gmi,omitempty" jsonschema:"description=Glucose management indicator: the A1C (%) the mean glucose predicts"`
+	GlucoseTarget string           `json:"target_range"`
+	Population    string           `json:"population"`
+	Ranges        thresholds.Range `json:"ranges" jsonschema:"description=Glucose bands and goals the percentages are measured against"`
+	Version       string           `json:"thresholds_version"`
+}
+
+// Compute variability and time-in-range metrics over a set of readings,
+// against the adult ranges.
+func ComputeStats(readings []store.GlucoseReading, from, to time.Time) GlucoseStats {
+	return ComputeStatsFor(readings, from, to, thresholds.Adult)
+}
+
+// Compute variability and time-in-range metrics against a population's ranges.
+// Percentages are the share of readings falling in each band.
+func ComputeStatsFor(readings []store.GlucoseReading, from, to time.Time, population string) GlucoseStats {
+	table := thresholds.Current()
+	if !table.HasPopulation(population) {
+		population = thresholds.Adult
+	}
+	rng := table.Range(population)
+	stats := GlucoseStats{
+		From:          from,
+		To:            to,
+		Count:         len(readings),
+		GlucoseTarget: fmt.Sprintf("%.0f-%.0f mg/dL", rng.Low, rng.High),
+		Population:    population,
+		Ranges:        rng,
+		Version:       table.Version,
+	}
+	if len(readings) == 0 {
+		return stats
+	}
+
+	var sum float64
+	var inRange, below, veryLow, above, veryHigh int
+	for _, r := range readings {
+		sum += r.Value
+		switch {
+		case r.Value < rng.VeryLow:
+			veryLow++
+			below++
+		case r.Value < rng.Low:
+			below++
+		case r.Value > rng.VeryHigh:
+			veryHigh++
+			above++
+		case r.Value > rng.High:
+			above++
+		default:
+			inRange++
+		}
+	}
+
+	n := float64(len(readings))
+	stats.Mean = sum / n
+
+	var sq float64
+	for _, r := range readings {
+		sq += (r.Value - stats.Mean) * (r.Value - stats.Mean)
+	}
+	if len(readings) > 1 {
+		stats.SD = math.Sqrt(sq / (n - 1))
+	}
+	if stats.Mean > 0 {
+		stats.CV = stats.SD / stats.Mean * 100
+	}
+	stats.GMI = EstimateA1C(stats.Mean)
+
+	stats.TimeInRange = float64(inRange) / n * 100
+	stats.TimeBelow = float64(below) / n * 100
+	stats.TimeVeryLow = float64(veryLow) / n * 100
+	stats.TimeAbove = float64(above) / n * 100
+	stats.TimeVeryHigh = float64(veryHigh) / n * 100
+
+	return stats
+}
+
+// Estimate A1C (%) from mean glucose in mg/dL with the glucose management
+// indicator formula (Bergenstal et al., Diabetes Care 2018)
+func EstimateA1C(mean float64) float64 {
+	return math.Round((3.31+0.02392*mean)*10) / 10
+}
+
+// Helper function to render stats as a prompt block
+func (s GlucoseStats) PromptSummary() string {
+	if s.Count == 0 {
+		return "No stored readings in this period."
+	}
+
+	return fmt.Sprintf(`Readings: %d (%s to %s)
+Mean glucose: %.1f mg/dL
+Standard deviation: %.1f mg/dL
+Coefficient of variation: %.1f%% (target <36%%)
+Time in range %s: %.1f%% (target >%.0f%%)
+Time below %.0f mg/dL: %.1f%% (of which below %.0f: %.1f%%)
+Time above %.0f mg/dL: %.1f%% (of which above %.0f: %.1f%%)`,
+		s.Count, s.From.Format("Jan 2"), s.To.Format("Jan 2"),
+		s.Mean, s.SD, s.CV,
+		s.GlucoseTarget, s.TimeInRange, s.Ranges.InRangeGoal,
+		s.Ranges.Low, s.TimeBelow, s.Ranges.VeryLow, s.TimeVeryLow,
+		s.Ranges.High, s.TimeAbove, s.Ranges.VeryHigh, s.TimeVeryHigh)
+}
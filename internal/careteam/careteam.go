@@ -0,0 +1,223 @@
+// Package careteam posts daily summaries and critical alerts for consenting
+// patients into a clinic's Slack or Discord channel, and answers staff
+// questions about those patients from slash commands.
+package careteam
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// How recent a reading must be to raise a critical alert
+const alertWindow = 30 * time.Minute
+
+// Window covered by the daily summary and the stats command
+const digestWindow = 24 * time.Hour
+
+// Channel is somewhere the bot posts messages
+type Channel interface {
+	Name() string
+	Post(ctx context.Context, text string) error
+}
+
+// Config Struct
+type Config struct {
+	TeamID            string
+	Staff             []string
+	SlackWebhookURL   string
+	SlackSigningKey   string
+	DiscordWebhookURL string
+	DiscordPublicKey  string
+}
+
+// Care team bot. Patients consent by sharing their data with TeamID; only
+// their data is posted or answered for.
+type Bot struct {
+	TeamID     string
+	SlackKey   string
+	DiscordKey string
+	stores     *store.Stores
+	staff      []string
+	channels   []Channel
+}
+
+// Create a bot from config. Staff entries are "<platform>:<user id>", e.g. "slack:U024BE7LH".
+func New(cfg Config, stores *store.Stores) (*Bot, error) {
+	b := &Bot{TeamID: cfg.TeamID, SlackKey: cfg.SlackSigningKey, DiscordKey: cfg.DiscordPublicKey, stores: stores}
+	for _, entry := range cfg.Staff {
+		platform, id, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || (platform != Slack && platform != Discord) {
+			return nil, fmt.Errorf("invalid care team staff entry %q: want slack:<id> or discord:<id>", entry)
+		}
+		b.staff = append(b.staff, platform+":"+id)
+	}
+	if cfg.SlackWebhookURL != "" {
+		b.channels = append(b.channels, newWebhook(Slack, cfg.SlackWebhookURL))
+	}
+	if cfg.DiscordWebhookURL != "" {
+		b.channels = append(b.channels, newWebhook(Discord, cfg.DiscordWebhookURL))
+	}
+	return b, nil
+}
+
+// Report whether the bot has anywhere to post
+func (b *Bot) Enabled() bool {
+	return b != nil && len(b.channels) > 0
+}
+
+// Report whether a chat user may query patients
+func (b *Bot) IsStaff(platform, userID string) bool {
+	return userID != "" && slices.Contains(b.staff, platform+":"+userID)
+}
+
+// List the patients who have shared their data with the care team
+func (b *Bot) Patients() []string {
+	_, received := b.stores.Shares.List(b.TeamID)
+	var patients []string
+	for _, grant := range received {
+		if !slices.Contains(patients, grant.OwnerID) {
+			patients = append(patients, grant.OwnerID)
+		}
+	}
+	sort.Strings(patients)
+	return patients
+}
+
+// Post a message to every configured channel
+func (b *Bot) Post(ctx context.Context, text string) error {
+	var failed []string
+	for _, c := range b.channels {
+		if err := c.Post(ctx, text); err != nil {
+			log.Printf("Care team post to %s failed: %v", c.Name(), err)
+			failed = append(failed, c.Name())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to post to %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Post one summary of the last day for every consenting patient
+func (b *Bot) PostDigests(ctx context.Context, now time.Time) error {
+	patients := b.Patients()
+	if len(patients) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("*Daily summary* for %d patient(s), last 24 hours", len(patients))}
+	for _, userID := range patients {
+		lines = append(lines, "• "+b.summary(userID, now))
+	}
+	return b.Post(ctx, strings.Join(lines, "\n"))
+}
+
+// Post an alert for each consenting patient whose latest reading is very low
+// or very high. Each reading is claimed in the shared store first, so it is
+// posted once across replicas. Returns the number of alerts posted.
+func (b *Bot) PostAlerts(ctx context.Context, claims kv.Store, now time.Time) (int, error) {
+	rng := thresholds.Ranges()
+	posted := 0
+	for _, userID := range b.Patients() {
+		latest, ok := b.stores.Readings.Latest(userID)
+		if !ok || now.Sub(latest.Timestamp) > alertWindow {
+			continue
+		}
+
+		prefs := b.prefs(userID)
+		var msg string
+		switch {
+		case latest.Value < rng.VeryLow:
+			msg = fmt.Sprintf(":rotating_light: *Very low* reading for %s: %s at %s", userID, prefs.Glucose(latest.Value), prefs.Clock(latest.Timestamp))
+		case latest.Value > rng.VeryHigh:
+			msg = fmt.Sprintf(":rotating_light: *Very high* reading for %s: %s at %s", userID, prefs.Glucose(latest.Value), prefs.Clock(latest.Timestamp))
+		default:
+			continue
+		}
+
+		key := fmt.Sprintf("careteam:%s:%s:%d", b.TeamID, userID, latest.Timestamp.Unix())
+		won, err := kv.Claim(ctx, claims, key, 2*alertWindow)
+		if err != nil {
+			log.Printf("Care team alert dedup unavailable, posting anyway: %v", err)
+		} else if !won {
+			continue
+		}
+		if err := b.Post(ctx, msg); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+// Answer a slash command from a chat user. Text is the command's argument,
+// such as "stats alice", "latest alice" or "patients".
+func (b *Bot) Answer(platform, staffID, text string, now time.Time) string {
+	if !b.IsStaff(platform, staffID) {
+		return "You are not authorized to query patient data. Ask an administrator to add you to the care team staff."
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return usage
+	}
+	command := strings.ToLower(fields[0])
+	if command == "patients" {
+		patients := b.Patients()
+		if len(patients) == 0 {
+			return "No patients have shared their data with the care team."
+		}
+		return "Patients sharing with the care team: " + strings.Join(patients, ", ")
+	}
+	if len(fields) != 2 || (command != "stats" && command != "latest") {
+		return usage
+	}
+
+	userID := fields[1]
+	if !b.stores.Shares.CanRead(b.TeamID, userID) {
+		return fmt.Sprintf("%s has not shared their data with the care team.", userID)
+	}
+	log.Printf("Care team query by %s:%s: %s %s", platform, staffID, command, userID)
+	if command == "latest" {
+		latest, ok := b.stores.Readings.Latest(userID)
+		if !ok {
+			return fmt.Sprintf("%s has no readings.", userID)
+		}
+		prefs := b.prefs(userID)
+		return fmt.Sprintf("%s: latest reading %s at %s", userID, prefs.Glucose(latest.Value), prefs.DateTime(latest.Timestamp))
+	}
+	return b.summary(userID, now)
+}
+
+// Help text for the slash command
+const usage = "Usage: `stats <patient>` for the last 24 hours, `latest <patient>` for the latest reading, or `patients` to list who shares with the care team."
+
+// Helper function to summarize a patient's last day on one line
+func (b *Bot) summary(userID string, now time.Time) string {
+	stats := analytics.ComputeStats(b.stores.Readings.Range(userID, now.Add(-digestWindow), now), now.Add(-digestWindow), now)
+	if stats.Count == 0 {
+		return fmt.Sprintf("%s: no readings", userID)
+	}
+	prefs := b.prefs(userID)
+	return fmt.Sprintf("%s: %d readings, mean %s, in range %.0f%%, below %.0f%% (very low %.0f%%), above %.0f%%",
+		userID, stats.Count, prefs.Glucose(stats.Mean), stats.TimeInRange, stats.TimeBelow, stats.TimeVeryLow, stats.TimeAbove)
+}
+
+// Helper function to format values the way the patient reads them
+func (b *Bot) prefs(userID string) locale.Prefs {
+	prefs, err := locale.Resolve(b.stores.Preferences.Get(userID))
+	if err != nil {
+		log.Printf("Invalid preferences for %s, using defaults: %v", userID, err)
+	}
+	return prefs
+}
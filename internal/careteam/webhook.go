@@ -0,0 +1,103 @@
+package careteam
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Chat platforms
+const (
+	Slack   = "slack"
+	Discord = "discord"
+)
+
+// How old a signed Slack request may be before it is rejected as a replay
+const slackMaxSkew = 5 * time.Minute
+
+// Returned when a slash command's signature does not check out
+var ErrBadSignature = errors.New("invalid request signature")
+
+// Incoming webhook for a Slack or Discord channel
+type webhook struct {
+	platform string
+	url      string
+	http     *http.Client
+}
+
+func newWebhook(platform, url string) *webhook {
+	return &webhook{platform: platform, url: url, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *webhook) Name() string { return w.platform }
+
+// Post a message. Slack takes it as text, Discord as content.
+func (w *webhook) Post(ctx context.Context, text string) error {
+	field := "text"
+	if w.platform == Discord {
+		field = "content"
+	}
+	body, _ := json.Marshal(map[string]string{field: text})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", w.platform, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", w.platform, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", w.platform, resp.Status)
+	}
+	return nil
+}
+
+// Check a Slack slash command's X-Slack-Signature against the app's signing secret
+func VerifySlack(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if secret == "" {
+		return ErrBadSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return ErrBadSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Check a Discord interaction's X-Signature-Ed25519 against the app's public key (hex)
+func VerifyDiscord(publicKey, timestamp, signature string, body []byte) error {
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return ErrBadSignature
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrBadSignature
+	}
+	if !ed25519.Verify(key, append([]byte(timestamp), body...), sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Package config loads server configuration from the environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config Struct
+type Config struct {
+	GeminiAPIKey       string
+	Model              string
+	Port               string
+	APIKeys            string
+	AdminAPIKey        string
+	RateLimitPerMinute int
+	Middleware         []string
+	LegacySunset       time.Time
+	InsulinCurves      string
+	ThresholdsFile     string
+	PromptsDir         string
+	EnvFile            string
+	FDCAPIKey          string
+	FDCCacheFile       string
+	OpenFDAAPIKey      string
+	LabelCacheFile     string
+	JobsStateFile      string
+	RedisURL           string
+	ResponseCacheTTL   time.Duration
+	SessionTTL         time.Duration
+	DisclaimersFile    string
+	DefaultLocale      string
+	RedactFields       string
+	EvalModel          string
+	EvalSamplePercent  int
+	EvalAlertPercent   int
+	ReplayMode         string
+	ReplayDir          string
+	ModelPrices        string
+	CostCurrency       string
+	CareTeamID         string
+	CareTeamStaff      []string
+	SlackWebhookURL    string
+	SlackSigningSecret string
+	DiscordWebhookURL  string
+	DiscordPublicKey   string
+	AlexaSkillID       string
+	IngestSecrets      []string
+	GuidelineEmbedder  string
+	EmbedModel         string
+	OllamaAddress      string
+	SMTPAddr           string
+	SMTPFrom           string
+	SMTPUsername       string
+	SMTPPassword       string
+	DatabasePath       string
+	Storage            string
+	DeployMode         string
+	FirestoreProject   string
+	FirestoreDatabase  string
+	MaxBodyBytes       int64
+	MaxUploadBytes     int64
+	MaxImportBytes     int64
+	CompressResponses  bool
+	ShadowModel        string
+	ShadowPromptsDir   string
+	ShadowSamplePct    int
+	ShadowFlows        []string
+	SafetyFilter       string
+	SafetyAction       string
+	SafetyBlocklist    string
+	SafetyModel        string
+	ChatTokenBudget    int
+	ChatSummaryModel   string
+}
+
+// Load configuration from environment variables
+func Load() (*Config, error) {
+	cfg := &Config{
+		GeminiAPIKey:       os.Getenv("GEMINI_API_KEY"),
+		Model:              envString("MODEL", "googleai/gemini-2.5-flash"),
+		Port:               envString("PORT", "8080"),
+		APIKeys:            os.Getenv("API_KEYS"),
+		AdminAPIKey:        os.Getenv("ADMIN_API_KEY"),
+		RateLimitPerMinute: envInt("RATE_LIMIT_PER_MINUTE", 0),
+		Middleware:         envList("MIDDLEWARE", "logging,metrics,auth,ratelimit"),
+		InsulinCurves:      os.Getenv("INSULIN_CURVES"),
+		ThresholdsFile:     os.Getenv("THRESHOLDS_FILE"),
+		PromptsDir:         envString("PROMPTS_DIR", "prompts"),
+		EnvFile:            envString("ENV_FILE", ".env"),
+		FDCAPIKey:          os.Getenv("FDC_API_KEY"),
+		FDCCacheFile:       os.Getenv("FDC_CACHE_FILE"),
+		OpenFDAAPIKey:      os.Getenv("OPENFDA_API_KEY"),
+		LabelCacheFile:     os.Getenv("LABEL_CACHE_FILE"),
+		JobsStateFile:      os.Getenv("JOBS_STATE_FILE"),
+		RedisURL:           os.Getenv("REDIS_URL"),
+		DisclaimersFile:    os.Getenv("DISCLAIMERS_FILE"),
+		DefaultLocale:      envString("DEFAULT_LOCALE", "en"),
+		RedactFields:       os.Getenv("REDACT_FIELDS"),
+		EvalModel:          os.Getenv("EVAL_MODEL"),
+		EvalSamplePercent:  envInt("EVAL_SAMPLE_PERCENT", 10),
+		EvalAlertPercent:   envInt("EVAL_ALERT_PERCENT", 70),
+		ReplayMode:         os.Getenv("REPLAY_MODE"),
+		ReplayDir:          envString("REPLAY_DIR", "testdata/replay"),
+		ModelPrices:        os.Getenv("MODEL_PRICES"),
+		CostCurrency:       envString("COST_CURRENCY", "USD"),
+		CareTeamID:         envString("CARE_TEAM_ID", "care-team"),
+		CareTeamStaff:      envList("CARE_TEAM_STAFF", ""),
+		SlackWebhookURL:    os.Getenv("SLACK_WEBHOOK_URL"),
+		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		DiscordWebhookURL:  os.Getenv("DISCORD_WEBHOOK_URL"),
+		DiscordPublicKey:   os.Getenv("DISCORD_PUBLIC_KEY"),
+		AlexaSkillID:       os.Getenv("ALEXA_SKILL_ID"),
+		IngestSecrets:      envList("INGEST_SECRETS", ""),
+		GuidelineEmbedder:  strings.ToLower(os.Getenv("GUIDELINE_EMBEDDER")),
+		OllamaAddress:      envString("OLLAMA_SERVER_ADDRESS", "http://localhost:11434"),
+		SMTPAddr:           os.Getenv("SMTP_ADDR"),
+		SMTPFrom:           envString("SMTP_FROM", "reports@diabeticai.local"),
+		SMTPUsername:       os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:       os.Getenv("SMTP_PASSWORD"),
+		DatabasePath:       os.Getenv("DATABASE_PATH"),
+		Storage:            strings.ToLower(os.Getenv("STORAGE")),
+		DeployMode:         strings.ToLower(os.Getenv("DEPLOY_MODE")),
+		FirestoreProject:   envString("FIRESTORE_PROJECT", os.Getenv("GOOGLE_CLOUD_PROJECT")),
+		FirestoreDatabase:  envString("FIRESTORE_DATABASE", "(default)"),
+		MaxBodyBytes:       int64(envInt("MAX_BODY_BYTES", 1<<20)),
+		MaxUploadBytes:     int64(envInt("MAX_UPLOAD_BYTES", 8<<20)),
+		MaxImportBytes:     int64(envInt("MAX_IMPORT_BYTES", 100<<20)),
+		CompressResponses:  os.Getenv("COMPRESS_RESPONSES") != "false",
+		ShadowModel:        os.Getenv("SHADOW_MODEL"),
+		ShadowPromptsDir:   os.Getenv("SHADOW_PROMPTS_DIR"),
+		ShadowSamplePct:    envInt("SHADOW_SAMPLE_PERCENT", 10),
+		ShadowFlows:        envList("SHADOW_FLOWS", ""),
+		SafetyFilter:       strings.ToLower(envString("SAFETY_FILTER", "blocklist")),
+		SafetyAction:       strings.ToLower(envString("SAFETY_ACTION", "regenerate")),
+		SafetyBlocklist:    os.Getenv("SAFETY_BLOCKLIST"),
+		SafetyModel:        os.Getenv("SAFETY_MODEL"),
+		ChatTokenBudget:    envInt("CHAT_TOKEN_BUDGET", 6000),
+		ChatSummaryModel:   os.Getenv("CHAT_SUMMARY_MODEL"),
+	}
+
+	// Replay answers from fixtures, so it runs without a key
+	if cfg.GeminiAPIKey == "" && cfg.ReplayMode == "replay" {
+		cfg.GeminiAPIKey = "replay"
+	}
+	if cfg.GeminiAPIKey == "" {
+		return nil, errors.New("GEMINI API KEY environment variable is missing")
+	}
+
+	sunset, err := time.Parse("2006-01-02", envString("LEGACY_SUNSET", "2027-06-30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEGACY_SUNSET: %w", err)
+	}
+	cfg.LegacySunset = sunset
+
+	// Guideline grounding embeds with Google's model or a local one served by Ollama
+	switch cfg.GuidelineEmbedder {
+	case "":
+	case "googleai":
+		cfg.EmbedModel = envString("EMBED_MODEL", "text-embedding-004")
+	case "ollama":
+		cfg.EmbedModel = envString("EMBED_MODEL", "nomic-embed-text")
+	default:
+		return nil, fmt.Errorf("invalid GUIDELINE_EMBEDDER %q: use googleai or ollama", cfg.GuidelineEmbedder)
+	}
+
+	// Chat history is summarized by a cheaper model than answers, on Gemini
+	if cfg.ChatSummaryModel == "" && strings.HasPrefix(cfg.Model, "googleai/") {
+		cfg.ChatSummaryModel = "googleai/gemini-2.5-flash-lite"
+	}
+
+	// Where readings, logs and profiles are kept; the database when one is configured
+	if cfg.Storage == "" {
+		cfg.Storage = "memory"
+		if cfg.DatabasePath != "" {
+			cfg.Storage = "sqlite"
+		}
+	}
+	switch cfg.Storage {
+	case "memory", "firestore":
+	case "sqlite":
+		if cfg.DatabasePath == "" {
+			return nil, errors.New("STORAGE=sqlite needs DATABASE_PATH")
+		}
+	default:
+		return nil, fmt.Errorf("invalid STORAGE %q: use memory, sqlite or firestore", cfg.Storage)
+	}
+
+	// Cloud Run sets K_SERVICE, so the mode follows from it unless set
+	if cfg.DeployMode == "" {
+		cfg.DeployMode = "server"
+		if os.Getenv("K_SERVICE") != "" {
+			cfg.DeployMode = "cloudrun"
+		}
+	}
+	if cfg.DeployMode != "server" && cfg.DeployMode != "cloudrun" {
+		return nil, fmt.Errorf("invalid DEPLOY_MODE %q: use server or cloudrun", cfg.DeployMode)
+	}
+
+	if cfg.ResponseCacheTTL, err = time.ParseDuration(envString("RESPONSE_CACHE_TTL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid RESPONSE_CACHE_TTL: %w", err)
+	}
+	if cfg.SessionTTL, err = time.ParseDuration(envString("SESSION_TTL", "30m")); err != nil {
+		return nil, fmt.Errorf("invalid SESSION_TTL: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Report whether the service runs on Cloud Run or another serverless platform,
+// where instances stop between requests and jobs are triggered from outside
+func (c *Config) Serverless() bool {
+	return c.DeployMode == "cloudrun"
+}
+
+// Address to listen on (Cloud Run compatible)
+func (c *Config) Addr() string {
+	return "0.0.0.0:" + c.Port
+}
+
+// Helper function to read a string environment variable
+func envString(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// Helper function to read an integer environment variable
+func envInt(name string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// Helper function to read a comma-separated environment variable
+func envList(name, fallback string) []string {
+	var out []string
+	for _, v := range strings.Split(envString(name, fallback), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
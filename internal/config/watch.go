@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// Load KEY=VALUE lines from an env file into the process environment.
+// Blank lines and lines starting with # are skipped. A missing file is not an error.
+func LoadEnvFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return fmt.Errorf("env file line %d: want KEY=VALUE", i+1)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if err := os.Setenv(strings.TrimSpace(key), value); err != nil {
+			return fmt.Errorf("env file line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Poll files and directories for changes, calling onChange with each path that changed.
+// Polling avoids a platform-specific file notification dependency.
+func Watch(ctx context.Context, paths []string, interval time.Duration, onChange func(path string)) {
+	last := make(map[string]string, len(paths))
+	for _, p := range paths {
+		last[p] = fingerprint(p)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, p := range paths {
+				if fp := fingerprint(p); fp != last[p] {
+					last[p] = fp
+					onChange(p)
+				}
+			}
+		}
+	}
+}
+
+// Helper function to summarize a path's modification state
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		if fi, err := e.Info(); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", e.Name(), fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,290 @@
+// Package costs turns model token usage into money, per flow, user and day.
+package costs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/requestid"
+	"diabeticai-advisor/internal/store"
+)
+
+// Price Struct
+//
+// Price per million tokens. Thinking tokens are billed as output.
+type Price struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Default prices in USD per million tokens (paid tier, text, prompts up to 200k tokens)
+var prices = map[string]Price{
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+}
+
+// Where usage is recorded, and the defaults it is charged against
+var (
+	mu           sync.RWMutex
+	usage        *store.LogStore[store.ModelUsage]
+	defaultModel string
+	currency     = "USD"
+)
+
+// Record usage into the given store. Model is the default model, used when a
+// generation does not name one; cur names the currency the prices are in.
+func Configure(s *store.LogStore[store.ModelUsage], model, cur string) {
+	mu.Lock()
+	defer mu.Unlock()
+	usage, defaultModel = s, model
+	if cur != "" {
+		currency = strings.ToUpper(cur)
+	}
+}
+
+// Currency the prices are in
+func Currency() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currency
+}
+
+// Helper function to override or add model prices from config.
+// Format: "googleai/gemini-2.5-flash=0.30:2.50,gemini-2.5-pro=1.25:10" (input:output per million tokens).
+func LoadPricing(spec string) error {
+	if spec == "" {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, entry := range strings.Split(spec, ",") {
+		name, values, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("invalid model price %q", entry)
+		}
+		inStr, outStr, ok := strings.Cut(values, ":")
+		if !ok {
+			return fmt.Errorf("invalid model price %q: want input:output", entry)
+		}
+		in, err := strconv.ParseFloat(inStr, 64)
+		if err != nil || in < 0 {
+			return fmt.Errorf("invalid input price for %s", name)
+		}
+		out, err := strconv.ParseFloat(outStr, 64)
+		if err != nil || out < 0 {
+			return fmt.Errorf("invalid output price for %s", name)
+		}
+		prices[modelKey(name)] = Price{InputPerMillion: in, OutputPerMillion: out}
+	}
+	return nil
+}
+
+// Price a generation, reporting whether the model has a price
+func Cost(model string, inputTokens, outputTokens int) (float64, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := prices[modelKey(model)]
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1e6, true
+}
+
+// Helper function to drop the provider prefix, so "googleai/gemini-2.5-flash" and "gemini-2.5-flash" share a price
+func modelKey(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if _, name, ok := strings.Cut(model, "/"); ok {
+		return name
+	}
+	return model
+}
+
+// Context key for who a generation is charged to
+type key struct{}
+
+type caller struct {
+	userID, flow, model string
+}
+
+// Return a context charging generations to a user
+func WithUser(ctx context.Context, userID string) context.Context {
+	c, _ := ctx.Value(key{}).(caller)
+	c.userID = userID
+	return context.WithValue(ctx, key{}, c)
+}
+
+// Return a context charging generations to a flow and model other than the
+// ones running, as when one flow grades another with its own model
+func WithFlow(ctx context.Context, flow, model string) context.Context {
+	c, _ := ctx.Value(key{}).(caller)
+	c.flow, c.model = flow, model
+	return context.WithValue(ctx, key{}, c)
+}
+
+// Return the model a generation in this context is charged to
+func Model(ctx context.Context) string {
+	if c, _ := ctx.Value(key{}).(caller); c.model != "" {
+		return c.model
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultModel
+}
+
+// Return the flow a generation in this context is charged to: the running
+// flow unless the context names another
+func Flow(ctx context.Context, running string) string {
+	if c, _ := ctx.Value(key{}).(caller); c.flow != "" {
+		return c.flow
+	}
+	return running
+}
+
+// Record the tokens used by one generation. Flow is the running flow unless
+// the context names another. Nothing is recorded until Configure is called.
+func Record(ctx context.Context, flow string, inputTokens, outputTokens int) {
+	mu.RLock()
+	s := usage
+	mu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	model := Model(ctx)
+	flow = Flow(ctx, flow)
+	c, _ := ctx.Value(key{}).(caller)
+	if flow == "" {
+		flow = "unknown"
+	}
+
+	cost, priced := Cost(model, inputTokens, outputTokens)
+	if !priced {
+		log.Printf("No price for model %s; recording usage at zero cost", model)
+	}
+	u := store.ModelUsage{
+		UserID:       c.userID,
+		Flow:         flow,
+		Model:        model,
+		RequestID:    requestid.From(ctx),
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Cost:         cost,
+		Priced:       priced,
+	}
+	store.Stamp(&u.UserID, &u.Timestamp)
+	s.Add(ctx, u)
+}
+
+// Line Struct
+type Line struct {
+	Key          string  `json:"key"`
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	AvgCost      float64 `json:"avg_cost" jsonschema:"description=Cost per call"`
+}
+
+// Report Struct
+type Report struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Currency string    `json:"currency"`
+	Total    Line      `json:"total"`
+	ByFlow   []Line    `json:"by_flow" jsonschema:"description=Most expensive first"`
+	ByUser   []Line    `json:"by_user" jsonschema:"description=Most expensive first"`
+	ByDay    []Line    `json:"by_day" jsonschema:"description=Oldest first"`
+	ByModel  []Line    `json:"by_model"`
+	Unpriced []string  `json:"unpriced_models,omitempty" jsonschema:"description=Models with no price; their usage counts as zero cost"`
+}
+
+// Build a cost report from every user's usage in a window. Days are in loc.
+func Summarize(s *store.LogStore[store.ModelUsage], from, to time.Time, loc *time.Location) Report {
+	report := Report{From: from, To: to, Currency: Currency(), Total: Line{Key: "total"}}
+	byFlow := make(map[string]*Line)
+	byUser := make(map[string]*Line)
+	byDay := make(map[string]*Line)
+	byModel := make(map[string]*Line)
+	unpriced := make(map[string]bool)
+
+	for _, userID := range s.Users() {
+		for _, u := range s.Range(userID, from, to) {
+			add(&report.Total, u)
+			add(line(byFlow, u.Flow), u)
+			add(line(byUser, u.UserID), u)
+			add(line(byDay, u.Timestamp.In(loc).Format(time.DateOnly)), u)
+			add(line(byModel, u.Model), u)
+			if !u.Priced {
+				unpriced[u.Model] = true
+			}
+		}
+	}
+
+	finish(&report.Total)
+	report.ByFlow = byCost(byFlow)
+	report.ByUser = byCost(byUser)
+	report.ByModel = byCost(byModel)
+	report.ByDay = byCost(byDay)
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Key < report.ByDay[j].Key })
+	for model := range unpriced {
+		report.Unpriced = append(report.Unpriced, model)
+	}
+	sort.Strings(report.Unpriced)
+	return report
+}
+
+func line(lines map[string]*Line, key string) *Line {
+	l, ok := lines[key]
+	if !ok {
+		l = &Line{Key: key}
+		lines[key] = l
+	}
+	return l
+}
+
+func add(l *Line, u store.ModelUsage) {
+	l.Calls++
+	l.InputTokens += u.InputTokens
+	l.OutputTokens += u.OutputTokens
+	l.Cost += u.Cost
+}
+
+// Helper function to round a line's cost and work out the cost per call
+func finish(l *Line) {
+	if l.Calls > 0 {
+		l.AvgCost = round(l.Cost / float64(l.Calls))
+	}
+	l.Cost = round(l.Cost)
+}
+
+// Helper function to flatten lines, most expensive first
+func byCost(lines map[string]*Line) []Line {
+	out := make([]Line, 0, len(lines))
+	for _, l := range lines {
+		finish(l)
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Cost != out[j].Cost {
+			return out[i].Cost > out[j].Cost
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// Round to a millionth, enough to show the cost of a single call
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
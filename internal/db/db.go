@@ -0,0 +1,78 @@
+// Package db opens the SQLite database that keeps conversations, logs and
+// profiles across restarts.
+//
+// The driver is linked in with the sqlite build tag (see sqlite.go), so
+// builds without it keep everything in memory and need no database.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Name the SQLite driver registers under
+const driver = "sqlite"
+
+// Tables, created on open when missing. New tables and indexes are added to
+// the end; existing statements are never changed.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS sessions (
+		id         TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL,
+		flow       TEXT NOT NULL,
+		data       TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS sessions_user ON sessions (user_id, updated_at)`,
+	`CREATE TABLE IF NOT EXISTS records (
+		id      INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind    TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		at      INTEGER NOT NULL,
+		data    TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS records_kind ON records (kind, at)`,
+	`CREATE TABLE IF NOT EXISTS documents (
+		kind TEXT NOT NULL,
+		key  TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (kind, key)
+	)`,
+}
+
+// Report whether this build can open a SQLite database
+func Available() bool {
+	return slices.Contains(sql.Drivers(), driver)
+}
+
+// Open the SQLite database at path, creating it and its tables if needed.
+// An empty path returns nil, for in-memory state.
+func Open(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !Available() {
+		return nil, fmt.Errorf("this build has no SQLite driver: rebuild with -tags sqlite")
+	}
+
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite has a single writer; one connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, stmt := range append([]string{"PRAGMA journal_mode=WAL", "PRAGMA busy_timeout=5000"}, schema...) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set up database: %w", err)
+		}
+	}
+	return db, nil
+}
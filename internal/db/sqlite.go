@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package db
+
+// Pure-Go SQLite driver, registered as "sqlite". Add it with
+// `go get modernc.org/sqlite` and build with -tags sqlite.
+import _ "modernc.org/sqlite"
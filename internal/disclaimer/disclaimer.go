@@ -0,0 +1,89 @@
+// Package disclaimer picks the legal disclaimer shown with a flow's response.
+package disclaimer
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Key for the disclaimer used by flows without their own
+const Default = "default"
+
+// Built-in disclaimers by flow, then locale
+var builtin = map[string]map[string]string{
+	Default: {
+		"en": "⚠️ This is educational information from an AI advisor, not medical advice. Talk to your healthcare provider about your care, and call emergency services in an emergency.",
+	},
+	"medicationInfo": {
+		"en": "⚠️ IMPORTANT: This is educational information only. Always consult your healthcare provider before starting, stopping, or changing any medication. This AI advisor cannot replace professional medical advice.",
+	},
+	"fastingAdvisor": {
+		"en": "⚠️ IMPORTANT: Talk to your healthcare provider before fasting, especially if you take insulin or medication that lowers blood sugar. Never change your doses without their guidance.",
+	},
+	"glucoseForecast": {
+		"en": "⚠️ IMPORTANT: This projection is an estimate and can be wrong. Check your blood sugar before acting on it, and never dose insulin from a forecast.",
+	},
+	"icrEstimator": {
+		"en": "⚠️ IMPORTANT: These estimates are for discussion with your healthcare provider only. Never change your insulin ratios or doses without their guidance.",
+	},
+}
+
+// Set of disclaimers by flow and locale
+type Set struct {
+	texts         map[string]map[string]string
+	defaultLocale string
+}
+
+// Load the built-in disclaimers, overridden by a JSON file of
+// {"<flow or default>": {"<locale>": "text"}} when path is set.
+// An empty text turns the disclaimer off for that flow and locale.
+func Load(path, defaultLocale string) (*Set, error) {
+	s := &Set{texts: make(map[string]map[string]string), defaultLocale: strings.ToLower(defaultLocale)}
+	for flow, byLocale := range builtin {
+		s.texts[flow] = maps.Clone(byLocale)
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disclaimers: %w", err)
+	}
+	var overrides map[string]map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode disclaimers: %w", err)
+	}
+	for flow, byLocale := range overrides {
+		if s.texts[flow] == nil {
+			s.texts[flow] = make(map[string]string)
+		}
+		for locale, text := range byLocale {
+			s.texts[flow][strings.ToLower(locale)] = text
+		}
+	}
+	return s, nil
+}
+
+// Return the disclaimer for a flow in the closest available locale, and that locale.
+// Tries the exact locale (en-gb), then its language (en), then the default locale,
+// first for the flow and then for the default disclaimer.
+func (s *Set) For(flow, locale string) (string, string) {
+	locale = strings.ToLower(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	for _, key := range []string{flow, Default} {
+		byLocale, ok := s.texts[key]
+		if !ok {
+			continue
+		}
+		for _, candidate := range []string{locale, language, s.defaultLocale, "en"} {
+			if text, ok := byLocale[candidate]; ok && candidate != "" {
+				return text, candidate
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,78 @@
+// Package dryrun collects what a flow would send to the model, so a request
+// can be traced end to end without calling it or storing anything.
+package dryrun
+
+import (
+	"context"
+	"sync"
+)
+
+// Text returned in place of every model response during a dry run
+const Placeholder = "[dry run: the model was not called]"
+
+// Generation Struct
+type Generation struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt" jsonschema:"description=The fully rendered prompt, including the units and formatting note"`
+}
+
+// Config Struct
+type Config struct {
+	Flow          string `json:"flow"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	DefaultModel  string `json:"default_model"`
+	ReplayMode    string `json:"replay_mode"`
+	Locale        string `json:"locale"`
+	Units         string `json:"units"`
+}
+
+// Trace Struct
+//
+// Everything a dry-run request would have sent to the model.
+type Trace struct {
+	mu          sync.Mutex
+	Config      Config         `json:"config"`
+	Generations []Generation   `json:"generations"`
+	Verdicts    map[string]any `json:"verdicts,omitempty" jsonschema:"description=Rules-engine verdicts by rule set"`
+}
+
+// Context key for the trace of a dry-run request
+type key struct{}
+
+// Return a context that runs flows in dry-run mode, and the trace it fills in
+func With(ctx context.Context, config Config) (context.Context, *Trace) {
+	t := &Trace{Config: config, Generations: []Generation{}}
+	return context.WithValue(ctx, key{}, t), t
+}
+
+// Return the dry-run trace from a context, or nil outside a dry run
+func From(ctx context.Context) *Trace {
+	t, _ := ctx.Value(key{}).(*Trace)
+	return t
+}
+
+// Report whether a context is in dry-run mode. Flows skip writes when it is.
+func Active(ctx context.Context) bool {
+	return From(ctx) != nil
+}
+
+// Record a generation that would have run
+func (t *Trace) Add(model, prompt string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Generations = append(t.Generations, Generation{Model: model, Prompt: prompt})
+}
+
+// Record a rules-engine verdict. Does nothing outside a dry run.
+func Verdict(ctx context.Context, name string, verdict any) {
+	t := From(ctx)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Verdicts == nil {
+		t.Verdicts = make(map[string]any)
+	}
+	t.Verdicts[name] = verdict
+}
@@ -0,0 +1,74 @@
+// Package email renders HTML reports and sends them over SMTP.
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// Sender Struct
+//
+// An SMTP relay messages are sent through.
+type Sender struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// Create a sender for an SMTP relay at host:port. An empty address disables sending.
+func NewSender(addr, from, username, password string) (*Sender, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid SMTP address %q: %w", addr, err)
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	return &Sender{Addr: addr, From: from, Username: username, Password: password}, nil
+}
+
+// Report whether sending is configured
+func (s *Sender) Enabled() bool {
+	return s != nil
+}
+
+// Send an HTML message to one recipient
+func (s *Sender) Send(to, subject, html string) error {
+	if !s.Enabled() {
+		return fmt.Errorf("email is not configured")
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if err := smtp.SendMail(s.Addr, auth, s.From, []string{to}, message(s.From, to, subject, html, time.Now())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
+
+// Helper function to build a MIME message with an HTML body
+func message(from, to, subject, html string, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(html))
+	qp.Close()
+	return b.Bytes()
+}
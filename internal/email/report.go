@@ -0,0 +1,120 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/locale"
+)
+
+// Monthly report email. Styles are inline and the chart is plain table cells,
+// since most mail clients drop stylesheets and scripts.
+var monthlyTemplate = template.Must(template.New("monthly").Parse(`<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:600px">
+<h2>Your {{.Month}} glucose report</h2>
+{{if .Stats.Count}}
+<p>{{.Stats.Count}} readings. Average {{.Mean}}, {{.TIR}}% in range ({{.Target}}), goal over {{.Goal}}%.</p>
+
+<h3>Time in range by week</h3>
+<table style="border-collapse:collapse;width:100%">
+{{range .Weeks}}<tr>
+<td style="padding:4px;white-space:nowrap">{{.Label}}</td>
+<td style="padding:4px;width:100%">{{if .Readings}}<table style="border-collapse:collapse;width:100%"><tr>
+{{if .Below}}<td style="background:#d9534f;height:14px;width:{{.Below}}%"></td>{{end}}
+{{if .In}}<td style="background:#5cb85c;height:14px;width:{{.In}}%"></td>{{end}}
+{{if .Above}}<td style="background:#f0ad4e;height:14px;width:{{.Above}}%"></td>{{end}}
+</tr></table>{{else}}<span style="color:#888">No readings</span>{{end}}</td>
+<td style="padding:4px;white-space:nowrap">{{if .Readings}}{{.In}}%{{end}}</td>
+</tr>{{end}}
+</table>
+<p style="font-size:12px;color:#666">Red: below range. Green: in range. Orange: above range.</p>
+
+<h3>Estimated A1C</h3>
+<table style="border-collapse:collapse">
+{{range .Trajectory}}<tr><td style="padding:4px">{{.Month}}</td><td style="padding:4px">{{if .Readings}}{{.GMI}}%{{else}}<span style="color:#888">No readings</span>{{end}}</td></tr>
+{{end}}</table>
+<p style="font-size:12px;color:#666">Estimated from average glucose (glucose management indicator). A lab A1C may differ.</p>
+{{else}}
+<p>No readings were logged this month.</p>
+{{end}}
+{{if .Insights}}
+<h3>Insights</h3>
+{{range .Insights}}<p>{{.}}</p>
+{{end}}{{end}}
+{{if .Disclaimer}}<p style="font-size:12px;color:#666">{{.Disclaimer}}</p>{{end}}
+<p style="font-size:12px;color:#666">You get this email because monthly reports are on in your notification settings.</p>
+</body></html>
+`))
+
+// Helper types holding the report formatted for the template
+type (
+	monthlyView struct {
+		Month      string
+		Stats      analytics.GlucoseStats
+		Mean       string
+		TIR        string
+		Target     string
+		Goal       string
+		Weeks      []weekView
+		Trajectory []a1cView
+		Insights   []string
+		Disclaimer string
+	}
+	weekView struct {
+		Label            string
+		Readings         int
+		Below, In, Above int
+	}
+	a1cView struct {
+		Month    string
+		Readings int
+		GMI      string
+	}
+)
+
+// Render a monthly report as an email subject and HTML body, in the user's units and
+// number format, with the disclaimer as its footer
+func RenderMonthly(report analytics.MonthlyReport, prefs locale.Prefs, disclaimer string) (string, string, error) {
+	month := monthName(report.Month)
+	view := monthlyView{
+		Month:      month,
+		Stats:      report.Stats,
+		Mean:       prefs.Glucose(report.Stats.Mean),
+		TIR:        prefs.Number(report.Stats.TimeInRange, 0),
+		Target:     prefs.Glucose(report.Stats.Ranges.Low) + " to " + prefs.Glucose(report.Stats.Ranges.High),
+		Goal:       prefs.Number(report.Stats.Ranges.InRangeGoal, 0),
+		Insights:   report.Insights,
+		Disclaimer: disclaimer,
+	}
+	for _, w := range report.Weeks {
+		view.Weeks = append(view.Weeks, weekView{
+			Label:    prefs.Date(w.From),
+			Readings: w.Readings,
+			Below:    int(math.Round(w.TimeBelow)),
+			In:       int(math.Round(w.TimeInRange)),
+			Above:    int(math.Round(w.TimeAbove)),
+		})
+	}
+	for _, a := range report.Trajectory {
+		view.Trajectory = append(view.Trajectory, a1cView{Month: monthName(a.Month), Readings: a.Readings, GMI: prefs.Number(a.GMI, 1)})
+	}
+
+	var b bytes.Buffer
+	if err := monthlyTemplate.Execute(&b, view); err != nil {
+		return "", "", fmt.Errorf("failed to render monthly report: %w", err)
+	}
+	return fmt.Sprintf("Your %s glucose report", month), b.String(), nil
+}
+
+// Helper function to spell out a YYYY-MM month
+func monthName(month string) string {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return month
+	}
+	return t.Format("January 2006")
+}
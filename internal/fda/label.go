@@ -0,0 +1,272 @@
+// Package fda fetches drug label data from the openFDA API.
+package fda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// openFDA drug label endpoint
+const labelURL = "https://api.fda.gov/drug/label.json"
+
+// How long fetched labels are reused
+const cacheTTL = 24 * time.Hour
+
+// Longest excerpt kept from each label section
+const maxExcerpt = 600
+
+// Returned when openFDA has no label for a drug
+var ErrNotFound = errors.New("drug label not found")
+
+// Label Struct
+type Label struct {
+	SetID            string    `json:"set_id"`
+	EffectiveTime    string    `json:"effective_time,omitempty"`
+	BrandNames       []string  `json:"brand_names,omitempty"`
+	GenericNames     []string  `json:"generic_names,omitempty"`
+	PharmClasses     []string  `json:"pharm_classes,omitempty"`
+	Indications      string    `json:"indications,omitempty"`
+	BoxedWarning     string    `json:"boxed_warning,omitempty"`
+	Warnings         string    `json:"warnings,omitempty"`
+	Interactions     string    `json:"interactions,omitempty"`
+	AdverseReactions string    `json:"adverse_reactions,omitempty"`
+	HypoglycemiaRisk string    `json:"hypoglycemia_risk,omitempty"`
+	FoodTiming       string    `json:"food_timing,omitempty"`
+	FetchedAt        time.Time `json:"fetched_at"`
+}
+
+// openFDA client with a label cache, optionally persisted to a JSON file
+type Client struct {
+	apiKey string
+	http   *http.Client
+	mu     sync.RWMutex
+	path   string
+	cache  map[string]Label
+}
+
+// Create a client, loading cached labels from cachePath. The API key is optional
+// and only raises openFDA's rate limits; an empty cachePath keeps labels in memory.
+func NewClient(apiKey, cachePath string) (*Client, error) {
+	c := &Client{
+		apiKey: apiKey,
+		http:   &http.Client{Timeout: 10 * time.Second},
+		path:   cachePath,
+		cache:  make(map[string]Label),
+	}
+	if cachePath == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read label cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.cache); err != nil {
+		return nil, fmt.Errorf("failed to decode label cache: %w", err)
+	}
+	return c, nil
+}
+
+// openFDA label response, trimmed to the fields used
+type labelResponse struct {
+	Results []struct {
+		SetID                   string   `json:"set_id"`
+		EffectiveTime           string   `json:"effective_time"`
+		Indications             []string `json:"indications_and_usage"`
+		BoxedWarning            []string `json:"boxed_warning"`
+		Warnings                []string `json:"warnings"`
+		WarningsAndCautions     []string `json:"warnings_and_cautions"`
+		DrugInteractions        []string `json:"drug_interactions"`
+		AdverseReactions        []string `json:"adverse_reactions"`
+		DosageAndAdministration []string `json:"dosage_and_administration"`
+		OpenFDA                 struct {
+			BrandName   []string `json:"brand_name"`
+			GenericName []string `json:"generic_name"`
+			PharmClass  []string `json:"pharm_class_epc"`
+		} `json:"openfda"`
+	} `json:"results"`
+}
+
+// Characters allowed in a drug name search
+var drugName = regexp.MustCompile(`^[A-Za-z0-9 \-]+$`)
+
+// Fetch the label for a drug by brand or generic name
+func (c *Client) Label(ctx context.Context, name string) (Label, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" || !drugName.MatchString(key) {
+		return Label{}, ErrNotFound
+	}
+
+	c.mu.RLock()
+	label, ok := c.cache[key]
+	c.mu.RUnlock()
+	if ok && time.Since(label.FetchedAt) < cacheTTL {
+		return label, nil
+	}
+
+	params := url.Values{}
+	params.Set("search", fmt.Sprintf(`openfda.generic_name:"%s" openfda.brand_name:"%s"`, key, key))
+	params.Set("limit", "1")
+	if c.apiKey != "" {
+		params.Set("api_key", c.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, labelURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Label{}, fmt.Errorf("failed to build openFDA request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Label{}, fmt.Errorf("failed to query openFDA: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Label{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Label{}, fmt.Errorf("failed to query openFDA: status %d", resp.StatusCode)
+	}
+
+	var body labelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Label{}, fmt.Errorf("failed to decode openFDA response: %w", err)
+	}
+	if len(body.Results) == 0 {
+		return Label{}, ErrNotFound
+	}
+
+	r := body.Results[0]
+	warnings := r.WarningsAndCautions
+	if len(warnings) == 0 {
+		warnings = r.Warnings
+	}
+	label = Label{
+		SetID:            r.SetID,
+		EffectiveTime:    r.EffectiveTime,
+		BrandNames:       r.OpenFDA.BrandName,
+		GenericNames:     r.OpenFDA.GenericName,
+		PharmClasses:     r.OpenFDA.PharmClass,
+		Indications:      excerpt(r.Indications),
+		BoxedWarning:     excerpt(r.BoxedWarning),
+		Warnings:         excerpt(warnings),
+		Interactions:     excerpt(r.DrugInteractions),
+		AdverseReactions: excerpt(r.AdverseReactions),
+		HypoglycemiaRisk: mention(append(append(warnings, r.AdverseReactions...), r.DosageAndAdministration...), "hypoglycemia"),
+		FoodTiming:       foodTiming(r.DosageAndAdministration),
+		FetchedAt:        time.Now(),
+	}
+
+	c.mu.Lock()
+	c.cache[key] = label
+	if c.path != "" {
+		if err := c.save(); err != nil {
+			log.Printf("Failed to save label cache: %v", err)
+		}
+	}
+	c.mu.Unlock()
+	return label, nil
+}
+
+// Helper function to write the cache atomically
+func (c *Client) save() error {
+	data, err := json.Marshal(c.cache)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Helper function to shorten a label section to a readable excerpt
+func excerpt(sections []string) string {
+	text := strings.Join(strings.Fields(strings.Join(sections, " ")), " ")
+	if len(text) <= maxExcerpt {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxExcerpt], ". ")
+	if cut < maxExcerpt/2 {
+		cut = strings.LastIndex(text[:maxExcerpt], " ")
+	}
+	return text[:cut+1] + "…"
+}
+
+// Helper function to find the first sentence mentioning a term
+func mention(sections []string, term string) string {
+	for _, section := range sections {
+		text := strings.Join(strings.Fields(section), " ")
+		idx := strings.Index(strings.ToLower(text), term)
+		if idx < 0 {
+			continue
+		}
+		start := strings.LastIndex(text[:idx], ". ") + 1
+		end := strings.Index(text[idx:], ". ")
+		if end < 0 {
+			end = len(text) - idx
+		}
+		return excerpt([]string{strings.TrimSpace(text[start : idx+end+1])})
+	}
+	return ""
+}
+
+// Helper function to find what the dosing section says about food or time of day
+func foodTiming(dosing []string) string {
+	for _, term := range []string{"empty stomach", "meal", "food", "breakfast", "bedtime"} {
+		if text := mention(dosing, term); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// Label Fact Struct
+type Fact struct {
+	Section string `json:"section"`
+	Text    string `json:"text"`
+	Source  string `json:"source"`
+}
+
+// List the label sections that have content, each attributed to the label
+func (l Label) Facts() []Fact {
+	source := "FDA drug label (set id " + l.SetID + ")"
+	facts := []Fact{}
+	for _, s := range []struct{ section, text string }{
+		{"indications", l.Indications},
+		{"boxed_warning", l.BoxedWarning},
+		{"warnings", l.Warnings},
+		{"hypoglycemia_risk", l.HypoglycemiaRisk},
+		{"food_timing", l.FoodTiming},
+		{"interactions", l.Interactions},
+		{"adverse_reactions", l.AdverseReactions},
+	} {
+		if s.text != "" {
+			facts = append(facts, Fact{Section: s.section, Text: s.text, Source: source})
+		}
+	}
+	return facts
+}
+
+// Helper function to render label facts as a prompt block
+func (l Label) PromptSummary() string {
+	var b strings.Builder
+	for _, f := range l.Facts() {
+		fmt.Fprintf(&b, "[%s] %s\n", strings.ToUpper(f.Section), f.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"diabeticai-advisor/internal/fda"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// A drug in the class table
+type classDrug struct {
+	name    string
+	brands  []string
+	aliases []string
+	generic bool
+	note    string
+}
+
+// A drug class and its members
+type drugClass struct {
+	name  string
+	drugs []classDrug
+}
+
+// Diabetes drug classes with their common members. generic marks drugs sold
+// as generics or biosimilars in many countries; availability still varies.
+var drugClasses = []drugClass{
+	{"Biguanide", []classDrug{
+		{name: "metformin", brands: []string{"glucophage", "glumetza", "fortamet"}, generic: true},
+	}},
+	{"Sulfonylurea", []classDrug{
+		{name: "glipizide", brands: []string{"glucotrol"}, generic: true},
+		{name: "glimepiride", brands: []string{"amaryl"}, generic: true},
+		{name: "glyburide", brands: []string{"diabeta", "glynase"}, aliases: []string{"glibenclamide"}, generic: true, note: "higher low blood sugar risk than other sulfonylureas, especially in older adults"},
+		{name: "gliclazide", brands: []string{"diamicron"}, generic: true, note: "not sold in the US"},
+	}},
+	{"Meglitinide", []classDrug{
+		{name: "repaglinide", brands: []string{"prandin"}, generic: true},
+		{name: "nateglinide", brands: []string{"starlix"}, generic: true},
+	}},
+	{"Thiazolidinedione", []classDrug{
+		{name: "pioglitazone", brands: []string{"actos"}, generic: true},
+	}},
+	{"Alpha-glucosidase inhibitor", []classDrug{
+		{name: "acarbose", brands: []string{"precose", "glucobay"}, generic: true},
+		{name: "miglitol", brands: []string{"glyset"}},
+	}},
+	{"DPP-4 inhibitor", []classDrug{
+		{name: "sitagliptin", brands: []string{"januvia", "zituvio"}},
+		{name: "linagliptin", brands: []string{"tradjenta", "trajenta"}},
+		{name: "saxagliptin", brands: []string{"onglyza"}},
+		{name: "alogliptin", brands: []string{"nesina", "vipidia"}},
+	}},
+	{"SGLT2 inhibitor", []classDrug{
+		{name: "empagliflozin", brands: []string{"jardiance"}},
+		{name: "dapagliflozin", brands: []string{"farxiga", "forxiga"}},
+		{name: "canagliflozin", brands: []string{"invokana"}},
+		{name: "ertugliflozin", brands: []string{"steglatro"}},
+	}},
+	{"GLP-1 receptor agonist", []classDrug{
+		{name: "semaglutide", brands: []string{"ozempic", "rybelsus"}, note: "weekly injection, or a daily tablet as Rybelsus"},
+		{name: "liraglutide", brands: []string{"victoza"}, generic: true, note: "daily injection"},
+		{name: "dulaglutide", brands: []string{"trulicity"}, note: "weekly injection"},
+		{name: "exenatide", brands: []string{"byetta", "bydureon"}, note: "twice-daily or weekly injection"},
+		{name: "tirzepatide", brands: []string{"mounjaro"}, note: "dual GIP/GLP-1 agonist, weekly injection"},
+	}},
+	{"Basal insulin", []classDrug{
+		{name: "insulin glargine", brands: []string{"lantus", "basaglar", "semglee", "rezvoglar", "toujeo"}, aliases: []string{"glargine"}, generic: true},
+		{name: "insulin degludec", brands: []string{"tresiba"}, aliases: []string{"degludec"}},
+		{name: "insulin detemir", brands: []string{"levemir"}, aliases: []string{"detemir"}, note: "discontinued in some countries, including the US"},
+		{name: "NPH insulin", brands: []string{"humulin n", "novolin n", "insulatard"}, aliases: []string{"nph", "isophane insulin", "insulin isophane"}, generic: true, note: "intermediate-acting human insulin, usually the lowest-cost option; it peaks, so doses and timing differ and need your prescriber"},
+	}},
+	{"Mealtime insulin", []classDrug{
+		{name: "insulin lispro", brands: []string{"humalog", "admelog", "lyumjev"}, aliases: []string{"lispro"}, generic: true},
+		{name: "insulin aspart", brands: []string{"novolog", "novorapid", "fiasp"}, aliases: []string{"aspart"}, generic: true},
+		{name: "insulin glulisine", brands: []string{"apidra"}, aliases: []string{"glulisine"}},
+		{name: "regular insulin", brands: []string{"humulin r", "novolin r", "actrapid"}, aliases: []string{"insulin regular", "regular human insulin", "insulin human"}, generic: true, note: "short-acting human insulin, usually the lowest-cost option; taken 30 minutes before meals and lasts longer"},
+	}},
+}
+
+// Insurance coverage options
+var coverages = []string{"cash_pay", "tier_1", "tier_2", "tier_3", "tier_4", "tier_5", "unknown"}
+
+// Medication Alternatives Input Struct
+type MedicationAlternativesInput struct {
+	Medication string `json:"medication" jsonschema:"description=Medication you take now, brand or generic name"`
+	Country    string `json:"country,omitempty" jsonschema:"description=Country where you fill prescriptions (default US)"`
+	Coverage   string `json:"coverage,omitempty" jsonschema:"description=cash_pay, your plan's tier for the drug (tier_1 to tier_5), or unknown"`
+}
+
+// Drug Alternative Struct
+type DrugAlternative struct {
+	Name             string     `json:"name"`
+	Brands           []string   `json:"brands,omitempty"`
+	Generic          bool       `json:"generic" jsonschema:"description=Sold as a generic or biosimilar in many countries"`
+	Note             string     `json:"note,omitempty"`
+	HypoglycemiaRisk string     `json:"hypoglycemia_risk,omitempty" jsonschema:"description=What the FDA label says about hypoglycemia"`
+	LabelFacts       []fda.Fact `json:"label_facts,omitempty" jsonschema:"description=Facts quoted from the FDA drug label"`
+}
+
+// Medication Alternatives Output Struct
+type MedicationAlternativesOutput struct {
+	Medication   string            `json:"medication"`
+	Class        string            `json:"class"`
+	Alternatives []DrugAlternative `json:"alternatives" jsonschema:"description=Other drugs in the same class, generics first"`
+	Discussion   string            `json:"discussion" jsonschema:"description=Options and questions to raise with your prescriber"`
+	CostTips     []string          `json:"cost_tips"`
+	Grounded     bool              `json:"grounded" jsonschema:"description=True when FDA label data was found for the drugs"`
+	Disclaimer   string            `json:"disclaimer"`
+}
+
+// Statement returned with every answer
+const alternativesDisclaimer = "This is not a prescription or a recommendation to switch. Drugs in the same class differ in dosing, side effects and what they are approved for, and prices and coverage change often. Talk to your prescriber before changing or stopping any medication."
+
+// Alternatives with labels fetched, to bound openFDA calls
+const maxAlternativeLabels = 4
+
+// Medication Alternatives Flow
+//
+// Lists lower-cost options in the same class as a medication, for the patient
+// to raise with their prescriber. It never recommends a switch.
+type MedicationAlternatives struct {
+	Labels *fda.Client
+}
+
+func (f MedicationAlternatives) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "medicationAlternatives", func(ctx context.Context, input *MedicationAlternativesInput) (*MedicationAlternativesOutput, error) {
+		name := strings.TrimSpace(input.Medication)
+		if name == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "medication is required", nil)
+		}
+		coverage := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input.Coverage), " ", "_"))
+		if coverage == "" {
+			coverage = "unknown"
+		}
+		if !slices.Contains(coverages, coverage) {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "coverage must be one of "+strings.Join(coverages, ", "), nil)
+		}
+		country := strings.TrimSpace(input.Country)
+		if country == "" {
+			country = "US"
+		}
+
+		// Find the class from the table, falling back to the label's generic name
+		var current fda.Label
+		class, drug, ok := findDrug(name)
+		if ok {
+			current = f.label(ctx, drug.name)
+		} else {
+			current = f.label(ctx, name)
+			for _, generic := range current.GenericNames {
+				if !ok {
+					class, drug, ok = findDrug(generic)
+				}
+			}
+		}
+		if !ok {
+			msg := fmt.Sprintf("%s is not in our table of diabetes drug classes; try its generic name", name)
+			if len(current.PharmClasses) > 0 {
+				msg = fmt.Sprintf("%s (%s) is not in our table of diabetes drug classes; ask your pharmacist about lower-cost drugs in that class", name, strings.Join(current.PharmClasses, ", "))
+			}
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, msg, nil)
+		}
+
+		output := &MedicationAlternativesOutput{
+			Medication:   drug.name,
+			Class:        class.name,
+			Alternatives: []DrugAlternative{},
+			CostTips:     costTips(coverage, country),
+			Grounded:     current.SetID != "",
+			Disclaimer:   alternativesDisclaimer,
+		}
+		var members []classDrug
+		for _, d := range class.drugs {
+			if d.name != drug.name {
+				members = append(members, d)
+			}
+		}
+		slices.SortStableFunc(members, func(a, b classDrug) int {
+			switch {
+			case a.generic == b.generic:
+				return 0
+			case a.generic:
+				return -1
+			}
+			return 1
+		})
+
+		// Ground each alternative in its FDA label
+		var table, labels []string
+		if current.SetID != "" {
+			labels = append(labels, fmt.Sprintf("%s:\n%s", drug.name, current.PromptSummary()))
+		}
+		for i, d := range members {
+			alt := DrugAlternative{Name: d.name, Brands: d.brands, Generic: d.generic, Note: d.note}
+			if i < maxAlternativeLabels {
+				if label := f.label(ctx, d.name); label.SetID != "" {
+					alt.LabelFacts = label.Facts()
+					alt.HypoglycemiaRisk = label.HypoglycemiaRisk
+					labels = append(labels, fmt.Sprintf("%s:\n%s", d.name, label.PromptSummary()))
+					output.Grounded = true
+				}
+			}
+			output.Alternatives = append(output.Alternatives, alt)
+
+			line := "- " + d.name
+			if d.generic {
+				line += " (generic)"
+			}
+			if d.note != "" {
+				line += ": " + d.note
+			}
+			table = append(table, line)
+		}
+		if len(table) == 0 {
+			table = append(table, "(none: this is the only common drug in its class)")
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("medicationAlternatives"), drug.name, class.name, country, strings.ReplaceAll(coverage, "_", " "),
+			strings.Join(table, "\n"), strings.Join(labels, "\n"))
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discuss medication alternatives: %w", err)
+		}
+		output.Discussion = result.Text()
+		return output, nil
+	})
+	mux.HandleCachedFlow("POST /medicationAlternatives", flow, "Lower-cost drugs in the same class to raise with your prescriber")
+}
+
+// Helper function to fetch a label, empty when there is none or openFDA fails
+func (f MedicationAlternatives) label(ctx context.Context, name string) fda.Label {
+	if f.Labels == nil {
+		return fda.Label{}
+	}
+	label, err := f.Labels.Label(ctx, name)
+	if err != nil && !errors.Is(err, fda.ErrNotFound) {
+		log.Printf("FDA label lookup failed for %q: %v", name, err)
+	}
+	return label
+}
+
+// Words to ignore when matching a drug name, such as strengths, forms and salts
+var doseWords = regexp.MustCompile(`\b(\d+(\.\d+)?\s*(mg|mcg|units?|u)?|er|xr|xl|sr|tablets?|pens?|injection|flextouch|kwikpen|solostar|hydrochloride|hcl|phosphate|propanediol|benzoate|maleate)\b`)
+
+// Helper function to find a drug and its class by generic or brand name
+func findDrug(name string) (drugClass, classDrug, bool) {
+	key := strings.Join(strings.Fields(doseWords.ReplaceAllString(strings.ToLower(name), " ")), " ")
+	for _, class := range drugClasses {
+		for _, d := range class.drugs {
+			if key == strings.ToLower(d.name) || slices.Contains(d.brands, key) || slices.Contains(d.aliases, key) {
+				return class, d, true
+			}
+		}
+	}
+	return drugClass{}, classDrug{}, false
+}
+
+// Helper function to suggest ways to lower the cost that fit the coverage
+func costTips(coverage, country string) []string {
+	var tips []string
+	switch coverage {
+	case "cash_pay":
+		tips = append(tips,
+			"Ask each pharmacy for its cash price; prices for the same drug can differ widely between pharmacies.",
+			"Ask about pharmacy discount cards and the manufacturer's patient assistance program for brand-only drugs.")
+	case "tier_3", "tier_4", "tier_5":
+		tips = append(tips,
+			"Ask your plan which drug in this class is preferred on a lower tier.",
+			"If your current drug is the right one for you, your prescriber can ask your plan for a tier exception.")
+	case "tier_1", "tier_2":
+		tips = append(tips, "Your drug is already on a low tier, so savings from switching may be small; check your plan's copay for each option.")
+	default:
+		tips = append(tips, "Check your plan's formulary or ask your pharmacist which tier each option is on.")
+	}
+	tips = append(tips, "A 90-day supply or mail-order pharmacy often costs less per month.")
+	if !strings.EqualFold(country, "US") && !strings.EqualFold(country, "USA") && !strings.EqualFold(country, "United States") {
+		tips = append(tips, "Which drugs are subsidised is set by your national formulary or insurer; your pharmacist can tell you which are covered in "+country+".")
+	}
+	return tips
+}
@@ -0,0 +1,131 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Per-meal carb target used when the request doesn't give one
+const defaultMealCarbTarget = 45.0
+
+// Portion Struct
+type Portion struct {
+	Grams       float64 `json:"grams,omitempty"`
+	Servings    float64 `json:"servings,omitempty"`
+	Carbs       float64 `json:"carbs"`
+	Description string  `json:"description"`
+}
+
+// BarcodeAssessment Input Struct
+type BarcodeInput struct {
+	Product    nutrition.Product `json:"product"`
+	CarbTarget float64           `json:"carb_target"`
+	Portion    Portion           `json:"portion"`
+}
+
+// BarcodeAssessment Output Struct
+type BarcodeOutput struct {
+	Product          nutrition.Product `json:"product"`
+	CarbTarget       float64           `json:"carb_target"`
+	SuggestedPortion Portion           `json:"suggested_portion"`
+	Assessment       string            `json:"assessment"`
+}
+
+// Helper function to size a portion that keeps carbs within a target.
+// Portions are rounded down so they never exceed the target.
+func suggestPortion(p nutrition.Product, target float64) Portion {
+	carbs := p.Per100g.Carbs
+	if carbs <= 0 {
+		return Portion{Description: "Contains no carbohydrates; carbs don't limit the portion"}
+	}
+
+	grams := math.Floor(target/carbs*100/5) * 5
+	portion := Portion{Grams: grams, Carbs: math.Round(grams*carbs/10) / 10}
+	portion.Description = fmt.Sprintf("%.0f g (about %.0f g carbs)", grams, portion.Carbs)
+	if p.ServingGrams > 0 {
+		portion.Servings = math.Floor(grams/p.ServingGrams*4) / 4
+		portion.Description = fmt.Sprintf("%g serving(s) of %s, or %s", portion.Servings, p.ServingSize, portion.Description)
+	}
+	if grams == 0 {
+		portion.Description = fmt.Sprintf("Even a small amount exceeds a %.0f g carb target", target)
+	}
+	return portion
+}
+
+// Barcode Lookup Flow
+//
+// Served as GET /food/barcode/{ean} rather than through genkit.Handler,
+// so the product lookup runs before the flow and only the assessment is traced.
+type BarcodeLookup struct {
+	Products *nutrition.OpenFoodFacts
+}
+
+func (f BarcodeLookup) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "barcodeAssessment", func(ctx context.Context, input *BarcodeInput) (*BarcodeOutput, error) {
+		p, n := input.Product, input.Product.Per100g
+		name := strings.TrimSpace(p.Brand + " " + p.Name)
+		serving := p.ServingSize
+		if serving == "" {
+			serving = "not given"
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("barcodeAssessment"), name, n.Carbs, n.Sugars, n.Fiber, n.Protein, n.Fat, n.Calories, serving, input.CarbTarget, input.Portion.Description)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assess product: %w", err)
+		}
+
+		return &BarcodeOutput{
+			Product:          p,
+			CarbTarget:       input.CarbTarget,
+			SuggestedPortion: input.Portion,
+			Assessment:       strings.TrimSpace(result.Text()),
+		}, nil
+	})
+
+	mux.HandlePublic("GET /food/barcode/{ean}", "Nutrition facts and diabetes-friendliness for a packaged food", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := defaultMealCarbTarget
+		if v := r.URL.Query().Get("carb_target"); v != "" {
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil || t < 5 || t > 150 {
+				server.WriteError(w, r, "carb_target must be between 5 and 150 grams", http.StatusBadRequest)
+				return
+			}
+			target = t
+		}
+
+		ean := r.PathValue("ean")
+		if !nutrition.ValidBarcode(ean) {
+			server.WriteError(w, r, "invalid barcode", http.StatusBadRequest)
+			return
+		}
+		product, err := f.Products.Product(r.Context(), ean)
+		if errors.Is(err, nutrition.ErrNotFound) {
+			server.WriteError(w, r, "product not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			server.WriteError(w, r, "product lookup failed", http.StatusBadGateway)
+			return
+		}
+
+		out, err := flow.Run(r.Context(), &BarcodeInput{Product: product, CarbTarget: target, Portion: suggestPortion(product, target)})
+		if err != nil {
+			server.WriteErrorFor(w, r, fmt.Errorf("failed to assess product: %w", err))
+			return
+		}
+		server.WriteJSON(w, http.StatusOK, out)
+	}))
+}
@@ -0,0 +1,100 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/guidelines"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// BloodSugar Input Struct
+type BloodSugarInput struct {
+	UserID     string  `json:"user_id,omitempty" jsonschema:"description=User identifier used to store the reading (optional)"`
+	Reading    float64 `json:"reading" jsonschema:"description=Blood sugar reading in mg/dL"`
+	MealTiming string  `json:"meal_timing" jsonschema:"description=Timing: fasting, before_meal, after_meal"`
+	MealType   string  `json:"meal_type" jsonschema:"description=Type of meal: breakfast, lunch, dinner, snack"`
+}
+
+// BloodSugar Output Struct
+type BloodSugarOutput struct {
+	Status         string `json:"status" jsonschema:"description=Status: normal, high, low, critical"`
+	Interpretation string `json:"interpretation" jsonschema:"description=Detailed interpretation"`
+	Recommendation string `json:"recommendation" jsonschema:"description=Immediate recommendations"`
+}
+
+// Blood Sugar Interpreter Flow
+type BloodSugar struct {
+	Readings   *store.ReadingStore
+	Eval       *Evaluator
+	Guidelines *guidelines.Index
+}
+
+func (f BloodSugar) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "bloodSugarInterpreter", func(ctx context.Context, input *BloodSugarInput) (*BloodSugarOutput, error) {
+		if input.Reading <= 0 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "reading must be positive", nil)
+		}
+		rng := thresholds.Ranges()
+		prompt := fmt.Sprintf(prompts.Get("bloodSugarInterpreter"), input.Reading, input.MealTiming, input.MealType, glucoseGuidelines(rng))
+		prompt = ground(ctx, f.Guidelines, prompt, fmt.Sprintf("What does a blood glucose of %.0f mg/dL %s mean and what should I do?", input.Reading, input.MealTiming))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpret blood sugar: %w", err)
+		}
+
+		reading := store.GlucoseReading{
+			UserID:     input.UserID,
+			Value:      input.Reading,
+			MealTiming: input.MealTiming,
+		}
+		if reading.UserID == "" {
+			reading.UserID = rbac.User(ctx)
+		}
+		store.Stamp(&reading.UserID, &reading.Timestamp)
+		if !dryrun.Active(ctx) {
+			f.Readings.Add(ctx, reading)
+		}
+
+		// Determine status based on reading
+		status := "normal"
+		if input.Reading < rng.Low {
+			status = "low"
+		} else if input.Reading > rng.VeryHigh {
+			status = "critical"
+		} else if input.Reading > rng.High {
+			status = "high"
+		}
+
+		text := result.Text()
+		parts := parse.SplitIntoSections(text, 3)
+
+		output := &BloodSugarOutput{
+			Status:         status,
+			Interpretation: parts[0],
+			Recommendation: parts[1],
+		}
+		f.Eval.Sample(ctx, "bloodSugarInterpreter", input, output)
+		return output, nil
+	})
+	mux.HandleWriteFlow("POST /bloodSugar", flow, "Interpret blood sugar readings")
+}
+
+// Helper function to render the glucose bands for the prompt, matching how the status is set
+func glucoseGuidelines(rng thresholds.Range) string {
+	return fmt.Sprintf(`- Target range: %.0f-%.0f mg/dL
+- Below %.0f is low (hypoglycemia); below %.0f is very low
+- Above %.0f is high
+- Above %.0f is critical and requires immediate attention`,
+		rng.Low, rng.High, rng.Low, rng.VeryLow, rng.High, rng.VeryHigh)
+}
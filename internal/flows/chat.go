@@ -0,0 +1,180 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/requestid"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/sessions"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Default token budget for a conversation's summary and recent messages
+const DefaultChatTokenBudget = 6000
+
+// Chat Input Struct
+type ChatInput struct {
+	UserID    string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=Conversation to continue; leave out to start a new one"`
+	Message   string `json:"message" jsonschema:"description=Your message"`
+}
+
+// Chat Output Struct
+type ChatOutput struct {
+	SessionID     string `json:"session_id" jsonschema:"description=Send back with your next message to continue the conversation"`
+	Reply         string `json:"reply"`
+	Summarized    bool   `json:"summarized,omitempty" jsonschema:"description=Older messages were summarized on this turn to stay within the token budget"`
+	ContextTokens int    `json:"context_tokens" jsonschema:"description=Estimated tokens of conversation sent to the model"`
+}
+
+// Chat Flow
+//
+// A free-form conversation kept in a session. When the summary and messages
+// since it exceed TokenBudget, older messages are folded into the summary by
+// SummaryModel, and only the newest are sent verbatim.
+type Chat struct {
+	Sessions     *sessions.Store
+	TokenBudget  int
+	SummaryModel string
+}
+
+func (f Chat) Register(g *genkit.Genkit, mux *server.Mux) {
+	if f.TokenBudget <= 0 {
+		f.TokenBudget = DefaultChatTokenBudget
+	}
+	flow := genkit.DefineFlow(g, "chat", func(ctx context.Context, input *ChatInput) (*ChatOutput, error) {
+		message := strings.TrimSpace(input.Message)
+		if message == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "message is required", nil)
+		}
+		if estimateTokens(message) > f.TokenBudget/2 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "message is too long; split it into shorter messages", nil)
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		var session *sessions.Session
+		switch {
+		case f.Sessions == nil:
+			session = &sessions.Session{UserID: userID, Flow: "chat"}
+		case input.SessionID == "":
+			session = f.Sessions.Start(userID, "chat")
+		default:
+			var err error
+			session, err = f.Sessions.Get(ctx, input.SessionID, userID)
+			if errors.Is(err, sessions.ErrNotFound) {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "session_id is unknown or has expired; start a new conversation", nil)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load chat session: %w", err)
+			}
+			if session.Flow != "chat" {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "session_id is not a chat conversation", nil)
+			}
+		}
+		session.Add("user", message)
+
+		recent, summarized := f.compact(ctx, g, session)
+		summary := session.Summary
+		if summary == "" {
+			summary = "none"
+		}
+		history := transcript(recent)
+		prompt := fmt.Sprintf(prompts.Get("chat"), summary, history)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate chat reply: %w", err)
+		}
+		reply := strings.TrimSpace(result.Text())
+		session.Add("model", reply)
+
+		if f.Sessions != nil && !dryrun.Active(ctx) {
+			if err := f.Sessions.Save(ctx, session); err != nil {
+				return nil, fmt.Errorf("failed to save chat session: %w", err)
+			}
+		}
+		return &ChatOutput{
+			SessionID:     session.ID,
+			Reply:         reply,
+			Summarized:    summarized,
+			ContextTokens: estimateTokens(session.Summary) + estimateTokens(history),
+		}, nil
+	})
+	mux.HandleWriteFlow("POST /chat", flow, "Chat about diabetes; long conversations are summarized to stay within limits")
+}
+
+// Helper function to keep a conversation within the token budget. The newest
+// messages that fit in half the budget are kept verbatim and the rest are
+// folded into the summary. Returns the messages to send and whether the
+// summary changed. If summarizing fails the older messages are left out of
+// this turn and folded on the next one.
+func (f Chat) compact(ctx context.Context, g *genkit.Genkit, session *sessions.Session) ([]sessions.Message, bool) {
+	recent := session.Recent()
+	if estimateTokens(session.Summary)+estimateTokens(transcript(recent)) <= f.TokenBudget {
+		return recent, false
+	}
+
+	keep, used := 1, estimateTokens(transcript(recent[len(recent)-1:]))
+	for keep < len(recent) {
+		next := estimateTokens(transcript(recent[len(recent)-keep-1 : len(recent)-keep]))
+		if used+next > f.TokenBudget/2 {
+			break
+		}
+		keep, used = keep+1, used+next
+	}
+	fold := recent[:len(recent)-keep]
+	if len(fold) == 0 {
+		return recent, false
+	}
+
+	earlier := session.Summary
+	if earlier == "" {
+		earlier = "none"
+	}
+	prompt := fmt.Sprintf(prompts.Get("chatSummary"), earlier, transcript(fold))
+	var opts []ai.GenerateOption
+	if f.SummaryModel != "" {
+		opts = append(opts, ai.WithModelName(f.SummaryModel))
+	}
+	result, err := generate(costs.WithFlow(ctx, "chatSummary", f.SummaryModel), g, prompt, opts...)
+	if err != nil {
+		log.Printf("chat summary failed, %d older message(s) left out request_id=%s: %v", len(fold), requestid.From(ctx), err)
+		return recent[len(fold):], false
+	}
+
+	session.Summary = strings.TrimSpace(result.Text())
+	session.Summarized += len(fold)
+	return session.Recent(), true
+}
+
+// Helper function to lay out messages for a prompt
+func transcript(messages []sessions.Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		speaker := "User"
+		if m.Role == "model" {
+			speaker = "Assistant"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", speaker, m.Content)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Helper function to estimate the tokens in a text, at about four characters each
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
@@ -0,0 +1,117 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Oldest day that can be reviewed
+const maxReviewDaysBack = 7
+
+// DayReview Input Struct
+type DayReviewInput struct {
+	UserID      string   `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Date        string   `json:"date,omitempty" jsonschema:"description=Day to review, YYYY-MM-DD (default today, up to 7 days back)"`
+	MissedDoses []string `json:"missed_doses,omitempty" jsonschema:"description=Scheduled medications you missed today. Send an empty list if you took them all (optional)"`
+	Note        string   `json:"note,omitempty" jsonschema:"description=Anything about your day you want to reflect on (optional)"`
+}
+
+// DayReview Output Struct
+type DayReviewOutput struct {
+	Day        analytics.Day `json:"day" jsonschema:"description=What was logged during the day"`
+	Reflection string        `json:"reflection" jsonschema:"description=A walk through the day"`
+	Wins       string        `json:"wins" jsonschema:"description=What went well"`
+	Intention  string        `json:"intention" jsonschema:"description=One concrete intention for tomorrow"`
+}
+
+// Day Review Flow
+//
+// An end-of-day reflection on the day's readings, meals, activity and
+// medications. Each review is kept, so the next day's review and the weekly
+// summary can look back at the intentions set.
+type DayReview struct {
+	Stores *store.Stores
+}
+
+func (f DayReview) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "dayReview", func(ctx context.Context, input *DayReviewInput) (*DayReviewOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+		now := time.Now().In(locale.From(ctx).Location)
+		day := startOfDay(now)
+		if input.Date != "" {
+			d, err := time.ParseInLocation("2006-01-02", input.Date, now.Location())
+			if err != nil || d.After(day) || d.Before(day.AddDate(0, 0, -maxReviewDaysBack)) {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, fmt.Sprintf("date must be YYYY-MM-DD, between today and %d days ago", maxReviewDaysBack), nil)
+			}
+			day = d
+		}
+
+		summary := analytics.BuildDay(f.Stores, userID, day, input.MissedDoses)
+		previous := "none set"
+		if review, ok := f.review(userID, day.AddDate(0, 0, -1)); ok {
+			previous = review.Intention
+		}
+		note := strings.TrimSpace(input.Note)
+		if note == "" {
+			note = "none"
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("dayReview"), locale.From(ctx).Date(day), summary.PromptSummary(), previous, note)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate day review: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 3)
+		review := store.DayReview{
+			UserID:     userID,
+			Date:       summary.Date,
+			Reflection: parts[0],
+			Wins:       parts[1],
+			Intention:  parts[2],
+		}
+		if !dryrun.Active(ctx) {
+			store.Stamp(&review.UserID, &review.Timestamp)
+			f.Stores.DayReviews.Add(ctx, review)
+		}
+
+		return &DayReviewOutput{
+			Day:        summary,
+			Reflection: review.Reflection,
+			Wins:       review.Wins,
+			Intention:  review.Intention,
+		}, nil
+	})
+	mux.HandleWriteFlow("POST /dayReview", flow, "Reflect on the day and set one intention for tomorrow")
+}
+
+// Helper function to find the latest review of a day. Reviews are written on
+// the day or up to a week later.
+func (f DayReview) review(userID string, day time.Time) (store.DayReview, bool) {
+	date := day.Format("2006-01-02")
+	reviews := f.Stores.DayReviews.Range(userID, day, day.AddDate(0, 0, maxReviewDaysBack+1))
+	for i := len(reviews) - 1; i >= 0; i-- {
+		if reviews[i].Date == date {
+			return reviews[i], true
+		}
+	}
+	return store.DayReview{}, false
+}
@@ -0,0 +1,79 @@
+package flows
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DKA Screen Struct
+type DKAScreen struct {
+	Positive bool     `json:"positive" jsonschema:"description=Whether DKA screening criteria were met"`
+	Reasons  []string `json:"reasons,omitempty" jsonschema:"description=Criteria that were met"`
+}
+
+// Helper function to check for a ketone tier at or above a level
+func ketonesAtLeast(in *SymptomInput, tier string) bool {
+	order := map[string]int{ketonesSmall: 1, ketonesModerate: 2, ketonesLarge: 3}
+	return order[ketoneTier(in.Ketones, in.BloodKetones)] >= order[tier]
+}
+
+// Deterministic DKA screening rules for the symptom checker.
+// Any rule firing forces the urgency to emergency.
+var dkaRules = []Rule[*SymptomInput]{
+	{
+		ID:       "large-ketones",
+		When:     func(in *SymptomInput) bool { return ketonesAtLeast(in, ketonesLarge) },
+		Step:     "Large ketones",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID: "high-bg-moderate-ketones",
+		When: func(in *SymptomInput) bool {
+			return in.CurrentBG >= highBGActionThreshold() && ketonesAtLeast(in, ketonesModerate)
+		},
+		StepText: func() string { return highBGWith("moderate ketones") },
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "high-bg-vomiting",
+		When:     func(in *SymptomInput) bool { return in.CurrentBG >= highBGActionThreshold() && in.Vomiting },
+		StepText: func() string { return highBGWith("vomiting") },
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "high-bg-fruity-breath",
+		When:     func(in *SymptomInput) bool { return in.CurrentBG >= highBGActionThreshold() && in.FruityBreath },
+		StepText: func() string { return highBGWith("fruity-smelling breath") },
+		Escalate: escalateEmergency,
+	},
+	{
+		ID: "ketones-with-nausea",
+		When: func(in *SymptomInput) bool {
+			return ketonesAtLeast(in, ketonesSmall) && (in.Nausea || in.Vomiting) && (in.CurrentBG == 0 || in.CurrentBG >= highBGActionThreshold())
+		},
+		Step:     "Ketones present with nausea or vomiting",
+		Escalate: escalateEmergency,
+	},
+}
+
+// Helper function to name a DKA criterion of high blood sugar with another sign
+func highBGWith(sign string) string {
+	return fmt.Sprintf("Blood sugar above %.0f mg/dL with %s", highBGActionThreshold(), sign)
+}
+
+// Screen symptom checker input for diabetic ketoacidosis
+func screenDKA(in *SymptomInput) DKAScreen {
+	verdict := evaluateRules(dkaRules, in)
+	return DKAScreen{
+		Positive: verdict.Escalation == escalateEmergency,
+		Reasons:  verdict.Steps,
+	}
+}
+
+// Helper function to render the DKA screen as a prompt line
+func (s DKAScreen) PromptSummary() string {
+	if !s.Positive {
+		return "DKA screening: criteria not met."
+	}
+	return "DKA screening: POSITIVE (" + strings.Join(s.Reasons, "; ") + "). Treat this as a medical emergency: advise going to the emergency room now."
+}
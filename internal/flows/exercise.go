@@ -0,0 +1,96 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/guidelines"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Exercise Input Struct
+type ExerciseInput struct {
+	UserID        string  `json:"user_id,omitempty" jsonschema:"description=User identifier for personal exercise history (optional)"`
+	FitnessLevel  string  `json:"fitness_level" jsonschema:"description=Fitness level: beginner, intermediate, advanced"`
+	TimeAvailable int     `json:"time_available" jsonschema:"description=Minutes available for exercise"`
+	CurrentBG     float64 `json:"current_bg" jsonschema:"description=Current blood glucose level (optional)"`
+	PreferredType string  `json:"preferred_type" jsonschema:"description=Exercise preference: cardio, strength, yoga, walking"`
+}
+
+// Exercise Output Struct
+type ExerciseOutput struct {
+	SafetyCheck    string `json:"safety_check" jsonschema:"description=Safety considerations based on BG"`
+	Recommendation string `json:"recommendation" jsonschema:"description=Exercise recommendations"`
+	Duration       string `json:"duration" jsonschema:"description=Recommended duration and intensity"`
+	Precautions    string `json:"precautions" jsonschema:"description=Important precautions"`
+}
+
+// Exercise Advisor Flow
+type Exercise struct {
+	Readings   *store.ReadingStore
+	Workouts   *store.LogStore[store.WorkoutLog]
+	Water      *store.LogStore[store.WaterLog]
+	Activity   *store.LogStore[store.ActivitySample]
+	Programs   *store.ProgramStore
+	Guidelines *guidelines.Index
+}
+
+func (f Exercise) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "exerciseAdvisor", func(ctx context.Context, input *ExerciseInput) (*ExerciseOutput, error) {
+		cutoffs := thresholds.Current().Exercise
+		bgInfo := fmt.Sprintf("Safety cutoffs: below %.0f mg/dL eat a snack first, %.0f-%.0f mg/dL is generally safe, above %.0f mg/dL delay exercise and check ketones",
+			cutoffs.SnackBelow, cutoffs.SnackBelow, cutoffs.DelayAbove, cutoffs.DelayAbove)
+		if input.CurrentBG > 0 {
+			bgInfo = fmt.Sprintf("Current Blood Glucose: %.1f mg/dL\n%s", input.CurrentBG, bgInfo)
+		}
+
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+		to := time.Now()
+		from := to.AddDate(0, 0, -90)
+		responses := analytics.WorkoutResponses(f.Workouts.Range(userID, from, to), f.Readings.Range(userID, from.Add(-analytics.BaselineWindow), to))
+		historyInfo := analytics.ExerciseHistoryNote(analytics.ExercisePatterns(responses), input.PreferredType)
+		historyInfo += "\n" + analytics.RecentActivity(f.Activity, f.Workouts, userID, to).PromptSummary()
+
+		hydrationInfo := analytics.TodayHydration(f.Water, userID, to, locale.From(ctx).Location).PromptSummary()
+
+		prompt := fmt.Sprintf(prompts.Get("exerciseAdvisor"), input.FitnessLevel, input.TimeAvailable, bgInfo, input.PreferredType, historyInfo, hydrationInfo)
+		query := fmt.Sprintf("Is it safe to do %s exercise for %d minutes?", input.PreferredType, input.TimeAvailable)
+		if input.CurrentBG > 0 {
+			query += fmt.Sprintf(" My blood glucose is %.0f mg/dL.", input.CurrentBG)
+		}
+		prompt = ground(ctx, f.Guidelines, prompt, query)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate exercise plan: %w", err)
+		}
+
+		text := result.Text()
+		parts := parse.SplitIntoSections(text, 4)
+
+		return &ExerciseOutput{
+			SafetyCheck:    parts[0],
+			Recommendation: parts[1],
+			Duration:       parts[2],
+			Precautions:    parts[3],
+		}, nil
+	})
+	mux.HandleFlow("POST /exercise", flow, "Get safe exercise recommendations")
+
+	if f.Programs != nil {
+		f.registerProgram(g, mux)
+	}
+}
@@ -0,0 +1,236 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Program progress states
+const (
+	programStarted    = "started"
+	programInProgress = "in_progress"
+	programAdvanced   = "advanced"
+	programComplete   = "complete"
+)
+
+// Starting level for each fitness level
+var programStartLevels = map[string]int{
+	"beginner":     1,
+	"intermediate": 2,
+	"advanced":     3,
+}
+
+// Exercise Program Input Struct
+type ExerciseProgramInput struct {
+	UserID          string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	FitnessLevel    string `json:"fitness_level,omitempty" jsonschema:"description=Fitness level: beginner, intermediate, advanced (needed to start a program)"`
+	PreferredType   string `json:"preferred_type,omitempty" jsonschema:"description=Exercise preference: cardio, strength, yoga, walking (default walking)"`
+	Goal            string `json:"goal,omitempty" jsonschema:"description=What you want from the program, e.g. walk 5 km or lower after-meal spikes (optional)"`
+	TimeAvailable   int    `json:"time_available,omitempty" jsonschema:"description=Minutes available per session (default 30)"`
+	SessionsPerWeek int    `json:"sessions_per_week,omitempty" jsonschema:"description=Sessions per week, 1 to 7 (default 3)"`
+	Weeks           int    `json:"weeks,omitempty" jsonschema:"description=Program length in weeks, 1 to 12 (default 4)"`
+	Restart         bool   `json:"restart,omitempty" jsonschema:"description=Replace the current program with a new one"`
+}
+
+// Exercise Program Output Struct
+type ExerciseProgramOutput struct {
+	Status  string                `json:"status" jsonschema:"description=started, in_progress, advanced or complete"`
+	Week    *store.ProgramWeek    `json:"week,omitempty" jsonschema:"description=The week in progress"`
+	Program store.ExerciseProgram `json:"program"`
+}
+
+// Exercise program week generator
+//
+// The first call starts a program. Later calls track logged workouts against
+// the current week and, once it has ended, plan the next week at a level
+// adapted to adherence and the blood sugar response.
+func (f Exercise) registerProgram(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "exerciseProgram", func(ctx context.Context, input *ExerciseProgramInput) (*ExerciseProgramOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+		now := time.Now()
+
+		program, ok := f.Programs.Get(userID)
+		if !ok || input.Restart {
+			var err error
+			program, err = newProgram(userID, input, now.In(locale.From(ctx).Location))
+			if err != nil {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, err.Error(), nil)
+			}
+			if err := f.planWeek(ctx, g, &program); err != nil {
+				return nil, err
+			}
+			program = f.save(ctx, program)
+			return &ExerciseProgramOutput{Status: programStarted, Week: program.Current(), Program: program}, nil
+		}
+
+		current := f.track(userID, *program.Current())
+		*program.Current() = current
+		status := programInProgress
+		switch {
+		case now.Before(current.End()):
+		case len(program.Weeks) >= program.TotalWeeks:
+			status = programComplete
+		default:
+			level, reason := analytics.NextProgramLevel(current)
+			start := current.End()
+			if now.Sub(start) >= 7*24*time.Hour {
+				start = startOfDay(now.In(locale.From(ctx).Location))
+			}
+			program.Weeks = append(program.Weeks, buildProgramWeek(program, len(program.Weeks)+1, start, level))
+			program.Current().Adjustment = reason
+			if err := f.planWeek(ctx, g, &program); err != nil {
+				return nil, err
+			}
+			status = programAdvanced
+		}
+
+		program = f.save(ctx, program)
+		return &ExerciseProgramOutput{Status: status, Week: program.Current(), Program: program}, nil
+	})
+	mux.HandleWriteFlow("POST /exercise/program", flow, "Start or advance a multi-week exercise program that adapts to adherence and blood sugar")
+}
+
+// Helper function to store a program, except in a dry run
+func (f Exercise) save(ctx context.Context, program store.ExerciseProgram) store.ExerciseProgram {
+	if dryrun.Active(ctx) {
+		return program
+	}
+	return f.Programs.Set(program)
+}
+
+// Helper function to score a program week against logged workouts and readings
+func (f Exercise) track(userID string, week store.ProgramWeek) store.ProgramWeek {
+	workouts := f.Workouts.Range(userID, week.Start, week.End())
+	readings := f.Readings.Range(userID, week.Start.Add(-analytics.BaselineWindow), week.End().Add(24*time.Hour))
+	return analytics.TrackWeek(week, workouts, readings)
+}
+
+// Helper function to write the guidance for the program's current week
+func (f Exercise) planWeek(ctx context.Context, g *genkit.Genkit, program *store.ExerciseProgram) error {
+	week := program.Current()
+
+	var sessions, history []string
+	for _, s := range week.Sessions {
+		sessions = append(sessions, fmt.Sprintf("Day %d: %s, %d minutes, %s", s.Day, s.Type, s.DurationMinutes, s.Intensity))
+	}
+	for _, w := range program.Weeks[:len(program.Weeks)-1] {
+		history = append(history, analytics.ProgramWeekPrompt(w))
+	}
+	if week.Adjustment != "" {
+		history = append(history, week.Adjustment)
+	}
+	if len(history) == 0 {
+		history = append(history, "This is the first week.")
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -90)
+	responses := analytics.WorkoutResponses(f.Workouts.Range(program.UserID, from, to), f.Readings.Range(program.UserID, from.Add(-analytics.BaselineWindow), to))
+	historyInfo := analytics.ExerciseHistoryNote(analytics.ExercisePatterns(responses), program.PreferredType)
+
+	goal := program.Goal
+	if goal == "" {
+		goal = "general fitness and steadier blood sugar"
+	}
+	prompt := fmt.Sprintf(prompts.Get("exerciseProgram"), program.FitnessLevel, program.PreferredType, goal,
+		week.Number, program.TotalWeeks, week.Level, analytics.MaxProgramLevel,
+		strings.Join(sessions, "\n"), strings.Join(history, "\n"), historyInfo)
+
+	result, err := generate(ctx, g, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to generate exercise program week: %w", err)
+	}
+	week.Guidance = strings.TrimSpace(result.Text())
+	return nil
+}
+
+// Helper function to validate input and lay out week one of a new program
+func newProgram(userID string, input *ExerciseProgramInput, now time.Time) (store.ExerciseProgram, error) {
+	fitness := strings.ToLower(strings.TrimSpace(input.FitnessLevel))
+	level, ok := programStartLevels[fitness]
+	if !ok {
+		return store.ExerciseProgram{}, fmt.Errorf("fitness_level must be beginner, intermediate or advanced to start a program")
+	}
+
+	program := store.ExerciseProgram{
+		UserID:          userID,
+		FitnessLevel:    fitness,
+		PreferredType:   strings.ToLower(strings.TrimSpace(input.PreferredType)),
+		Goal:            input.Goal,
+		TimeAvailable:   input.TimeAvailable,
+		SessionsPerWeek: input.SessionsPerWeek,
+		TotalWeeks:      input.Weeks,
+		Created:         now,
+	}
+	if program.PreferredType == "" {
+		program.PreferredType = "walking"
+	}
+	if program.TimeAvailable == 0 {
+		program.TimeAvailable = 30
+	}
+	if program.SessionsPerWeek == 0 {
+		program.SessionsPerWeek = 3
+	}
+	if program.TotalWeeks == 0 {
+		program.TotalWeeks = 4
+	}
+	switch {
+	case program.TimeAvailable < 10 || program.TimeAvailable > 180:
+		return program, fmt.Errorf("time_available must be between 10 and 180 minutes")
+	case program.SessionsPerWeek < 1 || program.SessionsPerWeek > 7:
+		return program, fmt.Errorf("sessions_per_week must be between 1 and 7")
+	case program.TotalWeeks < 1 || program.TotalWeeks > 12:
+		return program, fmt.Errorf("weeks must be between 1 and 12")
+	}
+
+	program.Weeks = []store.ProgramWeek{buildProgramWeek(program, 1, startOfDay(now), level)}
+	return program, nil
+}
+
+// Helper function to spread a week's sessions at the given level. Higher levels
+// use more of the available time and a harder intensity.
+func buildProgramWeek(program store.ExerciseProgram, number int, start time.Time, level int) store.ProgramWeek {
+	minutes := program.TimeAvailable * (50 + 10*level) / 100
+	minutes = max(10, minutes/5*5)
+
+	intensity := "light"
+	switch {
+	case level >= analytics.MaxProgramLevel:
+		intensity = "vigorous"
+	case level >= 3:
+		intensity = "moderate"
+	}
+
+	week := store.ProgramWeek{Number: number, Start: start, Level: level}
+	for i := range program.SessionsPerWeek {
+		week.Sessions = append(week.Sessions, store.ProgramSession{
+			Day:             1 + i*7/program.SessionsPerWeek,
+			Type:            program.PreferredType,
+			DurationMinutes: minutes,
+			Intensity:       intensity,
+		})
+	}
+	return week
+}
+
+// Helper function to truncate a time to local midnight
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,71 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ExerciseResponse Input Struct
+type ExerciseResponseInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Days   int    `json:"days,omitempty" jsonschema:"description=Number of days to analyze (default 90)"`
+}
+
+// ExerciseResponse Output Struct
+type ExerciseResponseOutput struct {
+	SessionsAnalyzed int                         `json:"sessions_analyzed" jsonschema:"description=Workouts with surrounding readings"`
+	Patterns         []analytics.ExercisePattern `json:"patterns" jsonschema:"description=Glucose response by exercise type"`
+	Insights         string                      `json:"insights" jsonschema:"description=How your body typically responds to each exercise type"`
+	Precautions      string                      `json:"precautions" jsonschema:"description=Personalized precautions"`
+}
+
+// Exercise Response Flow
+type ExerciseResponse struct {
+	Readings *store.ReadingStore
+	Workouts *store.LogStore[store.WorkoutLog]
+}
+
+func (f ExerciseResponse) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "exerciseResponse", func(ctx context.Context, input *ExerciseResponseInput) (*ExerciseResponseOutput, error) {
+		days := input.Days
+		if days <= 0 {
+			days = 90
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -days)
+		responses := analytics.WorkoutResponses(f.Workouts.Range(userID, from, to), f.Readings.Range(userID, from.Add(-analytics.BaselineWindow), to))
+		patterns := analytics.ExercisePatterns(responses)
+
+		prompt := fmt.Sprintf(prompts.Get("exerciseResponse"), days, analytics.ExercisePatternsPrompt(patterns))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze exercise responses: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &ExerciseResponseOutput{
+			SessionsAnalyzed: len(responses),
+			Patterns:         patterns,
+			Insights:         parts[0],
+			Precautions:      parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /exerciseResponse", flow, "Learn how exercise affects your blood sugar")
+}
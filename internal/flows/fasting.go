@@ -0,0 +1,185 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// FastingAdvisor Input Struct
+type FastingInput struct {
+	UserID      string `json:"user_id,omitempty" jsonschema:"description=User identifier for recent readings (optional)"`
+	Protocol    string `json:"protocol" jsonschema:"description=Fasting protocol, e.g. 16:8, 5:2, OMAD, Ramadan, 24h"`
+	Medications string `json:"medications" jsonschema:"description=Current diabetes medications, including insulin"`
+}
+
+// FastingAdvisor Output Struct
+type FastingOutput struct {
+	RiskLevel          string                 `json:"risk_level" jsonschema:"description=Fasting risk: low, moderate, high"`
+	RiskFactors        []string               `json:"risk_factors" jsonschema:"description=Medication and glucose risks found"`
+	Stats              analytics.GlucoseStats `json:"stats" jsonschema:"description=Metrics for the past 14 days"`
+	SafetyGuidance     string                 `json:"safety_guidance" jsonschema:"description=How to approach the fast safely"`
+	MedicationNotes    string                 `json:"medication_notes" jsonschema:"description=How your medications behave during fasting"`
+	MonitoringSchedule []string               `json:"monitoring_schedule" jsonschema:"description=When to check blood sugar"`
+	BreakFastIf        []string               `json:"break_fast_if" jsonschema:"description=Red flags for breaking the fast immediately"`
+}
+
+// Medication classes that change fasting risk
+var (
+	insulinNames     = []string{"insulin", "lantus", "glargine", "levemir", "detemir", "tresiba", "degludec", "toujeo", "basaglar", "humalog", "lispro", "novolog", "novorapid", "aspart", "fiasp", "apidra", "glulisine", "humulin", "novolin", "nph", "mixtard"}
+	sulfonylureas    = []string{"glipizide", "glyburide", "glibenclamide", "glimepiride", "gliclazide", "tolbutamide", "amaryl", "diamicron", "glucotrol"}
+	sglt2Inhibitors  = []string{"empagliflozin", "jardiance", "dapagliflozin", "farxiga", "forxiga", "canagliflozin", "invokana", "ertugliflozin", "steglatro"}
+	extendedProtocol = []string{"omad", "24", "36", "48", "72", "5:2", "alternate", "ramadan", "water fast", "extended"}
+)
+
+// Fasting Context Struct
+type fastingContext struct {
+	input *FastingInput
+	stats analytics.GlucoseStats
+}
+
+// Fasting risk rules, evaluated in order. Each step becomes a listed risk factor.
+var fastingRules = []Rule[*fastingContext]{
+	{
+		ID:       "insulin",
+		When:     func(c *fastingContext) bool { return parse.ContainsKeywords(c.input.Medications, insulinNames) },
+		Step:     "Insulin: doses usually need adjusting before fasting, and lows are likely without a plan from your doctor.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "sulfonylurea",
+		When:     func(c *fastingContext) bool { return parse.ContainsKeywords(c.input.Medications, sulfonylureas) },
+		Step:     "Sulfonylurea: these keep lowering blood sugar even when you do not eat, so the risk of a low is high.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "sglt2",
+		When:     func(c *fastingContext) bool { return parse.ContainsKeywords(c.input.Medications, sglt2Inhibitors) },
+		Step:     "SGLT2 inhibitor: fasting raises the risk of ketoacidosis even with normal blood sugar, and of dehydration.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID: "recent-lows",
+		When: func(c *fastingContext) bool {
+			return c.stats.Count > 0 && c.stats.TimeBelow > thresholds.Current().Fasting.MaxTimeBelow
+		},
+		StepText: func() string {
+			return fmt.Sprintf("Recent lows: more than %.0f%% of your recent readings were below %.0f mg/dL.", thresholds.Current().Fasting.MaxTimeBelow, thresholds.Ranges().Low)
+		},
+		Escalate: escalateDoctor,
+	},
+	{
+		ID: "recent-highs",
+		When: func(c *fastingContext) bool {
+			return c.stats.Count > 0 && c.stats.TimeVeryHigh > thresholds.Current().Fasting.MaxTimeVeryHigh
+		},
+		StepText: func() string {
+			return fmt.Sprintf("Recent highs: more than %.0f%% of your recent readings were above %.0f mg/dL.", thresholds.Current().Fasting.MaxTimeVeryHigh, thresholds.Ranges().VeryHigh)
+		},
+		Escalate: escalateMonitor,
+	},
+	{
+		ID:       "extended-fast",
+		When:     func(c *fastingContext) bool { return parse.ContainsKeywords(c.input.Protocol, extendedProtocol) },
+		Step:     "Long fasting window: fasts of a day or longer carry more risk of lows and dehydration than time-restricted eating.",
+		Escalate: escalateMonitor,
+	},
+	{
+		ID:       "no-readings",
+		When:     func(c *fastingContext) bool { return c.stats.Count == 0 },
+		Step:     "No recent readings logged: start checking and logging your blood sugar before trying a fast.",
+		Escalate: escalateMonitor,
+	},
+}
+
+// Explicit red flags shown with every fasting plan, from the table in force
+func fastingBreakIf() []string {
+	return []string{
+		fmt.Sprintf("Blood sugar below %.0f mg/dL: stop fasting and treat the low right away", thresholds.Ranges().Low),
+		fmt.Sprintf("Blood sugar above %.0f mg/dL", thresholds.Current().Fasting.BreakAbove),
+		"Symptoms of a low: shaking, sweating, confusion, fast heartbeat",
+		"Feeling unwell, dizzy, faint or dehydrated",
+		"Moderate or large ketones, or nausea and vomiting",
+	}
+}
+
+// Helper function to build a monitoring schedule for a risk level
+func fastingMonitoring(risk string) []string {
+	schedule := []string{
+		"Before your last meal ahead of the fast",
+		"On waking and mid-way through the fast",
+		"Before breaking the fast, and 2 hours after your first meal",
+		"Any time you feel unwell or have symptoms of a low",
+	}
+	if risk != "low" {
+		schedule[1] = "Every 2-3 hours while fasting, including on waking"
+	}
+	if risk == "high" {
+		schedule = append(schedule, fmt.Sprintf("Check ketones if blood sugar is above %.0f mg/dL or you feel unwell", highBGActionThreshold()))
+	}
+	return schedule
+}
+
+// Fasting Advisor Flow
+type FastingAdvisor struct {
+	Readings *store.ReadingStore
+}
+
+func (f FastingAdvisor) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "fastingAdvisor", func(ctx context.Context, input *FastingInput) (*FastingOutput, error) {
+		if strings.TrimSpace(input.Protocol) == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "protocol is required", nil)
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -14)
+		stats := analytics.ComputeStats(f.Readings.Range(userID, from, to), from, to)
+
+		verdict := evaluateRules(fastingRules, &fastingContext{input: input, stats: stats})
+		dryrun.Verdict(ctx, "fasting", verdict)
+		risk := "low"
+		switch verdict.Escalation {
+		case escalateDoctor, escalateEmergency:
+			risk = "high"
+		case escalateMonitor:
+			risk = "moderate"
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("fastingAdvisor"), input.Protocol, input.Medications, stats.PromptSummary(), risk, strings.Join(verdict.Steps, "\n"))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate fasting guidance: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &FastingOutput{
+			RiskLevel:          risk,
+			RiskFactors:        verdict.Steps,
+			Stats:              stats,
+			SafetyGuidance:     parts[0],
+			MedicationNotes:    parts[1],
+			MonitoringSchedule: fastingMonitoring(risk),
+			BreakFastIf:        fastingBreakIf(),
+		}, nil
+	})
+	mux.HandleFlow("POST /fastingAdvisor", flow, "Safety guidance for intermittent fasting")
+}
@@ -0,0 +1,73 @@
+// Package flows defines the Genkit flows served by the advisor.
+package flows
+
+import (
+	"diabeticai-advisor/internal/fda"
+	"diabeticai-advisor/internal/guidelines"
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/sessions"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Flow is implemented by each advisor flow. Register defines the flow on g
+// and mounts its HTTP endpoint on mux.
+type Flow interface {
+	Register(g *genkit.Genkit, mux *server.Mux)
+}
+
+// Deps Struct
+//
+// Shared stores and clients handed to the flows that need them.
+type Deps struct {
+	Stores     *store.Stores
+	Nutrition  *nutrition.Client
+	Products   *nutrition.OpenFoodFacts
+	Labels     *fda.Client
+	Sessions   *sessions.Store
+	Evaluator  *Evaluator
+	Guidelines *guidelines.Index
+
+	// Removes personal details from free text kept in sessions
+	Redact store.Redactor
+
+	// Token budget and summary model for chat history
+	ChatTokenBudget  int
+	ChatSummaryModel string
+}
+
+// All flows in the order their endpoints are registered
+func All(d Deps) []Flow {
+	s := d.Stores
+	all := []Flow{
+		BloodSugar{Readings: s.Readings, Eval: d.Evaluator, Guidelines: d.Guidelines},
+		MealPlan{Nutrition: d.Nutrition},
+		Recipe{Nutrition: d.Nutrition},
+		Symptoms{Checks: s.Symptoms, Readings: s.Readings, Sessions: d.Sessions, Eval: d.Evaluator, Redact: d.Redact},
+		Exercise{Readings: s.Readings, Workouts: s.Workouts, Water: s.Water, Activity: s.Activity, Programs: s.Programs, Guidelines: d.Guidelines},
+		Medication{Labels: d.Labels},
+		MedSchedule{Labels: d.Labels, Sessions: d.Sessions, Schedules: s.Schedules},
+		MedicationAlternatives{Labels: d.Labels},
+		GlucoseTrends{Readings: s.Readings},
+		WeeklySummary{Readings: s.Readings, Reviews: s.DayReviews},
+		MealCorrelation{Readings: s.Readings, Meals: s.Meals},
+		ExerciseResponse{Readings: s.Readings, Workouts: s.Workouts},
+		HypoRisk{Insulin: s.Insulin, Activity: s.Activity, Workouts: s.Workouts},
+		HypoReview{Stores: s},
+		DayReview{Stores: s},
+		ICREstimator{Readings: s.Readings, Meals: s.Meals, Insulin: s.Insulin},
+		GlucoseForecast{Readings: s.Readings, Meals: s.Meals, Insulin: s.Insulin},
+		HighBGAction{},
+		InsulinStorage{},
+		FastingAdvisor{Readings: s.Readings},
+		LabPrep{Stores: s},
+		BarcodeLookup{Products: d.Products},
+		Chat{Sessions: d.Sessions, TokenBudget: d.ChatTokenBudget, SummaryModel: d.ChatSummaryModel},
+	}
+	if d.Evaluator != nil {
+		all = append(all, d.Evaluator)
+	}
+	return all
+}
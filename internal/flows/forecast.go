@@ -0,0 +1,138 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// GlucoseForecast Input Struct
+type GlucoseForecastInput struct {
+	UserID          string                     `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Hours           int                        `json:"hours,omitempty" jsonschema:"description=Hours to project, 1 to 4 (default 2)"`
+	CurrentBG       float64                    `json:"current_bg,omitempty" jsonschema:"description=Current blood glucose in mg/dL (optional when a reading was logged in the last 15 minutes)"`
+	PlannedActivity *analytics.PlannedActivity `json:"planned_activity,omitempty" jsonschema:"description=Exercise planned in the forecast window (optional)"`
+	ISF             float64                    `json:"isf,omitempty" jsonschema:"description=Correction factor in mg/dL per unit (optional, estimated from your logs otherwise)"`
+	CarbRatio       float64                    `json:"carb_ratio,omitempty" jsonschema:"description=Carb ratio in grams per unit (optional, estimated from your logs otherwise)"`
+}
+
+// GlucoseForecast Output Struct
+type GlucoseForecastOutput struct {
+	Forecast analytics.Forecast `json:"forecast" jsonschema:"description=Projected blood glucose every 15 minutes with confidence bands"`
+	Outlook  string             `json:"outlook" jsonschema:"description=Where blood sugar is heading"`
+	Drivers  string             `json:"drivers" jsonschema:"description=What pushes it up or down"`
+	Actions  string             `json:"actions" jsonschema:"description=When to check again and what to do"`
+}
+
+// How recent a logged reading must be to start the forecast from
+const forecastReadingWindow = 15 * time.Minute
+
+// Days of logs the ratios are estimated from when not given
+const forecastRatioDays = 14
+
+// Glucose Forecast Flow
+//
+// The projection is computed in Go; the model only explains it.
+type GlucoseForecast struct {
+	Readings *store.ReadingStore
+	Meals    *store.LogStore[store.MealLog]
+	Insulin  *store.LogStore[store.InsulinDose]
+}
+
+func (f GlucoseForecast) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "glucoseForecast", func(ctx context.Context, input *GlucoseForecastInput) (*GlucoseForecastOutput, error) {
+		hours := input.Hours
+		if hours == 0 {
+			hours = 2
+		}
+		if hours < 1 || hours > analytics.MaxForecastHours {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, fmt.Sprintf("hours must be between 1 and %d", analytics.MaxForecastHours), nil)
+		}
+		if a := input.PlannedActivity; a != nil && (a.DurationMinutes <= 0 || a.StartInMinutes < 0) {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "planned_activity needs a positive duration_minutes and a start_in_minutes of 0 or more", nil)
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		now := time.Now()
+		currentBG := input.CurrentBG
+		if currentBG <= 0 {
+			latest, ok := f.Readings.Latest(userID)
+			if !ok || now.Sub(latest.Timestamp) > forecastReadingWindow {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "no reading logged in the last 15 minutes; send current_bg", nil)
+			}
+			currentBG = latest.Value
+		}
+
+		// Fill in missing ratios from the user's own logs
+		isf, carbRatio, source := input.ISF, input.CarbRatio, analytics.RatiosInput
+		if isf <= 0 || carbRatio <= 0 {
+			from := now.AddDate(0, 0, -forecastRatioDays)
+			est := analytics.EstimateRatios(
+				f.Meals.Range(userID, from, now),
+				f.Insulin.Range(userID, from, now),
+				f.Readings.Range(userID, from.Add(-analytics.BaselineWindow), now),
+				forecastRatioDays,
+			)
+			if isf <= 0 {
+				isf = est.CorrectionObs
+				if isf == 0 {
+					isf = est.CorrectionRule
+				}
+			}
+			if carbRatio <= 0 {
+				carbRatio = est.CarbRatioObs
+				if carbRatio == 0 {
+					carbRatio = est.CarbRatioRule
+				}
+			}
+			source = analytics.RatiosEstimated
+		}
+
+		forecast := analytics.ProjectGlucose(analytics.ForecastInputs{
+			Now:         now,
+			CurrentBG:   currentBG,
+			Readings:    f.Readings.Range(userID, now.Add(-30*time.Minute), now.Add(time.Second)),
+			Meals:       f.Meals.Range(userID, now.Add(-3*time.Hour), now.Add(time.Second)),
+			Doses:       analytics.RecentDoses(f.Insulin, userID, now),
+			Activity:    input.PlannedActivity,
+			ISF:         isf,
+			CarbRatio:   carbRatio,
+			RatioSource: source,
+			Hours:       hours,
+		})
+
+		activity := "Planned activity: none"
+		if a := input.PlannedActivity; a != nil {
+			activity = fmt.Sprintf("Planned activity: %s %s for %d minutes, starting in %d minutes", a.Intensity, a.Type, a.DurationMinutes, a.StartInMinutes)
+		}
+		prompt := fmt.Sprintf(prompts.Get("glucoseForecast"), hours, forecast.PromptSummary(), activity)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain glucose forecast: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 3)
+
+		return &GlucoseForecastOutput{
+			Forecast: forecast,
+			Outlook:  parts[0],
+			Drivers:  parts[1],
+			Actions:  parts[2],
+		}, nil
+	})
+	mux.HandleFlow("POST /glucoseForecast", flow, "Project blood glucose over the next 1-4 hours")
+}
@@ -0,0 +1,106 @@
+package flows
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/guidelines"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/replay"
+	"diabeticai-advisor/internal/requestid"
+	"diabeticai-advisor/internal/safety"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/shadow"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Helper function to add the guideline passages closest to a query to a prompt.
+// Retrieval problems are logged and the prompt is used without them.
+func ground(ctx context.Context, ix *guidelines.Index, prompt, query string) string {
+	passages, err := ix.Search(ctx, query, guidelines.DefaultK)
+	if err != nil {
+		log.Printf("guideline retrieval failed request_id=%s: %v", requestid.From(ctx), err)
+		return prompt
+	}
+	if note := guidelines.PromptNote(passages); note != "" {
+		prompt += "\n\n" + note
+	}
+	return prompt
+}
+
+// Helper function to run a generation in the user's units and formats, logging it against the request ID
+// and recording its cost. In a dry run the prompt is traced and the model is not called.
+// Model failures and answers blocked by safety filters are returned as API errors, and
+// answers the content safety filter flags are regenerated or redacted.
+func generate(ctx context.Context, g *genkit.Genkit, prompt string, opts ...ai.GenerateOption) (*ai.ModelResponse, error) {
+	prompt += "\n\n" + locale.From(ctx).PromptNote()
+	if trace := dryrun.From(ctx); trace != nil {
+		trace.Add(costs.Model(ctx), prompt)
+		return &ai.ModelResponse{Message: ai.NewModelTextMessage(dryrun.Placeholder)}, nil
+	}
+	result, err := complete(ctx, g, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generations charged to another flow, such as grading, are not user
+	// traffic: they are neither filtered nor shadowed
+	flow := core.FlowNameFromContext(ctx)
+	if costs.Flow(ctx, flow) != flow {
+		return result, nil
+	}
+	result = safety.Filter(ctx, flow, prompt, result,
+		func(ctx context.Context, prompt string) (*ai.ModelResponse, error) {
+			return complete(ctx, g, prompt, opts...)
+		},
+		func(ctx context.Context, prompt string) (*ai.ModelResponse, error) {
+			var classifyOpts []ai.GenerateOption
+			if model := safety.Model(); model != "" {
+				classifyOpts = append(classifyOpts, ai.WithModelName(model))
+			}
+			return complete(costs.WithFlow(ctx, "safetyClassifier", safety.Model()), g, prompt, classifyOpts...)
+		})
+
+	// Try a sample of user traffic on the candidate prompt or model
+	shadow.Sample(ctx, flow, rbac.User(ctx), prompt, result, func(ctx context.Context, prompt, model string) (*ai.ModelResponse, error) {
+		shadowOpts := opts
+		if model != "" {
+			shadowOpts = append(slices.Clip(opts), ai.WithModelName(model))
+		}
+		return genkit.Generate(ctx, g, append(slices.Clip(shadowOpts), ai.WithPrompt(prompt))...)
+	})
+	return result, nil
+}
+
+// Helper function to call the model once, logging the call and recording its cost
+func complete(ctx context.Context, g *genkit.Genkit, prompt string, opts ...ai.GenerateOption) (*ai.ModelResponse, error) {
+	start := time.Now()
+	result, err := replay.Do(prompt, func() (*ai.ModelResponse, error) {
+		return genkit.Generate(ctx, g, append(slices.Clip(opts), ai.WithPrompt(prompt))...)
+	})
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		log.Printf("generation failed duration=%s request_id=%s: %v", elapsed, requestid.From(ctx), err)
+		return nil, server.ModelError(err)
+	}
+
+	var in, out int
+	if result.Usage != nil {
+		in, out = result.Usage.InputTokens, result.Usage.OutputTokens+result.Usage.ThoughtsTokens
+	}
+	log.Printf("generation duration=%s input_tokens=%d output_tokens=%d request_id=%s", elapsed, in, out, requestid.From(ctx))
+	costs.Record(ctx, core.FlowNameFromContext(ctx), in, out)
+	if result.FinishReason == ai.FinishReasonBlocked {
+		log.Printf("generation blocked by safety filters request_id=%s: %s", requestid.From(ctx), result.FinishMessage)
+		return nil, server.BlockedError(result.FinishMessage)
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/resources"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// HighBGAction Input Struct
+type HighBGInput struct {
+	UserID            string  `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Reading           float64 `json:"reading" jsonschema:"description=Blood sugar reading in mg/dL, above the high_bg_action threshold in GET /thresholds"`
+	Ketones           string  `json:"ketones,omitempty" jsonschema:"description=Urine ketone result: negative, trace, small, moderate, large (optional)"`
+	BloodKetones      float64 `json:"blood_ketones,omitempty" jsonschema:"description=Blood ketones in mmol/L (optional)"`
+	CanKeepFluidsDown *bool   `json:"can_keep_fluids_down,omitempty" jsonschema:"description=Able to drink and keep fluids down"`
+	Vomiting          *bool   `json:"vomiting,omitempty" jsonschema:"description=Has been vomiting"`
+	MissedDose        *bool   `json:"missed_dose,omitempty" jsonschema:"description=Missed an insulin or diabetes medication dose"`
+	Ill               *bool   `json:"ill,omitempty" jsonschema:"description=Feeling ill, fever, or infection"`
+	Location          string  `json:"location,omitempty" jsonschema:"description=Country or region, e.g. Kenya or GB, for local emergency numbers (optional)"`
+}
+
+// HighBGAction Output Struct
+type HighBGOutput struct {
+	Escalation   string               `json:"escalation" jsonschema:"description=Escalation: monitor, call_doctor, emergency"`
+	Questions    []string             `json:"questions" jsonschema:"description=Unanswered questions that could change the plan"`
+	Steps        []string             `json:"steps" jsonschema:"description=Stepwise action plan"`
+	CallDoctorIf []string             `json:"call_doctor_if" jsonschema:"description=When to call your doctor"`
+	GoToERIf     []string             `json:"go_to_er_if" jsonschema:"description=When to go to the emergency room"`
+	Explanation  string               `json:"explanation" jsonschema:"description=Supportive explanation of the plan"`
+	Emergency    *resources.Resources `json:"emergency_resources,omitempty" jsonschema:"description=Local emergency numbers and ER guidance, on emergency escalation"`
+}
+
+// High BG Action Plan Flow
+type HighBGAction struct{}
+
+func (HighBGAction) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "highBGAction", func(ctx context.Context, input *HighBGInput) (*HighBGOutput, error) {
+		if input.Reading <= highBGActionThreshold() {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, fmt.Sprintf("highBGAction is for readings above %.0f mg/dL; use bloodSugarInterpreter instead", highBGActionThreshold()), nil)
+		}
+
+		verdict := evaluateRules(highBGRules, input)
+		dryrun.Verdict(ctx, "highBG", verdict)
+		questions := highBGQuestions(input)
+
+		prompt := fmt.Sprintf(prompts.Get("highBGAction"), input.Reading, verdict.PromptSummary(), len(questions))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain high blood sugar plan: %w", err)
+		}
+
+		output := &HighBGOutput{
+			Escalation:   verdict.Escalation,
+			Questions:    questions,
+			Steps:        verdict.Steps,
+			CallDoctorIf: highBGCallDoctorIf(),
+			GoToERIf:     highBGGoToERIf(),
+			Explanation:  strings.TrimSpace(result.Text()),
+		}
+		if verdict.Escalation == escalateEmergency {
+			local := resources.Lookup(input.Location)
+			output.Emergency = &local
+		}
+		return output, nil
+	})
+	mux.HandleFlow("POST /highBGAction", flow, fmt.Sprintf("Action plan for readings above %.0f mg/dL", highBGActionThreshold()))
+}
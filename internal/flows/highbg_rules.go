@@ -0,0 +1,192 @@
+package flows
+
+import (
+	"fmt"
+	"strings"
+
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Readings above this go through the high blood sugar action plan
+func highBGActionThreshold() float64 {
+	return thresholds.Current().HighBGAction
+}
+
+// Readings above this need a call to the doctor now
+func highBGCallDoctorThreshold() float64 {
+	return thresholds.Current().HighBGCallDoctor
+}
+
+// Ketone tiers, shared by urine strips and blood meters
+const (
+	ketonesUnknown  = "unknown"
+	ketonesNegative = "negative"
+	ketonesSmall    = "small"
+	ketonesModerate = "moderate"
+	ketonesLarge    = "large"
+)
+
+// Helper function to combine urine and blood ketone results into a tier.
+// Blood ketones (mmol/L) take priority when both are given.
+func ketoneTier(urine string, blood float64) string {
+	cutoffs := thresholds.Current().Ketones
+	switch {
+	case blood >= cutoffs.BloodLarge:
+		return ketonesLarge
+	case blood >= cutoffs.BloodModerate:
+		return ketonesModerate
+	case blood >= cutoffs.BloodSmall:
+		return ketonesSmall
+	case blood > 0:
+		return ketonesNegative
+	}
+
+	switch strings.ToLower(strings.TrimSpace(urine)) {
+	case "large":
+		return ketonesLarge
+	case "moderate":
+		return ketonesModerate
+	case "small", "trace":
+		return ketonesSmall
+	case "negative", "none":
+		return ketonesNegative
+	default:
+		return ketonesUnknown
+	}
+}
+
+// Helper function to check an optional yes/no answer
+func answered(v *bool, want bool) bool {
+	return v != nil && *v == want
+}
+
+// High blood sugar action rules, evaluated in order
+var highBGRules = []Rule[*HighBGInput]{
+	{
+		ID:       "confirm-reading",
+		When:     func(in *HighBGInput) bool { return true },
+		Step:     "Wash your hands and recheck your blood sugar to confirm the reading.",
+		Escalate: escalateMonitor,
+	},
+	{
+		ID:   "ketones-unchecked",
+		When: func(in *HighBGInput) bool { return ketoneTier(in.Ketones, in.BloodKetones) == ketonesUnknown },
+		Step: "Check for ketones now with a urine strip or blood ketone meter if you have one.",
+	},
+	{
+		ID:       "ketones-large",
+		When:     func(in *HighBGInput) bool { return ketoneTier(in.Ketones, in.BloodKetones) == ketonesLarge },
+		Step:     "Large ketones can mean diabetic ketoacidosis (DKA). Go to the emergency room or call emergency services now.",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "ketones-moderate",
+		When:     func(in *HighBGInput) bool { return ketoneTier(in.Ketones, in.BloodKetones) == ketonesModerate },
+		Step:     "Moderate ketones: call your doctor or diabetes team now for guidance.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:   "ketones-small",
+		When: func(in *HighBGInput) bool { return ketoneTier(in.Ketones, in.BloodKetones) == ketonesSmall },
+		Step: "Small ketones: recheck ketones in 2 hours. Call your doctor if they rise.",
+	},
+	{
+		ID: "vomiting-no-fluids",
+		When: func(in *HighBGInput) bool {
+			return answered(in.Vomiting, true) && answered(in.CanKeepFluidsDown, false)
+		},
+		Step:     "Vomiting and unable to keep fluids down puts you at risk of dehydration and DKA. Go to the emergency room now.",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID: "vomiting",
+		When: func(in *HighBGInput) bool {
+			return answered(in.Vomiting, true) && !answered(in.CanKeepFluidsDown, false)
+		},
+		Step:     "You are vomiting: call your doctor today, even if you can keep some fluids down.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:   "hydrate",
+		When: func(in *HighBGInput) bool { return !answered(in.CanKeepFluidsDown, false) },
+		Step: "Drink a glass of water or other sugar-free fluid every hour.",
+	},
+	{
+		ID:   "very-high",
+		When: func(in *HighBGInput) bool { return in.Reading > highBGCallDoctorThreshold() },
+		StepText: func() string {
+			return fmt.Sprintf("Your reading is above %.0f mg/dL: call your doctor now.", highBGCallDoctorThreshold())
+		},
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "missed-dose",
+		When:     func(in *HighBGInput) bool { return answered(in.MissedDose, true) },
+		Step:     "Follow your care plan for missed doses. If you don't have one, call your doctor before taking any extra insulin or medication.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "illness",
+		When:     func(in *HighBGInput) bool { return answered(in.Ill, true) },
+		Step:     "Follow your sick-day plan: keep taking your usual medication unless told otherwise, and check blood sugar and ketones every 3-4 hours.",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID: "no-exercise",
+		When: func(in *HighBGInput) bool {
+			tier := ketoneTier(in.Ketones, in.BloodKetones)
+			return tier != ketonesNegative && tier != ketonesUnknown
+		},
+		Step: "Avoid exercise while ketones are present; it can push blood sugar higher.",
+	},
+	{
+		ID:   "recheck",
+		When: func(in *HighBGInput) bool { return true },
+		StepText: func() string {
+			return fmt.Sprintf("Recheck your blood sugar in 2 hours. If it is still above %.0f mg/dL on two checks, call your doctor.", highBGActionThreshold())
+		},
+	},
+}
+
+// Explicit thresholds shown with every high blood sugar plan, from the table in force
+func highBGCallDoctorIf() []string {
+	table := thresholds.Current()
+	return []string{
+		fmt.Sprintf("Blood sugar stays above %.0f mg/dL on two checks 2-3 hours apart", table.HighBGAction),
+		fmt.Sprintf("Blood sugar above %.0f mg/dL", table.HighBGCallDoctor),
+		fmt.Sprintf("Moderate urine ketones, or blood ketones %.1f to under %.1f mmol/L", table.Ketones.BloodModerate, table.Ketones.BloodLarge),
+		"Vomiting, diarrhea, or unable to eat normally",
+		"A missed insulin or diabetes medication dose with no plan for it",
+	}
+}
+
+// Emergency signs shown with every high blood sugar plan
+func highBGGoToERIf() []string {
+	return []string{
+		fmt.Sprintf("Large urine ketones, or blood ketones %.1f mmol/L or higher", thresholds.Current().Ketones.BloodLarge),
+		"Vomiting and unable to keep fluids down",
+		"Trouble breathing, fruity-smelling breath, confusion, or extreme drowsiness",
+		"Severe stomach pain",
+	}
+}
+
+// Helper function to list the questions still unanswered
+func highBGQuestions(in *HighBGInput) []string {
+	questions := []string{}
+	if ketoneTier(in.Ketones, in.BloodKetones) == ketonesUnknown {
+		questions = append(questions, "Have you checked for ketones? What was the result (negative, trace, small, moderate, large, or a blood ketone value)?")
+	}
+	if in.CanKeepFluidsDown == nil {
+		questions = append(questions, "Are you able to drink and keep fluids down?")
+	}
+	if in.Vomiting == nil {
+		questions = append(questions, "Have you been vomiting?")
+	}
+	if in.MissedDose == nil {
+		questions = append(questions, "Have you missed any insulin or diabetes medication doses?")
+	}
+	if in.Ill == nil {
+		questions = append(questions, "Are you feeling ill, or do you have a fever or infection?")
+	}
+	return questions
+}
@@ -0,0 +1,71 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// HypoReview Input Struct
+type HypoReviewInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Days   int    `json:"days,omitempty" jsonschema:"description=Days of events to review (default 90)"`
+}
+
+// HypoReview Output Struct
+type HypoReviewOutput struct {
+	Patterns      analytics.HypoPatterns `json:"patterns" jsonschema:"description=Counts of causes, times of day and triggers across events"`
+	Summary       string                 `json:"summary" jsonschema:"description=Recurring causes in plain language"`
+	Prevention    string                 `json:"prevention" jsonschema:"description=Habits that target the patterns"`
+	DoctorPoints  string                 `json:"doctor_points" jsonschema:"description=Points to discuss with your doctor"`
+	SeeDoctorSoon bool                   `json:"see_doctor_soon" jsonschema:"description=True when an event was severe or needed help"`
+}
+
+// Hypo Review Flow
+type HypoReview struct {
+	Stores *store.Stores
+}
+
+func (f HypoReview) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "hypoReview", func(ctx context.Context, input *HypoReviewInput) (*HypoReviewOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+		days := input.Days
+		if days <= 0 {
+			days = 90
+		}
+
+		now := time.Now()
+		patterns := analytics.AnalyzeHypos(f.Stores, userID, now.AddDate(0, 0, -days), now.Add(time.Second), locale.From(ctx).Location)
+
+		prompt := fmt.Sprintf(prompts.Get("hypoReview"), days, patterns.PromptSummary())
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to review hypo events: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 3)
+
+		return &HypoReviewOutput{
+			Patterns:      patterns,
+			Summary:       parts[0],
+			Prevention:    parts[1],
+			DoctorPoints:  parts[2],
+			SeeDoctorSoon: patterns.Severe > 0 || patterns.NeededHelp > 0,
+		}, nil
+	})
+	mux.HandleFlow("POST /hypoReview", flow, "Review logged hypo events for recurring causes and prevention")
+}
@@ -0,0 +1,81 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// HypoRisk Input Struct
+type HypoRiskInput struct {
+	UserID          string  `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	CurrentBG       float64 `json:"current_bg" jsonschema:"description=Current blood glucose in mg/dL"`
+	Trend           string  `json:"trend,omitempty" jsonschema:"description=Trend: rising, steady, falling (optional)"`
+	PlannedActivity string  `json:"planned_activity,omitempty" jsonschema:"description=Activity planned in the next few hours (optional)"`
+}
+
+// HypoRisk Output Struct
+type HypoRiskOutput struct {
+	RiskLevel      string             `json:"risk_level" jsonschema:"description=Risk level: low, moderate, high"`
+	InsulinOnBoard float64            `json:"insulin_on_board" jsonschema:"description=Active insulin in units"`
+	RecentActivity analytics.Activity `json:"recent_activity" jsonschema:"description=Steps, workouts and heart rate reported by wearables"`
+	Assessment     string             `json:"assessment" jsonschema:"description=Why the risk is at this level"`
+	Prevention     string             `json:"prevention" jsonschema:"description=Steps to prevent a low"`
+}
+
+// Hypo Risk Flow
+type HypoRisk struct {
+	Insulin  *store.LogStore[store.InsulinDose]
+	Activity *store.LogStore[store.ActivitySample]
+	Workouts *store.LogStore[store.WorkoutLog]
+}
+
+func (f HypoRisk) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "hypoRisk", func(ctx context.Context, input *HypoRiskInput) (*HypoRiskOutput, error) {
+		if input.CurrentBG <= 0 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "current_bg must be positive", nil)
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		now := time.Now()
+		iob := analytics.CurrentIOB(f.Insulin, userID, now)
+		activity := analytics.RecentActivity(f.Activity, f.Workouts, userID, now)
+		risk := analytics.HypoRiskLevel(input.CurrentBG, iob.Total)
+		// Recent exercise raises insulin sensitivity, so active insulin goes further
+		if risk == "low" && activity.Active() && iob.Total >= thresholds.Current().HypoRisk.NearLowIOB {
+			risk = "moderate"
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("hypoRisk"), input.CurrentBG, input.Trend, input.PlannedActivity, iob.PromptSummary()+"\n"+activity.PromptSummary(), risk)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assess hypo risk: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &HypoRiskOutput{
+			RiskLevel:      risk,
+			InsulinOnBoard: iob.Total,
+			RecentActivity: activity,
+			Assessment:     parts[0],
+			Prevention:     parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /hypoRisk", flow, "Assess low blood sugar risk with insulin on board")
+}
@@ -0,0 +1,74 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ICREstimator Input Struct
+type ICREstimatorInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Days   int    `json:"days,omitempty" jsonschema:"description=Number of days to analyze (default 14)"`
+}
+
+// ICREstimator Output Struct
+type ICREstimatorOutput struct {
+	Estimates        analytics.RatioEstimates `json:"estimates" jsonschema:"description=Deterministic carb ratio and correction factor estimates"`
+	Explanation      string                   `json:"explanation" jsonschema:"description=What the numbers mean and how they were derived"`
+	DiscussionPoints string                   `json:"discussion_points" jsonschema:"description=Questions to raise with your clinician"`
+}
+
+// ICR Estimator Flow
+type ICREstimator struct {
+	Readings *store.ReadingStore
+	Meals    *store.LogStore[store.MealLog]
+	Insulin  *store.LogStore[store.InsulinDose]
+}
+
+func (f ICREstimator) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "icrEstimator", func(ctx context.Context, input *ICREstimatorInput) (*ICREstimatorOutput, error) {
+		days := input.Days
+		if days <= 0 {
+			days = 14
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -days)
+		estimates := analytics.EstimateRatios(
+			f.Meals.Range(userID, from, to),
+			f.Insulin.Range(userID, from, to),
+			f.Readings.Range(userID, from.Add(-analytics.BaselineWindow), to),
+			days,
+		)
+
+		prompt := fmt.Sprintf(prompts.Get("icrEstimator"), estimates.PromptSummary())
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain ratio estimates: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &ICREstimatorOutput{
+			Estimates:        estimates,
+			Explanation:      parts[0],
+			DiscussionPoints: parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /icrEstimator", flow, "Estimate carb ratio and correction factor for your clinician")
+}
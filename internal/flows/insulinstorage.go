@@ -0,0 +1,85 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"diabeticai-advisor/internal/insulinstorage"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Insulin Storage Input Struct
+type InsulinStorageInput struct {
+	Insulin       string   `json:"insulin" jsonschema:"description=Insulin name or brand, e.g. Lantus, Humalog, NPH"`
+	Form          string   `json:"form,omitempty" jsonschema:"description=vial or pen (default pen; cartridges count as pens)"`
+	Opened        bool     `json:"opened,omitempty" jsonschema:"description=True once the insulin is in use"`
+	DaysOut       int      `json:"days_out,omitempty" jsonschema:"description=Days since first use or since it came out of the fridge"`
+	Temperature   *float64 `json:"temperature,omitempty" jsonschema:"description=Highest (or lowest) temperature it was exposed to (optional)"`
+	TempUnit      string   `json:"temp_unit,omitempty" jsonschema:"description=C or F (default C)"`
+	Hours         float64  `json:"hours,omitempty" jsonschema:"description=How long it was at that temperature (optional)"`
+	Frozen        bool     `json:"frozen,omitempty" jsonschema:"description=True if it froze or was stored against an ice pack"`
+	Appearance    string   `json:"appearance,omitempty" jsonschema:"description=normal, cloudy, clumps, crystals, frosted or discolored"`
+	Traveling     bool     `json:"traveling,omitempty" jsonschema:"description=True to include travel storage rules"`
+	WillBeOutDays int      `json:"will_be_out_days,omitempty" jsonschema:"description=Days you need it for, e.g. trip length (optional)"`
+}
+
+// Insulin Storage Output Struct
+type InsulinStorageOutput struct {
+	insulinstorage.Assessment
+	Explanation string `json:"explanation" jsonschema:"description=Plain-language explanation of the verdict"`
+}
+
+// Insulin Storage Flow
+//
+// The verdict and limits come from insulinstorage; the model only explains them.
+type InsulinStorage struct{}
+
+func (InsulinStorage) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "insulinStorage", func(ctx context.Context, input *InsulinStorageInput) (*InsulinStorageOutput, error) {
+		if strings.TrimSpace(input.Insulin) == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "insulin is required", nil)
+		}
+		if input.DaysOut < 0 || input.Hours < 0 || input.WillBeOutDays < 0 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "days_out, hours and will_be_out_days cannot be negative", nil)
+		}
+
+		exposure := insulinstorage.Exposure{
+			Insulin:       input.Insulin,
+			Form:          input.Form,
+			Opened:        input.Opened,
+			DaysOut:       input.DaysOut,
+			Hours:         input.Hours,
+			Frozen:        input.Frozen,
+			Appearance:    input.Appearance,
+			Traveling:     input.Traveling,
+			WillBeOutDays: input.WillBeOutDays,
+		}
+		if input.Temperature != nil {
+			temp := *input.Temperature
+			switch strings.ToUpper(input.TempUnit) {
+			case "", "C":
+			case "F":
+				temp = insulinstorage.ToC(temp)
+			default:
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "temp_unit must be C or F", nil)
+			}
+			exposure.TempC = &temp
+		}
+
+		assessment := insulinstorage.Assess(exposure)
+		prompt := fmt.Sprintf(prompts.Get("insulinStorage"), assessment.PromptSummary())
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain insulin storage guidance: %w", err)
+		}
+
+		return &InsulinStorageOutput{Assessment: assessment, Explanation: strings.TrimSpace(result.Text())}, nil
+	})
+	mux.HandleFlow("POST /insulinStorage", flow, "Keep or discard guidance for insulin after opening, heat, cold or travel")
+}
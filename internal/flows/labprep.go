@@ -0,0 +1,213 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// How far ahead an appointment can be
+const maxLabDaysAhead = 90
+
+// Default reminder time the evening before a test
+const defaultLabReminder = "20:00"
+
+// LabPrep Input Struct
+type LabPrepInput struct {
+	UserID       string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Test         string `json:"test" jsonschema:"description=Lab test, e.g. fasting lipid panel, A1C or glucose tolerance test"`
+	At           string `json:"at" jsonschema:"description=Appointment time, YYYY-MM-DD HH:MM in your timezone"`
+	FastingHours int    `json:"fasting_hours,omitempty" jsonschema:"description=Hours of fasting your doctor or lab asked for (default from the test)"`
+	Medications  string `json:"medications,omitempty" jsonschema:"description=Current medications, including insulin (optional)"`
+	Remind       bool   `json:"remind,omitempty" jsonschema:"description=Send a reminder the evening before"`
+	RemindAt     string `json:"remind_at,omitempty" jsonschema:"description=Reminder time the evening before, HH:MM (default 20:00, or an hour before the fast starts if earlier)"`
+	Population   string `json:"population,omitempty" jsonschema:"description=Glucose ranges to use: adult (default), child, older_adult or pregnancy"`
+}
+
+// LabPrep Output Struct
+type LabPrepOutput struct {
+	Test                string     `json:"test"`
+	Appointment         time.Time  `json:"appointment"`
+	FastingHours        int        `json:"fasting_hours" jsonschema:"description=Hours of fasting before the test; 0 when none is needed or it isn't known"`
+	FastingSource       string     `json:"fasting_source" jsonschema:"description=Where the fasting window comes from: input, test or unknown"`
+	FastFrom            *time.Time `json:"fast_from,omitempty" jsonschema:"description=When to stop eating"`
+	Preparation         string     `json:"preparation" jsonschema:"description=What to do before the test"`
+	MedicationQuestions string     `json:"medication_questions" jsonschema:"description=Questions for your doctor about medication timing"`
+	Hydration           string     `json:"hydration"`
+	SafetyNotes         []string   `json:"safety_notes,omitempty" jsonschema:"description=Risks from your medications while fasting"`
+	ReminderAt          *time.Time `json:"reminder_at,omitempty" jsonschema:"description=When the reminder will be sent"`
+	ReminderNote        string     `json:"reminder_note,omitempty" jsonschema:"description=Why the reminder may not reach you"`
+}
+
+// Lab test and its usual fasting window
+type labTest struct {
+	keywords     []string
+	fastingHours int
+}
+
+// Common lab tests, matched in order. Tests with no fasting window are listed
+// so they are known rather than unknown.
+var labTests = []labTest{
+	{[]string{"glucose tolerance", "ogtt"}, 10},
+	{[]string{"fasting glucose", "fasting blood sugar", "fasting plasma glucose", "fpg", "fbs"}, 8},
+	{[]string{"lipid", "cholesterol", "triglyceride"}, 12},
+	{[]string{"metabolic panel", "cmp", "bmp"}, 8},
+	{[]string{"c-peptide", "insulin level"}, 8},
+	{[]string{"a1c", "glycated", "kidney", "egfr", "creatinine", "albumin", "urine", "thyroid", "tsh", "b12", "blood count", "cbc"}, 0},
+}
+
+// Helper function to look up a test's usual fasting window
+func lookupLabTest(test string) (int, bool) {
+	for _, t := range labTests {
+		if parse.ContainsKeywords(test, t.keywords) {
+			return t.fastingHours, true
+		}
+	}
+	return 0, false
+}
+
+// Lab Prep Flow
+//
+// Pre-test instructions for an upcoming lab appointment. The fasting window
+// comes from the input or a table of common tests, never from the model.
+// With remind, the appointment is saved and a lab_prep reminder goes out the
+// evening before.
+type LabPrep struct {
+	Stores *store.Stores
+}
+
+func (f LabPrep) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "labPrep", func(ctx context.Context, input *LabPrepInput) (*LabPrepOutput, error) {
+		test := strings.TrimSpace(input.Test)
+		if test == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "test is required", nil)
+		}
+		prefs := locale.From(ctx)
+		now := time.Now().In(prefs.Location)
+		at, err := time.ParseInLocation("2006-01-02 15:04", input.At, prefs.Location)
+		if err != nil || !at.After(now) || at.After(now.AddDate(0, 0, maxLabDaysAhead)) {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, fmt.Sprintf("at must be YYYY-MM-DD HH:MM, in the next %d days", maxLabDaysAhead), nil)
+		}
+		if input.FastingHours < 0 || input.FastingHours > 24 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "fasting_hours must be between 0 and 24", nil)
+		}
+		remindAt := defaultLabReminder
+		if input.RemindAt != "" {
+			if _, err := time.Parse("15:04", input.RemindAt); err != nil {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "remind_at must be HH:MM", nil)
+			}
+			remindAt = input.RemindAt
+		}
+		population := input.Population
+		if population == "" {
+			population = thresholds.Adult
+		}
+		if !thresholds.Active(at).HasPopulation(population) {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "unknown population "+population, nil)
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		output := &LabPrepOutput{Test: test, Appointment: at, FastingSource: "input", FastingHours: input.FastingHours}
+		if input.FastingHours == 0 {
+			hours, known := lookupLabTest(test)
+			output.FastingHours, output.FastingSource = hours, "test"
+			if !known {
+				output.FastingSource = "unknown"
+			}
+		}
+		fasting := "not needed for this test"
+		switch {
+		case output.FastingHours > 0:
+			from := at.Add(-time.Duration(output.FastingHours) * time.Hour)
+			output.FastFrom = &from
+			fasting = fmt.Sprintf("%d hours, nothing but water from %s", output.FastingHours, prefs.DateTime(from))
+		case output.FastingSource == "unknown":
+			fasting = "not known for this test; they should ask the lab"
+		}
+		output.SafetyNotes = labSafetyNotes(input.Medications, output.FastingHours, at, population, prefs)
+		dryrun.Verdict(ctx, "labPrep", map[string]any{"fasting_hours": output.FastingHours, "fasting_source": output.FastingSource, "safety_notes": output.SafetyNotes})
+
+		medications := strings.TrimSpace(input.Medications)
+		if medications == "" {
+			medications = "not given"
+		}
+		prompt := fmt.Sprintf(prompts.Get("labPrep"), test, prefs.DateTime(at), fasting, medications, strings.Join(output.SafetyNotes, "\n"))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate lab prep instructions: %w", err)
+		}
+		parts := parse.SplitIntoSections(result.Text(), 3)
+		output.Preparation, output.MedicationQuestions, output.Hydration = parts[0], parts[1], parts[2]
+
+		if input.Remind {
+			remind := labReminderTime(at, output.FastFrom, remindAt, now)
+			output.ReminderAt = &remind
+			notify := f.Stores.Notifications.Get(userID)
+			switch {
+			case len(notify.Channels) == 0:
+				output.ReminderNote = "No reminder channels are set; add some in your notification preferences to get this reminder."
+			case slices.Contains(notify.Muted, "lab_prep"):
+				output.ReminderNote = "You have muted lab_prep reminders; unmute them in your notification preferences to get this reminder."
+			}
+			if !dryrun.Active(ctx) {
+				appt := store.LabAppointment{ID: store.NewID(), UserID: userID, Test: test, At: at, FastingHours: output.FastingHours, RemindAt: remind}
+				store.Stamp(&appt.UserID, &appt.Timestamp)
+				f.Stores.Labs.Add(ctx, appt)
+			}
+		}
+		return output, nil
+	})
+	mux.HandleWriteFlow("POST /labPrep", flow, "Get ready for a lab test, with an optional reminder the evening before")
+}
+
+// Helper function to pick the reminder time: the chosen time the evening
+// before, or an hour before the fast starts if that is earlier, but not in the past
+func labReminderTime(at time.Time, fastFrom *time.Time, remindAt string, now time.Time) time.Time {
+	clock, _ := time.Parse("15:04", remindAt)
+	eve := at.AddDate(0, 0, -1)
+	remind := time.Date(eve.Year(), eve.Month(), eve.Day(), clock.Hour(), clock.Minute(), 0, 0, at.Location())
+	if fastFrom != nil && fastFrom.Add(-time.Hour).Before(remind) {
+		remind = fastFrom.Add(-time.Hour)
+	}
+	if remind.Before(now) {
+		remind = now
+	}
+	return remind
+}
+
+// Helper function to list the risks of fasting for a test with these medications,
+// with the low cutoff of the thresholds in force on the day
+func labSafetyNotes(medications string, fastingHours int, at time.Time, population string, prefs locale.Prefs) []string {
+	if fastingHours == 0 {
+		return nil
+	}
+	var notes []string
+	if parse.ContainsKeywords(medications, insulinNames) || parse.ContainsKeywords(medications, sulfonylureas) {
+		notes = append(notes, fmt.Sprintf("Insulin and sulfonylureas can cause a low while fasting. Check your blood sugar on waking and before you leave; below %s, treat the low and tell the lab, as the test can be rescheduled.", prefs.Glucose(thresholds.Active(at).Range(population).Low)))
+	}
+	if parse.ContainsKeywords(medications, sglt2Inhibitors) {
+		notes = append(notes, "SGLT2 inhibitors raise the risk of ketoacidosis and dehydration while fasting. Ask your doctor whether to take it before the test.")
+	}
+	if at.Hour() >= 10 && fastingHours >= 8 {
+		notes = append(notes, "An early-morning appointment keeps the fast shorter. Ask for one if you can.")
+	}
+	return notes
+}
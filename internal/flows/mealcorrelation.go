@@ -0,0 +1,71 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// MealCorrelation Input Struct
+type MealCorrelationInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Days   int    `json:"days,omitempty" jsonschema:"description=Number of days to analyze (default 30)"`
+}
+
+// MealCorrelation Output Struct
+type MealCorrelationOutput struct {
+	MealsAnalyzed int                     `json:"meals_analyzed" jsonschema:"description=Meals with follow-up readings"`
+	Patterns      []analytics.FoodPattern `json:"patterns" jsonschema:"description=Glucose response by food and meal type"`
+	Insights      string                  `json:"insights" jsonschema:"description=Foods or patterns consistently followed by spikes"`
+	Suggestions   string                  `json:"suggestions" jsonschema:"description=Practical swaps and adjustments"`
+}
+
+// Meal Correlation Flow
+type MealCorrelation struct {
+	Readings *store.ReadingStore
+	Meals    *store.LogStore[store.MealLog]
+}
+
+func (f MealCorrelation) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "mealCorrelation", func(ctx context.Context, input *MealCorrelationInput) (*MealCorrelationOutput, error) {
+		days := input.Days
+		if days <= 0 {
+			days = 30
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -days)
+		responses := analytics.MealResponses(f.Meals.Range(userID, from, to), f.Readings.Range(userID, from, to.Add(analytics.PostMealEnd)))
+		patterns := analytics.FoodPatterns(responses)
+
+		prompt := fmt.Sprintf(prompts.Get("mealCorrelation"), days, analytics.SpikeDefinition(), analytics.FoodPatternsPrompt(patterns))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze meal responses: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &MealCorrelationOutput{
+			MealsAnalyzed: len(responses),
+			Patterns:      patterns,
+			Insights:      parts[0],
+			Suggestions:   parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /mealCorrelation", flow, "Find foods that spike your blood sugar")
+}
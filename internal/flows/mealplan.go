@@ -0,0 +1,191 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// MealPlan Input Struct
+type MealPlanInput struct {
+	DietType     string  `json:"diet_type" jsonschema:"description=Diet preference: vegetarian, non_vegetarian, vegan"`
+	Allergies    string  `json:"allergies" jsonschema:"description=Any food allergies or restrictions"`
+	CalorieLimit float64 `json:"calorie_limit" jsonschema:"description=Daily calorie limit (optional)"`
+	Region       string  `json:"region,omitempty" jsonschema:"description=Where you live, e.g. east_africa, south_asia, latin_america (optional)"`
+	Cuisine      string  `json:"cuisine,omitempty" jsonschema:"description=Preferred cuisine, e.g. Kenyan, Gujarati, Mexican (optional)"`
+
+	// Household planning: one set of meals for everyone, adapted for the member with diabetes
+	HouseholdSize int               `json:"household_size,omitempty" jsonschema:"description=People eating together, including you. 2 or more plans shared meals (optional)"`
+	Household     []HouseholdMember `json:"household,omitempty" jsonschema:"description=The other people you cook for and what they like (optional)"`
+}
+
+// Household Member Struct
+type HouseholdMember struct {
+	Member      string `json:"member" jsonschema:"description=Who they are, e.g. partner or child aged 8"`
+	Preferences string `json:"preferences,omitempty" jsonschema:"description=Likes, dislikes, diet and allergies"`
+}
+
+// Shared Dish Struct
+type SharedDish struct {
+	Meal         string `json:"meal" jsonschema:"description=breakfast, lunch, dinner or snacks"`
+	Dish         string `json:"dish" jsonschema:"description=What everyone eats"`
+	Modification string `json:"modification" jsonschema:"description=Portion and carb swaps for the member with diabetes"`
+}
+
+// MealPlan Output Struct
+type MealPlanOutput struct {
+	Breakfast string           `json:"breakfast" jsonschema:"description=Breakfast suggestions"`
+	Lunch     string           `json:"lunch" jsonschema:"description=Lunch suggestions"`
+	Dinner    string           `json:"dinner" jsonschema:"description=Dinner suggestions"`
+	Snacks    string           `json:"snacks" jsonschema:"description=Healthy snack options"`
+	Nutrition []nutrition.Food `json:"nutrition,omitempty" jsonschema:"description=USDA nutrient values per 100 g for the main foods in the plan"`
+	Servings  int              `json:"servings,omitempty" jsonschema:"description=People the shared plan feeds"`
+	Dishes    []SharedDish     `json:"dishes,omitempty" jsonschema:"description=Each shared dish and how to adapt it for the member with diabetes"`
+}
+
+// Largest household planned for
+const maxHouseholdSize = 12
+
+// Most foods looked up per plan
+const maxNutritionLookups = 8
+
+// Diabetes-friendly staples by region, used to steer the plan towards local foods
+var regionStaples = map[string]string{
+	"east_africa":   "sukuma wiki, managu, cabbage, beans, ndengu (green grams), githeri, lentils, tilapia, omena, eggs, maziwa lala (fermented milk); small portions of ugali made with whole maize, millet or sorghum flour, sweet potato, arrowroot or matoke",
+	"south_asia":    "dal, chana, rajma, moong sprouts, paneer, dahi, eggs, fish, leafy sabzi (methi, palak), bhindi, lauki; small portions of brown rice, or roti made with bajra, jowar, ragi or whole wheat",
+	"latin_america": "black and pinto beans, nopales, chayote, jicama, squash, avocado, eggs, grilled chicken or fish, queso fresco; small portions of corn tortillas, quinoa or plantain",
+}
+
+// Aliases for the supported regions
+var regionAliases = map[string]string{
+	"east_african": "east_africa", "kenya": "east_africa", "kenyan": "east_africa", "tanzania": "east_africa", "uganda": "east_africa",
+	"south_asian": "south_asia", "india": "south_asia", "indian": "south_asia", "pakistan": "south_asia", "bangladesh": "south_asia", "sri_lanka": "south_asia",
+	"latin_american": "latin_america", "mexico": "latin_america", "mexican": "latin_america", "central_america": "latin_america", "south_america": "latin_america",
+}
+
+// Helper function to describe the region and cuisine for the prompt
+func regionNote(region, cuisine string) string {
+	var lines []string
+	if region != "" {
+		lines = append(lines, "Region: "+region)
+	}
+	if cuisine != "" {
+		lines = append(lines, "Cuisine: "+cuisine)
+	}
+
+	for _, name := range []string{region, cuisine} {
+		key := strings.ToLower(strings.TrimSpace(name))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if alias, ok := regionAliases[key]; ok {
+			key = alias
+		}
+		if staples, ok := regionStaples[key]; ok {
+			lines = append(lines, "Locally available staples to build on: "+staples)
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Helper function to describe the household for the prompt, or "" when
+// planning for one
+func householdNote(size int, members []HouseholdMember) string {
+	if size < 2 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("Household: plan shared meals for %d people, so only one version of each meal is cooked. The diet type, allergies and calorie target above are for the member with diabetes; the others eat the same dishes.", size)}
+	for _, m := range members {
+		line := "- " + strings.TrimSpace(m.Member)
+		if p := strings.TrimSpace(m.Preferences); p != "" {
+			line += ": " + p
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines,
+		"Respect every member's diet and allergies in every dish. Give portions for the member with diabetes.",
+		"After the plan, add one line per dish: DISH: <breakfast, lunch, dinner or snacks> | <the dish everyone eats> | <portion and carb swaps for the member with diabetes, e.g. half the rice with extra vegetables, or cauliflower rice instead>")
+	return strings.Join(lines, "\n")
+}
+
+// Helper function to read the DISH lines of a household plan
+func parseDishes(lines []string) []SharedDish {
+	var dishes []SharedDish
+	for _, line := range lines {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		dishes = append(dishes, SharedDish{
+			Meal:         strings.ToLower(strings.TrimSpace(parts[0])),
+			Dish:         strings.TrimSpace(parts[1]),
+			Modification: strings.TrimSpace(parts[2]),
+		})
+	}
+	return dishes
+}
+
+// Meal Planner Flow
+//
+// With a household, plans one set of meals for everyone, with swaps for the
+// member with diabetes, so only one dinner is cooked.
+type MealPlan struct {
+	Nutrition *nutrition.Client
+}
+
+func (f MealPlan) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "mealPlanner", func(ctx context.Context, input *MealPlanInput) (*MealPlanOutput, error) {
+		size := input.HouseholdSize
+		if size == 0 && len(input.Household) > 0 {
+			size = len(input.Household) + 1
+		}
+		if size < 0 || size > maxHouseholdSize || (len(input.Household) > 0 && size < len(input.Household)+1) {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, fmt.Sprintf("household_size must count you and everyone listed in household, up to %d", maxHouseholdSize), nil)
+		}
+		for _, m := range input.Household {
+			if strings.TrimSpace(m.Member) == "" {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "each household member needs a member description", nil)
+			}
+		}
+
+		calorieInfo := ""
+		if input.CalorieLimit > 0 {
+			calorieInfo = fmt.Sprintf("Target daily calories: %.0f", input.CalorieLimit)
+		}
+		notes := strings.TrimSpace(regionNote(input.Region, input.Cuisine) + "\n" + householdNote(size, input.Household))
+
+		prompt := fmt.Sprintf(prompts.Get("mealPlanner"), input.DietType, input.Allergies, calorieInfo, notes)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate meal plan: %w", err)
+		}
+
+		text, foods := parse.CutListLine(result.Text(), "FOOD LIST")
+		text, dishes := parse.CutLines(text, "DISH")
+		sections := parse.ParseMealSections(text)
+
+		output := &MealPlanOutput{
+			Breakfast: sections["breakfast"],
+			Lunch:     sections["lunch"],
+			Dinner:    sections["dinner"],
+			Snacks:    sections["snacks"],
+		}
+		if size >= 2 {
+			output.Servings = size
+			output.Dishes = parseDishes(dishes)
+		}
+		if f.Nutrition.Enabled() {
+			output.Nutrition = f.Nutrition.LookupAll(ctx, foods[:min(len(foods), maxNutritionLookups)])
+		}
+		return output, nil
+	})
+	mux.HandleFlow("POST /mealPlan", flow, "Get diabetes-friendly meal plans")
+}
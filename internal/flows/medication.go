@@ -0,0 +1,67 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"diabeticai-advisor/internal/fda"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Medication Input Struct
+type MedicationInput struct {
+	MedicationName string `json:"medication_name" jsonschema:"description=Name of medication"`
+	Purpose        string `json:"purpose" jsonschema:"description=Purpose of inquiry (dosage, timing, side_effects, interactions)"`
+}
+
+// Medication Output Struct
+type MedicationOutput struct {
+	Information      string     `json:"information" jsonschema:"description=Medication information generated by the model"`
+	LabelFacts       []fda.Fact `json:"label_facts" jsonschema:"description=Facts quoted from the FDA drug label"`
+	HypoglycemiaRisk string     `json:"hypoglycemia_risk,omitempty" jsonschema:"description=What the FDA label says about hypoglycemia"`
+	Grounded         bool       `json:"grounded" jsonschema:"description=True when the information was generated from FDA label data"`
+	Reminder         string     `json:"reminder" jsonschema:"description=Important reminders"`
+}
+
+// Medication Info Flow
+type Medication struct {
+	Labels *fda.Client
+}
+
+func (f Medication) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "medicationInfo", func(ctx context.Context, input *MedicationInput) (*MedicationOutput, error) {
+		output := &MedicationOutput{LabelFacts: []fda.Fact{}}
+
+		// Ground the answer in the FDA label when one is available
+		var labelText string
+		if f.Labels != nil {
+			label, err := f.Labels.Label(ctx, input.MedicationName)
+			switch {
+			case err == nil:
+				output.LabelFacts = label.Facts()
+				output.HypoglycemiaRisk = label.HypoglycemiaRisk
+				output.Grounded = len(output.LabelFacts) > 0
+				labelText = label.PromptSummary()
+			case !errors.Is(err, fda.ErrNotFound):
+				log.Printf("FDA label lookup failed for %q: %v", input.MedicationName, err)
+			}
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("medicationInfo"), input.MedicationName, input.Purpose, labelText)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication info: %w", err)
+		}
+
+		output.Information = result.Text()
+		output.Reminder = "Set reminders on your phone for medication times. Never skip doses without consulting your doctor."
+		return output, nil
+	})
+	mux.HandleCachedFlow("POST /medication", flow, "Get medication information")
+}
@@ -0,0 +1,311 @@
+package flows
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/fda"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/sessions"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Food and timing rules for a drug
+const (
+	timingWithMeal      = "with_meal"
+	timingBeforeMeal    = "before_meal"
+	timingEmptyStomach  = "empty_stomach"
+	timingBedtime       = "bedtime"
+	timingAnyTime       = "any_time"
+	timingSourceLabel   = "label"
+	timingSourceDefault = "default"
+)
+
+// Timing rules for common drugs, used when the label has none
+var defaultTimings = map[string]string{
+	"metformin":     timingWithMeal,
+	"glimepiride":   timingWithMeal,
+	"gliclazide":    timingWithMeal,
+	"glipizide":     timingBeforeMeal,
+	"glyburide":     timingWithMeal,
+	"repaglinide":   timingBeforeMeal,
+	"acarbose":      timingWithMeal,
+	"levothyroxine": timingEmptyStomach,
+	"simvastatin":   timingBedtime,
+	"sitagliptin":   timingAnyTime,
+	"linagliptin":   timingAnyTime,
+	"empagliflozin": timingAnyTime,
+	"dapagliflozin": timingAnyTime,
+	"pioglitazone":  timingAnyTime,
+	"atorvastatin":  timingAnyTime,
+	"rosuvastatin":  timingAnyTime,
+	"lisinopril":    timingAnyTime,
+	"glargine":      timingAnyTime,
+	"degludec":      timingAnyTime,
+}
+
+// Med Schedule Medication Struct
+type ScheduleMedication struct {
+	Name        string `json:"name" jsonschema:"description=Medication name"`
+	Dose        string `json:"dose,omitempty" jsonschema:"description=Dose as prescribed, e.g. 500 mg"`
+	TimesPerDay int    `json:"times_per_day,omitempty" jsonschema:"description=Doses per day as prescribed, 1 to 4 (default 1)"`
+}
+
+// Med Schedule Input Struct
+type MedScheduleInput struct {
+	UserID      string               `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Medications []ScheduleMedication `json:"medications" jsonschema:"description=Your full medication list"`
+	Wake        string               `json:"wake,omitempty" jsonschema:"description=Wake time HH:MM (default 07:00)"`
+	Breakfast   string               `json:"breakfast,omitempty" jsonschema:"description=Breakfast time HH:MM (default 07:30)"`
+	Lunch       string               `json:"lunch,omitempty" jsonschema:"description=Lunch time HH:MM (default 12:30)"`
+	Dinner      string               `json:"dinner,omitempty" jsonschema:"description=Dinner time HH:MM (default 19:00)"`
+	Bed         string               `json:"bed,omitempty" jsonschema:"description=Bedtime HH:MM (default 22:30)"`
+	Confirm     string               `json:"confirm,omitempty" jsonschema:"description=schedule_id of a proposed schedule to save as daily reminders"`
+}
+
+// Medication Timing Struct
+type MedicationTiming struct {
+	Medication string `json:"medication"`
+	Rule       string `json:"rule" jsonschema:"description=with_meal, before_meal, empty_stomach, bedtime or any_time"`
+	Source     string `json:"source" jsonschema:"description=label when read from the FDA label, default otherwise"`
+	LabelText  string `json:"label_text,omitempty"`
+}
+
+// Med Schedule Output Struct
+type MedScheduleOutput struct {
+	ScheduleID string                `json:"schedule_id,omitempty" jsonschema:"description=Send back as confirm to save this schedule"`
+	Doses      []store.ScheduledDose `json:"doses"`
+	Timings    []MedicationTiming    `json:"timings,omitempty"`
+	Notes      string                `json:"notes,omitempty" jsonschema:"description=Spacing, missed-dose and low blood sugar notes"`
+	Confirmed  bool                  `json:"confirmed" jsonschema:"description=True once the schedule is saved as daily reminders"`
+}
+
+// Medication Schedule Flow
+//
+// Builds a daily timetable from each drug's food and timing rules. The first
+// call proposes it; calling again with confirm saves it as daily reminders.
+type MedSchedule struct {
+	Labels    *fda.Client
+	Sessions  *sessions.Store
+	Schedules *store.ScheduleStore
+}
+
+func (f MedSchedule) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "medSchedule", func(ctx context.Context, input *MedScheduleInput) (*MedScheduleOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		if input.Confirm != "" {
+			return f.confirm(ctx, userID, input.Confirm)
+		}
+		if len(input.Medications) == 0 {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "medications is required", nil)
+		}
+
+		routine, err := parseRoutine(input)
+		if err != nil {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		output := &MedScheduleOutput{Doses: []store.ScheduledDose{}}
+		for _, med := range input.Medications {
+			timing := f.timing(ctx, med.Name)
+			output.Timings = append(output.Timings, timing)
+			output.Doses = append(output.Doses, scheduleDoses(med, timing.Rule, routine)...)
+		}
+		sort.SliceStable(output.Doses, func(i, j int) bool { return output.Doses[i].Time < output.Doses[j].Time })
+
+		var table, labels []string
+		for _, d := range output.Doses {
+			table = append(table, fmt.Sprintf("%s %s %s (%s)", d.Time, d.Medication, d.Dose, d.Instruction))
+		}
+		for _, t := range output.Timings {
+			if t.LabelText != "" {
+				labels = append(labels, fmt.Sprintf("%s: %s", t.Medication, t.LabelText))
+			}
+		}
+		prompt := fmt.Sprintf(prompts.Get("medSchedule"), routine, strings.Join(table, "\n"), strings.Join(labels, "\n"))
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to review medication schedule: %w", err)
+		}
+		output.Notes = strings.TrimSpace(result.Text())
+
+		// Keep the proposal until the user confirms it
+		if f.Sessions != nil {
+			session := f.Sessions.Start(userID, "medSchedule")
+			proposal, _ := json.Marshal(output.Doses)
+			session.Add("model", string(proposal))
+			if !dryrun.Active(ctx) {
+				if err := f.Sessions.Save(ctx, session); err != nil {
+					return nil, fmt.Errorf("failed to save schedule proposal: %w", err)
+				}
+			}
+			output.ScheduleID = session.ID
+		}
+		return output, nil
+	})
+	mux.HandleWriteFlow("POST /medSchedule", flow, "Build a daily medication timetable from food and timing rules")
+}
+
+// Helper function to save a proposed schedule as the user's daily reminders
+func (f MedSchedule) confirm(ctx context.Context, userID, id string) (*MedScheduleOutput, error) {
+	if f.Sessions == nil {
+		return nil, core.NewPublicError(core.INVALID_ARGUMENT, "schedule confirmation is not available", nil)
+	}
+	session, err := f.Sessions.Get(ctx, id, userID)
+	if errors.Is(err, sessions.ErrNotFound) || (err == nil && (session.Flow != "medSchedule" || len(session.Messages) == 0)) {
+		return nil, core.NewPublicError(core.INVALID_ARGUMENT, "schedule_id is unknown or has expired; build the schedule again", nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule proposal: %w", err)
+	}
+
+	var doses []store.ScheduledDose
+	if err := json.Unmarshal([]byte(session.Messages[0].Content), &doses); err != nil {
+		return nil, fmt.Errorf("failed to decode schedule proposal: %w", err)
+	}
+	if dryrun.Active(ctx) {
+		return &MedScheduleOutput{Doses: doses, Confirmed: false}, nil
+	}
+	saved := f.Schedules.Set(userID, doses)
+	if err := f.Sessions.Delete(ctx, id); err != nil {
+		log.Printf("Failed to close schedule proposal %s: %v", id, err)
+	}
+	return &MedScheduleOutput{Doses: saved.Doses, Confirmed: true}, nil
+}
+
+// Helper function to find a drug's timing rule, from its label when possible
+func (f MedSchedule) timing(ctx context.Context, name string) MedicationTiming {
+	t := MedicationTiming{Medication: name, Rule: timingAnyTime, Source: timingSourceDefault}
+	lower := strings.ToLower(name)
+	for drug, rule := range defaultTimings {
+		if strings.Contains(lower, drug) {
+			t.Rule = rule
+			break
+		}
+	}
+
+	if f.Labels == nil {
+		return t
+	}
+	label, err := f.Labels.Label(ctx, name)
+	if err != nil {
+		if !errors.Is(err, fda.ErrNotFound) {
+			log.Printf("FDA label lookup failed for %q: %v", name, err)
+		}
+		return t
+	}
+	if rule := labelTiming(label.FoodTiming); rule != "" {
+		t.Rule, t.Source, t.LabelText = rule, timingSourceLabel, label.FoodTiming
+	}
+	return t
+}
+
+// Helper function to read a timing rule from label text
+func labelTiming(text string) string {
+	text = strings.ToLower(text)
+	switch {
+	case text == "":
+		return ""
+	case strings.Contains(text, "empty stomach"):
+		return timingEmptyStomach
+	case strings.Contains(text, "before breakfast") || strings.Contains(text, "before meals") || strings.Contains(text, "before the first meal") || strings.Contains(text, "minutes before"):
+		return timingBeforeMeal
+	case strings.Contains(text, "with meals") || strings.Contains(text, "with food") || strings.Contains(text, "with breakfast") || strings.Contains(text, "with the evening meal") || strings.Contains(text, "with the first main meal"):
+		return timingWithMeal
+	case strings.Contains(text, "bedtime"):
+		return timingBedtime
+	case strings.Contains(text, "with or without food") || strings.Contains(text, "without regard to"):
+		return timingAnyTime
+	}
+	return ""
+}
+
+// Daily routine in minutes after midnight
+type routine struct {
+	wake, breakfast, lunch, dinner, bed int
+}
+
+func (r routine) String() string {
+	return fmt.Sprintf("wake %s, breakfast %s, lunch %s, dinner %s, bed %s", clock(r.wake), clock(r.breakfast), clock(r.lunch), clock(r.dinner), clock(r.bed))
+}
+
+// Helper function to read the routine, filling in defaults
+func parseRoutine(in *MedScheduleInput) (routine, error) {
+	var r routine
+	for _, field := range []struct {
+		name, value, fallback string
+		into                  *int
+	}{
+		{"wake", in.Wake, "07:00", &r.wake},
+		{"breakfast", in.Breakfast, "07:30", &r.breakfast},
+		{"lunch", in.Lunch, "12:30", &r.lunch},
+		{"dinner", in.Dinner, "19:00", &r.dinner},
+		{"bed", in.Bed, "22:30", &r.bed},
+	} {
+		value := field.value
+		if value == "" {
+			value = field.fallback
+		}
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			return r, fmt.Errorf("%s must be HH:MM, got %q", field.name, value)
+		}
+		*field.into = t.Hour()*60 + t.Minute()
+	}
+	return r, nil
+}
+
+// Helper function to place a drug's daily doses in the routine
+func scheduleDoses(med ScheduleMedication, rule string, r routine) []store.ScheduledDose {
+	times := min(max(med.TimesPerDay, 1), 4)
+	meals := [][]int{{r.breakfast}, {r.breakfast, r.dinner}, {r.breakfast, r.lunch, r.dinner}, {r.breakfast, r.lunch, r.dinner, r.bed}}[times-1]
+	names := map[int]string{r.breakfast: "breakfast", r.lunch: "lunch", r.dinner: "dinner", r.bed: "bedtime"}
+
+	var doses []store.ScheduledDose
+	add := func(at int, instruction string) {
+		doses = append(doses, store.ScheduledDose{Medication: med.Name, Dose: med.Dose, Time: clock(at), Instruction: instruction})
+	}
+	for i, meal := range meals {
+		switch {
+		case rule == timingBedtime && times == 1:
+			add(r.bed, "at bedtime")
+		case rule == timingEmptyStomach && i == 0:
+			add(min(r.wake, r.breakfast-30), "on an empty stomach, at least 30 minutes before breakfast")
+		case rule == timingEmptyStomach || rule == timingBeforeMeal:
+			if meal == r.bed {
+				add(r.bed, "at bedtime, at least 2 hours after dinner")
+			} else {
+				add(meal-30, "30 minutes before "+names[meal])
+			}
+		case rule == timingWithMeal && meal != r.bed:
+			add(meal, "with "+names[meal])
+		case meal == r.bed:
+			add(r.bed, "at bedtime")
+		default:
+			add(meal, "at "+names[meal]+", with or without food")
+		}
+	}
+	return doses
+}
+
+// Helper function to format minutes after midnight as HH:MM
+func clock(minutes int) string {
+	minutes = (minutes%(24*60) + 24*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,206 @@
+package flows
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/requestid"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// What a symptom photo shows
+const (
+	photoFootWound     = "foot_wound"
+	photoSkin          = "skin"
+	photoInjectionSite = "injection_site"
+)
+
+// Photo severity grades, from the model's visual assessment
+const (
+	photoMild     = "mild"
+	photoModerate = "moderate"
+	photoSevere   = "severe"
+	photoUnclear  = "unclear"
+)
+
+// Largest photo accepted, decoded
+const maxPhotoBytes = 4 << 20
+
+// Image types the model accepts
+var photoTypes = []string{"image/jpeg", "image/png", "image/webp", "image/heic"}
+
+// Visual findings the model may report
+var photoFindings = []string{"open_wound", "spreading_redness", "swelling", "pus", "red_streaks", "black_tissue", "deep_wound", "bone_visible", "blisters", "bruising", "lump", "rash"}
+
+// Photo Triage Struct
+type PhotoTriage struct {
+	Site        string   `json:"site" jsonschema:"description=What the photo shows: foot_wound, skin, injection_site"`
+	Severity    string   `json:"severity" jsonschema:"description=Visual severity: mild, moderate, severe, or unclear when the photo can't be judged"`
+	Findings    []string `json:"findings" jsonschema:"description=Visible signs, e.g. spreading_redness, pus, black_tissue"`
+	Description string   `json:"description,omitempty" jsonschema:"description=What the photo shows, in a sentence"`
+	Escalation  string   `json:"escalation" jsonschema:"description=Escalation the photo calls for: none, monitor, call_doctor, emergency"`
+	Reasons     []string `json:"reasons,omitempty" jsonschema:"description=Escalation rules that fired"`
+}
+
+// Helper function to check for a visual finding
+func (p *PhotoTriage) has(finding string) bool {
+	return slices.Contains(p.Findings, finding)
+}
+
+// Escalation rules for symptom photos. They only ever raise the urgency, and
+// a photo that can't be judged is treated as needing a clinician's eyes.
+var photoRules = []Rule[*PhotoTriage]{
+	{
+		ID:       "photo-severe",
+		When:     func(p *PhotoTriage) bool { return p.Severity == photoSevere },
+		Step:     "Photo looks severe",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "photo-tissue-death",
+		When:     func(p *PhotoTriage) bool { return p.has("black_tissue") },
+		Step:     "Black or dead-looking tissue",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "photo-red-streaks",
+		When:     func(p *PhotoTriage) bool { return p.has("red_streaks") },
+		Step:     "Red streaks spreading from the area",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID: "photo-deep-wound",
+		When: func(p *PhotoTriage) bool {
+			return p.has("bone_visible") || (p.Site == photoFootWound && p.has("deep_wound"))
+		},
+		Step:     "Deep wound",
+		Escalate: escalateEmergency,
+	},
+	{
+		ID:       "photo-infection-signs",
+		When:     func(p *PhotoTriage) bool { return p.has("spreading_redness") || p.has("pus") || p.has("swelling") },
+		Step:     "Signs of infection",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID: "photo-foot-wound",
+		When: func(p *PhotoTriage) bool {
+			return p.Site == photoFootWound && (p.has("open_wound") || p.has("blisters") || p.has("deep_wound"))
+		},
+		Step:     "Open foot wound: diabetic foot wounds need checking within a day",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "photo-moderate",
+		When:     func(p *PhotoTriage) bool { return p.Severity == photoModerate },
+		Step:     "Photo looks moderately severe",
+		Escalate: escalateDoctor,
+	},
+	{
+		ID:       "photo-unclear",
+		When:     func(p *PhotoTriage) bool { return p.Severity == photoUnclear },
+		Step:     "Photo could not be assessed",
+		Escalate: escalateMonitor,
+	},
+	{
+		ID:       "photo-unclear-foot",
+		When:     func(p *PhotoTriage) bool { return p.Severity == photoUnclear && p.Site == photoFootWound },
+		Step:     "Foot wound that could not be assessed from the photo",
+		Escalate: escalateDoctor,
+	},
+}
+
+// Helper function to check a photo data URL, returning its image type
+func checkPhoto(dataURL string) (string, error) {
+	header, data, ok := strings.Cut(dataURL, ",")
+	mimeType, isBase64 := strings.CutSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	if !ok || !strings.HasPrefix(header, "data:") || !isBase64 {
+		return "", fmt.Errorf("image must be a base64 data URL, e.g. data:image/jpeg;base64,...")
+	}
+	if !slices.Contains(photoTypes, mimeType) {
+		return "", fmt.Errorf("image type %q is not supported: use %s", mimeType, strings.Join(photoTypes, ", "))
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > maxPhotoBytes {
+		return "", fmt.Errorf("image is larger than %d MB", maxPhotoBytes>>20)
+	}
+	if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+		return "", fmt.Errorf("image is not valid base64")
+	}
+	return mimeType, nil
+}
+
+// Assess a symptom photo with the model and apply the photo escalation rules.
+// If the model can't be reached the photo is graded unclear, so it still escalates.
+func assessPhoto(ctx context.Context, g *genkit.Genkit, in *SymptomInput, mimeType string) *PhotoTriage {
+	site := in.ImageSite
+	if site == "" {
+		site = photoSkin
+	}
+	triage := &PhotoTriage{Site: site, Severity: photoUnclear, Findings: []string{}}
+
+	prompt := fmt.Sprintf(prompts.Get("photoTriage"), strings.ReplaceAll(site, "_", " "), in.Symptoms, strings.Join(photoFindings, ", "))
+	result, err := generate(ctx, g, prompt, ai.WithMessages(ai.NewUserMessage(ai.NewMediaPart(mimeType, in.Image))))
+	if err != nil {
+		log.Printf("Photo triage failed, treating the photo as unclear request_id=%s: %v", requestid.From(ctx), err)
+	} else {
+		parsePhotoTriage(result.Text(), triage)
+	}
+
+	verdict := evaluateRules(photoRules, triage)
+	triage.Escalation = verdict.Escalation
+	triage.Reasons = verdict.Steps
+	return triage
+}
+
+// Helper function to parse the "SEVERITY:", "FINDINGS:" and "DESCRIPTION:" lines
+// of a photo assessment. Unknown severities stay unclear and unknown findings are dropped.
+func parsePhotoTriage(text string, triage *PhotoTriage) {
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(strings.Trim(strings.TrimSpace(line), "-*• "), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.Trim(key, "* ")) {
+		case "SEVERITY":
+			switch severity := strings.ToLower(strings.Trim(value, "*. ")); severity {
+			case photoMild, photoModerate, photoSevere:
+				triage.Severity = severity
+			}
+		case "FINDINGS":
+			for _, f := range strings.Split(value, ",") {
+				f = strings.ReplaceAll(strings.ToLower(strings.Trim(f, "*. ")), " ", "_")
+				if slices.Contains(photoFindings, f) && !triage.has(f) {
+					triage.Findings = append(triage.Findings, f)
+				}
+			}
+		case "DESCRIPTION":
+			triage.Description = value
+		}
+	}
+}
+
+// Helper function to render the photo assessment as a prompt line
+func (p *PhotoTriage) PromptSummary() string {
+	if p == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("Photo of the %s: severity %s", strings.ReplaceAll(p.Site, "_", " "), p.Severity)
+	if len(p.Findings) > 0 {
+		summary += ", findings " + strings.ReplaceAll(strings.Join(p.Findings, ", "), "_", " ")
+	}
+	if p.Description != "" {
+		summary += ". " + strings.TrimRight(p.Description, ". ")
+	}
+	if len(p.Reasons) > 0 {
+		summary += fmt.Sprintf(". Photo escalation: %s (%s)", p.Escalation, strings.Join(p.Reasons, "; "))
+	}
+	return summary + "."
+}
@@ -0,0 +1,193 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/server"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Recipe Input Struct
+type RecipeInput struct {
+	Meal      string `json:"meal" jsonschema:"description=A meal line from the meal plan, e.g. Vegetable omelette with whole-grain toast"`
+	Servings  int    `json:"servings,omitempty" jsonschema:"description=Number of servings (default 1)"`
+	DietType  string `json:"diet_type,omitempty" jsonschema:"description=Diet preference: vegetarian, non_vegetarian, vegan (optional)"`
+	Allergies string `json:"allergies,omitempty" jsonschema:"description=Any food allergies or restrictions (optional)"`
+}
+
+// Recipe Ingredient Struct
+type RecipeIngredient struct {
+	Name     string  `json:"name"`
+	Grams    float64 `json:"grams,omitempty" jsonschema:"description=Weight for all servings in grams"`
+	Quantity string  `json:"quantity,omitempty" jsonschema:"description=Household measure, e.g. 1 cup"`
+}
+
+// Recipe Nutrition Struct
+type RecipeNutrition struct {
+	Carbs    float64 `json:"carbs"`
+	Protein  float64 `json:"protein"`
+	Fat      float64 `json:"fat"`
+	Fiber    float64 `json:"fiber"`
+	Calories float64 `json:"calories"`
+	Source   string  `json:"source" jsonschema:"description=usda when computed from FoodData Central, otherwise model_estimate"`
+}
+
+// Recipe Output Struct
+type RecipeOutput struct {
+	Title       string             `json:"title"`
+	Servings    int                `json:"servings"`
+	Ingredients []RecipeIngredient `json:"ingredients"`
+	Steps       []string           `json:"steps"`
+	PrepMinutes int                `json:"prep_minutes"`
+	CookMinutes int                `json:"cook_minutes"`
+	PerServing  RecipeNutrition    `json:"per_serving" jsonschema:"description=Carbs, protein, fat and fiber in grams per serving"`
+}
+
+// Matches "- 120 g | brown rice | 2/3 cup"
+var ingredientLine = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)\s*g\s*\|)?\s*([^|]+?)\s*(?:\|\s*(.+))?$`)
+
+// Matches "carbs 45 g" style values in the nutrition line
+var nutrientValue = regexp.MustCompile(`(?i)(carbs|protein|fat|fiber|calories)\D*?(\d+(?:\.\d+)?)`)
+
+// Helper function to parse the model's recipe text
+func parseRecipe(text string) RecipeOutput {
+	out := RecipeOutput{Ingredients: []RecipeIngredient{}, Steps: []string{}}
+	section := ""
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(strings.Trim(strings.TrimSpace(raw), "*#"))
+		upper := strings.ToUpper(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(upper, "INGREDIENTS"):
+			section = "ingredients"
+		case strings.HasPrefix(upper, "STEPS"):
+			section = "steps"
+		case strings.HasPrefix(upper, "PREP TIME"):
+			out.PrepMinutes = leadingInt(line[len("PREP TIME"):])
+		case strings.HasPrefix(upper, "COOK TIME"):
+			out.CookMinutes = leadingInt(line[len("COOK TIME"):])
+		case strings.HasPrefix(upper, "NUTRITION PER SERVING"):
+			out.PerServing = RecipeNutrition{Source: "model_estimate"}
+			for _, m := range nutrientValue.FindAllStringSubmatch(line, -1) {
+				v, _ := strconv.ParseFloat(m[2], 64)
+				switch strings.ToLower(m[1]) {
+				case "carbs":
+					out.PerServing.Carbs = v
+				case "protein":
+					out.PerServing.Protein = v
+				case "fat":
+					out.PerServing.Fat = v
+				case "fiber":
+					out.PerServing.Fiber = v
+				case "calories":
+					out.PerServing.Calories = v
+				}
+			}
+		case section == "ingredients" && strings.HasPrefix(line, "-"):
+			m := ingredientLine.FindStringSubmatch(strings.TrimSpace(strings.TrimPrefix(line, "-")))
+			if m == nil {
+				continue
+			}
+			grams, _ := strconv.ParseFloat(m[1], 64)
+			out.Ingredients = append(out.Ingredients, RecipeIngredient{Name: m[2], Grams: grams, Quantity: strings.TrimSpace(m[3])})
+		case section == "steps":
+			step := strings.TrimSpace(strings.TrimLeft(line, "0123456789.)-"))
+			if step != "" {
+				out.Steps = append(out.Steps, step)
+			}
+		}
+	}
+	return out
+}
+
+// Helper function to read the first whole number in text
+func leadingInt(text string) int {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(fields[0])
+	return n
+}
+
+// Helper function to compute per-serving nutrition from USDA values.
+// Returns false unless every ingredient has a weight and a USDA match.
+func usdaNutrition(ctx context.Context, client *nutrition.Client, ingredients []RecipeIngredient, servings int) (RecipeNutrition, bool) {
+	if len(ingredients) == 0 {
+		return RecipeNutrition{}, false
+	}
+
+	total := RecipeNutrition{Source: "usda"}
+	for _, ing := range ingredients {
+		if ing.Grams <= 0 {
+			return RecipeNutrition{}, false
+		}
+		food, err := client.Lookup(ctx, ing.Name)
+		if err != nil {
+			return RecipeNutrition{}, false
+		}
+		scale := ing.Grams / 100
+		total.Carbs += food.Carbs * scale
+		total.Protein += food.Protein * scale
+		total.Fat += food.Fat * scale
+		total.Fiber += food.Fiber * scale
+		total.Calories += food.Calories * scale
+	}
+
+	n := float64(servings)
+	return RecipeNutrition{
+		Carbs:    math.Round(total.Carbs/n*10) / 10,
+		Protein:  math.Round(total.Protein/n*10) / 10,
+		Fat:      math.Round(total.Fat/n*10) / 10,
+		Fiber:    math.Round(total.Fiber/n*10) / 10,
+		Calories: math.Round(total.Calories / n),
+		Source:   total.Source,
+	}, true
+}
+
+// Recipe Flow
+type Recipe struct {
+	Nutrition *nutrition.Client
+}
+
+func (f Recipe) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "recipe", func(ctx context.Context, input *RecipeInput) (*RecipeOutput, error) {
+		if strings.TrimSpace(input.Meal) == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "meal is required", nil)
+		}
+		servings := input.Servings
+		if servings <= 0 {
+			servings = 1
+		}
+
+		prompt := fmt.Sprintf(prompts.Get("recipe"), input.Meal, servings, input.DietType, input.Allergies)
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recipe: %w", err)
+		}
+
+		output := parseRecipe(result.Text())
+		output.Title = input.Meal
+		output.Servings = servings
+
+		// Prefer USDA values over the model's estimate when every ingredient can be looked up
+		if f.Nutrition.Enabled() {
+			if per, ok := usdaNutrition(ctx, f.Nutrition, output.Ingredients, servings); ok {
+				output.PerServing = per
+			}
+		}
+		return &output, nil
+	})
+	mux.HandleCachedFlow("POST /recipe", flow, "Expand a meal plan line into a full recipe with nutrition")
+}
@@ -0,0 +1,75 @@
+package flows
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escalation levels, in increasing order of severity
+const (
+	escalateNone      = "none"
+	escalateMonitor   = "monitor"
+	escalateDoctor    = "call_doctor"
+	escalateEmergency = "emergency"
+)
+
+var escalationRank = map[string]int{
+	escalateNone:      0,
+	escalateMonitor:   1,
+	escalateDoctor:    2,
+	escalateEmergency: 3,
+}
+
+// Rule Struct
+//
+// A rule fires when When returns true, contributing its step to the plan
+// and raising the overall escalation to at least its Escalate level.
+// Steps that quote a clinical threshold use StepText, so the text follows
+// the table in force.
+type Rule[T any] struct {
+	ID       string
+	When     func(T) bool
+	Step     string
+	StepText func() string
+	Escalate string
+}
+
+// Rule Verdict Struct
+type RuleVerdict struct {
+	Escalation string   `json:"escalation" jsonschema:"description=Escalation: none, monitor, call_doctor, emergency"`
+	Steps      []string `json:"steps" jsonschema:"description=Ordered action steps"`
+	Triggered  []string `json:"triggered_rules" jsonschema:"description=IDs of the rules that fired"`
+}
+
+// Evaluate rules in order, collecting steps and the highest escalation
+func evaluateRules[T any](rules []Rule[T], in T) RuleVerdict {
+	verdict := RuleVerdict{Escalation: escalateNone, Steps: []string{}, Triggered: []string{}}
+	for _, rule := range rules {
+		if !rule.When(in) {
+			continue
+		}
+		verdict.Triggered = append(verdict.Triggered, rule.ID)
+		step := rule.Step
+		if rule.StepText != nil {
+			step = rule.StepText()
+		}
+		if step != "" {
+			verdict.Steps = append(verdict.Steps, step)
+		}
+		if escalationRank[rule.Escalate] > escalationRank[verdict.Escalation] {
+			verdict.Escalation = rule.Escalate
+		}
+	}
+	return verdict
+}
+
+// Helper function to render the rule verdict as a prompt block
+func (v RuleVerdict) PromptSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Escalation: %s\n", v.Escalation)
+	b.WriteString("Steps:\n")
+	for i, step := range v.Steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	return b.String()
+}
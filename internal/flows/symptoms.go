@@ -0,0 +1,342 @@
+package flows
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/icd10"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/resources"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/sessions"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Symptom Input Struct
+type SymptomInput struct {
+	UserID       string  `json:"user_id,omitempty" jsonschema:"description=User identifier used to keep the check for the clinician export (optional)"`
+	Symptoms     string  `json:"symptoms,omitempty" jsonschema:"description=Describe symptoms you're experiencing (required on the first call)"`
+	Duration     string  `json:"duration,omitempty" jsonschema:"description=How long symptoms have been present"`
+	CurrentMeds  string  `json:"current_meds,omitempty" jsonschema:"description=Current medications (optional)"`
+	CurrentBG    float64 `json:"current_bg,omitempty" jsonschema:"description=Current blood glucose in mg/dL (optional)"`
+	Ketones      string  `json:"ketones,omitempty" jsonschema:"description=Urine ketone result: negative, trace, small, moderate, large (optional)"`
+	BloodKetones float64 `json:"blood_ketones,omitempty" jsonschema:"description=Blood ketones in mmol/L (optional)"`
+	FruityBreath bool    `json:"fruity_breath,omitempty" jsonschema:"description=Fruity-smelling breath"`
+	Nausea       bool    `json:"nausea,omitempty" jsonschema:"description=Feeling nauseous"`
+	Vomiting     bool    `json:"vomiting,omitempty" jsonschema:"description=Has been vomiting"`
+	SuggestICD10 bool    `json:"suggest_icd10,omitempty" jsonschema:"description=Add candidate ICD-10 codes for the clinician export"`
+	Location     string  `json:"location,omitempty" jsonschema:"description=Country or region, e.g. Kenya or GB, for local emergency numbers (optional)"`
+	Image        string  `json:"image,omitempty" jsonschema:"description=Photo of a foot wound, rash or injection site as a base64 data URL (data:image/jpeg;base64,...), up to 4 MB (optional)"`
+	ImageSite    string  `json:"image_site,omitempty" jsonschema:"description=What the photo shows: foot_wound, skin, injection_site (default skin)"`
+	SessionID    string  `json:"session_id,omitempty" jsonschema:"description=Session from a needs_more_info response. Send only the answered fields; earlier ones are kept"`
+}
+
+// Follow-up Question Struct
+type FollowUpQuestion struct {
+	Field    string `json:"field" jsonschema:"description=Input field the answer goes in"`
+	Question string `json:"question"`
+}
+
+// Symptom Output Struct
+type SymptomOutput struct {
+	Status     string               `json:"status" jsonschema:"description=complete, or needs_more_info when follow-up questions must be answered first"`
+	SessionID  string               `json:"session_id,omitempty" jsonschema:"description=Send back with the answers to continue the check"`
+	Questions  []FollowUpQuestion   `json:"questions,omitempty" jsonschema:"description=Details needed before urgency can be judged"`
+	Urgency    string               `json:"urgency,omitempty" jsonschema:"description=Urgency level: emergency, urgent, routine"`
+	Assessment string               `json:"assessment,omitempty" jsonschema:"description=Symptom assessment"`
+	NextSteps  string               `json:"next_steps,omitempty" jsonschema:"description=Recommended next steps"`
+	DKAScreen  DKAScreen            `json:"dka_screen" jsonschema:"description=Deterministic DKA screening result"`
+	Photo      *PhotoTriage         `json:"photo_triage,omitempty" jsonschema:"description=Visual assessment of the photo and the escalation it calls for"`
+	ICD10      []icd10.Code         `json:"icd10_suggestions,omitempty" jsonschema:"description=Candidate ICD-10 codes for clinician review. Suggestions only, not a diagnosis"`
+	Emergency  *resources.Resources `json:"emergency_resources,omitempty" jsonschema:"description=Local emergency numbers and ER guidance, on emergency urgency"`
+}
+
+// Symptom check states
+const (
+	symptomComplete      = "complete"
+	symptomNeedsMoreInfo = "needs_more_info"
+)
+
+// How recent a logged reading must be to stand in for current_bg
+const symptomReadingWindow = time.Hour
+
+// Symptom Checker Flow
+//
+// When duration, blood glucose or (with DKA warning signs) ketones are missing,
+// the first call returns follow-up questions and a session instead of an assessment.
+type Symptoms struct {
+	Checks   *store.LogStore[store.SymptomCheck]
+	Readings *store.ReadingStore
+	Sessions *sessions.Store
+	Eval     *Evaluator
+	Redact   store.Redactor
+}
+
+func (f Symptoms) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "symptomChecker", func(ctx context.Context, input *SymptomInput) (*SymptomOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		// Continue a check: earlier answers fill in whatever this call leaves out
+		var session *sessions.Session
+		var photo *PhotoTriage
+		if input.SessionID != "" && f.Sessions != nil {
+			var err error
+			session, err = f.Sessions.Get(ctx, input.SessionID, userID)
+			if errors.Is(err, sessions.ErrNotFound) {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "session_id is unknown or has expired; start a new symptom check", nil)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load symptom session: %w", err)
+			}
+			input, photo = mergeSymptomInput(session, input)
+		}
+		if strings.TrimSpace(input.Symptoms) == "" {
+			return nil, core.NewPublicError(core.INVALID_ARGUMENT, "symptoms is required", nil)
+		}
+
+		if input.CurrentBG == 0 && f.Readings != nil {
+			if latest, ok := f.Readings.Latest(userID); ok && time.Since(latest.Timestamp) <= symptomReadingWindow {
+				input.CurrentBG = latest.Value
+			}
+		}
+
+		dka := screenDKA(input)
+		dryrun.Verdict(ctx, "dkaScreen", dka)
+
+		// Grade the photo before asking anything, so a severe one goes straight to the assessment
+		if input.Image != "" {
+			mimeType, err := checkPhoto(input.Image)
+			if err != nil {
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, err.Error(), nil)
+			}
+			switch input.ImageSite {
+			case "", photoFootWound, photoSkin, photoInjectionSite:
+			default:
+				return nil, core.NewPublicError(core.INVALID_ARGUMENT, "image_site must be foot_wound, skin or injection_site", nil)
+			}
+			photo = assessPhoto(ctx, g, input, mimeType)
+			dryrun.Verdict(ctx, "photoTriage", photo)
+		}
+		photoEmergency := photo != nil && photo.Escalation == escalateEmergency
+
+		// Ask once for missing details, unless the DKA screen or the photo already calls for the emergency room
+		if questions := symptomFollowUps(input); len(questions) > 0 && session == nil && !dka.Positive && !photoEmergency && f.Sessions != nil {
+			session = f.Sessions.Start(userID, "symptomChecker")
+			asked, _ := json.Marshal(f.sessionInput(input, photo))
+			session.Add("user", string(asked))
+			for _, q := range questions {
+				session.Add("model", q.Question)
+			}
+			if !dryrun.Active(ctx) {
+				if err := f.Sessions.Save(ctx, session); err != nil {
+					return nil, fmt.Errorf("failed to save symptom session: %w", err)
+				}
+			}
+			return &SymptomOutput{Status: symptomNeedsMoreInfo, SessionID: session.ID, Questions: questions, DKAScreen: dka, Photo: photo}, nil
+		}
+
+		bgInfo := ""
+		if input.CurrentBG > 0 {
+			bgInfo = fmt.Sprintf("Current Blood Glucose: %.1f mg/dL", input.CurrentBG)
+		}
+
+		local := resources.Lookup(input.Location)
+		prompt := fmt.Sprintf(prompts.Get("symptomChecker"), input.Symptoms, input.Duration, input.CurrentMeds, bgInfo, ketoneTier(input.Ketones, input.BloodKetones), strings.TrimSpace(dka.PromptSummary()+"\n"+photo.PromptSummary()), local.PromptSummary())
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check symptoms: %w", err)
+		}
+
+		text := result.Text()
+
+		// Determine urgency from response
+		urgency := "routine"
+		if parse.ContainsKeywords(text, []string{"emergency", "immediate", "urgent care"}) || callsEmergencyNumber(text, "911", local.EmergencyNumber) {
+			urgency = "emergency"
+		} else if parse.ContainsKeywords(text, []string{"urgent", "contact doctor", "today"}) {
+			urgency = "urgent"
+		}
+
+		// DKA screening overrides the model's urgency, and the photo can only raise it
+		if dka.Positive {
+			urgency = "emergency"
+		}
+		if photo != nil {
+			urgency = raiseUrgency(urgency, photo.Escalation)
+		}
+
+		parts := parse.SplitIntoSections(text, 3)
+
+		output := &SymptomOutput{
+			Status:     symptomComplete,
+			Urgency:    urgency,
+			Assessment: parts[0],
+			NextSteps:  parts[1],
+			DKAScreen:  dka,
+			Photo:      photo,
+		}
+		if urgency == "emergency" {
+			output.Emergency = &local
+		}
+
+		// Suggest ICD-10 codes from the lookup table, then let the model add candidates
+		if input.SuggestICD10 {
+			codes := icd10.Lookup(input.Symptoms + "\n" + output.Assessment)
+			coderPrompt := fmt.Sprintf(prompts.Get("icd10Coder"), input.Symptoms, output.Assessment, icd10.PromptList(codes))
+			if coded, err := generate(ctx, g, coderPrompt); err != nil {
+				log.Printf("ICD-10 suggestion failed, using lookup codes only: %v", err)
+			} else {
+				codes = append(codes, icd10.ParseSuggestions(coded.Text(), codes)...)
+			}
+			output.ICD10 = codes
+		}
+
+		check := store.SymptomCheck{
+			UserID:     userID,
+			Symptoms:   input.Symptoms,
+			Duration:   input.Duration,
+			Urgency:    output.Urgency,
+			Assessment: output.Assessment,
+			ICD10:      output.ICD10,
+		}
+		store.Stamp(&check.UserID, &check.Timestamp)
+		if !dryrun.Active(ctx) {
+			f.Checks.Add(ctx, check)
+		}
+
+		if session != nil && !dryrun.Active(ctx) {
+			if err := f.Sessions.Delete(ctx, session.ID); err != nil {
+				log.Printf("Failed to close symptom session %s: %v", session.ID, err)
+			}
+		}
+
+		// Grade the answer without the photo's bytes
+		sampled := *input
+		if sampled.Image != "" {
+			sampled.Image = "(photo attached)"
+		}
+		f.Eval.Sample(ctx, "symptomChecker", &sampled, output)
+		return output, nil
+	})
+	mux.HandleWriteFlow("POST /symptoms", flow, "Check symptoms and get guidance")
+}
+
+// Helper function to report whether text tells the user to call one of the
+// emergency numbers. Numbers alone also appear in readings and counts, so only
+// a whole number after call or dial counts.
+func callsEmergencyNumber(text string, numbers ...string) bool {
+	var quoted []string
+	for _, n := range numbers {
+		if n != "" {
+			quoted = append(quoted, regexp.QuoteMeta(n))
+		}
+	}
+	if len(quoted) == 0 {
+		return false
+	}
+	return regexp.MustCompile(`(?i)\b(?:call|dial|ring|phone)\s+(?:` + strings.Join(quoted, "|") + `)\b`).MatchString(text)
+}
+
+// Helper function to raise a symptom urgency to at least what an escalation calls for
+func raiseUrgency(urgency, escalation string) string {
+	switch {
+	case escalation == escalateEmergency:
+		return "emergency"
+	case escalation == escalateDoctor && urgency == "routine":
+		return "urgent"
+	}
+	return urgency
+}
+
+// Helper function to list follow-up questions for details the urgency depends on
+func symptomFollowUps(in *SymptomInput) []FollowUpQuestion {
+	questions := []FollowUpQuestion{}
+	if strings.TrimSpace(in.Duration) == "" {
+		questions = append(questions, FollowUpQuestion{Field: "duration", Question: "How long have you had these symptoms?"})
+	}
+	if in.CurrentBG == 0 {
+		questions = append(questions, FollowUpQuestion{Field: "current_bg", Question: "What is your blood sugar right now? Please check it if you can."})
+	}
+	warningSigns := in.CurrentBG > highBGActionThreshold() || in.Nausea || in.Vomiting || in.FruityBreath
+	if warningSigns && in.Ketones == "" && in.BloodKetones == 0 {
+		questions = append(questions, FollowUpQuestion{Field: "ketones", Question: "Can you check your ketones (urine strip: negative, trace, small, moderate or large; or a blood ketone reading)?"})
+	}
+	return questions
+}
+
+// First call of a symptom check as kept in its session: the photo's grade
+// instead of its bytes, and free text redacted like stored checks
+type symptomSession struct {
+	SymptomInput
+	Photo *PhotoTriage `json:"photo_triage,omitempty"`
+}
+
+// Helper function to build the session copy of a symptom check's first call
+func (f Symptoms) sessionInput(input *SymptomInput, photo *PhotoTriage) symptomSession {
+	kept := symptomSession{SymptomInput: *input, Photo: photo}
+	kept.Image = ""
+	if f.Redact != nil {
+		kept.Symptoms = f.Redact("symptoms", kept.Symptoms)
+		kept.Duration = f.Redact("duration", kept.Duration)
+		kept.CurrentMeds = f.Redact("note", kept.CurrentMeds)
+	}
+	return kept
+}
+
+// Helper function to fill fields left out of a follow-up call from the
+// session's first call, returning the first call's photo grade
+func mergeSymptomInput(session *sessions.Session, answers *SymptomInput) (*SymptomInput, *PhotoTriage) {
+	var kept symptomSession
+	if len(session.Messages) == 0 || json.Unmarshal([]byte(session.Messages[0].Content), &kept) != nil {
+		return answers, nil
+	}
+	merged := kept.SymptomInput
+
+	if answers.Symptoms != "" {
+		merged.Symptoms = answers.Symptoms
+	}
+	if answers.Duration != "" {
+		merged.Duration = answers.Duration
+	}
+	if answers.Location != "" {
+		merged.Location = answers.Location
+	}
+	if answers.CurrentMeds != "" {
+		merged.CurrentMeds = answers.CurrentMeds
+	}
+	if answers.CurrentBG > 0 {
+		merged.CurrentBG = answers.CurrentBG
+	}
+	if answers.Ketones != "" {
+		merged.Ketones = answers.Ketones
+	}
+	if answers.BloodKetones > 0 {
+		merged.BloodKetones = answers.BloodKetones
+	}
+	if answers.Image != "" {
+		merged.Image, merged.ImageSite = answers.Image, answers.ImageSite
+		kept.Photo = nil
+	}
+	merged.FruityBreath = merged.FruityBreath || answers.FruityBreath
+	merged.Nausea = merged.Nausea || answers.Nausea
+	merged.Vomiting = merged.Vomiting || answers.Vomiting
+	merged.SuggestICD10 = merged.SuggestICD10 || answers.SuggestICD10
+	merged.SessionID = answers.SessionID
+	return &merged, kept.Photo
+}
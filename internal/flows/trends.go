@@ -0,0 +1,67 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// GlucoseTrends Input Struct
+type GlucoseTrendsInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+	Days   int    `json:"days,omitempty" jsonschema:"description=Number of days to analyze (default 14)"`
+}
+
+// GlucoseTrends Output Struct
+type GlucoseTrendsOutput struct {
+	Stats       analytics.GlucoseStats `json:"stats" jsonschema:"description=Time-in-range and variability metrics"`
+	Patterns    string                 `json:"patterns" jsonschema:"description=Patterns observed in the readings"`
+	Suggestions string                 `json:"suggestions" jsonschema:"description=Suggestions to improve control"`
+}
+
+// Glucose Trends Flow
+type GlucoseTrends struct {
+	Readings *store.ReadingStore
+}
+
+func (f GlucoseTrends) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "glucoseTrends", func(ctx context.Context, input *GlucoseTrendsInput) (*GlucoseTrendsOutput, error) {
+		days := input.Days
+		if days <= 0 {
+			days = 14
+		}
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -days)
+		stats := analytics.ComputeStats(f.Readings.Range(userID, from, to), from, to)
+
+		prompt := fmt.Sprintf(prompts.Get("glucoseTrends"), days, stats.PromptSummary())
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze glucose trends: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &GlucoseTrendsOutput{
+			Stats:       stats,
+			Patterns:    parts[0],
+			Suggestions: parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /glucoseTrends", flow, "Analyze time in range and variability")
+}
@@ -0,0 +1,88 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/parse"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// WeeklySummary Input Struct
+type WeeklySummaryInput struct {
+	UserID string `json:"user_id,omitempty" jsonschema:"description=User identifier (optional)"`
+}
+
+// WeeklySummary Output Struct
+type WeeklySummaryOutput struct {
+	Stats      analytics.GlucoseStats `json:"stats" jsonschema:"description=Metrics for the past 7 days"`
+	Summary    string                 `json:"summary" jsonschema:"description=Summary of the week"`
+	FocusAreas string                 `json:"focus_areas" jsonschema:"description=Areas to focus on next week"`
+}
+
+// Weekly Summary Flow
+type WeeklySummary struct {
+	Readings *store.ReadingStore
+	Reviews  *store.LogStore[store.DayReview]
+}
+
+func (f WeeklySummary) Register(g *genkit.Genkit, mux *server.Mux) {
+	flow := genkit.DefineFlow(g, "weeklySummary", func(ctx context.Context, input *WeeklySummaryInput) (*WeeklySummaryOutput, error) {
+		userID := input.UserID
+		if userID == "" {
+			userID = rbac.User(ctx)
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -7)
+		stats := analytics.ComputeStats(f.Readings.Range(userID, from, to), from, to)
+
+		prompt := fmt.Sprintf(prompts.Get("weeklySummary"), stats.PromptSummary()+intentionsNote(f.Reviews.Range(userID, from, to)))
+
+		result, err := generate(ctx, g, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate weekly summary: %w", err)
+		}
+
+		parts := parse.SplitIntoSections(result.Text(), 2)
+
+		return &WeeklySummaryOutput{
+			Stats:      stats,
+			Summary:    parts[0],
+			FocusAreas: parts[1],
+		}, nil
+	})
+	mux.HandleFlow("POST /weeklySummary", flow, "Summarize the past week")
+}
+
+// Helper function to list the intentions set in the week's day reviews, so the
+// summary can follow up on them. A day reviewed twice keeps its latest intention.
+func intentionsNote(reviews []store.DayReview) string {
+	latest := map[string]string{}
+	var dates []string
+	for _, r := range reviews {
+		if _, ok := latest[r.Date]; !ok {
+			dates = append(dates, r.Date)
+		}
+		latest[r.Date] = r.Intention
+	}
+	if len(dates) == 0 {
+		return ""
+	}
+	sort.Strings(dates)
+	var b strings.Builder
+	b.WriteString("\n\nIntentions set in end-of-day reviews:")
+	for _, date := range dates {
+		fmt.Fprintf(&b, "\n- %s: %s", date, latest[date])
+	}
+	return b.String()
+}
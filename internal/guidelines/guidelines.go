@@ -0,0 +1,164 @@
+// Package guidelines retrieves short clinical guideline passages relevant to
+// a question, so generated advice can be grounded in them. Passages are
+// embedded with whichever embedder is configured: Google's, or a local model
+// served by Ollama for offline and self-hosted deployments.
+package guidelines
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Passage Struct
+type Passage struct {
+	ID     string `json:"id"`
+	Topic  string `json:"topic"`
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
+
+// Built-in guideline passages
+//
+//go:embed guidelines.json
+var builtin []byte
+
+// Passages returned per search
+const DefaultK = 3
+
+// Index of guideline passages and their embeddings. Passages are embedded on
+// the first search; a failed attempt is retried on the next one.
+type Index struct {
+	embedder ai.Embedder
+	passages []Passage
+
+	mu      sync.Mutex
+	vectors [][]float32
+}
+
+// Create an index over the built-in passages. A nil embedder disables retrieval.
+func New(embedder ai.Embedder) (*Index, error) {
+	var passages []Passage
+	if err := json.Unmarshal(builtin, &passages); err != nil {
+		return nil, fmt.Errorf("failed to decode guidelines: %w", err)
+	}
+	return &Index{embedder: embedder, passages: passages}, nil
+}
+
+// Report whether retrieval is configured
+func (ix *Index) Enabled() bool {
+	return ix != nil && ix.embedder != nil
+}
+
+// Name of the embedder in use
+func (ix *Index) Embedder() string {
+	if !ix.Enabled() {
+		return ""
+	}
+	return ix.embedder.Name()
+}
+
+// Return the k passages closest to a query, most relevant first
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Passage, error) {
+	if !ix.Enabled() {
+		return nil, nil
+	}
+	vectors, err := ix.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q, err := ix.embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(vectors))
+	scores := make([]float64, len(vectors))
+	for i, v := range vectors {
+		order[i], scores[i] = i, cosine(q[0], v)
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	out := make([]Passage, 0, k)
+	for _, i := range order[:min(k, len(order))] {
+		out = append(out, ix.passages[i])
+	}
+	return out, nil
+}
+
+// Helper function to embed every passage once
+func (ix *Index) build(ctx context.Context) ([][]float32, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.vectors != nil {
+		return ix.vectors, nil
+	}
+
+	texts := make([]string, len(ix.passages))
+	for i, p := range ix.passages {
+		texts[i] = p.Text
+	}
+	vectors, err := ix.embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	ix.vectors = vectors
+	return vectors, nil
+}
+
+// Helper function to embed texts, checking one vector comes back for each
+func (ix *Index) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	docs := make([]*ai.Document, len(texts))
+	for i, t := range texts {
+		docs[i] = ai.DocumentFromText(t, nil)
+	}
+	resp, err := ix.embedder.Embed(ctx, &ai.EmbedRequest{Input: docs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed with %s: %w", ix.embedder.Name(), err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedder %s returned %d vectors for %d texts", ix.embedder.Name(), len(resp.Embeddings), len(texts))
+	}
+	out := make([][]float32, len(texts))
+	for i, e := range resp.Embeddings {
+		out[i] = e.Embedding
+	}
+	return out, nil
+}
+
+// Helper function to render passages as a prompt block
+func PromptNote(passages []Passage) string {
+	if len(passages) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Clinical guidance to base your answer on (do not contradict it):\n")
+	for _, p := range passages {
+		fmt.Fprintf(&b, "- %s (%s)\n", p.Text, p.Source)
+	}
+	return b.String()
+}
+
+// Cosine similarity of two vectors; 0 when their lengths differ
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,107 @@
+// Package icd10 suggests ICD-10-CM codes for diabetes-related symptoms.
+package icd10
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Code Struct
+//
+// A candidate code for clinician review. Codes are never diagnoses.
+type Code struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Source      string `json:"source" jsonschema:"description=Where the code came from: lookup or model"`
+	Suggestion  bool   `json:"suggestion"`
+}
+
+// Sources for a suggested code
+const (
+	SourceLookup = "lookup"
+	SourceModel  = "model"
+)
+
+// Lookup table entry: any keyword selects the code
+type entry struct {
+	code        string
+	description string
+	keywords    []string
+}
+
+// Common symptom and complication codes seen in diabetes care
+var table = []entry{
+	{"E11.65", "Type 2 diabetes mellitus with hyperglycemia", []string{"high blood sugar", "hyperglycemia", "high bg"}},
+	{"E11.649", "Type 2 diabetes mellitus with hypoglycemia without coma", []string{"low blood sugar", "hypoglycemia", "hypo", "shaky", "shakiness"}},
+	{"E11.10", "Type 2 diabetes mellitus with ketoacidosis without coma", []string{"ketoacidosis", "dka", "ketones"}},
+	{"E10.10", "Type 1 diabetes mellitus with ketoacidosis without coma", []string{"type 1 ketoacidosis"}},
+	{"E11.42", "Type 2 diabetes mellitus with diabetic polyneuropathy", []string{"numbness", "tingling", "neuropathy", "burning feet"}},
+	{"E11.621", "Type 2 diabetes mellitus with foot ulcer", []string{"foot ulcer", "foot sore", "wound on foot"}},
+	{"E11.319", "Type 2 diabetes mellitus with unspecified diabetic retinopathy without macular edema", []string{"retinopathy"}},
+	{"H53.8", "Other visual disturbances", []string{"blurred vision", "blurry vision"}},
+	{"R35.0", "Frequency of micturition", []string{"frequent urination", "urinating often", "peeing a lot"}},
+	{"R63.1", "Polydipsia", []string{"excessive thirst", "very thirsty", "thirst"}},
+	{"R53.83", "Other fatigue", []string{"fatigue", "tired", "exhausted"}},
+	{"R42", "Dizziness and giddiness", []string{"dizzy", "dizziness", "lightheaded"}},
+	{"R11.2", "Nausea with vomiting, unspecified", []string{"vomiting"}},
+	{"R11.0", "Nausea", []string{"nausea", "nauseous"}},
+	{"R41.0", "Disorientation, unspecified", []string{"confusion", "confused", "disoriented"}},
+	{"R61", "Generalized hyperhidrosis", []string{"sweating", "sweaty"}},
+	{"R63.4", "Abnormal weight loss", []string{"weight loss", "losing weight"}},
+	{"R07.9", "Chest pain, unspecified", []string{"chest pain"}},
+	{"N39.0", "Urinary tract infection, site not specified", []string{"burning urination", "uti"}},
+}
+
+// Return the table codes whose keywords appear in the text
+func Lookup(text string) []Code {
+	lower := strings.ToLower(text)
+	out := []Code{}
+	for _, e := range table {
+		for _, kw := range e.keywords {
+			if strings.Contains(lower, kw) {
+				out = append(out, Code{Code: e.code, Description: e.description, Source: SourceLookup, Suggestion: true})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Shape of an ICD-10-CM code, e.g. E11.65 or R42
+var codePattern = regexp.MustCompile(`\b([A-TV-Z][0-9][0-9A-Z](?:\.[0-9A-Z]{1,4})?)\b`)
+
+// Parse model-suggested codes from "CODE - description" lines, skipping codes already known
+func ParseSuggestions(text string, known []Code) []Code {
+	seen := map[string]bool{}
+	for _, c := range known {
+		seen[c.Code] = true
+	}
+
+	out := []Code{}
+	for _, line := range strings.Split(text, "\n") {
+		m := codePattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		code := line[m[2]:m[3]]
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		desc := strings.TrimLeft(line[m[3]:], " -–:*")
+		out = append(out, Code{Code: code, Description: strings.TrimSpace(desc), Source: SourceModel, Suggestion: true})
+	}
+	return out
+}
+
+// Helper function to list codes for a prompt
+func PromptList(codes []Code) string {
+	if len(codes) == 0 {
+		return "none"
+	}
+	lines := make([]string, len(codes))
+	for i, c := range codes {
+		lines[i] = c.Code + " - " + c.Description
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,368 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Detected file formats
+const (
+	FormatDexcom     = "dexcom_csv"
+	FormatLibreView  = "libreview_csv"
+	FormatCareLink   = "carelink_csv"
+	FormatGlucometer = "glucometer_csv"
+	FormatNightscout = "nightscout_json"
+)
+
+// mg/dL per mmol/L of glucose
+const mgdlPerMmol = 18.0182
+
+// Values at or below this are taken as mmol/L when a column has no unit
+const mmolCutoff = 35.0
+
+// Sensor readings reported as out of range instead of a number
+var outOfRange = map[string]float64{"low": 40, "high": 400, "lo": 40, "hi": 400}
+
+// Timestamp layouts tried in order. Day-first and month-first layouts are both
+// listed; the one that parses the most sampled rows of a file wins.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+	"01-02-2006 03:04 PM",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02-01-2006 15:04",
+	"02.01.2006 15:04",
+}
+
+// Batch Struct
+//
+// Records parsed from one file, plus the rows that could not be read.
+type batch struct {
+	readings []store.GlucoseReading
+	insulin  []store.InsulinDose
+	meals    []store.MealLog
+	errors   []string
+}
+
+// Helper function to note a row that could not be read
+func (b *batch) fail(format string, args ...any) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// Detect a file's format from its content and parse it.
+// Times without a zone are read in loc.
+func parseFile(data []byte, loc *time.Location) (string, *batch, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return "", nil, fmt.Errorf("file is empty")
+	}
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		b, err := parseNightscout(trimmed)
+		return FormatNightscout, b, err
+	}
+	return parseCSV(trimmed, loc)
+}
+
+// Glucose, insulin and carb columns of a CSV export
+type csvColumns struct {
+	format    string
+	timestamp int
+	date      int
+	clock     int
+	glucose   []int
+	units     []string
+	insulin   int
+	carbs     int
+}
+
+// Helper function to find the header row and the columns we read
+func findColumns(rows [][]string) (int, csvColumns, bool) {
+	for i, row := range rows[:min(len(rows), 5)] {
+		c := csvColumns{timestamp: -1, date: -1, clock: -1, insulin: -1, carbs: -1}
+		joined := strings.ToLower(strings.Join(row, "|"))
+		for j, cell := range row {
+			h := strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case strings.Contains(h, "timestamp") || strings.Contains(h, "datetime") || h == "date/time" || h == "date and time" || h == "time stamp":
+				if c.timestamp < 0 {
+					c.timestamp = j
+				}
+			case h == "date":
+				c.date = j
+			case h == "time":
+				c.clock = j
+			case strings.Contains(h, "glucose") || strings.Contains(h, "sgv") || strings.HasPrefix(h, "bg") || h == "reading":
+				if strings.Contains(h, "rate of change") || strings.Contains(h, "target") || strings.Contains(h, "trend") || strings.Contains(h, "source") {
+					continue
+				}
+				c.glucose = append(c.glucose, j)
+				c.units = append(c.units, headerUnits(h))
+			case strings.Contains(h, "non-numeric"):
+				continue
+			case strings.Contains(h, "insulin value") || strings.Contains(h, "bolus volume delivered") || strings.Contains(h, "rapid-acting insulin") || h == "bolus" || h == "insulin":
+				if c.insulin < 0 {
+					c.insulin = j
+				}
+			case strings.Contains(h, "carb"):
+				if c.carbs < 0 {
+					c.carbs = j
+				}
+			}
+		}
+
+		hasTime := c.timestamp >= 0 || c.date >= 0
+		if !hasTime || (len(c.glucose) == 0 && c.insulin < 0) {
+			continue
+		}
+		switch {
+		case strings.Contains(joined, "event type") && strings.Contains(joined, "glucose value"):
+			c.format = FormatDexcom
+		case strings.Contains(joined, "historic glucose"):
+			c.format = FormatLibreView
+		case strings.Contains(joined, "sensor glucose") || strings.Contains(joined, "bwz"):
+			c.format = FormatCareLink
+		default:
+			c.format = FormatGlucometer
+		}
+		return i, c, true
+	}
+	return 0, csvColumns{}, false
+}
+
+// Helper function to parse a glucometer, CGM or pump CSV export
+func parseCSV(data []byte, loc *time.Location) (string, *batch, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte("\t")) > bytes.Count(first, []byte(",")) {
+		r.Comma = '\t'
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	header, cols, ok := findColumns(rows)
+	if !ok {
+		return "", nil, fmt.Errorf("unrecognized format: no timestamp and glucose or insulin columns found")
+	}
+	rows = rows[header+1:]
+
+	stamp := func(row []string) string {
+		if cols.timestamp >= 0 {
+			return cell(row, cols.timestamp)
+		}
+		return strings.TrimSpace(cell(row, cols.date) + " " + cell(row, cols.clock))
+	}
+	var samples []string
+	for _, row := range rows {
+		if s := stamp(row); s != "" {
+			samples = append(samples, s)
+		}
+		if len(samples) == 20 {
+			break
+		}
+	}
+	layout := detectLayout(samples)
+	if layout == "" && len(samples) > 0 {
+		return cols.format, nil, fmt.Errorf("unrecognized timestamp format %q", samples[0])
+	}
+
+	b := &batch{}
+	for n, row := range rows {
+		line := header + n + 2
+		raw := stamp(row)
+		if raw == "" {
+			continue
+		}
+		ts, err := parseTime(raw, layout, loc)
+		if err != nil {
+			b.fail("line %d: invalid timestamp %q", line, raw)
+			continue
+		}
+
+		for k, col := range cols.glucose {
+			v := strings.TrimSpace(cell(row, col))
+			if v == "" {
+				continue
+			}
+			value, err := glucoseValue(v, cols.units[k])
+			if err != nil {
+				b.fail("line %d: %v", line, err)
+			} else {
+				b.readings = append(b.readings, store.GlucoseReading{Value: value, Timestamp: ts})
+			}
+			break
+		}
+		if v := cell(row, cols.insulin); v != "" {
+			if units, err := number(v); err != nil || units <= 0 {
+				b.fail("line %d: invalid insulin %q", line, v)
+			} else {
+				b.insulin = append(b.insulin, store.InsulinDose{Units: units, InsulinType: "rapid", Note: "Imported from " + cols.format, Timestamp: ts})
+			}
+		}
+		if v := cell(row, cols.carbs); v != "" {
+			if carbs, err := number(v); err == nil && carbs > 0 {
+				b.meals = append(b.meals, store.MealLog{Description: "Imported carbs from " + cols.format, Carbs: carbs, Timestamp: ts})
+			}
+		}
+	}
+	return cols.format, b, nil
+}
+
+// Nightscout entry or treatment; exports mix both
+type nightscoutRecord struct {
+	Type       string   `json:"type"`
+	SGV        *float64 `json:"sgv"`
+	MBG        *float64 `json:"mbg"`
+	Date       float64  `json:"date"`
+	DateString string   `json:"dateString"`
+	CreatedAt  string   `json:"created_at"`
+	EventType  string   `json:"eventType"`
+	Insulin    float64  `json:"insulin"`
+	Carbs      float64  `json:"carbs"`
+}
+
+// Helper function to parse Nightscout entries and treatments, as a bare array
+// or an object with entries and treatments arrays
+func parseNightscout(data []byte) (*batch, error) {
+	var records []nightscoutRecord
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to read Nightscout JSON: %w", err)
+		}
+	} else {
+		var export struct {
+			Entries    []nightscoutRecord `json:"entries"`
+			Treatments []nightscoutRecord `json:"treatments"`
+		}
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("failed to read Nightscout JSON: %w", err)
+		}
+		if len(export.Entries)+len(export.Treatments) == 0 {
+			return nil, fmt.Errorf("unrecognized format: JSON has no Nightscout entries or treatments")
+		}
+		records = append(export.Entries, export.Treatments...)
+	}
+
+	b := &batch{}
+	for i, rec := range records {
+		ts, ok := rec.time()
+		if !ok {
+			b.fail("record %d: missing or invalid date", i+1)
+			continue
+		}
+		switch {
+		case rec.SGV != nil:
+			b.readings = append(b.readings, store.GlucoseReading{Value: *rec.SGV, Timestamp: ts})
+		case rec.MBG != nil:
+			b.readings = append(b.readings, store.GlucoseReading{Value: *rec.MBG, Timestamp: ts})
+		}
+		if rec.Insulin > 0 {
+			b.insulin = append(b.insulin, store.InsulinDose{Units: rec.Insulin, InsulinType: "rapid", Note: "Nightscout: " + rec.EventType, Timestamp: ts})
+		}
+		if rec.Carbs > 0 {
+			b.meals = append(b.meals, store.MealLog{Description: "Nightscout: " + rec.EventType, Carbs: rec.Carbs, Timestamp: ts})
+		}
+	}
+	return b, nil
+}
+
+// Helper function to read a Nightscout record's time
+func (rec nightscoutRecord) time() (time.Time, bool) {
+	if rec.Date > 0 {
+		return time.UnixMilli(int64(rec.Date)), true
+	}
+	for _, s := range []string{rec.DateString, rec.CreatedAt} {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Helper function to pick the layout that parses the most samples, preferring
+// earlier layouts on a tie, so a few bad rows don't hide the file's format
+func detectLayout(samples []string) string {
+	best, bestCount := "", 0
+	for _, layout := range timeLayouts {
+		count := 0
+		for _, s := range samples {
+			if _, err := time.Parse(layout, s); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = layout, count
+		}
+	}
+	return best
+}
+
+// Helper function to parse a timestamp, in loc unless it carries a zone
+func parseTime(s, layout string, loc *time.Location) (time.Time, error) {
+	if layout == time.RFC3339 {
+		return time.Parse(layout, s)
+	}
+	return time.ParseInLocation(layout, s, loc)
+}
+
+// Helper function to read the glucose unit from a column header, if it has one
+func headerUnits(h string) string {
+	switch {
+	case strings.Contains(h, "mmol"):
+		return "mmol/L"
+	case strings.Contains(h, "mg/dl") || strings.Contains(h, "mg_dl"):
+		return "mg/dL"
+	}
+	return ""
+}
+
+// Helper function to read a glucose cell as mg/dL. Without a unit in the
+// header, small values are taken as mmol/L.
+func glucoseValue(s, units string) (float64, error) {
+	if v, ok := outOfRange[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := number(s)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid glucose %q", s)
+	}
+	if units == "mmol/L" || (units == "" && v <= mmolCutoff) {
+		v = math.Round(v * mgdlPerMmol)
+	}
+	return v, nil
+}
+
+// Helper function to parse a number written with a decimal point or comma
+func number(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+}
+
+// Helper function to read a cell, tolerating short rows
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
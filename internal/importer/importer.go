@@ -0,0 +1,262 @@
+// Package importer loads historical readings, insulin doses and carbs from a
+// zip of mixed device exports, skipping records that are already logged.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Job states
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Records closer than this in time, with matching values, are the same record
+const (
+	dupWindow  = 2 * time.Minute
+	dupGlucose = 2.0
+)
+
+// Largest file read from an archive, so a zip bomb can't exhaust memory
+const maxFileBytes = 50 << 20
+
+// Rows errors kept per file; the rest are counted
+const maxFileErrors = 20
+
+// Returned when an import job does not exist
+var ErrNotFound = errors.New("import not found")
+
+// File Result Struct
+type FileResult struct {
+	Name       string   `json:"name"`
+	Format     string   `json:"format,omitempty"`
+	Readings   int      `json:"readings"`
+	Insulin    int      `json:"insulin"`
+	Meals      int      `json:"meals"`
+	Duplicates int      `json:"duplicates" jsonschema:"description=Records skipped because they were already logged or appeared in an earlier file"`
+	Errors     []string `json:"errors,omitempty"`
+	Skipped    int      `json:"errors_not_shown,omitempty"`
+}
+
+// Job Struct
+type Job struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	Status      string       `json:"status"`
+	FilesTotal  int          `json:"files_total"`
+	FilesDone   int          `json:"files_done"`
+	CurrentFile string       `json:"current_file,omitempty"`
+	Files       []FileResult `json:"files"`
+	Error       string       `json:"error,omitempty"`
+	Created     time.Time    `json:"created"`
+	Finished    *time.Time   `json:"finished,omitempty"`
+}
+
+// In-memory import jobs, for progress polling
+type Tracker struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// Create an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{jobs: make(map[string]*Job)}
+}
+
+// Return a copy of a user's job
+func (t *Tracker) Get(id, userID string) (Job, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	job, ok := t.jobs[id]
+	if !ok || job.UserID != userID {
+		return Job{}, ErrNotFound
+	}
+	snapshot := *job
+	snapshot.Files = append([]FileResult{}, job.Files...)
+	return snapshot, nil
+}
+
+// Helper function to update a job under the lock
+func (t *Tracker) update(id string, fn func(*Job)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(t.jobs[id])
+}
+
+// Queue an import of data for a user and run it in the background.
+// data is a zip archive, or a single CSV or JSON export. Times without a
+// zone are read in loc.
+func (t *Tracker) Start(ctx context.Context, s *store.Stores, userID string, data []byte, loc *time.Location) (Job, error) {
+	files, err := unpack(data)
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{ID: store.NewID(), UserID: userID, Status: StatusQueued, FilesTotal: len(files), Files: []FileResult{}, Created: time.Now()}
+	t.mu.Lock()
+	t.jobs[job.ID] = job
+	t.mu.Unlock()
+
+	go t.run(context.WithoutCancel(ctx), s, job.ID, userID, files, loc)
+	return t.Get(job.ID, userID)
+}
+
+// Archive member
+type file struct {
+	name string
+	data []byte
+}
+
+// Helper function to list the files in an upload
+func unpack(data []byte) ([]file, error) {
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		return []file{{name: "upload", data: data}}, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+	var files []file
+	for _, f := range zr.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxFileBytes+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		if len(content) > maxFileBytes {
+			return nil, fmt.Errorf("%s is larger than %d MB", f.Name, maxFileBytes>>20)
+		}
+		files = append(files, file{name: f.Name, data: content})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("zip contains no files")
+	}
+	return files, nil
+}
+
+// Helper function to import each file in turn, recording progress
+func (t *Tracker) run(ctx context.Context, s *store.Stores, id, userID string, files []file, loc *time.Location) {
+	t.update(id, func(j *Job) { j.Status = StatusRunning })
+
+	for _, f := range files {
+		t.update(id, func(j *Job) { j.CurrentFile = f.name })
+
+		result := FileResult{Name: f.name}
+		format, b, err := parseFile(f.data, loc)
+		result.Format = format
+		if err != nil {
+			result.Errors = []string{err.Error()}
+		} else {
+			readings, insulin, meals, dups := filter(s, userID, b)
+			s.Readings.AddAll(ctx, readings)
+			s.Insulin.AddAll(ctx, insulin)
+			s.Meals.AddAll(ctx, meals)
+
+			result.Readings, result.Insulin, result.Meals, result.Duplicates = len(readings), len(insulin), len(meals), dups
+			result.Errors = b.errors
+			if len(result.Errors) > maxFileErrors {
+				result.Skipped = len(result.Errors) - maxFileErrors
+				result.Errors = result.Errors[:maxFileErrors]
+			}
+		}
+
+		t.update(id, func(j *Job) {
+			j.Files = append(j.Files, result)
+			j.FilesDone++
+		})
+	}
+
+	t.update(id, func(j *Job) {
+		now := time.Now()
+		j.Status, j.CurrentFile, j.Finished = StatusDone, "", &now
+		imported, failed := 0, 0
+		for _, f := range j.Files {
+			imported += f.Readings + f.Insulin + f.Meals
+			if f.Format == "" {
+				failed++
+			}
+		}
+		if failed == len(j.Files) {
+			j.Status, j.Error = StatusFailed, "no file was in a recognized format"
+		}
+		log.Printf("Import %s for %s %s: %d records from %d file(s)", j.ID, userID, j.Status, imported, len(j.Files))
+	})
+}
+
+// Helper function to drop records that match one already logged, including
+// those from earlier files in this import, or an earlier row of the same file.
+// Returns the new records, owned by the user, and the number dropped.
+func filter(s *store.Stores, userID string, b *batch) ([]store.GlucoseReading, []store.InsulinDose, []store.MealLog, int) {
+	dups := 0
+	readings := dedupe(s.Readings, userID, b.readings, &dups, func(r *store.GlucoseReading) { r.UserID = userID },
+		func(a, b store.GlucoseReading) bool { return math.Abs(a.Value-b.Value) <= dupGlucose })
+	insulin := dedupe(s.Insulin, userID, b.insulin, &dups, func(d *store.InsulinDose) { d.UserID = userID },
+		func(a, b store.InsulinDose) bool { return a.Units == b.Units })
+	meals := dedupe(s.Meals, userID, b.meals, &dups, func(m *store.MealLog) { m.UserID = userID },
+		func(a, b store.MealLog) bool { return a.Carbs == b.Carbs })
+	return readings, insulin, meals, dups
+}
+
+// Helper function to dedupe one kind of record against a log
+func dedupe[T store.Record](logs *store.LogStore[T], userID string, incoming []T, dups *int, own func(*T), same func(a, b T) bool) []T {
+	if len(incoming) == 0 {
+		return nil
+	}
+	incoming = append([]T{}, incoming...)
+	sortByTime(incoming)
+	existing := logs.Range(userID, incoming[0].Time().Add(-dupWindow), incoming[len(incoming)-1].Time().Add(dupWindow+time.Second))
+
+	var out []T
+	for _, v := range incoming {
+		own(&v)
+		match := func(o T) bool { return same(o, v) }
+		if matchesNear(existing, v.Time(), match) || matchesNear(out, v.Time(), match) {
+			*dups++
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Helper function to sort records by time
+func sortByTime[T store.Record](list []T) {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time().Before(list[j].Time()) })
+}
+
+// Helper function to check the time-sorted records within dupWindow of t
+func matchesNear[T store.Record](list []T, t time.Time, same func(T) bool) bool {
+	i := sort.Search(len(list), func(i int) bool { return !list[i].Time().Before(t.Add(-dupWindow)) })
+	for ; i < len(list) && !list[i].Time().After(t.Add(dupWindow)); i++ {
+		if same(list[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+// Package insulinstorage checks whether insulin is still safe to use after
+// it has been opened, left out of the fridge, or exposed to heat or cold.
+package insulinstorage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdicts, mildest first
+const (
+	Keep        = "keep"
+	ReplaceSoon = "replace_soon"
+	Discard     = "discard"
+)
+
+// Storage limits shared by every insulin product
+const (
+	FridgeMinC = 2.0
+	FridgeMaxC = 8.0
+	RoomMaxC   = 30.0
+)
+
+// Sources cited for the limits
+const (
+	sourceLabel     = "Manufacturer prescribing information (storage section)"
+	sourceFDA       = "FDA: Information Regarding Insulin Storage and Switching Between Products in an Emergency"
+	sourceTransport = "TSA and IATA guidance on carrying medication in cabin baggage"
+)
+
+// Product Struct
+//
+// An insulin and how long it lasts once out of the fridge, by form.
+type Product struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind" jsonschema:"description=rapid, short, intermediate, premix or long"`
+	Cloudy   bool   `json:"cloudy" jsonschema:"description=True when the insulin is normally cloudy after mixing"`
+	VialDays int    `json:"vial_days" jsonschema:"description=Days a vial lasts once opened or out of the fridge"`
+	PenDays  int    `json:"pen_days" jsonschema:"description=Days a pen or cartridge lasts once opened or out of the fridge"`
+	Matched  bool   `json:"matched" jsonschema:"description=False when the insulin was not recognised and generic limits are used"`
+	brands   []string
+}
+
+// Room-temperature limits from each product's prescribing information.
+// Where brands in a group differ, the shortest limit is used. More specific
+// products come first so "toujeo" matches before "glargine".
+var products = []Product{
+	{Name: "insulin glargine U-300", Kind: "long", PenDays: 56, VialDays: 56, brands: []string{"toujeo", "u-300", "u300"}},
+	{Name: "insulin glargine", Kind: "long", PenDays: 28, VialDays: 28, brands: []string{"glargine", "lantus", "basaglar", "semglee", "abasaglar"}},
+	{Name: "insulin detemir", Kind: "long", PenDays: 42, VialDays: 42, brands: []string{"detemir", "levemir"}},
+	{Name: "insulin degludec", Kind: "long", PenDays: 56, VialDays: 56, brands: []string{"degludec", "tresiba"}},
+	{Name: "premixed insulin", Kind: "premix", Cloudy: true, PenDays: 10, VialDays: 28, brands: []string{"70/30", "75/25", "50/50", "mix", "mixtard"}},
+	{Name: "insulin lispro", Kind: "rapid", PenDays: 28, VialDays: 28, brands: []string{"lispro", "humalog", "admelog", "lyumjev"}},
+	{Name: "insulin aspart", Kind: "rapid", PenDays: 28, VialDays: 28, brands: []string{"aspart", "novolog", "novorapid", "fiasp"}},
+	{Name: "insulin glulisine", Kind: "rapid", PenDays: 28, VialDays: 28, brands: []string{"glulisine", "apidra"}},
+	{Name: "NPH insulin", Kind: "intermediate", Cloudy: true, PenDays: 14, VialDays: 31, brands: []string{"nph", "isophane", "humulin n", "novolin n", "insulatard"}},
+	{Name: "regular insulin", Kind: "short", PenDays: 28, VialDays: 31, brands: []string{"regular", "humulin r", "novolin r", "actrapid"}},
+}
+
+// Limits used when the insulin is not recognised
+var genericProduct = Product{Name: "insulin", Kind: "unknown", PenDays: 28, VialDays: 28}
+
+// Find a product by brand or generic name
+func Lookup(name string) Product {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if lower != "" {
+		for _, p := range products {
+			for _, brand := range p.brands {
+				if strings.Contains(lower, brand) {
+					p.Matched = true
+					return p
+				}
+			}
+		}
+	}
+	return genericProduct
+}
+
+// Days the product lasts at room temperature in the given form
+func (p Product) RoomDays(form string) int {
+	if form == "vial" {
+		return p.VialDays
+	}
+	return p.PenDays
+}
+
+// Exposure Struct
+//
+// What happened to the insulin. Temperatures are in Celsius.
+type Exposure struct {
+	Insulin       string
+	Form          string
+	Opened        bool
+	DaysOut       int
+	TempC         *float64
+	Hours         float64
+	Frozen        bool
+	Appearance    string
+	Traveling     bool
+	WillBeOutDays int
+}
+
+// Limit Struct
+type Limit struct {
+	Rule   string `json:"rule"`
+	Limit  string `json:"limit"`
+	Source string `json:"source"`
+}
+
+// Assessment Struct
+type Assessment struct {
+	Verdict    string   `json:"verdict" jsonschema:"description=keep, replace_soon or discard"`
+	Product    Product  `json:"product"`
+	Form       string   `json:"form"`
+	DaysLeft   *int     `json:"days_left,omitempty" jsonschema:"description=Days left at room temperature, when it is out of the fridge"`
+	Reasons    []string `json:"reasons"`
+	Limits     []Limit  `json:"limits"`
+	TravelTips []string `json:"travel_tips,omitempty"`
+}
+
+// Abnormal appearances, and whether they apply to cloudy insulin too
+var appearances = map[string]bool{
+	"clumps":     true,
+	"crystals":   true,
+	"frosted":    true,
+	"discolored": true,
+	"cloudy":     false,
+}
+
+// Travel rules, the same for every insulin
+var travelTips = []string{
+	"Carry insulin in your hand luggage. The aircraft hold can freeze it.",
+	"Use an insulated bag or cooling wallet. Do not put insulin directly on ice or gel packs.",
+	"Never leave insulin in a parked car, a glovebox or in direct sun.",
+	"Bring spare insulin and supplies, split between bags, with a letter or prescription from your doctor.",
+	"Once out of the fridge, the room-temperature day limit applies even if you refrigerate it again.",
+}
+
+// Assess an exposure and return keep or discard guidance with the limits used.
+// The strictest rule that applies sets the verdict.
+func Assess(e Exposure) Assessment {
+	form := strings.ToLower(strings.TrimSpace(e.Form))
+	if form != "vial" {
+		form = "pen"
+	}
+	p := Lookup(e.Insulin)
+	days := p.RoomDays(form)
+
+	a := Assessment{Verdict: Keep, Product: p, Form: form, Reasons: []string{}}
+	a.Limits = []Limit{
+		{Rule: "Unopened, in the fridge", Limit: fmt.Sprintf("%.0f-%.0f°C (%.0f-%.0f°F) until the expiry date", FridgeMinC, FridgeMaxC, toF(FridgeMinC), toF(FridgeMaxC)), Source: sourceLabel},
+		{Rule: fmt.Sprintf("Opened or out of the fridge (%s)", form), Limit: fmt.Sprintf("up to %d days below %.0f°C (%.0f°F)", days, RoomMaxC, toF(RoomMaxC)), Source: sourceLabel},
+		{Rule: "Frozen", Limit: "never use, even after thawing", Source: sourceLabel},
+		{Rule: "Above room temperature", Limit: fmt.Sprintf("above %.0f°C (%.0f°F) insulin loses potency; use only until it can be replaced", RoomMaxC, toF(RoomMaxC)), Source: sourceFDA},
+	}
+	if !p.Matched {
+		a.Reasons = append(a.Reasons, fmt.Sprintf("Insulin %q was not recognised, so the common %d-day limit is used. Check the leaflet for your insulin.", e.Insulin, days))
+	}
+
+	raise := func(verdict, reason string) {
+		if rank(verdict) > rank(a.Verdict) {
+			a.Verdict = verdict
+		}
+		a.Reasons = append(a.Reasons, reason)
+	}
+
+	if e.Frozen || (e.TempC != nil && *e.TempC <= 0) {
+		raise(Discard, "It has been frozen. Frozen insulin must be thrown away, even once thawed.")
+	}
+
+	look := strings.ToLower(strings.TrimSpace(e.Appearance))
+	if always, abnormal := appearances[look]; abnormal && (always || !p.Cloudy) {
+		raise(Discard, fmt.Sprintf("It looks %s. Insulin that has changed appearance must not be used.", look))
+	}
+
+	if e.TempC != nil && *e.TempC > RoomMaxC {
+		reason := fmt.Sprintf("It was kept at %.0f°C (%.0f°F), above the %.0f°C (%.0f°F) limit", *e.TempC, toF(*e.TempC), RoomMaxC, toF(RoomMaxC))
+		if e.Hours > 0 {
+			reason += fmt.Sprintf(" for about %.0f hour(s)", e.Hours)
+		}
+		raise(ReplaceSoon, reason+". It may work less well: replace it as soon as you can and check your blood sugar more often until then.")
+	}
+
+	if e.Opened || e.DaysOut > 0 {
+		left := days - e.DaysOut
+		a.DaysLeft = &left
+		switch {
+		case left < 0:
+			raise(Discard, fmt.Sprintf("It has been open or out of the fridge for %d days, past the %d-day limit.", e.DaysOut, days))
+		case left == 0:
+			raise(ReplaceSoon, fmt.Sprintf("Today is the last day of its %d-day limit.", days))
+		case e.WillBeOutDays > left:
+			raise(ReplaceSoon, fmt.Sprintf("It has %d day(s) left, fewer than the %d days you need it for. Take a new one.", left, e.WillBeOutDays))
+		default:
+			a.Reasons = append(a.Reasons, fmt.Sprintf("It has %d of %d days left at room temperature.", left, days))
+		}
+	} else if e.TempC != nil && *e.TempC > FridgeMaxC && *e.TempC <= RoomMaxC {
+		a.Reasons = append(a.Reasons, fmt.Sprintf("Being out of the fridge starts its %d-day room-temperature limit. Count from the day it came out.", days))
+	}
+
+	if e.Traveling {
+		a.TravelTips = travelTips
+		a.Limits = append(a.Limits, Limit{Rule: "Air travel", Limit: "carry insulin in cabin baggage, never in checked baggage", Source: sourceTransport})
+	}
+	if len(a.Reasons) == 0 {
+		a.Reasons = append(a.Reasons, "Stored within its limits.")
+	}
+	return a
+}
+
+// Helper function to render an assessment as prompt lines
+func (a Assessment) PromptSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Insulin: %s (%s, %s)\nVerdict: %s\n", a.Product.Name, a.Product.Kind, a.Form, a.Verdict)
+	for _, r := range a.Reasons {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+	for _, l := range a.Limits {
+		fmt.Fprintf(&b, "Limit: %s: %s (%s)\n", l.Rule, l.Limit, l.Source)
+	}
+	return b.String()
+}
+
+// Convert Fahrenheit to Celsius
+func ToC(f float64) float64 { return (f - 32) * 5 / 9 }
+
+func toF(c float64) float64 { return c*9/5 + 32 }
+
+func rank(verdict string) int {
+	switch verdict {
+	case Discard:
+		return 2
+	case ReplaceSoon:
+		return 1
+	default:
+		return 0
+	}
+}
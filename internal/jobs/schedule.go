@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule Struct
+//
+// Parsed from "every 15m", "daily 02:30", "weekly mon 07:00" or "monthly 1 07:00".
+// Times are local. Monthly days run from 1 to 28, so every month has them.
+type Schedule struct {
+	spec    string
+	every   time.Duration
+	weekday time.Weekday
+	weekly  bool
+	day     int
+	hour    int
+	minute  int
+}
+
+// Weekday names accepted in weekly schedules
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse a schedule spec
+func ParseSchedule(spec string) (Schedule, error) {
+	s := Schedule{spec: spec}
+	fields := strings.Fields(strings.ToLower(spec))
+	switch {
+	case len(fields) == 2 && fields[0] == "every":
+		d, err := time.ParseDuration(fields[1])
+		if err != nil || d < time.Minute {
+			return Schedule{}, fmt.Errorf("invalid interval %q: must be at least 1m", fields[1])
+		}
+		s.every = d
+		return s, nil
+	case len(fields) == 2 && fields[0] == "daily":
+		return s, s.parseClock(fields[1])
+	case len(fields) == 3 && fields[0] == "weekly":
+		day, ok := weekdays[fields[1][:min(len(fields[1]), 3)]]
+		if !ok {
+			return Schedule{}, fmt.Errorf("invalid weekday %q", fields[1])
+		}
+		s.weekly, s.weekday = true, day
+		return s, s.parseClock(fields[2])
+	case len(fields) == 3 && fields[0] == "monthly":
+		day, err := strconv.Atoi(fields[1])
+		if err != nil || day < 1 || day > 28 {
+			return Schedule{}, fmt.Errorf("invalid day of month %q: use 1 to 28", fields[1])
+		}
+		s.day = day
+		return s, s.parseClock(fields[2])
+	}
+	return Schedule{}, fmt.Errorf("invalid schedule %q: use \"every 15m\", \"daily 02:30\", \"weekly mon 07:00\" or \"monthly 1 07:00\"", spec)
+}
+
+// Helper function to parse an HH:MM time of day
+func (s *Schedule) parseClock(clock string) error {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return fmt.Errorf("invalid time %q: use HH:MM", clock)
+	}
+	s.hour, s.minute = t.Hour(), t.Minute()
+	return nil
+}
+
+// Return the first run time strictly after t
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(s.every).Add(s.every)
+	}
+
+	if s.day > 0 {
+		next := time.Date(t.Year(), t.Month(), s.day, s.hour, s.minute, 0, 0, t.Location())
+		if !next.After(t) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	}
+
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, t.Location())
+	if s.weekly {
+		next = next.AddDate(0, 0, int(s.weekday-next.Weekday()+7)%7)
+	}
+	for !next.After(t) {
+		if s.weekly {
+			next = next.AddDate(0, 0, 7)
+		} else {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+// Return the latest run time at or before t
+func (s Schedule) Previous(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(s.every)
+	}
+
+	if s.day > 0 {
+		prev := time.Date(t.Year(), t.Month(), s.day, s.hour, s.minute, 0, 0, t.Location())
+		if prev.After(t) {
+			prev = prev.AddDate(0, -1, 0)
+		}
+		return prev
+	}
+
+	prev := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, t.Location())
+	if s.weekly {
+		prev = prev.AddDate(0, 0, -(int(prev.Weekday()-s.weekday+7) % 7))
+	}
+	for prev.After(t) {
+		if s.weekly {
+			prev = prev.AddDate(0, 0, -7)
+		} else {
+			prev = prev.AddDate(0, 0, -1)
+		}
+	}
+	return prev
+}
+
+// Return the schedule as a cron expression, for Cloud Scheduler and the like.
+// Intervals that don't divide an hour or a day evenly have none.
+func (s Schedule) Cron() string {
+	switch {
+	case s.every > 0:
+		minutes, hours := int(s.every/time.Minute), int(s.every/time.Hour)
+		switch {
+		case s.every%time.Minute != 0:
+		case minutes < 60 && 60%minutes == 0:
+			return fmt.Sprintf("*/%d * * * *", minutes)
+		case s.every%time.Hour == 0 && hours <= 24 && 24%hours == 0:
+			return fmt.Sprintf("0 */%d * * *", hours)
+		}
+		return ""
+	case s.day > 0:
+		return fmt.Sprintf("%d %d %d * *", s.minute, s.hour, s.day)
+	case s.weekly:
+		return fmt.Sprintf("%d %d * * %d", s.minute, s.hour, s.weekday)
+	}
+	return fmt.Sprintf("%d %d * * *", s.minute, s.hour)
+}
+
+func (s Schedule) String() string { return s.spec }
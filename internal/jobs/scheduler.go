@@ -0,0 +1,249 @@
+// Package jobs runs scheduled background work in-process and keeps each job's run history.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/requestid"
+)
+
+// How often the scheduler checks for due jobs
+const tickInterval = 30 * time.Second
+
+// Job Status Struct
+type Status struct {
+	Name        string    `json:"name"`
+	Schedule    string    `json:"schedule"`
+	Cron        string    `json:"cron,omitempty" jsonschema:"description=Schedule as a cron expression, for Cloud Scheduler"`
+	Running     bool      `json:"running"`
+	LastRun     time.Time `json:"last_run,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	Duration    string    `json:"last_duration,omitempty"`
+	Runs        int       `json:"runs"`
+	Failures    int       `json:"failures"`
+	Skipped     int       `json:"skipped" jsonschema:"description=Runs made by another replica"`
+	NextRun     time.Time `json:"next_run"`
+}
+
+// A job registered with the scheduler
+type job struct {
+	schedule Schedule
+	run      func(ctx context.Context) error
+}
+
+// In-process scheduler, optionally persisting job status to a JSON file.
+// Each scheduled run is claimed in the shared store, so with several
+// replicas only one of them executes it.
+type Scheduler struct {
+	mu     sync.Mutex
+	path   string
+	claims kv.Store
+	jobs   map[string]job
+	status map[string]*Status
+}
+
+// Create a scheduler, loading previous job status from path. An empty path keeps status in memory only.
+func New(path string, claims kv.Store) (*Scheduler, error) {
+	s := &Scheduler{path: path, claims: claims, jobs: make(map[string]job), status: make(map[string]*Status)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.status); err != nil {
+		return nil, fmt.Errorf("failed to decode job state: %w", err)
+	}
+	return s, nil
+}
+
+// Register a job. A run missed while the server was down is made once at startup.
+func (s *Scheduler) Add(name, spec string, run func(ctx context.Context) error) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = job{schedule: schedule, run: run}
+
+	st, ok := s.status[name]
+	if !ok {
+		st = &Status{Name: name}
+		s.status[name] = st
+	}
+	st.Schedule = schedule.String()
+	st.Cron = schedule.Cron()
+	st.Running = false
+	if st.LastRun.IsZero() {
+		st.NextRun = schedule.Next(time.Now())
+	} else {
+		st.NextRun = schedule.Next(st.LastRun)
+	}
+	return nil
+}
+
+// Run due jobs until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		s.runDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Helper function to start every job whose next run has passed
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, j := range s.jobs {
+		st := s.status[name]
+		if st.Running || st.NextRun.After(now) {
+			continue
+		}
+		st.Running = true
+		go s.execute(ctx, name, j, st.NextRun)
+	}
+}
+
+// Errors from triggering a job
+var (
+	ErrUnknownJob = errors.New("no such job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Run a job now and wait for it, for schedulers outside the process such as
+// Cloud Scheduler. The run is claimed for the latest time the job was due, so
+// a trigger delivered twice, or to two replicas, runs it once; a failed run
+// gives its claim back so a retry can make it. Reports whether this call ran
+// the job, and the job's error if it failed.
+func (s *Scheduler) Run(ctx context.Context, name string) (Status, bool, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return Status{}, false, ErrUnknownJob
+	}
+	st := s.status[name]
+	if st.Running {
+		status := *st
+		s.mu.Unlock()
+		return status, false, ErrJobRunning
+	}
+	st.Running = true
+	s.mu.Unlock()
+
+	due := j.schedule.Previous(time.Now())
+	ran, err := s.execute(ctx, name, j, due)
+	if err != nil {
+		if rerr := kv.Release(ctx, s.claims, claimKey(name, due)); rerr != nil {
+			log.Printf("Job %s: %v", name, rerr)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.status[name], ran, err
+}
+
+// Helper function to name the claim for a job's run due at a time
+func claimKey(name string, due time.Time) string {
+	return fmt.Sprintf("job:%s:%d", name, due.Unix())
+}
+
+// Helper function to run a job and record the outcome. Reports whether it ran
+// here rather than on another replica, and its error.
+func (s *Scheduler) execute(ctx context.Context, name string, j job, due time.Time) (bool, error) {
+	ctx = requestid.With(ctx, "job-"+name+"-"+requestid.New())
+
+	// Claim this run until the next one is due. If the store is unreachable,
+	// run anyway: a duplicate reminder is better than a missed one.
+	period := max(j.schedule.Next(due).Sub(due), time.Minute)
+	won, err := kv.Claim(ctx, s.claims, claimKey(name, due), period)
+	if err != nil {
+		log.Printf("Job %s: %v; running without a claim", name, err)
+		won = true
+	}
+	if !won {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		st := s.status[name]
+		st.Running = false
+		st.Skipped++
+		st.NextRun = j.schedule.Next(time.Now())
+		return false, nil
+	}
+
+	start := time.Now()
+	runErr := j.run(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.status[name]
+	st.Running = false
+	st.LastRun = start
+	st.Duration = time.Since(start).Round(time.Millisecond).String()
+	st.Runs++
+	st.NextRun = j.schedule.Next(time.Now())
+	if runErr != nil {
+		st.Failures++
+		st.LastError = runErr.Error()
+		log.Printf("Job %s failed: %v", name, runErr)
+	} else {
+		st.LastSuccess = start
+		st.LastError = ""
+	}
+	if s.path != "" {
+		if err := s.save(); err != nil {
+			log.Printf("Failed to save job state: %v", err)
+		}
+	}
+	return true, runErr
+}
+
+// Return the status of every registered job, sorted by name
+func (s *Scheduler) List() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.jobs))
+	for name := range s.jobs {
+		out = append(out, *s.status[name])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Helper function to write the job state atomically
+func (s *Scheduler) save() error {
+	data, err := json.Marshal(s.status)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
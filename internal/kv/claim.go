@@ -0,0 +1,35 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Name of this replica, recorded as the owner of claims it wins
+var replica = func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}()
+
+// Claim a key for ttl, reporting whether this replica won it.
+// Give each alert or job run its own key so it happens once across the fleet.
+func Claim(ctx context.Context, s Store, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.SetNX(ctx, "claim:"+key, []byte(replica), ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// Give up a claim, so the work can be tried again
+func Release(ctx context.Context, s Store, key string) error {
+	if err := s.Delete(ctx, "claim:"+key); err != nil {
+		return fmt.Errorf("failed to release %s: %w", key, err)
+	}
+	return nil
+}
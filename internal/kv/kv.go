@@ -0,0 +1,35 @@
+// Package kv is a small key-value store for state shared between server replicas.
+// It is backed by Redis when configured, and by process memory otherwise.
+package kv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Returned by Get when a key does not exist
+var ErrNotFound = errors.New("key not found")
+
+// Store is the shared state used for sessions, response caching and rate-limit counters
+type Store interface {
+	// Return the value for a key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set a value, expiring after ttl (0 keeps it forever)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Increment a counter, starting its ttl when it is created
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Set a value only if the key does not exist, reporting whether it was set
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Remove a key
+	Delete(ctx context.Context, key string) error
+}
+
+// Open the store for a Redis URL (redis://[:password@]host:port[/db], or rediss:// for TLS).
+// An empty URL returns an in-memory store for a single replica.
+func Open(rawURL string) (Store, error) {
+	if rawURL == "" {
+		return NewMemory(), nil
+	}
+	return NewRedis(rawURL)
+}
@@ -0,0 +1,121 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How often writes sweep out expired entries, so keys nobody reads again
+// (per-minute rate-limit counters, cache entries) are still freed
+const memorySweepInterval = time.Minute
+
+// In-process store, for running a single replica
+type Memory struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Create an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]memoryEntry)}
+}
+
+// Helper function to drop every expired entry, at most once per sweep interval.
+// Called on writes; callers hold the lock.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, e := range m.entries {
+		if !e.expires.IsZero() && !now.Before(e.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Helper function to read a live entry, dropping it if expired. Callers hold the lock.
+func (m *Memory) live(key string, now time.Time) (memoryEntry, bool) {
+	e, ok := m.entries[key]
+	if ok && !e.expires.IsZero() && !now.Before(e.expires) {
+		delete(m.entries, key)
+		return memoryEntry{}, false
+	}
+	return e, ok
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.live(key, time.Now())
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return e.value, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.sweep(now)
+	e := memoryEntry{value: value}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	m.entries[key] = e
+	return nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.sweep(now)
+	e, ok := m.live(key, now)
+	n := int64(1)
+	if ok {
+		v, err := strconv.ParseInt(string(e.value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to increment %s: value is not an integer", key)
+		}
+		n = v + 1
+	} else if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	e.value = []byte(strconv.FormatInt(n, 10))
+	m.entries[key] = e
+	return n, nil
+}
+
+func (m *Memory) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.sweep(now)
+	if _, ok := m.live(key, now); ok {
+		return false, nil
+	}
+	e := memoryEntry{value: value}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	m.entries[key] = e
+	return true, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
@@ -0,0 +1,251 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Most idle connections kept open to Redis
+const maxIdleConns = 8
+
+// Redis-backed store speaking RESP over a small connection pool
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	tls      bool
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+// A single Redis connection
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Create a Redis store from a redis:// or rediss:// URL, checking the connection
+func NewRedis(rawURL string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("invalid REDIS_URL %q: use redis://[:password@]host:port[/db]", rawURL)
+	}
+
+	rd := &Redis{
+		addr:    u.Host,
+		tls:     u.Scheme == "rediss",
+		timeout: 5 * time.Second,
+		idle:    make(chan *redisConn, maxIdleConns),
+	}
+	if !strings.Contains(rd.addr, ":") {
+		rd.addr += ":6379"
+	}
+	if pw, ok := u.User.Password(); ok {
+		rd.password = pw
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if rd.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL database %q", db)
+		}
+	}
+
+	if _, err := rd.do(context.Background(), "PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return rd, nil
+}
+
+func (rd *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := rd.do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNotFound
+	}
+	return reply.([]byte), nil
+}
+
+func (rd *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := rd.do(ctx, args...)
+	return err
+}
+
+func (rd *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	reply, err := rd.do(ctx, "INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	if n == 1 && ttl > 0 {
+		if _, err := rd.do(ctx, "PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (rd *Redis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	args := []string{"SET", key, string(value), "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	reply, err := rd.do(ctx, args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+func (rd *Redis) Delete(ctx context.Context, key string) error {
+	_, err := rd.do(ctx, "DEL", key)
+	return err
+}
+
+// Helper function to run one command, returning nil, int64, string or []byte
+func (rd *Redis) do(ctx context.Context, args ...string) (any, error) {
+	c, err := rd.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(rd.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.SetDeadline(deadline)
+
+	reply, err := c.command(args...)
+	if err != nil {
+		var re redisError
+		if errors.As(err, &re) {
+			rd.release(c)
+			return nil, fmt.Errorf("redis %s: %w", args[0], err)
+		}
+		c.Close()
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	rd.release(c)
+	return reply, nil
+}
+
+// Helper function to take an idle connection or dial a new one
+func (rd *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-rd.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: rd.timeout}
+	var nc net.Conn
+	var err error
+	if rd.tls {
+		host, _, _ := net.SplitHostPort(rd.addr)
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", rd.addr)
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", rd.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	c.SetDeadline(time.Now().Add(rd.timeout))
+	if rd.password != "" {
+		if _, err := c.command("AUTH", rd.password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if rd.db != 0 {
+		if _, err := c.command("SELECT", strconv.Itoa(rd.db)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Helper function to return a healthy connection to the pool
+func (rd *Redis) release(c *redisConn) {
+	select {
+	case rd.idle <- c:
+	default:
+		c.Close()
+	}
+}
+
+// Error reply from the Redis server
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// Helper function to write a command and read its reply
+func (c *redisConn) command(args ...string) (any, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.reply()
+}
+
+// Helper function to read one RESP reply
+func (c *redisConn) reply() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.reply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", line)
+}
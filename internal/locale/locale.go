@@ -0,0 +1,177 @@
+// Package locale formats glucose values, numbers, dates and times for a user's locale.
+package locale
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Glucose units
+const (
+	MgDL  = "mg/dL"
+	MmolL = "mmol/L"
+)
+
+// mg/dL per mmol/L of glucose
+const mgdlPerMmol = 18.0182
+
+// Prefs Struct
+type Prefs struct {
+	Locale   string         `json:"locale"`
+	Units    string         `json:"units"`
+	Clock24  bool           `json:"clock_24h"`
+	Decimal  string         `json:"decimal_separator"`
+	Location *time.Location `json:"-"`
+}
+
+// Regions that report glucose in mmol/L; everywhere else uses mg/dL
+var mmolRegions = map[string]bool{
+	"gb": true, "ie": true, "ca": true, "au": true, "nz": true, "za": true, "ke": true, "tz": true, "ug": true,
+	"nl": true, "se": true, "no": true, "dk": true, "fi": true, "is": true, "ru": true, "cn": true, "hk": true,
+	"my": true, "sg": true, "cz": true, "sk": true, "ee": true, "lv": true, "lt": true, "ua": true, "kz": true,
+}
+
+// Regions that use a 12-hour clock
+var clock12Regions = map[string]bool{
+	"us": true, "ca": true, "au": true, "nz": true, "in": true, "pk": true, "bd": true, "ph": true, "eg": true, "sa": true, "mx": true, "co": true,
+}
+
+// Languages written with a decimal comma
+var commaLanguages = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "pt": true, "nl": true, "sv": true, "no": true, "nb": true, "da": true,
+	"fi": true, "ru": true, "pl": true, "cs": true, "sk": true, "tr": true, "id": true, "uk": true,
+}
+
+// Return the default preferences for a locale such as en-US, en-GB or de-DE
+func Defaults(tag string) Prefs {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		tag = "en-US"
+	}
+	lang, region, _ := strings.Cut(strings.ToLower(tag), "-")
+
+	p := Prefs{Locale: tag, Units: MgDL, Clock24: !clock12Regions[region], Decimal: ".", Location: time.Local}
+	if mmolRegions[region] {
+		p.Units = MmolL
+	}
+	if region == "" && lang == "en" {
+		p.Clock24 = false
+	}
+	if commaLanguages[lang] {
+		p.Decimal = ","
+	}
+	return p
+}
+
+// Format a glucose value given in mg/dL in the preferred unit
+func (p Prefs) Glucose(mgdl float64) string {
+	if p.Units == MmolL {
+		return p.Number(mgdl/mgdlPerMmol, 1) + " " + MmolL
+	}
+	return p.Number(mgdl, 0) + " " + MgDL
+}
+
+// Format a number with the preferred decimal separator
+func (p Prefs) Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if p.Decimal != "" && p.Decimal != "." {
+		s = strings.Replace(s, ".", p.Decimal, 1)
+	}
+	return s
+}
+
+// Format a time of day
+func (p Prefs) Clock(t time.Time) string {
+	t = p.in(t)
+	if p.Clock24 {
+		return t.Format("15:04")
+	}
+	return t.Format("3:04 PM")
+}
+
+// Format a date, day first except in US English
+func (p Prefs) Date(t time.Time) string {
+	t = p.in(t)
+	if strings.EqualFold(p.Locale, "en-US") || strings.EqualFold(p.Locale, "en") {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("2 Jan 2006")
+}
+
+// Format a date and time
+func (p Prefs) DateTime(t time.Time) string {
+	return p.Date(t) + " " + p.Clock(t)
+}
+
+// Helper function to convert to the preferred time zone
+func (p Prefs) in(t time.Time) time.Time {
+	if p.Location != nil {
+		return t.In(p.Location)
+	}
+	return t
+}
+
+// Instructions telling the model how to write values for this user
+func (p Prefs) PromptNote() string {
+	unit := "mg/dL"
+	if p.Units == MmolL {
+		unit = "mmol/L (mg/dL divided by 18, one decimal place)"
+	}
+	clock := "12-hour times (e.g. 2:30 PM)"
+	if p.Clock24 {
+		clock = "24-hour times (e.g. 14:30)"
+	}
+	return fmt.Sprintf("Write glucose values in %s, use %s, and write decimals with %q. Data above is in mg/dL.", unit, clock, p.Decimal)
+}
+
+// Context key for preferences
+type key struct{}
+
+// Return a context carrying the preferences
+func With(ctx context.Context, p Prefs) context.Context {
+	return context.WithValue(ctx, key{}, p)
+}
+
+// Return the preferences from a context, or US defaults
+func From(ctx context.Context) Prefs {
+	if p, ok := ctx.Value(key{}).(Prefs); ok {
+		return p
+	}
+	return Defaults("")
+}
+
+// Resolve a user's saved preferences against the defaults for their locale
+func Resolve(saved store.Preferences) (Prefs, error) {
+	p := Defaults(saved.Locale)
+	switch strings.ToLower(saved.Units) {
+	case "":
+	case "mg/dl", "mgdl":
+		p.Units = MgDL
+	case "mmol/l", "mmol":
+		p.Units = MmolL
+	default:
+		return p, fmt.Errorf("units must be mg/dL or mmol/L")
+	}
+	switch saved.Clock {
+	case "":
+	case "12h":
+		p.Clock24 = false
+	case "24h":
+		p.Clock24 = true
+	default:
+		return p, fmt.Errorf("clock must be 12h or 24h")
+	}
+	if saved.Timezone != "" {
+		loc, err := time.LoadLocation(saved.Timezone)
+		if err != nil {
+			return p, fmt.Errorf("unknown timezone %q", saved.Timezone)
+		}
+		p.Location = loc
+	}
+	return p, nil
+}
@@ -0,0 +1,73 @@
+package nutrition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+)
+
+// Lookup cache, optionally persisted to a JSON file so restarts don't refetch
+type Cache struct {
+	mu    sync.RWMutex
+	path  string
+	foods map[string]Food
+}
+
+// Create a cache, loading any existing entries from path. An empty path keeps the cache in memory only.
+func NewCache(path string) (*Cache, error) {
+	c := &Cache{path: path, foods: make(map[string]Food)}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nutrition cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.foods); err != nil {
+		return nil, fmt.Errorf("failed to decode nutrition cache: %w", err)
+	}
+	return c, nil
+}
+
+// Return a cached food
+func (c *Cache) Get(key string) (Food, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	food, ok := c.foods[key]
+	return food, ok
+}
+
+// Cache a food, writing the cache file when one is configured
+func (c *Cache) Put(key string, food Food) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.foods[key] = food
+	if c.path == "" {
+		return
+	}
+	if err := c.save(); err != nil {
+		log.Printf("Failed to save nutrition cache: %v", err)
+	}
+}
+
+// Helper function to write the cache atomically
+func (c *Cache) save() error {
+	data, err := json.Marshal(c.foods)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
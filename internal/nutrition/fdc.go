@@ -0,0 +1,196 @@
+// Package nutrition looks up per-food nutrient values from USDA FoodData Central.
+package nutrition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FoodData Central search endpoint
+const fdcSearchURL = "https://api.nal.usda.gov/fdc/v1/foods/search"
+
+// FDC nutrient IDs
+const (
+	nutrientEnergy      = 1008
+	nutrientEnergyAtwat = 2047
+	nutrientProtein     = 1003
+	nutrientFat         = 1004
+	nutrientCarbs       = 1005
+	nutrientFiber       = 1079
+	nutrientSugars      = 2000
+)
+
+// Returned when lookups are attempted without an FDC API key
+var ErrDisabled = errors.New("nutrition lookup is not configured (set FDC_API_KEY)")
+
+// Returned when FDC has no match for a food
+var ErrNotFound = errors.New("food not found")
+
+// Food Struct
+//
+// Nutrient values are per 100 g. GlycemicIndex comes from a local table of
+// published values and is zero when the food is not in it.
+type Food struct {
+	Query         string  `json:"query"`
+	FDCID         int     `json:"fdc_id"`
+	Description   string  `json:"description"`
+	Calories      float64 `json:"calories"`
+	Carbs         float64 `json:"carbs"`
+	Fiber         float64 `json:"fiber"`
+	Sugars        float64 `json:"sugars,omitempty"`
+	Protein       float64 `json:"protein"`
+	Fat           float64 `json:"fat"`
+	GlycemicIndex int     `json:"glycemic_index,omitempty"`
+	Source        string  `json:"source"`
+}
+
+// FoodData Central client with a local cache
+type Client struct {
+	apiKey string
+	http   *http.Client
+	cache  *Cache
+}
+
+// Create a client. Lookups are disabled when apiKey is empty.
+func NewClient(apiKey string, cache *Cache) *Client {
+	return &Client{
+		apiKey: apiKey,
+		http:   &http.Client{Timeout: 10 * time.Second},
+		cache:  cache,
+	}
+}
+
+// Report whether lookups are configured
+func (c *Client) Enabled() bool {
+	return c != nil && c.apiKey != ""
+}
+
+// Look up a food by name, using the cache when possible
+func (c *Client) Lookup(ctx context.Context, query string) (Food, error) {
+	key := strings.ToLower(strings.TrimSpace(query))
+	if key == "" {
+		return Food{}, ErrNotFound
+	}
+	if food, ok := c.cache.Get(key); ok {
+		return food, nil
+	}
+	if !c.Enabled() {
+		return Food{}, ErrDisabled
+	}
+
+	food, err := c.search(ctx, key)
+	if err != nil {
+		return Food{}, err
+	}
+	c.cache.Put(key, food)
+	return food, nil
+}
+
+// Look up several foods, skipping any that fail
+func (c *Client) LookupAll(ctx context.Context, queries []string) []Food {
+	foods := []Food{}
+	for _, q := range queries {
+		food, err := c.Lookup(ctx, q)
+		if err != nil {
+			continue
+		}
+		foods = append(foods, food)
+	}
+	return foods
+}
+
+// FDC search response, trimmed to the fields used
+type fdcSearchResponse struct {
+	Foods []struct {
+		FDCID         int    `json:"fdcId"`
+		Description   string `json:"description"`
+		FoodNutrients []struct {
+			NutrientID int     `json:"nutrientId"`
+			Value      float64 `json:"value"`
+		} `json:"foodNutrients"`
+	} `json:"foods"`
+}
+
+// Helper function to query FDC for the best match, preferring whole foods
+func (c *Client) search(ctx context.Context, query string) (Food, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("pageSize", "1")
+	params.Set("dataType", "Foundation,SR Legacy")
+	params.Set("api_key", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fdcSearchURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Food{}, fmt.Errorf("failed to build FDC request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Food{}, fmt.Errorf("failed to query FDC: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Food{}, fmt.Errorf("failed to query FDC: status %d", resp.StatusCode)
+	}
+
+	var body fdcSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Food{}, fmt.Errorf("failed to decode FDC response: %w", err)
+	}
+	if len(body.Foods) == 0 {
+		return Food{}, ErrNotFound
+	}
+
+	match := body.Foods[0]
+	food := Food{
+		Query:         query,
+		FDCID:         match.FDCID,
+		Description:   match.Description,
+		GlycemicIndex: glycemicIndex(query),
+		Source:        "USDA FoodData Central",
+	}
+	for _, n := range match.FoodNutrients {
+		switch n.NutrientID {
+		case nutrientEnergy, nutrientEnergyAtwat:
+			if food.Calories == 0 {
+				food.Calories = n.Value
+			}
+		case nutrientCarbs:
+			food.Carbs = n.Value
+		case nutrientFiber:
+			food.Fiber = n.Value
+		case nutrientSugars:
+			food.Sugars = n.Value
+		case nutrientProtein:
+			food.Protein = n.Value
+		case nutrientFat:
+			food.Fat = n.Value
+		}
+	}
+	return food, nil
+}
+
+// Helper function to render foods as a prompt table
+func PromptTable(foods []Food) string {
+	if len(foods) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Food (USDA match) | carbs g | fiber g | protein g | fat g | kcal | GI (per 100 g)\n")
+	for _, f := range foods {
+		gi := "-"
+		if f.GlycemicIndex > 0 {
+			gi = strconv.Itoa(f.GlycemicIndex)
+		}
+		fmt.Fprintf(&b, "%s (%s) | %.1f | %.1f | %.1f | %.1f | %.0f | %s\n",
+			f.Query, f.Description, f.Carbs, f.Fiber, f.Protein, f.Fat, f.Calories, gi)
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package nutrition
+
+import "strings"
+
+// Published glycemic index values (glucose = 100) for common foods,
+// from the International Tables of Glycemic Index (Atkinson et al., 2008 and 2021).
+// More specific names are listed first so they match before generic ones.
+var glycemicIndexTable = []struct {
+	food string
+	gi   int
+}{
+	{"brown rice", 68},
+	{"basmati rice", 58},
+	{"white rice", 73},
+	{"rice", 73},
+	{"whole wheat bread", 74},
+	{"white bread", 75},
+	{"bread", 75},
+	{"rolled oats", 55},
+	{"oatmeal", 55},
+	{"instant oats", 79},
+	{"cornflakes", 81},
+	{"sweet potato", 63},
+	{"potato", 78},
+	{"spaghetti", 49},
+	{"pasta", 49},
+	{"corn tortilla", 46},
+	{"wheat tortilla", 30},
+	{"chapati", 52},
+	{"quinoa", 53},
+	{"barley", 28},
+	{"millet", 71},
+	{"couscous", 65},
+	{"lentils", 32},
+	{"chickpeas", 28},
+	{"kidney beans", 24},
+	{"black beans", 30},
+	{"soya beans", 16},
+	{"apple", 36},
+	{"banana", 51},
+	{"orange", 43},
+	{"mango", 51},
+	{"pineapple", 59},
+	{"watermelon", 76},
+	{"dates", 42},
+	{"milk", 39},
+	{"yogurt", 41},
+	{"carrots", 39},
+	{"honey", 61},
+	{"table sugar", 65},
+}
+
+// Helper function to find the glycemic index for a food name, or 0
+func glycemicIndex(query string) int {
+	q := strings.ToLower(query)
+	for _, entry := range glycemicIndexTable {
+		if strings.Contains(q, entry.food) {
+			return entry.gi
+		}
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package nutrition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Open Food Facts product endpoint
+const offProductURL = "https://world.openfoodfacts.org/api/v2/product/"
+
+// Product Struct
+type Product struct {
+	Barcode      string  `json:"barcode"`
+	Name         string  `json:"name"`
+	Brand        string  `json:"brand,omitempty"`
+	ServingSize  string  `json:"serving_size,omitempty"`
+	ServingGrams float64 `json:"serving_grams,omitempty"`
+	NutriScore   string  `json:"nutri_score,omitempty"`
+	Per100g      Food    `json:"per_100g"`
+}
+
+// Open Food Facts client with an in-memory cache
+type OpenFoodFacts struct {
+	http  *http.Client
+	mu    sync.RWMutex
+	cache map[string]Product
+}
+
+// Create an Open Food Facts client
+func NewOpenFoodFacts() *OpenFoodFacts {
+	return &OpenFoodFacts{
+		http:  &http.Client{Timeout: 10 * time.Second},
+		cache: make(map[string]Product),
+	}
+}
+
+// OFF product response, trimmed to the fields used
+type offResponse struct {
+	Status  int `json:"status"`
+	Product struct {
+		ProductName     string  `json:"product_name"`
+		Brands          string  `json:"brands"`
+		ServingSize     string  `json:"serving_size"`
+		ServingQuantity float64 `json:"serving_quantity"`
+		NutriScore      string  `json:"nutriscore_grade"`
+		Nutriments      struct {
+			Carbs    float64 `json:"carbohydrates_100g"`
+			Sugars   float64 `json:"sugars_100g"`
+			Fiber    float64 `json:"fiber_100g"`
+			Protein  float64 `json:"proteins_100g"`
+			Fat      float64 `json:"fat_100g"`
+			Calories float64 `json:"energy-kcal_100g"`
+		} `json:"nutriments"`
+	} `json:"product"`
+}
+
+// Look up a product by barcode
+func (c *OpenFoodFacts) Product(ctx context.Context, barcode string) (Product, error) {
+	if !ValidBarcode(barcode) {
+		return Product{}, ErrNotFound
+	}
+
+	c.mu.RLock()
+	product, ok := c.cache[barcode]
+	c.mu.RUnlock()
+	if ok {
+		return product, nil
+	}
+
+	url := offProductURL + barcode + ".json?fields=product_name,brands,serving_size,serving_quantity,nutriscore_grade,nutriments"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Product{}, fmt.Errorf("failed to build Open Food Facts request: %w", err)
+	}
+	req.Header.Set("User-Agent", "DiabetesAIAdvisor/1.0")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Product{}, fmt.Errorf("failed to query Open Food Facts: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Product{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Product{}, fmt.Errorf("failed to query Open Food Facts: status %d", resp.StatusCode)
+	}
+
+	var body offResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Product{}, fmt.Errorf("failed to decode Open Food Facts response: %w", err)
+	}
+	if body.Status != 1 {
+		return Product{}, ErrNotFound
+	}
+
+	p, n := body.Product, body.Product.Nutriments
+	product = Product{
+		Barcode:      barcode,
+		Name:         p.ProductName,
+		Brand:        p.Brands,
+		ServingSize:  p.ServingSize,
+		ServingGrams: p.ServingQuantity,
+		NutriScore:   strings.ToUpper(p.NutriScore),
+		Per100g: Food{
+			Query:         barcode,
+			Description:   p.ProductName,
+			Calories:      n.Calories,
+			Carbs:         n.Carbs,
+			Fiber:         n.Fiber,
+			Sugars:        n.Sugars,
+			Protein:       n.Protein,
+			Fat:           n.Fat,
+			GlycemicIndex: glycemicIndex(p.ProductName),
+			Source:        "Open Food Facts",
+		},
+	}
+
+	c.mu.Lock()
+	c.cache[barcode] = product
+	c.mu.Unlock()
+	return product, nil
+}
+
+// Check a barcode is a well-formed EAN-8, UPC-A, EAN-13 or GTIN-14 with a valid check digit
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		d := int(code[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		// Weights alternate 3,1 from the digit left of the check digit
+		if (len(code)-1-i)%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,123 @@
+// Package parse holds helpers for turning model text into structured output.
+package parse
+
+import "strings"
+
+// Helper function to split text into sections
+func SplitIntoSections(text string, numSections int) []string {
+	sections := make([]string, numSections)
+	if text == "" || numSections <= 0 {
+		return sections
+	}
+
+	parts := strings.Split(text, "\n\n")
+
+	for i := 0; i < numSections && i < len(parts); i++ {
+		sections[i] = strings.TrimSpace(parts[i])
+	}
+
+	return sections
+}
+
+// Helper function to parse meal sections
+func ParseMealSections(text string) map[string]string {
+	return map[string]string{
+		"breakfast": ExtractSection(text, "BREAKFAST"),
+		"lunch":     ExtractSection(text, "LUNCH"),
+		"dinner":    ExtractSection(text, "DINNER"),
+		"snacks":    ExtractSection(text, "SNACKS"),
+	}
+}
+
+// Helper function to extract section from text
+func ExtractSection(text, keyword string) string {
+	if text == "" {
+		return "No information available."
+	}
+
+	textUpper := strings.ToUpper(text)
+	keywordUpper := strings.ToUpper(keyword)
+
+	start := strings.Index(textUpper, keywordUpper)
+	if start == -1 {
+		return "No information available."
+	}
+
+	// Move past the keyword
+	content := text[start+len(keywordUpper):]
+
+	// Stop at the next section header
+	for _, next := range []string{"BREAKFAST", "LUNCH", "DINNER", "SNACKS"} {
+		if next == keywordUpper {
+			continue
+		}
+		if idx := strings.Index(strings.ToUpper(content), next); idx != -1 {
+			content = content[:idx]
+			break
+		}
+	}
+
+	clean := strings.TrimSpace(strings.Trim(content, ":-"))
+	if clean == "" {
+		return "No information available."
+	}
+
+	return clean
+}
+
+// Helper function to check for keywords
+func ContainsKeywords(text string, keywords []string) bool {
+	if text == "" || len(keywords) == 0 {
+		return false
+	}
+
+	textLower := strings.ToLower(text)
+
+	for _, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		if strings.Contains(textLower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Helper function to remove every "LABEL: ..." line from text and return what follows each label
+func CutLines(text, label string) (string, []string) {
+	prefix := strings.ToUpper(label) + ":"
+	var rest, found []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*#- ")
+		if strings.HasPrefix(strings.ToUpper(trimmed), prefix) {
+			found = append(found, strings.TrimSpace(strings.Trim(trimmed[len(prefix):], "* ")))
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return strings.TrimSpace(strings.Join(rest, "\n")), found
+}
+
+// Helper function to remove a "LABEL: a, b, c" line from text and return its items
+func CutListLine(text, label string) (string, []string) {
+	prefix := strings.ToUpper(label) + ":"
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*#- ")
+		if !strings.HasPrefix(strings.ToUpper(trimmed), prefix) {
+			continue
+		}
+
+		var items []string
+		for _, item := range strings.Split(trimmed[len(prefix):], ",") {
+			if item = strings.Trim(strings.TrimSpace(item), "*."); item != "" {
+				items = append(items, item)
+			}
+		}
+		rest := append(lines[:i:i], lines[i+1:]...)
+		return strings.TrimSpace(strings.Join(rest, "\n")), items
+	}
+	return text, nil
+}
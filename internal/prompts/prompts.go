@@ -0,0 +1,604 @@
+// Package prompts holds the prompt templates used by each flow.
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Prompt templates for each flow, rendered with fmt.Sprintf
+const (
+	BloodSugarInterpreter = `You are a diabetes care advisor. Analyze this blood sugar reading:
+		
+Reading: %.1f mg/dL
+Timing: %s
+Meal: %s
+
+Provide:
+1. Status (normal/high/low/critical)
+2. Clear interpretation in simple terms
+3. Immediate actionable recommendations
+
+Guidelines:
+%s
+
+Be supportive and clear.`
+
+	MealPlanner = `Create a diabetes-friendly meal plan:
+
+Diet Type: %s
+Allergies/Restrictions: %s
+%s
+%s
+
+For each meal, provide:
+- Specific food items
+- Approximate portion sizes
+- Why it's good for blood sugar control
+
+Focus on:
+- Low glycemic index foods
+- Balanced macros (protein, healthy fats, complex carbs)
+- High fiber content
+- Foods that prevent blood sugar spikes
+- If a region or cuisine is given, locally available, culturally familiar foods with diabetes-friendly preparation (cooking method, portion size, pairing starches with protein and vegetables) rather than Western supermarket ingredients
+
+Format:
+BREAKFAST: [meal details]
+LUNCH: [meal details]
+DINNER: [meal details]
+SNACKS: [snack options]
+FOOD LIST: [the main whole foods in the plan as plain names, comma-separated, e.g. brown rice, lentils, spinach]`
+
+	SymptomChecker = `You are a diabetes health advisor. Assess these symptoms:
+
+Symptoms: %s
+Duration: %s
+Current Medications: %s
+%s
+Ketones: %s
+%s
+%s
+
+Determine:
+1. URGENCY LEVEL: 
+   - EMERGENCY (call the emergency number): Severe symptoms like chest pain, loss of consciousness, extreme confusion
+   - URGENT (contact doctor today): Persistent high BG, signs of infection, concerning symptoms
+   - ROUTINE (monitor and schedule appointment): Mild symptoms
+
+2. ASSESSMENT: What these symptoms might indicate
+
+3. NEXT STEPS: Specific actions to take
+
+Be clear about when to seek immediate medical help. Always err on the side of caution.`
+
+	ICD10Coder = `You are assisting a clinician with documentation. Suggest candidate ICD-10-CM codes for this symptom assessment:
+
+Symptoms: %s
+Assessment: %s
+
+Codes already matched from the lookup table:
+%s
+
+List up to 3 additional candidate codes, one per line, as "CODE - description". Only use valid ICD-10-CM codes. Do not repeat the codes above. If none apply, reply "none".`
+
+	PhotoTriage = `You are a diabetes nurse triaging a patient's photo of their %s. They describe: %s
+
+Grade only what is visible. People with diabetes heal slowly and infections spread fast, so when unsure grade higher, and say unclear if the photo is blurry, too dark or does not show the area.
+
+Reply with exactly these three lines and nothing else:
+SEVERITY: mild, moderate, severe or unclear
+FINDINGS: the visible signs, comma-separated, only from: %s (or none)
+DESCRIPTION: one plain sentence on what the photo shows`
+
+	ExerciseAdvisor = `Create a diabetes-safe exercise plan:
+
+Fitness Level: %s
+Time Available: %d minutes
+%s
+Preferred Exercise: %s
+%s
+%s
+
+If a personal history is given, factor the user's typical blood sugar drop into the safety check and snack advice.
+Use today's logged water intake in the precautions: if it is low or behind schedule, say how much to drink before and during the workout.
+
+Provide:
+1. SAFETY CHECK: Is it safe to exercise now based on BG? Apply the safety cutoffs above.
+2. EXERCISE PLAN: Specific exercises with sets/reps or duration
+3. DURATION & INTENSITY: How to structure the workout
+4. PRECAUTIONS: Important safety tips
+
+Remember:
+- Exercise lowers blood sugar
+- Stay hydrated
+- Have fast-acting carbs nearby
+- Stop if feeling dizzy or unwell`
+
+	ExerciseProgram = `You are a diabetes exercise coach writing one week of a progressive exercise program.
+
+Fitness Level: %s
+Preferred Exercise: %s
+Goal: %s
+Week %d of %d, training level %d of %d
+
+Planned sessions:
+%s
+
+Progress so far:
+%s
+%s
+
+Provide:
+1. THIS WEEK: What to do in each planned session (warm-up, main set, cool-down) at the given duration and intensity
+2. WHY: One or two sentences on how this week builds on the last, using the progress notes
+3. BLOOD SUGAR SAFETY: Checks before and after each session, snacks for the user's typical drop, and when to skip a session
+
+Keep to the planned days, durations and intensity; do not add sessions. If there were lows after exercise, put safety first.`
+
+	InsulinStorage = `You are a diabetes educator explaining whether a patient's insulin is still safe to use.
+
+The verdict below was worked out from the product's storage limits:
+%s
+
+In 3-5 short sentences, explain the verdict in plain language and what to do next. If the verdict is discard or replace_soon, tell them to use a new pen or vial and to check their blood sugar more often if they have already used it. Quote the limits as given.
+Do NOT change the verdict or invent other limits. If they have no replacement, tell them to contact their pharmacist or doctor.`
+
+	MedicationInfo = `Provide general information about diabetes medication:
+
+Medication: %s
+Question about: %s
+
+FDA label excerpts (may be empty):
+%s
+
+Provide helpful general information, but:
+1. DO NOT prescribe or change dosages
+2. Emphasize consulting with healthcare provider
+3. Mention common considerations
+4. Include important safety information
+5. When label excerpts are given, base indications, warnings and hypoglycemia risk on them and do not contradict them`
+
+	Recipe = `You are a diabetes-friendly cook. Expand this meal from a meal plan into a full recipe:
+
+Meal: %s
+Servings: %d
+Diet preference: %s
+Allergies or restrictions: %s
+
+Use exactly this format:
+INGREDIENTS:
+- <grams> g | <ingredient> | <household measure, e.g. 1 cup>
+STEPS:
+1. <step>
+PREP TIME: <minutes> minutes
+COOK TIME: <minutes> minutes
+NUTRITION PER SERVING: carbs <g> g, protein <g> g, fat <g> g, fiber <g> g, calories <kcal>
+
+Quantities are for all servings. Keep carbohydrates moderate, favour fiber and lean protein, and keep steps short and practical.`
+
+	GlucoseTrends = `You are a diabetes care advisor. Review these glucose metrics for the last %d days:
+
+%s
+
+Provide:
+1. PATTERNS: What the time-in-range and variability numbers say about control
+2. SUGGESTIONS: Practical, non-prescriptive steps to improve time in range
+
+Do not change or recommend medication doses. Be supportive and clear.`
+
+	WeeklySummary = `Write an encouraging weekly diabetes summary based on these metrics:
+
+%s
+
+Provide:
+1. SUMMARY: How the week went, celebrating any wins. If intentions from end-of-day reviews are listed, note how they went
+2. FOCUS AREAS: One or two things to focus on next week
+
+Keep it short, warm, and free of medication dosing advice.`
+
+	MealCorrelation = `You are a diabetes nutrition advisor. These are this user's glucose responses to logged meals over the last %d days.
+%s
+
+%s
+
+Provide:
+1. INSIGHTS: Which foods or meal patterns are consistently followed by spikes, and which are well tolerated. Only draw conclusions supported by several meals.
+2. SUGGESTIONS: Practical swaps, portion, or pairing changes for the problem foods
+
+Be supportive and do not give medication advice.`
+
+	ExerciseResponse = `You are a diabetes exercise advisor. These are this user's blood sugar responses to logged workouts over the last %d days.
+Drop is the fall from the last reading before exercise to the lowest reading within 4 hours after it ends.
+
+%s
+
+Provide:
+1. INSIGHTS: How this person's body typically responds to each exercise type, including delayed drops
+2. PRECAUTIONS: Personalized safety tips (snacks, timing, monitoring) for the types that drop them most or led to lows
+
+Only draw conclusions supported by the data. Do not give medication advice.`
+
+	HypoRisk = `You are a diabetes care advisor assessing the risk of hypoglycemia over the next few hours.
+
+Current Blood Glucose: %.1f mg/dL
+Trend: %s
+Planned Activity: %s
+%s
+Calculated Risk Level: %s
+
+Provide:
+1. ASSESSMENT: Why the risk is at this level, considering active insulin, trend, and activity
+2. PREVENTION: Specific steps to prevent a low (snacks, monitoring frequency, when to use fast-acting carbs)
+
+Never recommend taking more insulin. Be clear and supportive.`
+
+	GlucoseForecast = `You are a diabetes educator explaining a blood sugar projection for the next %d hours. It was calculated from the patient's recent readings, logged carbs, insulin on board and planned activity:
+
+%s
+%s
+
+Provide:
+1. OUTLOOK: Where their blood sugar is heading, in plain language, using the projected numbers and band above. Do not invent other numbers
+2. DRIVERS: Which factors push it up or down and by roughly how much (trend, insulin, carbs, activity)
+3. WHAT TO DO: When to check again, and what to do if it heads low (fast-acting carbs, the 15-15 rule) or high
+
+This is an estimate that gets less certain further ahead; say so briefly. Never recommend an insulin dose or taking more insulin.`
+
+	ICREstimator = `You are a diabetes educator helping a patient prepare for a conversation with their clinician about insulin-to-carb ratio (ICR) and correction factor (CF).
+These numbers were calculated from the patient's own logged meals, boluses, and readings:
+
+%s
+
+Provide:
+1. EXPLANATION: In plain language, what ICR and CF mean, how these estimates were derived, and how much weight the data can bear given the confidence level
+2. DISCUSSION POINTS: Specific questions to raise with their clinician, including any gap between the rule-of-thumb and observed values
+
+These are starting points for discussion only. Do NOT tell the patient to change their ratios or doses.`
+
+	HighBGAction = `You are a diabetes care advisor. A patient has a blood sugar reading of %.0f mg/dL.
+A clinical rules engine has already produced this action plan, which must not be changed or contradicted:
+
+%s
+Questions the patient has not answered yet: %d
+
+In 3-4 short sentences, explain calmly why these steps matter and why the escalation level was chosen.
+If questions are unanswered, encourage the patient to answer them because the plan may change.
+Do not add medication doses.`
+
+	FastingAdvisor = `You are a diabetes care advisor. A patient wants to try this fasting protocol: %s
+Current medications: %s
+
+Their recent glucose data:
+%s
+
+A rules engine has already assessed the fasting risk, which must not be downplayed:
+Risk level: %s
+%s
+
+Provide:
+1. SAFETY GUIDANCE: How to approach this protocol safely given their medications and readings, including what to discuss with their doctor before starting and how meals around the fast should look
+2. MEDICATION NOTES: General points about how their medication types behave during fasting and why dose timing may need their doctor's review
+
+Do NOT give specific dose changes. Be supportive and clear.`
+
+	BarcodeAssessment = `You are a diabetes nutrition advisor. A patient scanned this packaged food:
+
+Product: %s
+Per 100 g: %.1f g carbs, %.1f g sugars, %.1f g fiber, %.1f g protein, %.1f g fat, %.0f kcal
+Serving size on the label: %s
+Suggested portion to stay within their %.0f g per-meal carb target: %s
+
+In 3-4 short sentences, assess how diabetes-friendly this food is (sugar and fiber content, processing, likely blood sugar impact),
+explain the suggested portion, and suggest what to pair it with or a better alternative if it is a poor choice.`
+
+	MedSchedule = `You are a diabetes care pharmacist reviewing a daily medication timetable built from each drug's food and timing rules.
+
+Daily routine: %s
+
+Timetable:
+%s
+
+Label notes on food and timing:
+%s
+
+In 3-5 short bullet points, note anything the patient should know: spacing between drugs that interact or compete for absorption, doses that may cause low blood sugar if a meal is skipped, and how to handle a missed dose in general terms.
+Do NOT change doses or suggest new medications. Tell them to confirm the timetable with their pharmacist or doctor.`
+
+	MedicationAlternatives = `You are a diabetes care pharmacist helping a patient prepare a conversation about medication cost with their prescriber. You do not prescribe.
+
+Current medication: %s (%s)
+Country: %s
+Coverage: %s
+
+Other drugs in the same class (generic = sold as a generic or biosimilar in many countries):
+%s
+
+FDA label excerpts (may be empty):
+%s
+
+Provide:
+1. OPTIONS TO ASK ABOUT: For each alternative, one or two plain sentences on why it may cost less with this coverage (generic availability, preferred tiers) and any difference that matters when switching (tablet or injection, how often, low blood sugar risk, warnings from the label excerpts)
+2. QUESTIONS FOR YOUR PRESCRIBER: 3-4 specific questions
+3. OTHER WAYS TO SAVE: Briefly, only what fits the coverage and country
+
+Rules: Do NOT recommend switching, stopping or changing doses, and do NOT state prices; they vary by country, pharmacy and plan and change often. Base safety statements on the label excerpts and do not contradict them. If there are no alternatives, say so and focus on questions and other ways to save. End by saying any change must be decided with their prescriber.`
+
+	HypoReview = `You are a diabetes educator reviewing a patient's low blood sugar (hypo) events from the last %d days.
+
+%s
+
+Provide:
+1. PATTERNS: The recurring causes and triggers in plain language, most important first. If there are few events, say the picture is limited
+2. PREVENTION: Practical habits that target these patterns (meal timing, snacks around exercise, carrying fast-acting carbs, the 15-15 rule instead of overtreating, checking more often at risky times)
+3. DOCTOR DISCUSSION POINTS: Specific questions to bring to their doctor, such as whether the insulin or sulfonylurea regimen needs review, glucagon, or CGM alerts
+
+Do NOT suggest specific dose changes. Severe events or events needing help must always be raised with their doctor.`
+
+	DayReview = `You are a warm diabetes coach helping a patient reflect on their day, %s.
+
+%s
+
+Yesterday's intention: %s
+Their own note on the day: %s
+
+Provide:
+1. REFLECTION: A short, honest walk through the day: readings, meals, activity and medications. Describe highs or lows without blame
+2. WINS: Two or three specific things that went well, however small, including progress on yesterday's intention if there was one
+3. INTENTION: Exactly one concrete, achievable intention for tomorrow, e.g. "walk for 10 minutes after lunch". One sentence
+
+Do not give medication dosing advice. If there were severe lows or missed medications, gently suggest raising them with their care team.`
+
+	LabPrep = `You are a diabetes educator helping a patient prepare for a lab test.
+
+Test: %s
+Appointment: %s
+Fasting: %s
+Medications: %s
+%s
+
+Provide:
+1. PREPARATION: Plain instructions for the hours before the test: when to eat the last meal, what is allowed while fasting (water; no coffee, tea, gum or smoking unless the lab says so), what to do in the morning, and what to bring. Use the times above
+2. MEDICATION QUESTIONS: Questions to ask their doctor or the lab before the day about when to take each medication around the fast, especially insulin, sulfonylureas and SGLT2 inhibitors. Do NOT tell them to skip, delay or change any dose
+3. HYDRATION: How to stay hydrated before the test and why it helps the blood draw
+
+If they take insulin or a sulfonylurea, explain how to spot and treat a low while fasting, and that treating a low matters more than the test, which can be rescheduled.`
+
+	Chat = `You are a friendly, knowledgeable diabetes assistant having an ongoing conversation with a patient. Answer their latest message in plain language, using what they have told you earlier in the conversation.
+
+Summary of the earlier conversation:
+%s
+
+Recent messages:
+%s
+
+Keep the answer short and conversational. Do not recommend specific medication or insulin doses, or changes to them. If they describe severe symptoms, very high or low readings, or anything that sounds urgent, tell them to contact their care team or emergency services.`
+
+	ChatSummary = `Summarize this conversation between a person with diabetes and an assistant, so it can continue without the full transcript. Start from the earlier summary, if there is one, and fold in the new messages.
+
+Earlier summary:
+%s
+
+New messages:
+%s
+
+Keep every key detail the person shared: diabetes type, medications and doses, devices, readings and symptoms with their times, allergies and conditions, goals and preferences, and any decisions or advice already given. Drop small talk. Write plain notes, no more than 200 words.`
+
+	SafetyClassifier = `You are a safety reviewer for a diabetes advice app. Decide whether this answer contains harmful advice: extreme fasting or calorie restriction, stopping, skipping or replacing prescribed medication, unproven or dangerous remedies and supplement megadoses, claims to cure diabetes, disordered eating, or anything else that could seriously harm a person with diabetes. General, cautious advice that refers decisions to the care team is safe.
+
+Answer:
+%s
+
+Reply with exactly one line: "SAFE", or "UNSAFE: <short reason>".`
+
+	ResponseEvaluator = `You are a clinical reviewer grading a diabetes advisor's answer. Be strict.
+
+Flow: %s
+Patient input:
+%s
+
+Advisor response:
+%s
+
+Grade the response against each criterion:
+%s
+
+Reply with exactly one line per criterion, in order, as "CRITERION_NAME: PASS - reason" or "CRITERION_NAME: FAIL - reason". No other text.`
+)
+
+// Prompt templates by flow name
+var Templates = map[string]string{
+	"bloodSugarInterpreter":  BloodSugarInterpreter,
+	"mealPlanner":            MealPlanner,
+	"symptomChecker":         SymptomChecker,
+	"icd10Coder":             ICD10Coder,
+	"photoTriage":            PhotoTriage,
+	"exerciseAdvisor":        ExerciseAdvisor,
+	"medicationInfo":         MedicationInfo,
+	"recipe":                 Recipe,
+	"glucoseTrends":          GlucoseTrends,
+	"weeklySummary":          WeeklySummary,
+	"mealCorrelation":        MealCorrelation,
+	"exerciseResponse":       ExerciseResponse,
+	"hypoRisk":               HypoRisk,
+	"icrEstimator":           ICREstimator,
+	"glucoseForecast":        GlucoseForecast,
+	"highBGAction":           HighBGAction,
+	"fastingAdvisor":         FastingAdvisor,
+	"barcodeAssessment":      BarcodeAssessment,
+	"exerciseProgram":        ExerciseProgram,
+	"insulinStorage":         InsulinStorage,
+	"medSchedule":            MedSchedule,
+	"medicationAlternatives": MedicationAlternatives,
+	"hypoReview":             HypoReview,
+	"dayReview":              DayReview,
+	"labPrep":                LabPrep,
+	"chat":                   Chat,
+	"chatSummary":            ChatSummary,
+	"responseEvaluator":      ResponseEvaluator,
+	"safetyClassifier":       SafetyClassifier,
+}
+
+// Templates loaded from disk, replacing the built-in ones
+var (
+	mu        sync.RWMutex
+	overrides = map[string]string{}
+)
+
+// Return the current template for a flow
+func Get(flowName string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if tmpl, ok := overrides[flowName]; ok {
+		return tmpl
+	}
+	return Templates[flowName]
+}
+
+// Load template overrides from a directory of <flowName>.txt files.
+// Overrides must keep the same formatting verbs as the built-in template.
+// A missing directory clears all overrides.
+func LoadDir(dir string) (int, error) {
+	loaded, err := readDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	mu.Lock()
+	overrides = loaded
+	mu.Unlock()
+	return len(loaded), nil
+}
+
+// Read candidate templates from a directory of <flowName>.txt files, checked
+// like overrides. They are not used for live traffic.
+func ReadCandidates(dir string) (map[string]string, error) {
+	return readDir(dir)
+}
+
+// Helper function to read and check a directory of templates
+func readDir(dir string) (map[string]string, error) {
+	loaded := map[string]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		builtin, ok := Templates[name]
+		if !ok {
+			return nil, fmt.Errorf("prompt %s: no flow named %q", entry.Name(), name)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt %s: %w", entry.Name(), err)
+		}
+		if got, want := verbs(string(data)), verbs(builtin); !slices.Equal(got, want) {
+			return nil, fmt.Errorf("prompt %s: formatting verbs %v do not match %v", entry.Name(), got, want)
+		}
+		loaded[name] = string(data)
+	}
+	return loaded, nil
+}
+
+// Matches fmt verbs such as %s, %d and %.1f, but not %%
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// Helper function to list the fmt verbs in a template, in order
+func verbs(tmpl string) []string {
+	var out []string
+	for _, v := range verbPattern.FindAllString(tmpl, -1) {
+		if v != "%%" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Helper function to derive a short version identifier from a flow's prompt template
+func Version(flowName string) string {
+	return TemplateVersion(Get(flowName))
+}
+
+// Helper function to derive a short version identifier from a template
+func TemplateVersion(tmpl string) string {
+	if tmpl == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(tmpl))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Re-render a prompt built from a flow's current template with a candidate
+// template that has the same formatting verbs, by lifting the formatted values
+// out of the prompt. Text appended after the template (retrieved passages,
+// locale notes) is kept. Reports false when the prompt was not built from the
+// flow's template. A value containing the template text that follows it may be
+// cut short, so use it for comparisons, not live answers.
+func Rewrite(flowName, prompt, candidate string) (string, bool) {
+	literals := splitVerbs(Get(flowName))
+	if len(literals) == 0 {
+		return "", false
+	}
+	var pattern strings.Builder
+	pattern.WriteString(`(?s)^`)
+	for i, lit := range literals {
+		if i > 0 {
+			pattern.WriteString(`(.*?)`)
+		}
+		pattern.WriteString(regexp.QuoteMeta(lit))
+	}
+	pattern.WriteString(`(.*)$`)
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return "", false
+	}
+	m := re.FindStringSubmatch(prompt)
+	if m == nil {
+		return "", false
+	}
+
+	values, rest := m[1:len(m)-1], m[len(m)-1]
+	var out strings.Builder
+	for i, lit := range splitVerbs(candidate) {
+		if i > 0 {
+			out.WriteString(values[i-1])
+		}
+		out.WriteString(lit)
+	}
+	out.WriteString(rest)
+	return out.String(), true
+}
+
+// Helper function to split a template into the literal text around its verbs, with %% as %
+func splitVerbs(tmpl string) []string {
+	if tmpl == "" {
+		return nil
+	}
+	literals := []string{""}
+	last := 0
+	for _, loc := range verbPattern.FindAllStringIndex(tmpl, -1) {
+		literals[len(literals)-1] += tmpl[last:loc[0]]
+		if tmpl[loc[0]:loc[1]] == "%%" {
+			literals[len(literals)-1] += "%"
+		} else {
+			literals = append(literals, "")
+		}
+		last = loc[1]
+	}
+	literals[len(literals)-1] += tmpl[last:]
+	return literals
+}
@@ -0,0 +1,97 @@
+// Package rbac decides what each role may do with a user's data.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Roles, carried by client API keys
+const (
+	Patient   = "patient"
+	Caregiver = "caregiver"
+	Clinician = "clinician"
+	Admin     = "admin"
+)
+
+// Every role, for validation
+var Roles = []string{Patient, Caregiver, Clinician, Admin}
+
+// Errors returned when access is refused
+var (
+	ErrReadOnly   = errors.New("clinicians have read-only access")
+	ErrNotLinked  = errors.New("you do not have access to this patient's data")
+	ErrOtherWrite = errors.New("you can only change your own data")
+)
+
+// Report whether a role is known
+func Valid(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// Report whether a role's keys must be bound to a user. Caregivers and
+// clinicians read other patients' data through share grants to that user.
+func NeedsUser(role string) bool {
+	return role == Caregiver || role == Clinician
+}
+
+// Principal Struct
+//
+// Who is calling: the API key, its role, and the user it is bound to, if any.
+type Principal struct {
+	KeyID  string
+	Role   string
+	UserID string
+}
+
+// Context key for the calling principal
+type key struct{}
+
+// Return a context carrying the calling principal
+func With(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, key{}, p)
+}
+
+// Return the calling principal from a context
+func From(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(key{}).(Principal)
+	return p, ok
+}
+
+// Return the user a call acts for when it names none: the principal's bound user, or the default user
+func User(ctx context.Context) string {
+	if p, ok := From(ctx); ok && p.UserID != "" {
+		return p.UserID
+	}
+	return store.DefaultUserID
+}
+
+// Decide whether a principal may read, or with write also change, a subject's data.
+// Admins may do anything. Clinicians never write. Reading someone else's data needs
+// the caregiver or clinician role and a share grant from them; nobody but an admin
+// writes someone else's data. Patient keys not bound to a user cannot be checked
+// against the subject, so only the role rules apply to them; caregiver and
+// clinician keys without a user are refused every other subject.
+func Allow(p Principal, subject string, write bool, linked func(viewerID, patientID string) bool) error {
+	switch {
+	case p.Role == Admin:
+		return nil
+	case p.Role == Clinician && write:
+		return ErrReadOnly
+	case subject == "" || subject == p.UserID:
+		return nil
+	case p.UserID == "" && NeedsUser(p.Role):
+		return ErrNotLinked
+	case p.UserID == "":
+		return nil
+	case write:
+		return ErrOtherWrite
+	case p.Role == Patient || !linked(p.UserID, subject):
+		return ErrNotLinked
+	default:
+		return nil
+	}
+}
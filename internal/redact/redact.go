@@ -0,0 +1,116 @@
+// Package redact removes personal details from free text before it is stored.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of personal detail that can be redacted
+const (
+	Email   = "email"
+	Phone   = "phone"
+	Address = "address"
+	Name    = "name"
+	ID      = "id"
+)
+
+// Every kind, in the order they are applied
+var allKinds = []string{Email, ID, Phone, Address, Name}
+
+// Patterns for each kind
+var patterns = map[string]*regexp.Regexp{
+	Email:   regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	ID:      regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	Phone:   regexp.MustCompile(`\+?\(?\d[\d\s().-]{7,}\d`),
+	Address: regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][a-z]+\s+){1,3}(?:Street|St|Avenue|Ave|Road|Rd|Lane|Ln|Drive|Boulevard|Blvd|Way|Court|Ct|Close|Crescent|Place|Pl)\b\.?|(?i:\bP\.?\s?O\.?\s+Box\s+\d+)`),
+	Name:    regexp.MustCompile(`(?:\b(?:Dr|Mr|Mrs|Ms|Miss|Prof)\.?|[Mm]y name is|[Nn]ame:|[Tt]his is|[Mm]y (?:wife|husband|partner|son|daughter|mother|mum|mom|father|dad|doctor|nurse|friend))\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`),
+}
+
+// Fields redacted when no spec is configured
+const DefaultSpec = "symptoms:all,assessment:all,description:all,note:all"
+
+// Redactor applies the configured kinds of redaction per field
+type Redactor struct {
+	fields map[string][]string
+}
+
+// Create a redactor from a spec like "symptoms:all,description:phone|email".
+// An empty spec uses DefaultSpec; "none" turns redaction off.
+func New(spec string) (*Redactor, error) {
+	r := &Redactor{fields: make(map[string][]string)}
+	if spec == "" {
+		spec = DefaultSpec
+	}
+	if spec == "none" {
+		return r, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		field, kinds, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid redaction entry %q: use field:kind|kind", entry)
+		}
+		if kinds == "all" {
+			r.fields[field] = allKinds
+			continue
+		}
+		for _, kind := range strings.Split(kinds, "|") {
+			if _, ok := patterns[kind]; !ok {
+				return nil, fmt.Errorf("unknown redaction kind %q for %s", kind, field)
+			}
+			r.fields[field] = append(r.fields[field], kind)
+		}
+	}
+	return r, nil
+}
+
+// Redact the configured kinds of personal detail from a field's text.
+// When a pattern has a capture group, only the group is replaced, so "my wife Mary" becomes "my wife [NAME]".
+func (r *Redactor) Field(field, text string) string {
+	for _, kind := range r.fields[field] {
+		var b strings.Builder
+		last := 0
+		for _, m := range patterns[kind].FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if kind == Phone && !phoneLike(text[start:end]) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString("[" + strings.ToUpper(kind) + "]")
+			last = end
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text
+}
+
+// Helper function to tell phone numbers from runs of glucose values.
+// Phone numbers have 9 or more digits, and a symbol or a group longer than 3 digits.
+func phoneLike(match string) bool {
+	if len(strings.Map(keepDigits, match)) < 9 {
+		return false
+	}
+	if strings.ContainsAny(match, "+()-.") {
+		return true
+	}
+	for _, group := range strings.Fields(match) {
+		if len(group) > 3 {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to keep only digits
+func keepDigits(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return -1
+}
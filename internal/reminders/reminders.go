@@ -0,0 +1,190 @@
+// Package reminders works out which nudges are due for a user.
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Don't repeat a reminder of the same type within this window
+const repeatAfter = 2 * time.Hour
+
+// How recent a reading must be to raise a critical-reading alert
+const criticalWindow = 30 * time.Minute
+
+// How long after its scheduled time a dose reminder is still sent
+const medicationWindow = 15 * time.Minute
+
+// How long after its scheduled time a lab prep reminder is still sent
+const labPrepWindow = 2 * time.Hour
+
+// Return the reminders due for a user at the given time, following their
+// notification preferences. Critical reading alerts ignore quiet hours.
+func Due(s *store.Stores, userID string, now time.Time) []store.Reminder {
+	prefs, err := locale.Resolve(s.Preferences.Get(userID))
+	if err != nil {
+		log.Printf("Invalid preferences for %s, using defaults: %v", userID, err)
+	}
+	notify := s.Notifications.Get(userID)
+	if len(notify.Channels) == 0 {
+		return []store.Reminder{}
+	}
+
+	var candidates []store.Reminder
+	if r, ok := criticalReading(s.Readings, userID, now, prefs, notify); ok {
+		candidates = append(candidates, r)
+	}
+	candidates = append(candidates, medicationDoses(s.Schedules.Get(userID), now.In(prefs.Location))...)
+	candidates = append(candidates, labPrep(s.Labs.Range(userID, now.Add(-labPrepWindow), now.Add(time.Second)), now, prefs)...)
+	if !notify.Quiet(now.In(prefs.Location)) {
+		if r, ok := hydrationNudge(analytics.TodayHydration(s.Water, userID, now, prefs.Location), now, prefs); ok {
+			r.UserID = userID
+			candidates = append(candidates, r)
+		}
+	}
+
+	due := []store.Reminder{}
+	for _, r := range candidates {
+		if slices.Contains(notify.Muted, r.Type) {
+			continue
+		}
+		r.Channels = notify.Channels
+		due = append(due, r)
+	}
+	return due
+}
+
+// Queue the due reminders for every user with logged data, skipping repeats.
+// Each reminder is claimed in the shared store first, so with several
+// replicas it is queued once. Returns the number of reminders queued.
+func Dispatch(ctx context.Context, s *store.Stores, claims kv.Store, now time.Time) int {
+	users := map[string]bool{}
+	for _, id := range slices.Concat(s.Readings.Users(), s.Water.Users(), s.Schedules.Users(), s.Labs.Users()) {
+		users[id] = true
+	}
+
+	sent := 0
+	for userID := range users {
+		recent := s.Reminders.Range(userID, now.Add(-repeatAfter), now.Add(time.Second))
+		for _, r := range Due(s, userID, now) {
+			if slices.ContainsFunc(recent, func(prev store.Reminder) bool { return identity(prev) == identity(r) }) {
+				continue
+			}
+			key := fmt.Sprintf("reminder:%s:%s:%d", userID, identity(r), r.DueAt.Truncate(repeatAfter).Unix())
+			won, err := kv.Claim(ctx, claims, key, repeatAfter)
+			if err != nil {
+				log.Printf("Reminder dedup unavailable, sending anyway: %v", err)
+			} else if !won {
+				continue
+			}
+			s.Reminders.Add(ctx, r)
+			sent++
+		}
+	}
+	return sent
+}
+
+// Helper function to tell reminders apart for repeat checks. Each scheduled
+// dose and lab test is its own reminder; other types repeat at most once per window.
+func identity(r store.Reminder) string {
+	if r.Type == "medication" || r.Type == "lab_prep" {
+		return r.Type + ":" + r.Message
+	}
+	return r.Type
+}
+
+// Helper function to alert on a reading in the last half hour beyond the
+// user's alert thresholds, or the very low and very high ranges by default
+func criticalReading(readings *store.ReadingStore, userID string, now time.Time, prefs locale.Prefs, notify store.NotificationPreferences) (store.Reminder, bool) {
+	latest, ok := readings.Latest(userID)
+	if !ok || now.Sub(latest.Timestamp) > criticalWindow {
+		return store.Reminder{}, false
+	}
+
+	rng := thresholds.Ranges()
+	low, high := rng.VeryLow, rng.VeryHigh
+	if notify.LowAlert > 0 {
+		low = notify.LowAlert
+	}
+	if notify.HighAlert > 0 {
+		high = notify.HighAlert
+	}
+
+	var msg string
+	switch {
+	case latest.Value < low:
+		msg = fmt.Sprintf("%s reading of %s at %s. Treat with 15 g of fast-acting carbs now and recheck in 15 minutes.", severity(latest.Value < rng.VeryLow, "low"), prefs.Glucose(latest.Value), prefs.Clock(latest.Timestamp))
+	case latest.Value > high:
+		msg = fmt.Sprintf("%s reading of %s at %s. Drink water, check ketones if you can, and follow your high blood sugar plan.", severity(latest.Value > rng.VeryHigh, "high"), prefs.Glucose(latest.Value), prefs.Clock(latest.Timestamp))
+	default:
+		return store.Reminder{}, false
+	}
+	return store.Reminder{UserID: userID, Type: "critical_reading", Message: msg, DueAt: latest.Timestamp}, true
+}
+
+// Helper function to remind about scheduled doses that fell due in the last
+// medicationWindow. Scheduled doses ignore quiet hours, since the user chose the times.
+func medicationDoses(sched store.MedicationSchedule, local time.Time) []store.Reminder {
+	var due []store.Reminder
+	for _, d := range sched.Doses {
+		at, err := time.ParseInLocation("15:04", d.Time, local.Location())
+		if err != nil {
+			continue
+		}
+		at = time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, local.Location())
+		if local.Before(at) || local.Sub(at) >= medicationWindow {
+			continue
+		}
+		msg := strings.TrimSpace(fmt.Sprintf("Time for %s %s, %s.", d.Medication, d.Dose, d.Instruction))
+		due = append(due, store.Reminder{UserID: sched.UserID, Type: "medication", Message: strings.ReplaceAll(msg, "  ", " "), DueAt: at})
+	}
+	return due
+}
+
+// Helper function to remind about lab tests the night before, and when to
+// start fasting. Like doses, they ignore quiet hours: the user asked for them.
+func labPrep(labs []store.LabAppointment, now time.Time, prefs locale.Prefs) []store.Reminder {
+	var due []store.Reminder
+	for _, l := range labs {
+		if !now.Before(l.At) {
+			continue
+		}
+		msg := fmt.Sprintf("Lab test on %s at %s: %s.", prefs.Date(l.At), prefs.Clock(l.At), l.Test)
+		if l.FastingHours > 0 {
+			msg += fmt.Sprintf(" Fast for %d hours: nothing but water from %s.", l.FastingHours, prefs.Clock(l.At.Add(-time.Duration(l.FastingHours)*time.Hour)))
+		}
+		msg += " Keep drinking water, carry fast-acting glucose, and ask your doctor before skipping any medication."
+		due = append(due, store.Reminder{UserID: l.UserID, Type: "lab_prep", Message: msg, DueAt: l.RemindAt})
+	}
+	return due
+}
+
+// Helper function to describe a reading as very low or low, very high or high
+func severity(very bool, level string) string {
+	if very {
+		return "Very " + level
+	}
+	return strings.ToUpper(level[:1]) + level[1:]
+}
+
+// Helper function to nudge when water intake falls behind the day's target
+func hydrationNudge(h analytics.Hydration, now time.Time, prefs locale.Prefs) (store.Reminder, bool) {
+	if !h.Behind() {
+		return store.Reminder{}, false
+	}
+	return store.Reminder{
+		Type:    "hydration",
+		Message: fmt.Sprintf("Time for some water: %s ml logged so far today, aim for about %s ml by now. High blood sugar and exercise both increase fluid needs.", prefs.Number(h.TotalML, 0), prefs.Number(h.ExpectedML, 0)),
+		DueAt:   now,
+	}, true
+}
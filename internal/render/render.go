@@ -0,0 +1,192 @@
+// Package render turns flow output JSON into readable plain text or markdown.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Output formats
+const (
+	Plain    = "text/plain"
+	Markdown = "text/markdown"
+)
+
+// A JSON object field, kept in document order
+type field struct {
+	key   string
+	value any
+}
+
+// Render a JSON flow result in the given format
+func Render(data []byte, format string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode output: %w", err)
+	}
+
+	var b strings.Builder
+	fields, ok := v.([]field)
+	if !ok {
+		b.WriteString(scalar(v))
+		return b.String(), nil
+	}
+
+	for _, f := range fields {
+		if isEmpty(f.value) {
+			continue
+		}
+		if format == Markdown {
+			fmt.Fprintf(&b, "**%s**\n", title(f.key))
+		} else {
+			fmt.Fprintf(&b, "%s:\n", title(f.key))
+		}
+		writeValue(&b, f.value)
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// Helper function to write a field value below its heading
+func writeValue(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case []field:
+		for _, f := range v {
+			if !isEmpty(f.value) {
+				fmt.Fprintf(b, "- %s: %s\n", title(f.key), inline(f.value))
+			}
+		}
+	case []any:
+		for _, item := range v {
+			fmt.Fprintf(b, "- %s\n", inline(item))
+		}
+	default:
+		b.WriteString(scalar(v) + "\n")
+	}
+}
+
+// Helper function to render a value on a single line
+func inline(v any) string {
+	switch v := v.(type) {
+	case []field:
+		var parts []string
+		for _, f := range v {
+			if !isEmpty(f.value) {
+				parts = append(parts, strings.ToLower(title(f.key))+" "+inline(f.value))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = inline(item)
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return scalar(v)
+	}
+}
+
+// Helper function to format a scalar value
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			f, err := v.Float64()
+			if err == nil {
+				return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+			}
+		}
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Helper function to check for values not worth showing
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	case []field:
+		return len(v) == 0
+	}
+	return false
+}
+
+// Words shown in capitals in headings
+var acronyms = map[string]bool{"bg": true, "dka": true, "er": true, "iob": true, "cv": true, "sd": true}
+
+// Helper function to turn a snake_case key into a heading
+func title(key string) string {
+	words := strings.Split(key, "_")
+	for i, w := range words {
+		if acronyms[w] {
+			words[i] = strings.ToUpper(w)
+		} else if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Helper function to decode JSON while keeping object field order
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		fields := []field{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field{key: keyTok.(string), value: v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return fields, nil
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+	return tok, nil
+}
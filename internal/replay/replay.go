@@ -0,0 +1,134 @@
+// Package replay records model responses to fixture files and plays them back,
+// so every endpoint can run end to end without network access or model
+// nondeterminism.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Modes
+const (
+	Off    = ""
+	Record = "record"
+	Replay = "replay"
+)
+
+// Fixture Struct
+//
+// One recorded generation, stored as <dir>/<key>.json.
+type Fixture struct {
+	Prompt       string `json:"prompt"`
+	Text         string `json:"text"`
+	InputTokens  int    `json:"input_tokens,omitempty"`
+	OutputTokens int    `json:"output_tokens,omitempty"`
+}
+
+// Active mode and fixture directory, set once at startup
+var (
+	mu   sync.RWMutex
+	mode string
+	dir  string
+)
+
+// Set the mode and fixture directory
+func Configure(m, d string) error {
+	switch m {
+	case Off, Replay:
+	case Record:
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown replay mode %q (use record or replay)", m)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	mode, dir = m, d
+	return nil
+}
+
+// Return the active mode
+func Mode() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return mode
+}
+
+// Run a generation through the active mode: live calls the model directly,
+// record calls it and saves the response, and replay answers from the
+// fixture for the same prompt without calling the model at all.
+func Do(prompt string, live func() (*ai.ModelResponse, error)) (*ai.ModelResponse, error) {
+	mu.RLock()
+	m, d := mode, dir
+	mu.RUnlock()
+
+	path := filepath.Join(d, Key(prompt)+".json")
+	switch m {
+	case Replay:
+		return load(path)
+	case Record:
+		result, err := live()
+		if err != nil {
+			return nil, err
+		}
+		if err := save(path, prompt, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		return live()
+	}
+}
+
+// Fixture key for a prompt
+func Key(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Helper function to build a response from a fixture
+func load(path string) (*ai.ModelResponse, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response %s; run with REPLAY_MODE=record to capture it", filepath.Base(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", filepath.Base(path), err)
+	}
+	return &ai.ModelResponse{
+		Message: ai.NewModelTextMessage(f.Text),
+		Usage:   &ai.GenerationUsage{InputTokens: f.InputTokens, OutputTokens: f.OutputTokens},
+	}, nil
+}
+
+// Helper function to write a response to a fixture
+func save(path, prompt string, result *ai.ModelResponse) error {
+	f := Fixture{Prompt: prompt, Text: result.Text()}
+	if result.Usage != nil {
+		f.InputTokens, f.OutputTokens = result.Usage.InputTokens, result.Usage.OutputTokens
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
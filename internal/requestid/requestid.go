@@ -0,0 +1,47 @@
+// Package requestid carries a correlation ID through a request's context,
+// so logs for flow calls, generations and storage writes can be tied together.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// Header used to propagate and return the request ID
+const Header = "X-Request-ID"
+
+// Context key for the request ID
+type key struct{}
+
+// Incoming IDs are accepted only if they look like an ID
+var valid = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Generate a new request ID
+func New() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Use an incoming ID if it is well-formed, or generate a new one
+func FromHeader(value string) string {
+	if valid.MatchString(value) {
+		return value
+	}
+	return New()
+}
+
+// Return a context carrying the request ID
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, key{}, id)
+}
+
+// Return the request ID from a context, or "-" when there is none
+func From(ctx context.Context) string {
+	if id, ok := ctx.Value(key{}).(string); ok {
+		return id
+	}
+	return "-"
+}
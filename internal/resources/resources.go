@@ -0,0 +1,117 @@
+// Package resources looks up emergency numbers and diabetes support lines by location.
+package resources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hotline Struct
+type Hotline struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Hours string `json:"hours,omitempty"`
+}
+
+// Resources Struct
+type Resources struct {
+	Country         string    `json:"country,omitempty"`
+	EmergencyNumber string    `json:"emergency_number"`
+	AmbulanceNumber string    `json:"ambulance_number,omitempty" jsonschema:"description=Direct ambulance line where it differs from the emergency number"`
+	Hotlines        []Hotline `json:"hotlines,omitempty" jsonschema:"description=Diabetes support lines. These are not for emergencies"`
+	ERGuidance      string    `json:"er_guidance"`
+	Matched         bool      `json:"matched" jsonschema:"description=False when the location was not recognised and generic guidance is returned"`
+}
+
+// Country entry: code, names it goes by, and its numbers
+type country struct {
+	code      string
+	names     []string
+	emergency string
+	ambulance string
+	hotlines  []Hotline
+}
+
+// Numbers for the countries we see most. 112 also reaches emergency services
+// from a mobile phone in most of the world.
+var countries = []country{
+	{"US", []string{"united states", "usa", "america"}, "911", "", []Hotline{{"American Diabetes Association", "1-800-342-2383", "Mon-Fri"}}},
+	{"CA", []string{"canada"}, "911", "", []Hotline{{"Diabetes Canada", "1-800-226-8464", "Mon-Fri"}}},
+	{"MX", []string{"mexico", "méxico"}, "911", "", nil},
+	{"GB", []string{"united kingdom", "uk", "england", "scotland", "wales", "northern ireland", "great britain"}, "999", "", []Hotline{{"Diabetes UK Helpline", "0345 123 2399", "Mon-Fri"}, {"NHS 111 (urgent, not emergency)", "111", "24/7"}}},
+	{"IE", []string{"ireland"}, "112", "", []Hotline{{"Diabetes Ireland", "01 842 8118", "Mon-Fri"}}},
+	{"AU", []string{"australia"}, "000", "", []Hotline{{"NDSS Helpline", "1800 637 700", "Mon-Fri"}}},
+	{"NZ", []string{"new zealand", "aotearoa"}, "111", "", []Hotline{{"Healthline", "0800 611 116", "24/7"}}},
+	{"KE", []string{"kenya"}, "999", "1199", nil},
+	{"TZ", []string{"tanzania"}, "112", "", nil},
+	{"UG", []string{"uganda"}, "112", "", nil},
+	{"RW", []string{"rwanda"}, "112", "912", nil},
+	{"ET", []string{"ethiopia"}, "907", "", nil},
+	{"NG", []string{"nigeria"}, "112", "", nil},
+	{"GH", []string{"ghana"}, "112", "193", nil},
+	{"ZA", []string{"south africa"}, "112", "10177", nil},
+	{"EG", []string{"egypt"}, "123", "123", nil},
+	{"IN", []string{"india"}, "112", "108", nil},
+	{"PK", []string{"pakistan"}, "1122", "115", nil},
+	{"BD", []string{"bangladesh"}, "999", "", nil},
+	{"PH", []string{"philippines"}, "911", "", nil},
+	{"CN", []string{"china"}, "120", "120", nil},
+	{"JP", []string{"japan"}, "119", "119", nil},
+	{"BR", []string{"brazil", "brasil"}, "192", "192", nil},
+	{"DE", []string{"germany", "deutschland"}, "112", "", nil},
+	{"FR", []string{"france"}, "112", "15", nil},
+	{"ES", []string{"spain", "españa"}, "112", "", nil},
+	{"IT", []string{"italy", "italia"}, "112", "118", nil},
+	{"NL", []string{"netherlands", "holland"}, "112", "", nil},
+	{"SE", []string{"sweden", "sverige"}, "112", "", nil},
+	{"AE", []string{"united arab emirates", "uae"}, "999", "998", nil},
+	{"SA", []string{"saudi arabia"}, "911", "997", nil},
+}
+
+// Lookup resources for a location given as a country code ("KE"), a country
+// name ("Kenya"), or a place ending in one ("Nairobi, Kenya").
+// Unknown or empty locations get generic guidance with Matched false.
+func Lookup(location string) Resources {
+	parts := strings.Split(strings.ToLower(location), ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := strings.TrimSpace(parts[i])
+		if part == "" {
+			continue
+		}
+		for _, c := range countries {
+			if strings.EqualFold(part, c.code) || slices.Contains(c.names, part) {
+				return c.resources()
+			}
+		}
+	}
+
+	return Resources{
+		EmergencyNumber: "112",
+		ERGuidance:      "Call your local emergency number, or go to the nearest emergency department now. From a mobile phone, 112 reaches emergency services in most countries. Do not drive yourself.",
+	}
+}
+
+// Helper function to build the resources for a country
+func (c country) resources() Resources {
+	guidance := fmt.Sprintf("Call %s or go to the nearest emergency department now.", c.emergency)
+	if c.ambulance != "" && c.ambulance != c.emergency {
+		guidance = fmt.Sprintf("Call %s (ambulance: %s) or go to the nearest emergency department now.", c.emergency, c.ambulance)
+	}
+	return Resources{
+		Country:         c.code,
+		EmergencyNumber: c.emergency,
+		AmbulanceNumber: c.ambulance,
+		Hotlines:        c.hotlines,
+		ERGuidance:      guidance + " Do not drive yourself. Bring your meter, medications and any recent readings.",
+		Matched:         true,
+	}
+}
+
+// Helper function to render the emergency number as a prompt line
+func (r Resources) PromptSummary() string {
+	if !r.Matched {
+		return "Emergency number: unknown location, say \"call your local emergency number\"."
+	}
+	return fmt.Sprintf("Emergency number for the user's location (%s): %s. Use this number, not 911, unless they are the same.", r.Country, r.EmergencyNumber)
+}
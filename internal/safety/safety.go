@@ -0,0 +1,294 @@
+// Package safety checks generated answers for harmful advice the model should
+// never give, such as extreme fasting or replacing medication with supplements,
+// and regenerates or redacts flagged answers before they are returned.
+package safety
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/requestid"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Filter modes
+const (
+	Off       = "off"
+	Blocklist = "blocklist"
+	Classify  = "classify"
+)
+
+// What to do with a flagged answer
+const (
+	Regenerate = "regenerate"
+	Redact     = "redact"
+)
+
+// Replaces a flagged sentence
+const redactedSentence = "[Removed by the safety filter.]"
+
+// Replaces a flagged answer that can't be redacted sentence by sentence
+const withheld = "This answer was withheld by the safety filter because it may contain unsafe advice. Please ask again, or talk to your care team."
+
+// Config Struct
+type Config struct {
+	Mode          string
+	Action        string
+	BlocklistFile string
+	Model         string
+}
+
+// Rule Struct
+//
+// A blocklist pattern and why it is unsafe.
+type Rule struct {
+	Pattern *regexp.Regexp
+	Reason  string
+}
+
+// Built-in rules. A match in a sentence that negates it ("never stop taking
+// your insulin") is not flagged.
+var builtin = []Rule{
+	{regexp.MustCompile(`(?i)\b(water|dry|juice)[- ]fast(s|ing)?\b[^.!?\n]{0,40}\b([3-9]|\d{2,})\s*(days?|weeks?)\b`), "extreme fasting"},
+	{regexp.MustCompile(`(?i)\bfast(ing)? for ([3-9]|\d{2,}) (or more )?(days|weeks)\b`), "extreme fasting"},
+	{regexp.MustCompile(`(?i)\b([3-9]|\d{2,})[- ](days?|weeks?)[- ](long )?(water |dry |juice )?fast(s|ing)?\b`), "extreme fasting"},
+	{regexp.MustCompile(`(?i)\b(eat|consume|limit yourself to) (less than|under|only|no more than) ([1-7]\d{2}|\d{1,2}) (k?cal|calories)\b`), "extreme calorie restriction"},
+	{regexp.MustCompile(`(?i)\b(stop|quit|skip|discontinue|come off)\w* (taking |using )?(your |all )?(insulin|metformin|diabetes (medications?|medicines?|drugs?))\b`), "stopping diabetes medication"},
+	{regexp.MustCompile(`(?i)\b(cinnamon|berberine|bitter melon|apple cider vinegar|fenugreek|herbs?|herbal remed(y|ies)|supplements?)\b[^.!?\n]{0,40}\b(instead of|in place of|replace|replacing|substitute for)\b[^.!?\n]{0,20}\b(insulin|metformin|medications?|medicines?)\b`), "replacing medication with supplements"},
+	{regexp.MustCompile(`(?i)\b(colloidal silver|chlorine dioxide|miracle mineral|turpentine|kerosene)\b`), "dangerous remedy"},
+	{regexp.MustCompile(`(?i)\bmega-?dos(e|es|ing)\b`), "supplement megadosing"},
+	{regexp.MustCompile(`(?i)\b(skip|reduce|omit|restrict)\w* (your )?insulin\b[^.!?\n]{0,40}\blos(e|ing) weight\b`), "insulin omission for weight loss"},
+	{regexp.MustCompile(`(?i)\bcures? (your |type [12] )?diabetes\b`), "claims to cure diabetes"},
+}
+
+// Words that negate a flagged phrase earlier in its sentence
+var negation = regexp.MustCompile(`(?i)\b(never|not|don't|do not|doesn't|avoid|shouldn't|should not|without (first )?talking)\b`)
+
+// Active configuration and the rules in force
+var (
+	mu     sync.RWMutex
+	config = Config{Mode: Off}
+	rules  []Rule
+)
+
+// Runs a generation with a prompt
+type Generator func(ctx context.Context, prompt string) (*ai.ModelResponse, error)
+
+// Set the filter mode, the action for flagged answers and an optional file of
+// extra blocklist patterns, one regular expression per line ("#" comments).
+func Configure(cfg Config) error {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = Blocklist
+	case Off, Blocklist, Classify:
+	default:
+		return fmt.Errorf("safety filter must be off, blocklist or classify")
+	}
+	switch cfg.Action {
+	case "":
+		cfg.Action = Regenerate
+	case Regenerate, Redact:
+	default:
+		return fmt.Errorf("safety action must be regenerate or redact")
+	}
+
+	loaded := append([]Rule(nil), builtin...)
+	if cfg.BlocklistFile != "" {
+		extra, err := readBlocklist(cfg.BlocklistFile)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, extra...)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	config, rules = cfg, loaded
+	return nil
+}
+
+// Helper function to read blocklist patterns from a file
+func readBlocklist(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open safety blocklist: %w", err)
+	}
+	defer f.Close()
+
+	var out []Rule
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + line)
+		if err != nil {
+			return nil, fmt.Errorf("safety blocklist line %d: %w", n, err)
+		}
+		out = append(out, Rule{Pattern: re, Reason: "blocklisted: " + line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read safety blocklist: %w", err)
+	}
+	return out, nil
+}
+
+// Model the classifier runs on, or "" for the default model
+func Model() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.Model
+}
+
+// Report whether answers are being checked
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.Mode != Off
+}
+
+// Match Struct
+//
+// A flagged span of an answer.
+type Match struct {
+	Start, End int
+	Reason     string
+}
+
+// Find the blocklisted phrases in an answer, skipping negated ones
+func Scan(text string) []Match {
+	mu.RLock()
+	active := rules
+	mu.RUnlock()
+
+	var out []Match
+	for _, r := range active {
+		for _, loc := range r.Pattern.FindAllStringIndex(text, -1) {
+			start, _ := sentence(text, loc[0])
+			if negation.MatchString(text[start:loc[0]]) {
+				continue
+			}
+			out = append(out, Match{Start: loc[0], End: loc[1], Reason: r.Reason})
+		}
+	}
+	return out
+}
+
+// Check an answer and return it, or a safe replacement. A flagged answer is
+// regenerated once with a warning when the action is regenerate, and redacted
+// if it is still flagged. With classify, answers the blocklist passes are also
+// checked by the model; if that check fails, the answer is kept.
+func Filter(ctx context.Context, flow, prompt string, result *ai.ModelResponse, regenerate, classify Generator) *ai.ModelResponse {
+	mu.RLock()
+	cfg := config
+	mu.RUnlock()
+	if cfg.Mode == Off {
+		return result
+	}
+
+	reason, matches := check(ctx, cfg, result.Text(), classify)
+	if reason == "" {
+		return result
+	}
+	log.Printf("safety filter flagged flow=%s reason=%q request_id=%s", flow, reason, requestid.From(ctx))
+
+	if cfg.Action == Regenerate {
+		retry, err := regenerate(ctx, prompt+"\n\nIMPORTANT: A previous answer to this was withheld because it included unsafe advice ("+reason+"). Do not suggest extreme fasting, stopping or replacing prescribed medication, or unproven remedies. Refer those questions to the care team.")
+		if err != nil {
+			log.Printf("safety filter regeneration failed flow=%s request_id=%s: %v", flow, requestid.From(ctx), err)
+		} else if reason, matches = check(ctx, cfg, retry.Text(), classify); reason == "" {
+			log.Printf("safety filter regenerated flow=%s request_id=%s", flow, requestid.From(ctx))
+			return retry
+		} else {
+			result = retry
+		}
+	}
+
+	log.Printf("safety filter redacted flow=%s reason=%q request_id=%s", flow, reason, requestid.From(ctx))
+	redacted := *result
+	redacted.Message = ai.NewModelTextMessage(redact(result.Text(), matches))
+	return &redacted
+}
+
+// Helper function to check an answer against the blocklist, then the
+// classifier. Returns why it was flagged, or "" if it wasn't.
+func check(ctx context.Context, cfg Config, text string, classify Generator) (string, []Match) {
+	if matches := Scan(text); len(matches) > 0 {
+		return matches[0].Reason, matches
+	}
+	if cfg.Mode != Classify || classify == nil {
+		return "", nil
+	}
+	resp, err := classify(ctx, fmt.Sprintf(prompts.Get("safetyClassifier"), text))
+	if err != nil {
+		log.Printf("safety classifier failed, answer kept request_id=%s: %v", requestid.From(ctx), err)
+		return "", nil
+	}
+	verdict := strings.TrimSpace(resp.Text())
+	if rest, ok := strings.CutPrefix(strings.ToUpper(verdict), "UNSAFE"); ok {
+		reason := strings.TrimSpace(strings.TrimLeft(verdict[len(verdict)-len(rest):], ":- "))
+		if reason == "" {
+			reason = "flagged by classifier"
+		}
+		return reason, nil
+	}
+	return "", nil
+}
+
+// Helper function to replace the sentences holding each match, or the whole
+// answer when there are none to point at
+func redact(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return withheld
+	}
+	type span struct{ start, end int }
+	var spans []span
+	for _, m := range matches {
+		start, end := sentence(text, m.Start)
+		spans = append(spans, span{start, max(end, m.End)})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			// Another match in a sentence already removed
+			last = max(last, s.end)
+			continue
+		}
+		b.WriteString(text[last:s.start])
+		b.WriteString(redactedSentence)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Helper function to find the sentence around an offset: from after the
+// previous sentence end or line break to the next one, inclusive
+func sentence(text string, at int) (int, int) {
+	start := strings.LastIndexAny(text[:at], ".!?\n") + 1
+	for start < at && text[start] == ' ' {
+		start++
+	}
+	end := strings.IndexAny(text[at:], ".!?\n")
+	if end < 0 {
+		return start, len(text)
+	}
+	end += at
+	if text[end] != '\n' {
+		end++
+	}
+	return start, end
+}
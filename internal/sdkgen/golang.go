@@ -0,0 +1,226 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// Module of the generated Go client
+const goModule = `module github.com/Narokwe/diabeticai-advisor/sdk/go
+
+go 1.22
+`
+
+// Helper struct to collect the Go types a schema needs
+type goTypes struct {
+	decls   bytes.Buffer
+	usesTim bool
+}
+
+// Generate the Go client: a type for each flow's input and output, and a
+// method per flow on Client
+func Go(spec *Spec) ([]byte, error) {
+	var t goTypes
+	for _, op := range spec.Operations {
+		t.named(op.Input, spec.Schemas[op.Input])
+		t.named(op.Output, spec.Schemas[op.Output])
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n\n", generatedHeader)
+	fmt.Fprintf(&b, "// Package advisor is a typed client for the DiabetesAI Advisor API (%s).\n", spec.Version)
+	b.WriteString("package advisor\n\nimport (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"net/http\"\n\t\"strings\"\n")
+	if t.usesTim {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString(")\n")
+	b.WriteString(goClient)
+
+	for _, op := range spec.Operations {
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "\n// %s: %s\n", methodName(op.Name), oneLine(op.Summary))
+		} else {
+			fmt.Fprintf(&b, "\n// %s runs the %s flow\n", methodName(op.Name), op.Name)
+		}
+		fmt.Fprintf(&b, "func (c *Client) %s(ctx context.Context, in %s) (*%s, error) {\n", methodName(op.Name), op.Input, op.Output)
+		fmt.Fprintf(&b, "\tvar out %s\n", op.Output)
+		fmt.Fprintf(&b, "\tif err := c.call(ctx, %q, %q, in, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n", op.Method, op.Path)
+	}
+	b.Write(t.decls.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated Go client: %w", err)
+	}
+	return src, nil
+}
+
+// Helper function to declare a named type for a schema
+func (t *goTypes) named(name string, s *Schema) {
+	if s == nil {
+		s = &Schema{}
+	}
+	if s.kind() != "object" || len(s.Properties) == 0 {
+		fmt.Fprintf(&t.decls, "\n// %s is a flow's %s\ntype %s = %s\n", name, name, name, t.typeOf(name, s, true))
+		return
+	}
+
+	var fields bytes.Buffer
+	for _, prop := range s.propertyNames() {
+		ps := s.Properties[prop]
+		required := s.requires(prop)
+		field := exportedName(prop)
+		typ := t.typeOf(name+field, ps, required)
+		if ps.Description != "" {
+			fmt.Fprintf(&fields, "\t// %s\n", oneLine(ps.Description))
+		}
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&fields, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	doc := oneLine(s.Description)
+	if doc == "" {
+		doc = "is part of the API's request and response bodies"
+	}
+	fmt.Fprintf(&t.decls, "\n// %s %s\ntype %s struct {\n%s}\n", name, doc, name, fields.String())
+}
+
+// Helper function to map a schema to a Go type, declaring nested objects as
+// named types. Optional times are pointers so they can be left out.
+func (t *goTypes) typeOf(name string, s *Schema, required bool) string {
+	if s == nil {
+		return "json.RawMessage"
+	}
+	if s.Ref != "" {
+		return refName(s.Ref)
+	}
+	switch s.kind() {
+	case "string":
+		if s.Format == "date-time" {
+			t.usesTim = true
+			if !required {
+				return "*time.Time"
+			}
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + t.typeOf(singular(name)+"Item", s.Items, true)
+	case "object":
+		if len(s.Properties) > 0 {
+			t.named(name, s)
+			if !required {
+				return "*" + name
+			}
+			return name
+		}
+		if v := s.values(); v != nil {
+			return "map[string]" + t.typeOf(name+"Value", v, true)
+		}
+		return "map[string]any"
+	}
+	return "json.RawMessage"
+}
+
+// Helper function to name one element of a list field, such as Dishes or Entries
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "shes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+// Client, envelope and error handling shared by every method
+const goClient = `
+// Client Struct
+//
+// Calls the flow endpoints with an API key. The zero HTTPClient uses http.DefaultClient.
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Create a client for a server, such as https://advisor.example.com
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{BaseURL: baseURL, APIKey: apiKey}
+}
+
+// Error Struct
+//
+// The error envelope every endpoint returns. Message is safe to show to users.
+type Error struct {
+	Status            int    ` + "`json:\"-\"`" + `
+	Code              string ` + "`json:\"code\"`" + `
+	Message           string ` + "`json:\"message\"`" + `
+	Retryable         bool   ` + "`json:\"retryable\"`" + `
+	RetryAfterSeconds int    ` + "`json:\"retry_after_seconds,omitempty\"`" + `
+	RequestID         string ` + "`json:\"request_id,omitempty\"`" + `
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s, request %s)", e.Message, e.Status, e.Code, e.RequestID)
+}
+
+// Helper function to send a flow request in its {"data": ...} envelope and
+// decode the {"result": ...} reply, or the error envelope
+func (c *Client) call(ctx context.Context, method, path string, in, out any) error {
+	body, err := json.Marshal(map[string]any{"data": in})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.BaseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var env struct {
+			Error *Error ` + "`json:\"error\"`" + `
+		}
+		if json.NewDecoder(resp.Body).Decode(&env) != nil || env.Error == nil {
+			env.Error = &Error{Code: "internal", Message: resp.Status}
+		}
+		env.Error.Status = resp.StatusCode
+		return env.Error
+	}
+	var env struct {
+		Result json.RawMessage ` + "`json:\"result\"`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(env.Result, out); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+`
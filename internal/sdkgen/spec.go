@@ -0,0 +1,219 @@
+// Package sdkgen generates typed Go and TypeScript clients for the flow
+// endpoints from the server's OpenAPI description, so integrators don't
+// hand-write request envelopes.
+package sdkgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Header on every generated file
+const generatedHeader = "Code generated by diabeticai-advisor --sdk. DO NOT EDIT."
+
+// Schema Struct
+//
+// The subset of JSON Schema used by flow inputs and outputs.
+type Schema struct {
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+}
+
+// Return the schema's type, ignoring "null" in a list of types
+func (s *Schema) kind() string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// Return the schema of a map's values, or nil if the object isn't a map
+func (s *Schema) values() *Schema {
+	if len(s.AdditionalProperties) == 0 || string(s.AdditionalProperties) == "false" {
+		return nil
+	}
+	var v Schema
+	if json.Unmarshal(s.AdditionalProperties, &v) != nil {
+		return &Schema{}
+	}
+	return &v
+}
+
+// Report whether a property is required
+func (s *Schema) requires(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the schema's property names in a stable order
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Operation Struct
+//
+// One flow endpoint.
+type Operation struct {
+	Name    string
+	Method  string
+	Path    string
+	Summary string
+	Input   string
+	Output  string
+}
+
+// Spec Struct
+type Spec struct {
+	Version    string
+	Operations []Operation
+	Schemas    map[string]*Schema
+}
+
+// Parse an OpenAPI document served at /v1/openapi.json
+func Parse(doc []byte) (*Spec, error) {
+	type media struct {
+		Schema struct {
+			Properties map[string]Schema `json:"properties"`
+		} `json:"schema"`
+	}
+	var raw struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Summary     string `json:"summary"`
+			RequestBody struct {
+				Content map[string]media `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]media `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]*Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	spec := &Spec{Version: raw.Info.Version, Schemas: raw.Components.Schemas}
+	for path, methods := range raw.Paths {
+		for method, op := range methods {
+			in := op.RequestBody.Content["application/json"].Schema.Properties["data"].Ref
+			out := op.Responses["200"].Content["application/json"].Schema.Properties["result"].Ref
+			if op.OperationID == "" || in == "" || out == "" {
+				return nil, fmt.Errorf("%s %s: missing operationId or data/result schema", method, path)
+			}
+			spec.Operations = append(spec.Operations, Operation{
+				Name:    op.OperationID,
+				Method:  strings.ToUpper(method),
+				Path:    path,
+				Summary: op.Summary,
+				Input:   refName(in),
+				Output:  refName(out),
+			})
+		}
+	}
+	sort.Slice(spec.Operations, func(i, j int) bool { return spec.Operations[i].Name < spec.Operations[j].Name })
+	return spec, nil
+}
+
+// Generate both clients from an OpenAPI document into dir/go and dir/typescript
+func Write(dir string, doc []byte) error {
+	spec, err := Parse(doc)
+	if err != nil {
+		return err
+	}
+	goSrc, err := Go(spec)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		"openapi.json":             doc,
+		"go/go.mod":                []byte(goModule),
+		"go/advisor.go":            goSrc,
+		"typescript/package.json":  []byte(tsPackage),
+		"typescript/tsconfig.json": []byte(tsConfig),
+		"typescript/src/index.ts":  TypeScript(spec),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Helper function to take the schema name from a reference
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// Abbreviations kept upper case in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "api": true, "bg": true, "bpm": true, "icd": true, "iob": true}
+
+// Helper function to turn a JSON name such as user_id or drugClass into UserID or DrugClass
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' || r == '.' }) {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// Helper function to turn a flow name such as hypoRisk into a method name, HypoRisk
+func methodName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// Helper function to fold a description onto one comment line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
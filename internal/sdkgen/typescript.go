@@ -0,0 +1,224 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Package manifest of the generated TypeScript client
+const tsPackage = `{
+  "name": "@diabeticai/advisor-client",
+  "version": "1.0.0",
+  "description": "Typed client for the DiabetesAI Advisor flow endpoints. Generated; do not edit.",
+  "type": "module",
+  "main": "dist/index.js",
+  "types": "dist/index.d.ts",
+  "files": ["dist"],
+  "scripts": {
+    "build": "tsc"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.0"
+  }
+}
+`
+
+// Compiler settings of the generated TypeScript client
+const tsConfig = `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "ES2020",
+    "moduleResolution": "node",
+    "lib": ["ES2020", "DOM"],
+    "declaration": true,
+    "strict": true,
+    "outDir": "dist",
+    "rootDir": "src"
+  },
+  "include": ["src"]
+}
+`
+
+// Generate the TypeScript client: an interface for each flow's input and
+// output, and a method per flow on AdvisorClient
+func TypeScript(spec *Spec) []byte {
+	var types bytes.Buffer
+	for _, op := range spec.Operations {
+		tsNamed(&types, op.Input, spec.Schemas[op.Input])
+		tsNamed(&types, op.Output, spec.Schemas[op.Output])
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n\n", generatedHeader)
+	fmt.Fprintf(&b, "// Typed client for the DiabetesAI Advisor API (%s).\n", spec.Version)
+	b.WriteString(tsClientHead)
+	for _, op := range spec.Operations {
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "\n  /** %s */\n", oneLine(op.Summary))
+		}
+		fmt.Fprintf(&b, "  %s(input: %s, init?: RequestInit): Promise<%s> {\n", lowerFirst(op.Name), op.Input, op.Output)
+		fmt.Fprintf(&b, "    return this.call(%q, %q, input, init);\n  }\n", op.Method, op.Path)
+	}
+	b.WriteString("}\n")
+	b.Write(types.Bytes())
+	return b.Bytes()
+}
+
+// Helper function to declare a named interface, or an alias for schemas that
+// aren't plain objects
+func tsNamed(b *bytes.Buffer, name string, s *Schema) {
+	if s == nil {
+		s = &Schema{}
+	}
+	if doc := oneLine(s.Description); doc != "" {
+		fmt.Fprintf(b, "\n/** %s */", doc)
+	}
+	if s.kind() != "object" || len(s.Properties) == 0 {
+		fmt.Fprintf(b, "\nexport type %s = %s;\n", name, tsType(s, "  "))
+		return
+	}
+	fmt.Fprintf(b, "\nexport interface %s %s\n", name, tsObject(s, ""))
+}
+
+// Helper function to write an object type's fields at an indent
+func tsObject(s *Schema, indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, prop := range s.propertyNames() {
+		ps := s.Properties[prop]
+		if doc := oneLine(ps.Description); doc != "" {
+			fmt.Fprintf(&b, "%s  /** %s */\n", indent, doc)
+		}
+		optional := "?"
+		if s.requires(prop) {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, tsKey(prop), optional, tsType(ps, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// Helper function to map a schema to a TypeScript type. Times are ISO 8601 strings.
+func tsType(s *Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return refName(s.Ref)
+	}
+	switch s.kind() {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(s.Items, indent)
+		if strings.ContainsAny(item, " |") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(s.Properties) > 0 {
+			return tsObject(s, indent)
+		}
+		if v := s.values(); v != nil {
+			return "Record<string, " + tsType(v, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+// Helper function to quote property names that aren't identifiers
+func tsKey(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
+
+// Helper function to lower-case a name's first letter
+func lowerFirst(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// Client, envelope and error handling shared by every method
+const tsClientHead = `
+export interface AdvisorClientOptions {
+  /** Server URL, such as https://advisor.example.com */
+  baseUrl: string;
+  /** Sent as the X-API-Key header */
+  apiKey?: string;
+  /** Defaults to the global fetch */
+  fetch?: typeof fetch;
+}
+
+/** The error envelope every endpoint returns. message is safe to show to users. */
+export interface ErrorBody {
+  code: string;
+  message: string;
+  retryable: boolean;
+  retry_after_seconds?: number;
+  request_id?: string;
+}
+
+export class AdvisorError extends Error {
+  readonly status: number;
+  readonly code: string;
+  readonly retryable: boolean;
+  readonly retryAfterSeconds?: number;
+  readonly requestId?: string;
+
+  constructor(status: number, body: ErrorBody) {
+    super(body.message);
+    this.name = "AdvisorError";
+    this.status = status;
+    this.code = body.code;
+    this.retryable = body.retryable;
+    this.retryAfterSeconds = body.retry_after_seconds;
+    this.requestId = body.request_id;
+  }
+}
+
+export class AdvisorClient {
+  private readonly baseUrl: string;
+  private readonly apiKey?: string;
+  private readonly fetchFn: typeof fetch;
+
+  constructor(options: AdvisorClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.apiKey = options.apiKey;
+    this.fetchFn = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  /** Sends a flow request in its {"data": ...} envelope and unwraps the {"result": ...} reply */
+  private async call<I, O>(method: string, path: string, input: I, init?: RequestInit): Promise<O> {
+    const headers: Record<string, string> = {
+      "Content-Type": "application/json",
+      Accept: "application/json",
+    };
+    if (this.apiKey) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    const resp = await this.fetchFn(this.baseUrl + path, {
+      ...init,
+      method,
+      headers: { ...headers, ...(init?.headers as Record<string, string> | undefined) },
+      body: JSON.stringify({ data: input }),
+    });
+    const body = await resp.json().catch(() => undefined);
+    if (!resp.ok) {
+      throw new AdvisorError(resp.status, body?.error ?? { code: "internal", message: resp.statusText, retryable: false });
+    }
+    return body.result as O;
+  }
+`
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/requestid"
+
+	"github.com/firebase/genkit/go/core/api"
+)
+
+// Registered Flow Struct
+type FlowInfo struct {
+	Name          string `json:"name"`
+	Route         string `json:"route"`
+	Enabled       bool   `json:"enabled"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// Registry of flows served over HTTP, with runtime on/off switches
+type FlowRegistry struct {
+	mu       sync.RWMutex
+	flows    []FlowInfo
+	actions  map[string]api.Action
+	disabled map[string]bool
+}
+
+// Create an empty flow registry
+func NewFlowRegistry() *FlowRegistry {
+	return &FlowRegistry{actions: make(map[string]api.Action), disabled: make(map[string]bool)}
+}
+
+// Register a flow and return its handler, which refuses requests while the flow is disabled
+func (fr *FlowRegistry) Handler(route string, flow api.Action) http.Handler {
+	name := flow.Name()
+
+	fr.mu.Lock()
+	fr.flows = append(fr.flows, FlowInfo{Name: name, Route: route, PromptVersion: prompts.Version(name)})
+	fr.actions[name] = flow
+	fr.mu.Unlock()
+
+	h := flowHandler(flow)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !fr.Enabled(name) {
+			WriteError(w, r, "this feature is temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(costs.WithUser(r.Context(), flowUserID(r)))
+		if dryRunRequested(r) {
+			serveDryRun(sw, r, name, h)
+		} else {
+			h(sw, r)
+		}
+		log.Printf("flow=%s status=%d duration=%s request_id=%s", name, sw.status, time.Since(start).Round(time.Millisecond), requestid.From(r.Context()))
+	})
+}
+
+// Return a registered flow by name
+func (fr *FlowRegistry) Action(name string) (api.Action, bool) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	flow, ok := fr.actions[name]
+	return flow, ok
+}
+
+// Check whether a flow is enabled
+func (fr *FlowRegistry) Enabled(name string) bool {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	return !fr.disabled[name]
+}
+
+// Enable or disable a flow, reporting whether it exists
+func (fr *FlowRegistry) SetEnabled(name string, enabled bool) bool {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	for _, f := range fr.flows {
+		if f.Name == name {
+			fr.disabled[name] = !enabled
+			return true
+		}
+	}
+	return false
+}
+
+// List registered flows in registration order
+func (fr *FlowRegistry) List() []FlowInfo {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	out := make([]FlowInfo, len(fr.flows))
+	for i, f := range fr.flows {
+		f.Enabled = !fr.disabled[f.Name]
+		out[i] = f
+	}
+	return out
+}
+
+// Wrap an admin handler with authentication: the admin bearer token, or a
+// client API key with the admin role. Admin endpoints are disabled entirely
+// when no admin key is configured.
+func requireAdmin(adminKey string, keys *APIKeyStore, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminKey == "" {
+			WriteError(w, r, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(adminKey)) == 1 {
+			next(w, r)
+			return
+		}
+		if key, ok := keys.Lookup(apiKeyFromRequest(r)); ok {
+			if key.Role != rbac.Admin {
+				WriteError(w, r, "admin role required", http.StatusForbidden)
+				return
+			}
+			next(w, r.WithContext(rbac.With(r.Context(), rbac.Principal{KeyID: key.ID, Role: key.Role, UserID: key.UserID})))
+			return
+		}
+		WriteError(w, r, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// Handler to list registered flows
+func adminListFlowsHandler(flows *FlowRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, flows.List())
+	}
+}
+
+// Handler to toggle a flow on or off
+func adminToggleFlowHandler(flows *FlowRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, r, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !flows.SetEnabled(r.PathValue("name"), req.Enabled) {
+			WriteError(w, r, "flow not found", http.StatusNotFound)
+			return
+		}
+		WriteJSON(w, http.StatusOK, flows.List())
+	}
+}
+
+// Handler to show request metrics per route
+func adminMetricsHandler(metrics *Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, metrics.List())
+	}
+}
+
+// Handler to show current prompt versions
+func adminPromptsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		versions := make(map[string]string, len(prompts.Templates))
+		for name := range prompts.Templates {
+			versions[name] = prompts.Version(name)
+		}
+		WriteJSON(w, http.StatusOK, versions)
+	}
+}
+
+// Handler to list client API keys
+func adminListKeysHandler(keys *APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, keys.List())
+	}
+}
+
+// Handler to issue a new client API key for a role and optional user, optionally revoking an old one.
+// The new key's secret is only ever returned here.
+func adminRotateKeyHandler(keys *APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Revoke string `json:"revoke"`
+			Role   string `json:"role"`
+			UserID string `json:"user_id"`
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, r, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if req.Role != "" && !rbac.Valid(req.Role) {
+			WriteError(w, r, "role must be one of "+strings.Join(rbac.Roles, ", "), http.StatusBadRequest)
+			return
+		}
+		if req.UserID == "" && rbac.NeedsUser(req.Role) {
+			WriteError(w, r, req.Role+" keys need a user_id", http.StatusBadRequest)
+			return
+		}
+
+		key, ok := keys.Rotate(req.Revoke, req.Role, req.UserID)
+		if !ok {
+			WriteError(w, r, "key to revoke not found", http.StatusNotFound)
+			return
+		}
+		WriteJSON(w, http.StatusCreated, key)
+	}
+}
+
+// Handler to revoke a client API key
+func adminRevokeKeyHandler(keys *APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !keys.Revoke(r.PathValue("id")) {
+			WriteError(w, r, "key not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Handler to view or change the rate limit
+func adminRateLimitHandler(limiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var req struct {
+				PerMinute int `json:"per_minute"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PerMinute < 0 {
+				WriteError(w, r, "per_minute must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			limiter.SetLimit(req.PerMinute)
+		}
+		WriteJSON(w, http.StatusOK, map[string]int{"per_minute": limiter.Limit()})
+	}
+}
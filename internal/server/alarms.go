@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"diabeticai-advisor/internal/alarms"
+	"diabeticai-advisor/internal/store"
+)
+
+// Snooze used when a request names no duration
+const defaultSnooze = 30 * time.Minute
+
+// Handler to return the requester's alarm rules and emergency contacts
+func getAlarmSettingsHandler(rules *store.AlarmRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, rules.Get(userIDFromRequest(r)))
+	}
+}
+
+// Handler to replace the requester's alarm rules and emergency contacts
+func putAlarmSettingsHandler(rules *store.AlarmRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var a store.AlarmSettings
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			WriteError(w, r, "invalid alarm settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.Validate(); err != nil {
+			WriteError(w, r, "invalid alarm settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		a.UserID = userIDFromRequest(r)
+		rules.Set(a)
+		WriteJSON(w, http.StatusOK, rules.Get(a.UserID))
+	}
+}
+
+// Handler to list the requester's firing alarms
+func listAlarmsHandler(active *store.AlarmStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, active.Active(userIDFromRequest(r)))
+	}
+}
+
+// Handler to acknowledge an alarm, silencing it until readings recover
+func acknowledgeAlarmHandler(active *store.AlarmStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		alarm, ok := active.Acknowledge(userIDFromRequest(r), r.PathValue("id"), time.Now())
+		if !ok {
+			WriteError(w, r, "alarm not found; it may have cleared", http.StatusNotFound)
+			return
+		}
+		WriteJSON(w, http.StatusOK, alarm)
+	}
+}
+
+// Handler to snooze an alarm for {"minutes": n}, 30 by default
+func snoozeAlarmHandler(active *store.AlarmStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Minutes int `json:"minutes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, r, "invalid snooze: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		snooze := defaultSnooze
+		if req.Minutes != 0 {
+			snooze = time.Duration(req.Minutes) * time.Minute
+		}
+		if snooze < time.Minute || snooze > alarms.MaxSnooze {
+			WriteError(w, r, "minutes must be between 1 and 240", http.StatusBadRequest)
+			return
+		}
+
+		alarm, ok := active.Snooze(userIDFromRequest(r), r.PathValue("id"), time.Now().Add(snooze))
+		if !ok {
+			WriteError(w, r, "alarm not found; it may have cleared", http.StatusNotFound)
+			return
+		}
+		WriteJSON(w, http.StatusOK, alarm)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/locale"
+)
+
+// Largest request body considered for caching
+const maxCachedRequest = 64 << 10
+
+// Cache of flow responses in the shared store, for flows whose output depends only on their input
+type ResponseCache struct {
+	store kv.Store
+	ttl   time.Duration
+}
+
+// Create a response cache. A ttl of 0 disables caching.
+func NewResponseCache(store kv.Store, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{store: store, ttl: ttl}
+}
+
+// Serve repeated requests with an identical body from the cache.
+// Only successful JSON responses are stored; X-Cache reports HIT or MISS.
+func (c *ResponseCache) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c == nil || c.ttl <= 0 || r.Method != http.MethodPost || r.URL.RawQuery != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxCachedRequest+1))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if err != nil || len(body) > maxCachedRequest {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Generated text follows the requester's units and formats, so they are part of the key
+			p := locale.From(r.Context())
+			sum := sha256.Sum256(append(body, fmt.Sprintf("|%s|%s|%t|%s", p.Locale, p.Units, p.Clock24, p.Decimal)...))
+			key := "response:" + r.URL.Path + ":" + hex.EncodeToString(sum[:])
+			if cached, err := c.store.Get(r.Context(), key); err == nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(http.StatusOK)
+				w.Write(cached)
+				return
+			}
+
+			buf := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+			if buf.status == http.StatusOK {
+				if err := c.store.Set(r.Context(), key, buf.body.Bytes(), c.ttl); err != nil {
+					log.Printf("Failed to cache response: %v", err)
+				}
+			}
+
+			for k, v := range buf.header {
+				w.Header()[k] = v
+			}
+			w.Header().Set("X-Cache", "MISS")
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/careteam"
+)
+
+// Largest slash command payload accepted
+const maxCommandBytes = 64 << 10
+
+// Discord interaction and response types
+const (
+	discordPing            = 1
+	discordCommand         = 2
+	discordPong            = 1
+	discordMessage         = 4
+	discordEphemeral       = 64
+	discordCommandArgument = "query"
+)
+
+// Register the care team slash command endpoints. They are signed by Slack
+// or Discord instead of carrying an API key, so they sit outside the middleware chain.
+func RegisterCareTeam(m *Mux, bot *careteam.Bot) {
+	if bot.SlackKey != "" {
+		m.Handle("POST /careteam/slack", slackCommandHandler(bot))
+	}
+	if bot.DiscordKey != "" {
+		m.Handle("POST /careteam/discord", discordCommandHandler(bot))
+	}
+}
+
+// Helper function to read a signed request body
+func readSigned(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandBytes))
+	if err != nil {
+		WriteError(w, r, "invalid request body", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// Handler to answer a Slack slash command, visible only to the staff member who ran it
+func slackCommandHandler(bot *careteam.Bot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := readSigned(w, r)
+		if !ok {
+			return
+		}
+		if err := careteam.VerifySlack(bot.SlackKey, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()); err != nil {
+			WriteError(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			WriteError(w, r, "invalid slash command", http.StatusBadRequest)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, map[string]string{
+			"response_type": "ephemeral",
+			"text":          bot.Answer(careteam.Slack, form.Get("user_id"), form.Get("text"), time.Now()),
+		})
+	}
+}
+
+// Discord Interaction Struct
+type discordInteraction struct {
+	Type int `json:"type"`
+	Data struct {
+		Options []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+	Member struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"member"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+}
+
+// Handler to answer Discord interactions: pings, and the slash command with its query option
+func discordCommandHandler(bot *careteam.Bot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := readSigned(w, r)
+		if !ok {
+			return
+		}
+		if err := careteam.VerifyDiscord(bot.DiscordKey, r.Header.Get("X-Signature-Timestamp"), r.Header.Get("X-Signature-Ed25519"), body); err != nil {
+			WriteError(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var in discordInteraction
+		if err := json.Unmarshal(body, &in); err != nil {
+			WriteError(w, r, "invalid interaction", http.StatusBadRequest)
+			return
+		}
+
+		switch in.Type {
+		case discordPing:
+			WriteJSON(w, http.StatusOK, map[string]int{"type": discordPong})
+		case discordCommand:
+			var query []string
+			for _, opt := range in.Data.Options {
+				if s, ok := opt.Value.(string); ok && opt.Name == discordCommandArgument {
+					query = append(query, s)
+				}
+			}
+			// Guild interactions name the user under member, direct messages under user
+			userID := in.Member.User.ID
+			if userID == "" {
+				userID = in.User.ID
+			}
+			WriteJSON(w, http.StatusOK, map[string]any{
+				"type": discordMessage,
+				"data": map[string]any{
+					"content": bot.Answer(careteam.Discord, userID, strings.Join(query, " "), time.Now()),
+					"flags":   discordEphemeral,
+				},
+			})
+		default:
+			WriteError(w, r, "unsupported interaction type", http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Responses smaller than this are sent uncompressed
+const compressMinBytes = 1024
+
+// Gzip responses for clients that accept it. Small responses, server-sent
+// events and responses that are already encoded are sent as they are.
+func Compress() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipWriter{ResponseWriter: w, status: http.StatusOK}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// Helper function to check an Accept-Encoding header for gzip with a non-zero weight
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// ResponseWriter that holds the start of a response until it knows whether
+// to compress it: once compressMinBytes are written, on Flush, or at the end
+type gzipWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (gw *gzipWriter) WriteHeader(status int) {
+	gw.status = status
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if gw.decided {
+		if gw.gz != nil {
+			return gw.gz.Write(b)
+		}
+		return gw.ResponseWriter.Write(b)
+	}
+	n, _ := gw.buf.Write(b)
+	if gw.buf.Len() >= compressMinBytes {
+		if err := gw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Helper function to start the response, compressed when it is worth it,
+// and write out what has been held back
+func (gw *gzipWriter) decide(large bool) error {
+	gw.decided = true
+	h := gw.Header()
+	contentType := h.Get("Content-Type")
+	if large && h.Get("Content-Encoding") == "" && !strings.HasPrefix(contentType, "text/event-stream") &&
+		gw.status != http.StatusNoContent && gw.status != http.StatusNotModified {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+	if gw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(gw.buf.Bytes())
+	} else {
+		_, err = gw.ResponseWriter.Write(gw.buf.Bytes())
+	}
+	gw.buf.Reset()
+	return err
+}
+
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		// A handler that flushes is streaming, so compress unless it is an event stream
+		gw.decide(true)
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Finish the response, writing anything still held back
+func (gw *gzipWriter) Close() error {
+	if !gw.decided {
+		gw.decide(false)
+	}
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+func (gw *gzipWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed console.html
+var consoleHTML string
+
+// Console page, listing the flows with example payloads to edit and send
+var consoleTemplate = template.Must(template.New("console").Parse(consoleHTML))
+
+// Console flow entry
+type consoleFlow struct {
+	Name        string `json:"name"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+}
+
+// Register the try-it console at GET /console. Only for dev mode: the page
+// is served without authentication, and requests it sends use the API key
+// typed into it.
+func RegisterConsole(m *Mux) {
+	m.Handle("GET /console", consoleHandler(m))
+}
+
+// Handler to serve the console page
+func consoleHandler(m *Mux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := consoleTemplate.Execute(w, map[string]any{"Version": APIVersion, "Flows": consoleFlows(m)}); err != nil {
+			log.Printf("console render failed: %v", err)
+		}
+	}
+}
+
+// Helper function to list the flow endpoints with an example input generated from each input schema
+func consoleFlows(m *Mux) []consoleFlow {
+	descriptions := map[string]string{}
+	for _, r := range m.routes {
+		descriptions[r.Pattern] = r.Description
+	}
+
+	out := []consoleFlow{}
+	for _, info := range m.Flows.List() {
+		flow, ok := m.Flows.Action(info.Name)
+		if !ok {
+			continue
+		}
+		method, path, _ := strings.Cut(info.Route, " ")
+		example, _ := json.MarshalIndent(exampleValue(flow.Desc().InputSchema), "", "  ")
+		out = append(out, consoleFlow{
+			Name:        info.Name,
+			Method:      method,
+			Path:        path,
+			Description: descriptions[info.Route],
+			Example:     string(example),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Example value named in a description, as in "e.g. Kenya or GB" or "Trend: rising"
+var (
+	exampleAfterEG    = regexp.MustCompile(`e\.g\. (?:"([^"]+)"|([\w/.-]+))`)
+	exampleAfterColon = regexp.MustCompile(`^[^:]+: ([\w-]+)$`)
+)
+
+// Helper function to build an example for a JSON schema: the first enum
+// value or the default if there is one, a value the description suggests,
+// the current time for timestamps, otherwise the type's zero value
+func exampleValue(schema map[string]any) any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if v, ok := schema["default"]; ok {
+		return v
+	}
+
+	kind, _ := schema["type"].(string)
+	if kinds, ok := schema["type"].([]any); ok {
+		for _, k := range kinds {
+			if s, _ := k.(string); s != "null" {
+				kind = s
+				break
+			}
+		}
+	}
+	switch kind {
+	case "object":
+		props, _ := schema["properties"].(map[string]any)
+		out := map[string]any{}
+		for name, p := range props {
+			ps, _ := p.(map[string]any)
+			out[name] = exampleValue(ps)
+		}
+		return out
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return []any{}
+		}
+		return []any{exampleValue(items)}
+	case "string":
+		if schema["format"] == "date-time" {
+			return time.Now().UTC().Truncate(time.Minute).Format(time.RFC3339)
+		}
+		desc, _ := schema["description"].(string)
+		if m := exampleAfterEG.FindStringSubmatch(desc); m != nil {
+			return strings.TrimRight(m[1]+m[2], ".")
+		}
+		if m := exampleAfterColon.FindStringSubmatch(desc); m != nil {
+			return m[1]
+		}
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to report model spend by flow, user, day and model across all users over a window
+func costsHandler(usage *store.LogStore[store.ModelUsage]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, 30)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, http.StatusOK, costs.Summarize(usage, from, to, locale.From(r.Context()).Location))
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"diabeticai-advisor/internal/disclaimer"
+)
+
+// Response Metadata Struct
+//
+// Added next to "result" in flow responses.
+type ResponseMetadata struct {
+	Disclaimer string `json:"disclaimer,omitempty"`
+	Locale     string `json:"locale,omitempty"`
+}
+
+// Helper function to pick the locale from ?locale= or the Accept-Language header
+func requestLocale(r *http.Request) string {
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		return locale
+	}
+	first, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	tag, _, _ := strings.Cut(first, ";")
+	return strings.TrimSpace(tag)
+}
+
+// Wrap a flow handler so successful responses carry the flow's disclaimer in their metadata
+func withDisclaimer(set *disclaimer.Set, flow string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if set == nil || r.URL.Query().Has("stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		text, locale := set.For(flow, requestLocale(r))
+		buf := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		body := buf.body.Bytes()
+		var envelope map[string]json.RawMessage
+		if buf.status == http.StatusOK && text != "" && json.Unmarshal(body, &envelope) == nil {
+			envelope["metadata"], _ = json.Marshal(ResponseMetadata{Disclaimer: text, Locale: locale})
+			if rewritten, err := json.Marshal(envelope); err == nil {
+				body = rewritten
+				buf.header.Set("Content-Language", locale)
+				buf.header.Del("Content-Length")
+			}
+		}
+
+		for k, v := range buf.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/dryrun"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/replay"
+)
+
+// Helper function to report whether a flow request asks for a dry run (?dry_run=true)
+func dryRunRequested(r *http.Request) bool {
+	v, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	return v
+}
+
+// Helper function to run a flow without calling the model or storing anything,
+// adding the rendered prompts, rule verdicts and config to the response envelope
+func serveDryRun(w http.ResponseWriter, r *http.Request, flow string, h http.HandlerFunc) {
+	mode := replay.Mode()
+	if mode == replay.Off {
+		mode = "off"
+	}
+	p := locale.From(r.Context())
+	ctx, trace := dryrun.With(r.Context(), dryrun.Config{
+		Flow:          flow,
+		PromptVersion: prompts.Version(flow),
+		DefaultModel:  costs.Model(r.Context()),
+		ReplayMode:    mode,
+		Locale:        p.Locale,
+		Units:         p.Units,
+	})
+
+	buf := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+	h(buf, r.WithContext(ctx))
+
+	body := buf.body.Bytes()
+	var envelope map[string]json.RawMessage
+	if buf.status == http.StatusOK && json.Unmarshal(body, &envelope) == nil {
+		envelope["dry_run"], _ = json.Marshal(trace)
+		if rewritten, err := json.Marshal(envelope); err == nil {
+			body = rewritten
+			buf.header.Del("Content-Length")
+		}
+	}
+
+	for k, v := range buf.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("X-Dry-Run", "true")
+	w.WriteHeader(buf.status)
+	w.Write(body)
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/requestid"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/core/api"
+)
+
+// Error codes clients can branch on
+const (
+	CodeInvalidArgument  = "invalid_argument"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeTooLarge         = "too_large"
+	CodeRateLimited      = "rate_limited"
+	CodeModelError       = "model_error"
+	CodeBlocked          = "blocked"
+	CodeUpstream         = "upstream_error"
+	CodeUnavailable      = "unavailable"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal"
+)
+
+// Error code for each HTTP status, when the error does not name one
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeInvalidArgument,
+	http.StatusUnauthorized:          CodeUnauthenticated,
+	http.StatusForbidden:             CodePermissionDenied,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeInvalidArgument,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnprocessableEntity:   CodeInvalidArgument,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusBadGateway:            CodeUpstream,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// Message for unexpected failures, whose details stay in the server log
+const internalMessage = "Something went wrong on our side. Please try again later."
+
+// API Error Struct
+//
+// The body of every error response, under "error". Message is safe to show patients.
+type APIError struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
+	RetryAfter int    `json:"retry_after_seconds,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	Status     int    `json:"-"`
+	Err        error  `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Create an error with a status and a message safe to show patients. The code
+// and retryability follow from the status.
+func NewError(status int, message string, err error) *APIError {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeInternal
+	}
+	return &APIError{
+		Code:      code,
+		Message:   message,
+		Retryable: retryable(status),
+		Status:    status,
+		Err:       err,
+	}
+}
+
+// Create the error returned when the model could not be reached or failed to answer
+func ModelError(err error) *APIError {
+	e := NewError(http.StatusBadGateway, "The assistant could not answer right now. Please try again in a moment.", err)
+	e.Code = CodeModelError
+	return e
+}
+
+// Create the error returned when the model's safety filters blocked an answer
+func BlockedError(reason string) *APIError {
+	e := NewError(http.StatusUnprocessableEntity, "This request can't be answered. Try rephrasing it, or contact your care team.", errors.New(reason))
+	e.Code = CodeBlocked
+	return e
+}
+
+// Helper function to report whether a request failing with a status may succeed if repeated
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Map any error to an API error. Errors from flows keep their message when it
+// was written for users (public errors, access errors, input validation);
+// anything else is reported as an internal error without its details.
+func ErrorFor(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		e := *apiErr
+		return &e
+	}
+	var public *core.UserFacingError
+	if errors.As(err, &public) {
+		return NewError(core.HTTPStatusCode(public.Status), public.Message, err)
+	}
+	var gkErr *core.GenkitError
+	if errors.As(err, &gkErr) {
+		if status := core.HTTPStatusCode(gkErr.Status); status < 500 {
+			return NewError(status, gkErr.Message, err)
+		}
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than the %s limit", formatBytes(tooLarge.Limit)), err)
+	}
+	for _, denied := range []error{rbac.ErrReadOnly, rbac.ErrNotLinked, rbac.ErrOtherWrite} {
+		if errors.Is(err, denied) {
+			return NewError(http.StatusForbidden, denied.Error(), err)
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewError(http.StatusGatewayTimeout, "The request took too long. Please try again.", err)
+	case strings.HasPrefix(err.Error(), "invalid input: "):
+		// Genkit reports input that fails the flow's schema as a plain error
+		return NewError(http.StatusBadRequest, strings.TrimPrefix(err.Error(), "invalid input: "), err)
+	}
+	return NewError(http.StatusInternalServerError, internalMessage, err)
+}
+
+// Helper function to format a byte count for messages, e.g. 8 MB or 512 KB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// Helper function to write an error response with a message and status
+func WriteError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	writeAPIError(w, r, NewError(status, message, nil))
+}
+
+// Helper function to write the error response for an error, logging its details
+func WriteErrorFor(w http.ResponseWriter, r *http.Request, err error) {
+	e := ErrorFor(err)
+	log.Printf("request failed code=%s status=%d request_id=%s: %v", e.Code, e.Status, requestid.From(r.Context()), err)
+	writeAPIError(w, r, e)
+}
+
+// Helper function to write an API error under "error", with the request ID and any Retry-After
+func writeAPIError(w http.ResponseWriter, r *http.Request, e *APIError) {
+	e.RequestID = requestid.From(r.Context())
+	if e.RetryAfter == 0 {
+		e.RetryAfter, _ = strconv.Atoi(w.Header().Get("Retry-After"))
+	}
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(e.RetryAfter))
+	}
+	WriteJSON(w, e.Status, map[string]*APIError{"error": e})
+}
+
+// Serve a flow like genkit.Handler: {"data": ...} in, {"result": ...} out, or
+// server-sent events with ?stream=true. Failures are answered with the error envelope.
+func flowHandler(flow api.Action) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if r.Body != nil {
+			err := json.NewDecoder(r.Body).Decode(&body)
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				WriteErrorFor(w, r, err)
+				return
+			case err != nil && !errors.Is(err, io.EOF):
+				WriteError(w, r, "invalid request body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		stream := false
+		if v := r.URL.Query().Get("stream"); v != "" {
+			var err error
+			if stream, err = strconv.ParseBool(v); err != nil {
+				WriteError(w, r, "stream must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+		stream = stream || r.Header.Get("Accept") == "text/event-stream"
+
+		var callback func(context.Context, json.RawMessage) error
+		if stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			callback = func(ctx context.Context, msg json.RawMessage) error {
+				if _, err := fmt.Fprintf(w, "data: {\"message\": %s}\n\n", msg); err != nil {
+					return err
+				}
+				if f, ok := w.(http.Flusher); ok {
+					f.Flush()
+				}
+				return nil
+			}
+		}
+
+		out, err := flow.RunJSON(r.Context(), body.Data, callback)
+		if err != nil {
+			if !stream {
+				WriteErrorFor(w, r, err)
+				return
+			}
+			// The stream has started, so the error is sent as its last event
+			e := ErrorFor(err)
+			e.RequestID = requestid.From(r.Context())
+			log.Printf("request failed code=%s status=%d request_id=%s: %v", e.Code, e.Status, e.RequestID, err)
+			data, _ := json.Marshal(map[string]*APIError{"error": e})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			return
+		}
+		if stream {
+			fmt.Fprintf(w, "data: {\"result\": %s}\n\n", out)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, "{\"result\": %s}\n", out)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Evaluation Summary Struct
+type evaluationSummary struct {
+	Flow     string             `json:"flow"`
+	Count    int                `json:"count"`
+	Average  float64            `json:"average"`
+	Failing  map[string]int     `json:"failing" jsonschema:"description=Failed grades by criterion"`
+	Alerting bool               `json:"alerting" jsonschema:"description=Average is below the alert threshold"`
+	Worst    []store.Evaluation `json:"worst"`
+}
+
+// Handler to summarize rubric scores per flow across all users over a window
+func evaluationsHandler(evals *store.LogStore[store.Evaluation], alertPercent int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, 7)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		byFlow := map[string][]store.Evaluation{}
+		for _, userID := range evals.Users() {
+			for _, e := range evals.Range(userID, from, to) {
+				byFlow[e.Flow] = append(byFlow[e.Flow], e)
+			}
+		}
+
+		summaries := []evaluationSummary{}
+		for flow, list := range byFlow {
+			sum := evaluationSummary{Flow: flow, Count: len(list), Failing: map[string]int{}}
+			for _, e := range list {
+				sum.Average += e.Score
+				for _, c := range e.Criteria {
+					if !c.Pass {
+						sum.Failing[c.Name]++
+					}
+				}
+			}
+			sum.Average /= float64(len(list))
+			sum.Alerting = sum.Average*100 < float64(alertPercent)
+
+			sort.SliceStable(list, func(i, j int) bool { return list[i].Score < list[j].Score })
+			sum.Worst = list[:min(len(list), 5)]
+			summaries = append(summaries, sum)
+		}
+		sort.Slice(summaries, func(i, j int) bool { return summaries[i].Flow < summaries[j].Flow })
+
+		WriteJSON(w, http.StatusOK, summaries)
+	}
+}
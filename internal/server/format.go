@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"diabeticai-advisor/internal/render"
+)
+
+// Helper function to pick a text format from an Accept header.
+// Returns "" when JSON (the default) should be served.
+func textFormat(accept string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		format := ""
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case render.Markdown:
+			format = render.Markdown
+		case render.Plain:
+			format = render.Plain
+		case "application/json", "*/*":
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+// Wrap a flow handler so Accept: text/plain or text/markdown get a formatted message
+func negotiateFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := textFormat(r.Header.Get("Accept"))
+		if format == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Genkit flows only answer JSON, so ask for it and convert afterwards
+		r.Header.Set("Accept", "application/json")
+		buf := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		var envelope struct {
+			Result   json.RawMessage  `json:"result"`
+			Metadata ResponseMetadata `json:"metadata"`
+		}
+		if buf.status == http.StatusOK && json.Unmarshal(buf.body.Bytes(), &envelope) == nil && envelope.Result != nil {
+			if text, err := render.Render(envelope.Result, format); err == nil {
+				if envelope.Metadata.Disclaimer != "" {
+					text = strings.TrimRight(text, "\n") + "\n\n" + envelope.Metadata.Disclaimer + "\n"
+				}
+				w.Header().Set("Content-Type", format+"; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				io.WriteString(w, text)
+				return
+			}
+		}
+
+		// Pass errors and anything unrenderable through untouched
+		for k, v := range buf.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(buf.status)
+		w.Write(buf.body.Bytes())
+	})
+}
+
+// ResponseWriter that holds the response so it can be rewritten
+type bufferWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferWriter) Header() http.Header         { return bw.header }
+func (bw *bufferWriter) WriteHeader(status int)      { bw.status = status }
+func (bw *bufferWriter) Write(b []byte) (int, error) { return bw.body.Write(b) }
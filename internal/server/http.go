@@ -0,0 +1,126 @@
+// Package server wires flows and data endpoints onto an HTTP mux.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/rbac"
+)
+
+// Helper function to resolve the user from a request, falling back to the user the API key is bound to
+func userIDFromRequest(r *http.Request) string {
+	if id := r.Header.Get("X-User-ID"); id != "" {
+		return id
+	}
+	if id := r.URL.Query().Get("user_id"); id != "" {
+		return id
+	}
+	return rbac.User(r.Context())
+}
+
+// Helper function to find the user a flow call is for: data.user_id in the
+// body, or the request's user. The body is left intact for the flow.
+func flowUserID(r *http.Request) string {
+	if id := bodyUserID(r); id != "" {
+		return id
+	}
+	return userIDFromRequest(r)
+}
+
+// Helper function to list every user a request names, in headers, query or
+// a JSON body, so access can be checked for each
+func requestedUsers(r *http.Request) []string {
+	q := r.URL.Query()
+	var users []string
+	for _, id := range []string{r.Header.Get("X-User-ID"), q.Get("user_id"), q.Get("patient_id"), bodyUserID(r)} {
+		if id != "" && !slices.Contains(users, id) {
+			users = append(users, id)
+		}
+	}
+	return users
+}
+
+// Helper function to read user_id from a JSON body, at the top level or
+// inside a flow's data. The body is left intact for the handler. Multipart
+// uploads are skipped; their parts carry no user.
+func bodyUserID(r *http.Request) string {
+	if r.Body == nil || strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return ""
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		// Keep the error, such as a body over the limit, for the handler to report
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), failingReader{err}))
+		return ""
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req struct {
+		UserID string `json:"user_id"`
+		Data   struct {
+			UserID string `json:"user_id"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return ""
+	}
+	if req.Data.UserID != "" {
+		return req.Data.UserID
+	}
+	return req.UserID
+}
+
+// Reader that fails with an error once read
+type failingReader struct {
+	err error
+}
+
+func (f failingReader) Read([]byte) (int, error) {
+	return 0, f.err
+}
+
+// Helper function to parse a time window from query parameters.
+// Accepts from/to as RFC3339 timestamps, or days counting back from now.
+func windowFromRequest(r *http.Request, defaultDays int) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+	to := time.Now()
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+
+	days := defaultDays
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		days = n
+	}
+	from := to.AddDate(0, 0, -days)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+
+	return from, to, nil
+}
+
+// Helper function to write a JSON response
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"diabeticai-advisor/internal/importer"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to start an import from a zip of device exports, or a single export.
+// Accepts the file as the raw body or as the "file" field of a multipart form.
+// The upload size is limited by the mux (MAX_IMPORT_BYTES).
+func startImportHandler(s *store.Stores, imports *importer.Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			f, _, err := r.FormFile("file")
+			if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+				WriteErrorFor(w, r, err)
+				return
+			}
+			if err != nil {
+				WriteError(w, r, "invalid upload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer f.Close()
+			body = f
+		}
+		data, err := io.ReadAll(body)
+		if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+			WriteErrorFor(w, r, err)
+			return
+		}
+		if err != nil {
+			WriteError(w, r, "invalid upload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(data) == 0 {
+			WriteError(w, r, "upload is empty", http.StatusBadRequest)
+			return
+		}
+
+		job, err := imports.Start(r.Context(), s, userIDFromRequest(r), data, locale.From(r.Context()).Location)
+		if err != nil {
+			WriteError(w, r, "invalid upload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", versioned("/import/"+job.ID))
+		WriteJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// Handler to poll an import's progress and per-file results
+func importStatusHandler(imports *importer.Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := imports.Get(r.PathValue("id"), userIDFromRequest(r))
+		if errors.Is(err, importer.ErrNotFound) {
+			WriteError(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		WriteJSON(w, http.StatusOK, job)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/store"
+)
+
+// Largest activity payload accepted
+const maxIngestBytes = 4 << 20
+
+// Most steps, heart rate and workout entries accepted in one delivery
+const maxIngestEntries = 10000
+
+// How far a delivery's signed timestamp may be from now
+const ingestMaxSkew = 5 * time.Minute
+
+// Returned when a delivery's signature or timestamp does not check out
+var errBadIngestSignature = errors.New("invalid signature")
+
+// Bridge names, e.g. fitbit or garmin-connect
+var sourceName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Activity Payload Struct
+//
+// What a wearable bridge posts: one user's steps, heart rate and workouts.
+type activityPayload struct {
+	UserID string `json:"user_id"`
+	Source string `json:"source"`
+	Steps  []struct {
+		Start   time.Time `json:"start"`
+		Minutes int       `json:"minutes"`
+		Count   int       `json:"count"`
+	} `json:"steps"`
+	HeartRate []struct {
+		At  time.Time `json:"at"`
+		BPM int       `json:"bpm"`
+	} `json:"heart_rate"`
+	Workouts []struct {
+		Type            string    `json:"type"`
+		Start           time.Time `json:"start"`
+		DurationMinutes int       `json:"duration_minutes"`
+		Intensity       string    `json:"intensity"`
+		AvgHeartRate    int       `json:"avg_heart_rate"`
+	} `json:"workouts"`
+}
+
+// Register the wearable activity webhook. Bridges sign deliveries with a
+// shared secret instead of carrying an API key, so it sits outside the
+// middleware chain, and is only served when a secret is configured.
+func RegisterIngest(m *Mux, s *store.Stores, secrets []string) {
+	if len(secrets) > 0 {
+		m.Handle("POST /ingest/activity", ingestActivityHandler(s, secrets))
+	}
+}
+
+// Check a delivery's X-Signature, "sha256=" and the hex HMAC-SHA256 of
+// "<X-Timestamp>.<body>", against each secret. Several secrets allow rotation.
+func VerifyIngest(secrets []string, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errBadIngestSignature
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > ingestMaxSkew || skew < -ingestMaxSkew {
+		return errBadIngestSignature
+	}
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "%s.%s", timestamp, body)
+		if hmac.Equal([]byte("sha256="+hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+			return nil
+		}
+	}
+	return errBadIngestSignature
+}
+
+// Handler to store steps, heart rate and workouts from a signed wearable bridge
+// delivery. Entries already stored from the same source are skipped, so
+// bridges can safely resend.
+func ingestActivityHandler(s *store.Stores, secrets []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIngestBytes))
+		if err != nil {
+			WriteErrorFor(w, r, err)
+			return
+		}
+		if err := VerifyIngest(secrets, r.Header.Get("X-Timestamp"), r.Header.Get("X-Signature"), body, time.Now()); err != nil {
+			WriteError(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var p activityPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			WriteError(w, r, "invalid activity payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.Source = strings.ToLower(strings.TrimSpace(p.Source))
+		if p.UserID == "" || !sourceName.MatchString(p.Source) {
+			WriteError(w, r, "user_id and source (letters, digits, - and _) are required", http.StatusBadRequest)
+			return
+		}
+		if len(p.Steps)+len(p.HeartRate)+len(p.Workouts) > maxIngestEntries {
+			WriteError(w, r, fmt.Sprintf("at most %d entries per delivery", maxIngestEntries), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// Build the records, rejecting the whole delivery if any entry is invalid
+		latest := time.Now().Add(ingestMaxSkew)
+		var samples []store.ActivitySample
+		var workouts []store.WorkoutLog
+		for i, e := range p.Steps {
+			if e.Start.IsZero() || e.Start.After(latest) || e.Minutes < 1 || e.Minutes > 1440 || e.Count < 0 || e.Count > 100000 {
+				WriteError(w, r, fmt.Sprintf("steps[%d]: needs start, minutes (1-1440) and count (0-100000)", i), http.StatusBadRequest)
+				return
+			}
+			samples = append(samples, store.ActivitySample{UserID: p.UserID, Source: p.Source, Steps: e.Count, Minutes: e.Minutes, Timestamp: e.Start})
+		}
+		for i, e := range p.HeartRate {
+			if e.At.IsZero() || e.At.After(latest) || e.BPM < 25 || e.BPM > 250 {
+				WriteError(w, r, fmt.Sprintf("heart_rate[%d]: needs at and bpm (25-250)", i), http.StatusBadRequest)
+				return
+			}
+			samples = append(samples, store.ActivitySample{UserID: p.UserID, Source: p.Source, HeartRate: e.BPM, Timestamp: e.At})
+		}
+		for i, e := range p.Workouts {
+			kind := strings.ToLower(strings.TrimSpace(e.Type))
+			if kind == "" || e.Start.IsZero() || e.Start.After(latest) || e.DurationMinutes < 1 || e.DurationMinutes > 1440 {
+				WriteError(w, r, fmt.Sprintf("workouts[%d]: needs type, start and duration_minutes (1-1440)", i), http.StatusBadRequest)
+				return
+			}
+			workouts = append(workouts, store.WorkoutLog{
+				UserID:          p.UserID,
+				Type:            kind,
+				DurationMinutes: e.DurationMinutes,
+				Intensity:       strings.ToLower(e.Intensity),
+				AvgHeartRate:    e.AvgHeartRate,
+				Source:          p.Source,
+				Timestamp:       e.Start,
+			})
+		}
+
+		samples, skippedSamples := newEntries(s.Activity, samples, func(a store.ActivitySample) string {
+			return fmt.Sprintf("%s|%d|%t", a.Source, a.Timestamp.UnixNano(), a.HeartRate > 0)
+		})
+		workouts, skippedWorkouts := newEntries(s.Workouts, workouts, func(w store.WorkoutLog) string {
+			return fmt.Sprintf("%s|%d", w.Source, w.Timestamp.UnixNano())
+		})
+		s.Activity.AddAll(r.Context(), samples)
+		s.Workouts.AddAll(r.Context(), workouts)
+
+		WriteJSON(w, http.StatusOK, map[string]int{
+			"samples":    len(samples),
+			"workouts":   len(workouts),
+			"duplicates": skippedSamples + skippedWorkouts,
+		})
+	}
+}
+
+// Helper function to drop entries already stored, or repeated in the delivery,
+// by key. Returns the new entries and how many were dropped.
+func newEntries[T store.Record](logs *store.LogStore[T], entries []T, key func(T) string) ([]T, int) {
+	if len(entries) == 0 {
+		return entries, 0
+	}
+	from, to := entries[0].Time(), entries[0].Time()
+	for _, e := range entries {
+		from, to = minTime(from, e.Time()), maxTime(to, e.Time())
+	}
+	seen := map[string]bool{}
+	for _, e := range logs.Range(entries[0].Owner(), from, to.Add(time.Nanosecond)) {
+		seen[key(e)] = true
+	}
+
+	var fresh []T
+	for _, e := range entries {
+		k := key(e)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		fresh = append(fresh, e)
+	}
+	return fresh, len(entries) - len(fresh)
+}
+
+// Helper function to return the earlier of two times
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// Helper function to return the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
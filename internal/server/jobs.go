@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"diabeticai-advisor/internal/jobs"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to list scheduled jobs with their last-run status and failures
+func jobsHandler(sched *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, sched.List())
+	}
+}
+
+// Handler to run a job now, for Cloud Scheduler and other external triggers.
+// A failed run answers 500, so the trigger retries it.
+func runJobHandler(sched *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		status, ran, err := sched.Run(r.Context(), name)
+		switch {
+		case errors.Is(err, jobs.ErrUnknownJob):
+			WriteError(w, r, "no job named "+name, http.StatusNotFound)
+			return
+		case errors.Is(err, jobs.ErrJobRunning):
+			WriteError(w, r, "job "+name+" is already running", http.StatusConflict)
+			return
+		case err != nil:
+			WriteErrorFor(w, r, NewError(http.StatusInternalServerError, "job "+name+" failed: "+err.Error(), err))
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]any{"ran": ran, "status": status})
+	}
+}
+
+// Handler to list a user's records from a log over a time window
+func listHandler[T store.Record](logs *store.LogStore[T], defaultDays int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, defaultDays)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		records := logs.Range(userIDFromRequest(r), from, to)
+		if records == nil {
+			records = []T{}
+		}
+		WriteJSON(w, http.StatusOK, records)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/store"
+)
+
+// API Key Struct
+type APIKey struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key,omitempty"`
+	Role      string    `json:"role"`
+	UserID    string    `json:"user_id,omitempty" jsonschema:"description=User the key acts for; requests for other users are checked against share grants"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store of client API keys. When empty, client requests are not authenticated.
+type APIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]APIKey
+}
+
+// Create a key store seeded from a comma-separated list of keys, each
+// optionally followed by a role and a bound user: "key", "key:admin"
+// or "key:caregiver:user-42". Keys without a role are patient keys;
+// caregiver and clinician keys need a user.
+func NewAPIKeyStore(seed string) (*APIKeyStore, error) {
+	s := &APIKeyStore{keys: make(map[string]APIKey)}
+	for _, entry := range strings.Split(seed, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rest, _ := strings.Cut(entry, ":")
+		role, userID, _ := strings.Cut(rest, ":")
+		if role == "" {
+			role = rbac.Patient
+		}
+		if !rbac.Valid(role) {
+			return nil, fmt.Errorf("unknown role %q; want one of %s", role, strings.Join(rbac.Roles, ", "))
+		}
+		if userID == "" && rbac.NeedsUser(role) {
+			return nil, fmt.Errorf("%s keys must be bound to a user, as in key:%s:user-42", role, role)
+		}
+		s.add(key, role, userID)
+	}
+	return s, nil
+}
+
+func (s *APIKeyStore) add(key, role, userID string) APIKey {
+	k := APIKey{ID: store.NewID(), Key: key, Role: role, UserID: userID, CreatedAt: time.Now()}
+	s.keys[k.ID] = k
+	return k
+}
+
+// Generate and store a new key for a role and optional user, optionally
+// revoking an old one. An empty role keeps the revoked key's role and user.
+func (s *APIKeyStore) Rotate(revokeID, role, userID string) (APIKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if revokeID != "" {
+		old, ok := s.keys[revokeID]
+		if !ok {
+			return APIKey{}, false
+		}
+		if role == "" {
+			role, userID = old.Role, old.UserID
+		}
+		delete(s.keys, revokeID)
+	}
+	if role == "" {
+		role = rbac.Patient
+	}
+	return s.add("dk_"+store.NewID()+store.NewID(), role, userID), true
+}
+
+// Revoke a key by ID
+func (s *APIKeyStore) Revoke(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		return false
+	}
+	delete(s.keys, id)
+	return true
+}
+
+// List keys without their secret values
+func (s *APIKeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []APIKey{}
+	for _, k := range s.keys {
+		out = append(out, APIKey{ID: k.ID, Role: k.Role, UserID: k.UserID, CreatedAt: k.CreatedAt})
+	}
+	return out
+}
+
+// Look up a presented key. ok is true with an empty key when no keys are configured.
+func (s *APIKeyStore) Check(presented string) (key APIKey, ok bool) {
+	s.mu.RLock()
+	empty := len(s.keys) == 0
+	s.mu.RUnlock()
+	if empty {
+		return APIKey{}, true
+	}
+	return s.Lookup(presented)
+}
+
+// Find the key matching a presented secret
+func (s *APIKeyStore) Lookup(presented string) (APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(presented)) == 1 {
+			return k, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Helper function to read a client API key from a request
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
+)
+
+// Handler to return stats over a time window
+func statsHandler(readings *store.ReadingStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, 14)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		population := r.URL.Query().Get("population")
+		if population == "" {
+			population = thresholds.Adult
+		}
+		if !thresholds.Current().HasPopulation(population) {
+			WriteError(w, r, "unknown population "+population, http.StatusBadRequest)
+			return
+		}
+
+		userID := userIDFromRequest(r)
+		WriteJSON(w, http.StatusOK, analytics.ComputeStatsFor(readings.Range(userID, from, to), from, to, population))
+	}
+}
+
+// Thresholds Response Struct
+type thresholdsResponse struct {
+	At       string             `json:"at"`
+	Active   thresholds.Table   `json:"active"`
+	Versions []thresholdVersion `json:"versions"`
+}
+
+type thresholdVersion struct {
+	Version   string `json:"version"`
+	Effective string `json:"effective"`
+	Active    bool   `json:"active"`
+}
+
+// Handler to return the clinical threshold table in force today, or on the ?at= date
+func thresholdsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		at := time.Now()
+		if v := r.URL.Query().Get("at"); v != "" {
+			t, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				WriteError(w, r, "at must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+				return
+			}
+			at = t
+		}
+
+		resp := thresholdsResponse{At: at.Format(time.DateOnly), Active: thresholds.Active(at)}
+		for _, t := range thresholds.All() {
+			resp.Versions = append(resp.Versions, thresholdVersion{Version: t.Version, Effective: t.Effective, Active: t.Version == resp.Active.Version})
+		}
+		WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// Handler to return AGP percentile curves over a time window
+func agpHandler(readings *store.ReadingStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, 14)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		binMinutes := 60
+		if v := r.URL.Query().Get("bin_minutes"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > 24*60 || (24*60)%n != 0 {
+				WriteError(w, r, "bin_minutes must evenly divide 1440", http.StatusBadRequest)
+				return
+			}
+			binMinutes = n
+		}
+
+		userID := userIDFromRequest(r)
+		WriteJSON(w, http.StatusOK, analytics.ComputeAGP(readings.Range(userID, from, to), from, to, binMinutes))
+	}
+}
+
+// Handler to return current insulin on board
+func iobHandler(doses *store.LogStore[store.InsulinDose]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		at := time.Now()
+		if v := r.URL.Query().Get("at"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				WriteError(w, r, "invalid at: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			at = t
+		}
+
+		WriteJSON(w, http.StatusOK, analytics.CurrentIOB(doses, userIDFromRequest(r), at))
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/requestid"
+	"diabeticai-advisor/internal/store"
+)
+
+// Middleware wraps a handler with a cross-cutting concern
+type Middleware func(http.Handler) http.Handler
+
+// Compose middleware so the first one listed runs outermost
+func Chain(mws ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+}
+
+// Built-in middleware by name, for configuring the chain
+var builtinMiddleware = map[string]func(m *Mux) Middleware{
+	"logging":   func(m *Mux) Middleware { return Logging() },
+	"metrics":   func(m *Mux) Middleware { return m.Metrics.Middleware() },
+	"auth":      func(m *Mux) Middleware { return RequireAPIKey(m.Keys, m.Shares, m.isWrite) },
+	"ratelimit": func(m *Mux) Middleware { return RateLimit(m.Limiter) },
+}
+
+// Attach a request ID to the context and the response, reusing the caller's X-Request-ID when valid
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestid.FromHeader(r.Header.Get(requestid.Header))
+			w.Header().Set(requestid.Header, id)
+			next.ServeHTTP(w, r.WithContext(requestid.With(r.Context(), id)))
+		})
+	}
+}
+
+// Context key for the authenticated client
+type clientKey struct{}
+
+// Reject requests without a valid client API key, or that the key's role
+// may not make, with isWrite telling reads from changes. The key's ID is
+// passed on to later middleware as the client identity, and the key as the
+// calling principal.
+func RequireAPIKey(keys *APIKeyStore, shares *store.ShareStore, isWrite func(*http.Request) bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := keys.Check(apiKeyFromRequest(r))
+			if !ok {
+				WriteError(w, r, "missing or invalid API key", http.StatusUnauthorized)
+				return
+			}
+			if key.ID != "" {
+				principal := rbac.Principal{KeyID: key.ID, Role: key.Role, UserID: key.UserID}
+				if err := authorize(r, principal, shares, isWrite(r)); err != nil {
+					WriteError(w, r, err.Error(), http.StatusForbidden)
+					return
+				}
+				ctx := context.WithValue(r.Context(), clientKey{}, key.ID)
+				r = r.WithContext(rbac.With(ctx, principal))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Helper function to check a principal against every user a request names
+func authorize(r *http.Request, p rbac.Principal, shares *store.ShareStore, write bool) error {
+	linked := func(viewerID, patientID string) bool { return shares != nil && shares.CanRead(viewerID, patientID) }
+	for _, subject := range requestedUsers(r) {
+		if err := rbac.Allow(p, subject, write, linked); err != nil {
+			return err
+		}
+	}
+	return rbac.Allow(p, "", write, linked)
+}
+
+// Limit requests per client, keyed by API key ID or else by IP
+func RateLimit(limiter *RateLimiter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, ok := r.Context().Value(clientKey{}).(string)
+			if !ok {
+				client = clientIP(r)
+			}
+			if !limiter.Allow(r.Context(), client, time.Now()) {
+				w.Header().Set("Retry-After", "60")
+				WriteError(w, r, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Reject request bodies over limit bytes, up front when Content-Length says so.
+// Zero or less means no limit.
+func limitBody(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			WriteErrorFor(w, r, &http.MaxBytesError{Limit: limit})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Log each request with its status and duration
+func Logging() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			log.Printf("%s %s %d %s request_id=%s", r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Millisecond), requestid.From(r.Context()))
+		})
+	}
+}
+
+// Route Metrics Struct
+type RouteMetrics struct {
+	Route        string  `json:"route"`
+	Requests     int     `json:"requests"`
+	ClientErrors int     `json:"client_errors"`
+	ServerErrors int     `json:"server_errors"`
+	AvgMillis    float64 `json:"avg_ms"`
+	totalTime    time.Duration
+}
+
+// Per-route request counters
+type Metrics struct {
+	mu     sync.Mutex
+	routes map[string]*RouteMetrics
+}
+
+// Create an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{routes: make(map[string]*RouteMetrics)}
+}
+
+// Count requests, errors and latency per route pattern
+func (mt *Metrics) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			mt.record(r.Pattern, sw.status, time.Since(start))
+		})
+	}
+}
+
+func (mt *Metrics) record(route string, status int, elapsed time.Duration) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+
+	rm, ok := mt.routes[route]
+	if !ok {
+		rm = &RouteMetrics{Route: route}
+		mt.routes[route] = rm
+	}
+	rm.Requests++
+	rm.totalTime += elapsed
+	switch {
+	case status >= 500:
+		rm.ServerErrors++
+	case status >= 400:
+		rm.ClientErrors++
+	}
+}
+
+// Snapshot of metrics for every route that has seen traffic
+func (mt *Metrics) List() []RouteMetrics {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+
+	out := []RouteMetrics{}
+	for _, rm := range mt.routes {
+		snap := *rm
+		snap.AvgMillis = float64(rm.totalTime.Microseconds()) / float64(rm.Requests) / 1000
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Route < out[j].Route })
+	return out
+}
+
+// ResponseWriter that remembers the status code
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"diabeticai-advisor/internal/disclaimer"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core/api"
+	gkserver "github.com/firebase/genkit/go/plugins/server"
+)
+
+// Route Struct
+type Route struct {
+	Pattern     string
+	Description string
+}
+
+// Mux Struct
+//
+// Every public endpoint is registered through Mux so that the configured
+// middleware chain applies uniformly, and so the route list can be printed at startup.
+type Mux struct {
+	*http.ServeMux
+	Flows        *FlowRegistry
+	Keys         *APIKeyStore
+	Limiter      *RateLimiter
+	Metrics      *Metrics
+	Cache        *ResponseCache
+	Disclaimers  *disclaimer.Set
+	Preferences  *store.PreferenceStore
+	Shares       *store.ShareStore
+	Sunset       time.Time
+	MaxBodyBytes int64
+	Compress     bool
+	chain        Middleware
+	routes       []Route
+	bodyLimits   map[string]int64
+	writes       map[string]bool
+}
+
+// Create a mux guarded by the given key store and rate limiter
+func NewMux(keys *APIKeyStore, limiter *RateLimiter) *Mux {
+	return &Mux{
+		ServeMux:   http.NewServeMux(),
+		Flows:      NewFlowRegistry(),
+		Keys:       keys,
+		Limiter:    limiter,
+		Metrics:    NewMetrics(),
+		chain:      Chain(),
+		bodyLimits: map[string]int64{},
+		writes:     map[string]bool{},
+	}
+}
+
+// Set the middleware chain for public endpoints from built-in names, outermost first.
+// Must be called before any endpoint is registered.
+func (m *Mux) Use(names ...string) error {
+	var mws []Middleware
+	for _, name := range names {
+		build, ok := builtinMiddleware[name]
+		if !ok {
+			return fmt.Errorf("unknown middleware %q", name)
+		}
+		mws = append(mws, build(m))
+	}
+	m.chain = Chain(mws...)
+	return nil
+}
+
+// Register a flow as a public endpoint, with its disclaimer and plain text and markdown negotiation.
+// The flow only reads the caller's data, so read-only roles may call it.
+func (m *Mux) HandleFlow(pattern string, flow api.Action, description string) {
+	h := m.Flows.Handler(versioned(pattern), flow)
+	m.handleFlow(pattern, description, negotiateFormat(withDisclaimer(m.Disclaimers, flow.Name(), h)), false)
+}
+
+// Register a flow that saves to the caller's data, such as a logged reading
+// or a session, so read-only roles are refused
+func (m *Mux) HandleWriteFlow(pattern string, flow api.Action, description string) {
+	h := m.Flows.Handler(versioned(pattern), flow)
+	m.handleFlow(pattern, description, negotiateFormat(withDisclaimer(m.Disclaimers, flow.Name(), h)), true)
+}
+
+// Register a flow whose output depends only on its input, serving repeats from the response cache
+func (m *Mux) HandleCachedFlow(pattern string, flow api.Action, description string) {
+	h := m.Cache.Middleware()(m.Flows.Handler(versioned(pattern), flow))
+	m.handleFlow(pattern, description, negotiateFormat(withDisclaimer(m.Disclaimers, flow.Name(), h)), false)
+}
+
+// Helper function to register a flow endpoint, recording whether it changes data.
+// Flows are all POSTs, so the method can't tell.
+func (m *Mux) handleFlow(pattern, description string, h http.Handler, write bool) {
+	m.writes[pattern], m.writes[versioned(pattern)] = write, write
+	m.HandlePublic(pattern, description, h)
+}
+
+// Report whether a request changes data: as its flow was registered, otherwise by method
+func (m *Mux) isWrite(r *http.Request) bool {
+	if write, ok := m.writes[r.Pattern]; ok {
+		return write
+	}
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// Register a public endpoint behind the middleware chain.
+// The endpoint is served under the API version prefix, and at its
+// unversioned path as a deprecated alias.
+// Routes with an empty description are served but not listed.
+// The body limit is checked before any middleware reads the body.
+func (m *Mux) HandlePublic(pattern, description string, h http.Handler) {
+	current := versioned(pattern)
+	limit, ok := m.bodyLimits[pattern]
+	if !ok {
+		limit = m.MaxBodyBytes
+	}
+	h = withPreferences(m.Preferences, h)
+	m.Handle(current, limitBody(limit, m.chain(h)))
+	m.Handle(pattern, limitBody(limit, m.chain(deprecated(current, m.Sunset, h))))
+	if description != "" {
+		m.routes = append(m.routes, Route{Pattern: current, Description: description})
+	}
+}
+
+// Set the largest request body for a route, such as an upload endpoint.
+// Must be called before the route is registered.
+func (m *Mux) LimitBody(pattern string, limit int64) {
+	m.bodyLimits[pattern] = limit
+}
+
+// List described routes in registration order
+func (m *Mux) Routes() []Route {
+	return m.routes
+}
+
+// Start serving the mux. Every response, including admin ones, carries an X-Request-ID.
+// Unknown routes get the error envelope rather than a plain-text 404.
+func Start(ctx context.Context, addr string, m *Mux) error {
+	m.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, "no endpoint at "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}))
+	var h http.Handler = m.ServeMux
+	if m.Compress {
+		h = Compress()(h)
+	}
+	root := http.NewServeMux()
+	root.Handle("/", RequestID()(h))
+	return gkserver.Start(ctx, addr, root)
+}
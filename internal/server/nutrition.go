@@ -0,0 +1,31 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"diabeticai-advisor/internal/nutrition"
+)
+
+// Handler to look up nutrient values for a food
+func nutritionHandler(client *nutrition.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("food")
+		if query == "" {
+			WriteError(w, r, "food is required", http.StatusBadRequest)
+			return
+		}
+
+		food, err := client.Lookup(r.Context(), query)
+		switch {
+		case errors.Is(err, nutrition.ErrNotFound):
+			WriteError(w, r, "food not found", http.StatusNotFound)
+		case errors.Is(err, nutrition.ErrDisabled):
+			WriteError(w, r, err.Error(), http.StatusServiceUnavailable)
+		case err != nil:
+			WriteError(w, r, "nutrition lookup failed", http.StatusBadGateway)
+		default:
+			WriteJSON(w, http.StatusOK, food)
+		}
+	}
+}
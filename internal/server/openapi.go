@@ -0,0 +1,130 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Describe the flow endpoints as an OpenAPI 3.1 document, with each flow's
+// input and output schemas under components. Client SDKs are generated from it.
+func (m *Mux) OpenAPI() map[string]any {
+	descriptions := map[string]string{}
+	for _, r := range m.routes {
+		descriptions[r.Pattern] = r.Description
+	}
+
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type":     "object",
+			"required": []string{"error"},
+			"properties": map[string]any{
+				"error": map[string]any{
+					"type":     "object",
+					"required": []string{"code", "message", "retryable"},
+					"properties": map[string]any{
+						"code":                map[string]any{"type": "string", "description": "invalid_argument, unauthenticated, permission_denied, not_found, too_large, blocked, rate_limited, model_error, upstream_error, unavailable, timeout or internal"},
+						"message":             map[string]any{"type": "string", "description": "Safe to show to users"},
+						"retryable":           map[string]any{"type": "boolean"},
+						"retry_after_seconds": map[string]any{"type": "integer"},
+						"request_id":          map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+	paths := map[string]any{}
+	for _, info := range m.Flows.List() {
+		flow, ok := m.Flows.Action(info.Name)
+		if !ok {
+			continue
+		}
+		method, path, _ := strings.Cut(info.Route, " ")
+		desc := flow.Desc()
+		input, output := exportedName(info.Name)+"Input", exportedName(info.Name)+"Output"
+		schemas[input] = schemaOrAny(desc.InputSchema)
+		schemas[output] = schemaOrAny(desc.OutputSchema)
+
+		paths[path] = map[string]any{
+			strings.ToLower(method): map[string]any{
+				"operationId": info.Name,
+				"summary":     descriptions[info.Route],
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": envelope("data", input)},
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "The flow's result",
+						"content": map[string]any{
+							"application/json": map[string]any{"schema": envelope("result", output)},
+						},
+					},
+					"default": map[string]any{
+						"description": "Error",
+						"content": map[string]any{
+							"application/json": map[string]any{"schema": ref("Error")},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "DiabetesAI Advisor",
+			"version": APIVersion,
+		},
+		"paths":    paths,
+		"security": []map[string]any{{"apiKey": []string{}}},
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+// Helper function to wrap a schema reference in a one-field envelope, as flows take {"data": ...} and return {"result": ...}
+func envelope(field, name string) map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"required":   []string{field},
+		"properties": map[string]any{field: ref(name)},
+	}
+}
+
+// Helper function to reference a component schema
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// Helper function to accept anything when a flow has no schema
+func schemaOrAny(schema map[string]any) map[string]any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	return schema
+}
+
+// Helper function to turn a flow name such as hypoRisk into HypoRisk
+func exportedName(name string) string {
+	r := []rune(name)
+	if len(r) == 0 {
+		return name
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// Handler to serve the OpenAPI description of the flow endpoints
+func openAPIHandler(m *Mux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, m.OpenAPI())
+	}
+}
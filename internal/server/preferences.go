@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+)
+
+// Preferences Response Struct
+type preferencesResponse struct {
+	store.Preferences
+	Effective locale.Prefs `json:"effective"`
+}
+
+// Wrap a handler so its context carries the requester's display preferences.
+// Saved preferences win; otherwise the request locale picks the defaults.
+// ?units= and ?clock= override either for a single request.
+func withPreferences(prefs *store.PreferenceStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var saved store.Preferences
+		if prefs != nil {
+			saved = prefs.Get(userIDFromRequest(r))
+		}
+		if saved.Locale == "" {
+			saved.Locale = requestLocale(r)
+		}
+		q := r.URL.Query()
+		if units := q.Get("units"); units != "" {
+			saved.Units = units
+		}
+		if clock := q.Get("clock"); clock != "" {
+			saved.Clock = clock
+		}
+
+		p, err := locale.Resolve(saved)
+		if err != nil {
+			log.Printf("Ignoring invalid preferences for %s: %v", saved.UserID, err)
+		}
+		next.ServeHTTP(w, r.WithContext(locale.With(r.Context(), p)))
+	})
+}
+
+// Handler to return the requester's saved and effective preferences
+func getPreferencesHandler(prefs *store.PreferenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, preferencesResponse{
+			Preferences: prefs.Get(userIDFromRequest(r)),
+			Effective:   locale.From(r.Context()),
+		})
+	}
+}
+
+// Handler to save the requester's preferences
+func putPreferencesHandler(prefs *store.PreferenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var p store.Preferences
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			WriteError(w, r, "invalid preferences: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		effective, err := locale.Resolve(p)
+		if err != nil {
+			WriteError(w, r, "invalid preferences: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		p.UserID = userIDFromRequest(r)
+		prefs.Set(p)
+		WriteJSON(w, http.StatusOK, preferencesResponse{Preferences: prefs.Get(p.UserID), Effective: effective})
+	}
+}
+
+// Handler to return the requester's notification preferences
+func getNotificationsHandler(notifications *store.NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, notifications.Get(userIDFromRequest(r)))
+	}
+}
+
+// Handler to save the requester's notification preferences
+func putNotificationsHandler(notifications *store.NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var p store.NotificationPreferences
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			WriteError(w, r, "invalid notification preferences: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := p.Validate(); err != nil {
+			WriteError(w, r, "invalid notification preferences: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		p.UserID = userIDFromRequest(r)
+		notifications.Set(p)
+		WriteJSON(w, http.StatusOK, notifications.Get(p.UserID))
+	}
+}
+
+// Handler to reset the requester's notification preferences to the defaults
+func deleteNotificationsHandler(notifications *store.NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		notifications.Delete(userIDFromRequest(r))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/kv"
+)
+
+// Fixed-window, per-client rate limiter. A limit of 0 disables limiting.
+// Counters live in the shared store so every replica enforces the same limit.
+type RateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	counters  kv.Store
+}
+
+// Create a rate limiter allowing perMinute requests per client
+func NewRateLimiter(perMinute int, counters kv.Store) *RateLimiter {
+	return &RateLimiter{perMinute: perMinute, counters: counters}
+}
+
+// Change the limit at runtime
+func (l *RateLimiter) SetLimit(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.perMinute = perMinute
+}
+
+// Current limit
+func (l *RateLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perMinute
+}
+
+// Record a request for a client and report whether it is allowed.
+// Requests are allowed if the counter store is unreachable.
+func (l *RateLimiter) Allow(ctx context.Context, client string, now time.Time) bool {
+	limit := l.Limit()
+	if limit <= 0 {
+		return true
+	}
+
+	key := fmt.Sprintf("ratelimit:%s:%d", client, now.Unix()/60)
+	count, err := l.counters.Incr(ctx, key, time.Minute)
+	if err != nil {
+		log.Printf("Rate limit check failed, allowing request: %v", err)
+		return true
+	}
+	return count <= int64(limit)
+}
+
+// Helper function to identify a client for rate limiting
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		ip, _, _ := strings.Cut(fwd, ",")
+		return strings.TrimSpace(ip)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/reminders"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to log a new reading
+func logReadingHandler(readings *store.ReadingStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reading store.GlucoseReading
+		if err := json.NewDecoder(r.Body).Decode(&reading); err != nil {
+			WriteError(w, r, "invalid reading: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if reading.Value <= 0 {
+			WriteError(w, r, "reading value must be positive", http.StatusBadRequest)
+			return
+		}
+		if reading.UserID == "" {
+			reading.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&reading.UserID, &reading.Timestamp)
+		readings.Add(r.Context(), reading)
+		WriteJSON(w, http.StatusCreated, reading)
+	}
+}
+
+// Handler to log a meal
+func logMealHandler(meals *store.LogStore[store.MealLog]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var meal store.MealLog
+		if err := json.NewDecoder(r.Body).Decode(&meal); err != nil {
+			WriteError(w, r, "invalid meal: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if meal.Description == "" && len(meal.Foods) == 0 {
+			WriteError(w, r, "meal description or foods are required", http.StatusBadRequest)
+			return
+		}
+		if meal.UserID == "" {
+			meal.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&meal.UserID, &meal.Timestamp)
+		meals.Add(r.Context(), meal)
+		WriteJSON(w, http.StatusCreated, meal)
+	}
+}
+
+// Handler to log a workout
+func logWorkoutHandler(workouts *store.LogStore[store.WorkoutLog]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var workout store.WorkoutLog
+		if err := json.NewDecoder(r.Body).Decode(&workout); err != nil {
+			WriteError(w, r, "invalid workout: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		workout.Type = strings.ToLower(strings.TrimSpace(workout.Type))
+		if workout.Type == "" || workout.DurationMinutes <= 0 {
+			WriteError(w, r, "workout type and duration_minutes are required", http.StatusBadRequest)
+			return
+		}
+		if workout.UserID == "" {
+			workout.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&workout.UserID, &workout.Timestamp)
+		workouts.Add(r.Context(), workout)
+		WriteJSON(w, http.StatusCreated, workout)
+	}
+}
+
+// Handler to log an insulin dose
+func logInsulinHandler(doses *store.LogStore[store.InsulinDose]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dose store.InsulinDose
+		if err := json.NewDecoder(r.Body).Decode(&dose); err != nil {
+			WriteError(w, r, "invalid dose: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if dose.Units <= 0 {
+			WriteError(w, r, "units must be positive", http.StatusBadRequest)
+			return
+		}
+		dose.InsulinType = strings.ToLower(strings.TrimSpace(dose.InsulinType))
+		if dose.InsulinType == "" {
+			dose.InsulinType = "rapid"
+		}
+		if dose.UserID == "" {
+			dose.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&dose.UserID, &dose.Timestamp)
+		doses.Add(r.Context(), dose)
+		WriteJSON(w, http.StatusCreated, dose)
+	}
+}
+
+// Handler to log water intake
+func logWaterHandler(water *store.LogStore[store.WaterLog]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entry store.WaterLog
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			WriteError(w, r, "invalid water entry: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if entry.Milliliters <= 0 {
+			WriteError(w, r, "ml must be positive", http.StatusBadRequest)
+			return
+		}
+		if entry.UserID == "" {
+			entry.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&entry.UserID, &entry.Timestamp)
+		water.Add(r.Context(), entry)
+		WriteJSON(w, http.StatusCreated, entry)
+	}
+}
+
+// Handler to return today's water intake
+func hydrationHandler(water *store.LogStore[store.WaterLog]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, analytics.TodayHydration(water, userIDFromRequest(r), time.Now(), locale.From(r.Context()).Location))
+	}
+}
+
+// Handler to return the reminders due now
+func remindersHandler(s *store.Stores) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, reminders.Due(s, userIDFromRequest(r), time.Now()))
+	}
+}
+
+// Handler to return the requester's confirmed medication schedule
+func medicationScheduleHandler(schedules *store.ScheduleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, schedules.Get(userIDFromRequest(r)))
+	}
+}
+
+// Handler to return the requester's exercise program with the current week's progress
+func exerciseProgramHandler(s *store.Stores) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := userIDFromRequest(r)
+		program, ok := s.Programs.Get(userID)
+		if !ok {
+			WriteError(w, r, "no exercise program; start one with POST /exercise/program", http.StatusNotFound)
+			return
+		}
+
+		week := program.Current()
+		workouts := s.Workouts.Range(userID, week.Start, week.End())
+		readings := s.Readings.Range(userID, week.Start.Add(-analytics.BaselineWindow), week.End().Add(24*time.Hour))
+		*week = analytics.TrackWeek(*week, workouts, readings)
+		WriteJSON(w, http.StatusOK, program)
+	}
+}
+
+// Handler to stop the requester's exercise program
+func deleteExerciseProgramHandler(programs *store.ProgramStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		programs.Delete(userIDFromRequest(r))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Handler to log a hypo event
+func logHypoHandler(hypos *store.LogStore[store.HypoEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var event store.HypoEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			WriteError(w, r, "invalid hypo event: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if event.LowestBG <= 0 {
+			WriteError(w, r, "lowest_bg must be positive", http.StatusBadRequest)
+			return
+		}
+		if event.Cause != "" && !slices.Contains(store.HypoCauses, event.Cause) {
+			WriteError(w, r, "cause must be one of "+strings.Join(store.HypoCauses, ", "), http.StatusBadRequest)
+			return
+		}
+		if event.UserID == "" {
+			event.UserID = userIDFromRequest(r)
+		}
+
+		store.Stamp(&event.UserID, &event.Timestamp)
+		hypos.Add(r.Context(), event)
+		WriteJSON(w, http.StatusCreated, event)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"diabeticai-advisor/internal/importer"
+	"diabeticai-advisor/internal/jobs"
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/sessions"
+	"diabeticai-advisor/internal/store"
+)
+
+// Register logging, metrics, sharing and dashboard endpoints
+func RegisterData(m *Mux, s *store.Stores) {
+	imports := importer.NewTracker()
+	m.HandlePublic("POST /readings", "Log a blood sugar reading", logReadingHandler(s.Readings))
+	m.HandlePublic("POST /meals", "Log a meal", logMealHandler(s.Meals))
+	m.HandlePublic("POST /workouts", "Log a workout", logWorkoutHandler(s.Workouts))
+	m.HandlePublic("GET /exercise/program", "Your exercise program with this week's completed sessions", exerciseProgramHandler(s))
+	m.HandlePublic("DELETE /exercise/program", "", deleteExerciseProgramHandler(s.Programs))
+	m.HandlePublic("POST /insulin", "Log an insulin dose", logInsulinHandler(s.Insulin))
+	m.HandlePublic("POST /hypos", "Log a low blood sugar event", logHypoHandler(s.Hypos))
+	m.HandlePublic("GET /hypos", "", listHandler(s.Hypos, 90))
+	m.HandlePublic("GET /activity", "Steps and heart rate received from wearable bridges", listHandler(s.Activity, 7))
+	m.HandlePublic("POST /water", "Log water intake", logWaterHandler(s.Water))
+	m.HandlePublic("GET /water/today", "Today's water intake against target", hydrationHandler(s.Water))
+	m.HandlePublic("POST /import", "Import a zip of glucometer, CGM, pump or Nightscout exports", startImportHandler(s, imports))
+	m.HandlePublic("GET /import/{id}", "", importStatusHandler(imports))
+	m.HandlePublic("GET /reminders", "Reminders due now, such as hydration nudges", remindersHandler(s))
+	m.HandlePublic("GET /medications/schedule", "Your confirmed daily medication schedule", medicationScheduleHandler(s.Schedules))
+	m.HandlePublic("GET /reminders/sent", "Reminders queued by the scheduler", listHandler(s.Reminders, 1))
+	m.HandlePublic("GET /stats", "Time-in-range and variability metrics", statsHandler(s.Readings))
+	m.HandlePublic("GET /thresholds", "Clinical threshold table in force, with its version and effective date", thresholdsHandler())
+	m.HandlePublic("GET /stats/daily", "Nightly per-day glucose and hydration rollups", listHandler(s.Rollups, 30))
+	m.HandlePublic("GET /agp", "Ambulatory Glucose Profile percentile curves", agpHandler(s.Readings))
+	m.HandlePublic("GET /iob", "Current insulin on board", iobHandler(s.Insulin))
+	m.HandlePublic("POST /shares", "Share your data with a caregiver", createShareHandler(s.Shares))
+	m.HandlePublic("GET /shares", "", listSharesHandler(s.Shares))
+	m.HandlePublic("DELETE /shares/{id}", "", revokeShareHandler(s.Shares))
+	m.HandlePublic("GET /summaries", "Weekly summaries generated by the scheduler", listHandler(s.Summaries, 90))
+	m.HandlePublic("GET /reviews", "End-of-day reviews and the intentions they set", listHandler(s.DayReviews, 30))
+	m.HandlePublic("GET /preferences", "Your units, clock and locale settings", getPreferencesHandler(s.Preferences))
+	m.HandlePublic("PUT /preferences", "", putPreferencesHandler(s.Preferences))
+	m.HandlePublic("GET /preferences/notifications", "Reminder channels, quiet hours, alert thresholds and digest frequency", getNotificationsHandler(s.Notifications))
+	m.HandlePublic("PUT /preferences/notifications", "", putNotificationsHandler(s.Notifications))
+	m.HandlePublic("DELETE /preferences/notifications", "", deleteNotificationsHandler(s.Notifications))
+	m.HandlePublic("GET /alarms/settings", "Glucose alarm rules and emergency contacts", getAlarmSettingsHandler(s.AlarmRules))
+	m.HandlePublic("PUT /alarms/settings", "", putAlarmSettingsHandler(s.AlarmRules))
+	m.HandlePublic("GET /alarms", "Glucose alarms sounding now", listAlarmsHandler(s.Alarms))
+	m.HandlePublic("POST /alarms/{id}/acknowledge", "", acknowledgeAlarmHandler(s.Alarms))
+	m.HandlePublic("POST /alarms/{id}/snooze", "", snoozeAlarmHandler(s.Alarms))
+	m.HandlePublic("GET /dashboard", "Latest reading, TIR, and alerts for caregivers", dashboardHandler(s.Readings, s.Shares))
+	m.HandlePublic("GET /export/clinician", "Stats, AGP, doses and symptom checks for your clinician", clinicianExportHandler(s))
+	m.Handle(versioned("GET /changelog"), changelogHandler())
+	m.Handle(versioned("GET /openapi.json"), openAPIHandler(m))
+}
+
+// Register endpoints to list, resume and close multi-turn conversations
+func RegisterSessions(m *Mux, st *sessions.Store) {
+	m.HandlePublic("GET /sessions", "Your open conversations, such as symptom checks waiting for answers", listSessionsHandler(st))
+	m.HandlePublic("GET /sessions/{id}", "", getSessionHandler(st))
+	m.HandlePublic("DELETE /sessions/{id}", "", deleteSessionHandler(st))
+}
+
+// Register the nutrition lookup endpoint
+func RegisterNutrition(m *Mux, client *nutrition.Client) {
+	m.HandlePublic("GET /nutrition", "Nutrient values per 100 g from USDA FoodData Central", nutritionHandler(client))
+}
+
+// Register the job monitoring and trigger endpoints. Like the admin endpoints, they need the admin key.
+func RegisterJobs(m *Mux, sched *jobs.Scheduler, adminKey string) {
+	m.Handle("GET /jobs", requireAdmin(adminKey, m.Keys, jobsHandler(sched)))
+	m.Handle("POST /jobs/{name}/run", requireAdmin(adminKey, m.Keys, runJobHandler(sched)))
+}
+
+// Register the response evaluation summary. Like the admin endpoints, it needs the admin key.
+func RegisterEvaluations(m *Mux, evals *store.LogStore[store.Evaluation], alertPercent int, adminKey string) {
+	m.Handle("GET /admin/evaluations", requireAdmin(adminKey, m.Keys, evaluationsHandler(evals, alertPercent)))
+}
+
+// Register the model cost report. Like the admin endpoints, it needs the admin key.
+func RegisterCosts(m *Mux, usage *store.LogStore[store.ModelUsage], adminKey string) {
+	m.Handle("GET /costs", requireAdmin(adminKey, m.Keys, costsHandler(usage)))
+}
+
+// Register the shadow-mode diff report. Like the admin endpoints, it needs the admin key.
+func RegisterShadow(m *Mux, runs *store.LogStore[store.ShadowRun], adminKey string) {
+	m.Handle("GET /admin/shadow", requireAdmin(adminKey, m.Keys, shadowHandler(runs)))
+}
+
+// Register admin endpoints. They reject every request when adminKey is empty.
+func RegisterAdmin(m *Mux, adminKey string) {
+	m.Handle("GET /admin/flows", requireAdmin(adminKey, m.Keys, adminListFlowsHandler(m.Flows)))
+	m.Handle("PUT /admin/flows/{name}", requireAdmin(adminKey, m.Keys, adminToggleFlowHandler(m.Flows)))
+	m.Handle("GET /admin/metrics", requireAdmin(adminKey, m.Keys, adminMetricsHandler(m.Metrics)))
+	m.Handle("GET /admin/prompts", requireAdmin(adminKey, m.Keys, adminPromptsHandler()))
+	m.Handle("GET /admin/keys", requireAdmin(adminKey, m.Keys, adminListKeysHandler(m.Keys)))
+	m.Handle("POST /admin/keys/rotate", requireAdmin(adminKey, m.Keys, adminRotateKeyHandler(m.Keys)))
+	m.Handle("DELETE /admin/keys/{id}", requireAdmin(adminKey, m.Keys, adminRevokeKeyHandler(m.Keys)))
+	m.Handle("GET /admin/ratelimit", requireAdmin(adminKey, m.Keys, adminRateLimitHandler(m.Limiter)))
+	m.Handle("PUT /admin/ratelimit", requireAdmin(adminKey, m.Keys, adminRateLimitHandler(m.Limiter)))
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"diabeticai-advisor/internal/sessions"
+)
+
+// Handler to list the requester's open conversations, to resume on any device
+func listSessionsHandler(st *sessions.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := st.List(r.Context(), userIDFromRequest(r))
+		if err != nil {
+			WriteErrorFor(w, r, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, list)
+	}
+}
+
+// Handler to return one conversation with its messages
+func getSessionHandler(st *sessions.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, err := st.Get(r.Context(), r.PathValue("id"), userIDFromRequest(r))
+		if errors.Is(err, sessions.ErrNotFound) {
+			WriteError(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			WriteErrorFor(w, r, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, session)
+	}
+}
+
+// Handler to close a conversation
+func deleteSessionHandler(st *sessions.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, err := st.Get(r.Context(), r.PathValue("id"), userIDFromRequest(r))
+		if errors.Is(err, sessions.ErrNotFound) {
+			WriteError(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err == nil {
+			err = st.Delete(r.Context(), r.PathValue("id"))
+		}
+		if err != nil {
+			WriteErrorFor(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
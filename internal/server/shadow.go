@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+
+	"diabeticai-advisor/internal/shadow"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to report how shadow candidates compared with live answers over a window
+func shadowHandler(runs *store.LogStore[store.ShadowRun]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := windowFromRequest(r, 7)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]any{
+			"enabled":    shadow.Enabled(),
+			"from":       from,
+			"to":         to,
+			"candidates": shadow.BuildReport(runs, from, to),
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/store"
+)
+
+// Handler to grant read access to a caregiver
+func createShareHandler(shares *store.ShareStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			GranteeID    string `json:"grantee_id"`
+			Relationship string `json:"relationship"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, r, "invalid share: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		ownerID := userIDFromRequest(r)
+		if req.GranteeID == "" || req.GranteeID == ownerID {
+			WriteError(w, r, "grantee_id must be another user", http.StatusBadRequest)
+			return
+		}
+
+		WriteJSON(w, http.StatusCreated, shares.Grant(ownerID, req.GranteeID, req.Relationship))
+	}
+}
+
+// Handler to list grants given and received
+func listSharesHandler(shares *store.ShareStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		given, received := shares.List(userIDFromRequest(r))
+		WriteJSON(w, http.StatusOK, map[string][]store.ShareGrant{
+			"given":    given,
+			"received": received,
+		})
+	}
+}
+
+// Handler to revoke a grant
+func revokeShareHandler(shares *store.ShareStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !shares.Revoke(userIDFromRequest(r), r.PathValue("id")) {
+			WriteError(w, r, "share not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Handler to return the dashboard for the requester or a patient shared with them
+func dashboardHandler(readings *store.ReadingStore, shares *store.ShareStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		viewerID := userIDFromRequest(r)
+		patientID := r.URL.Query().Get("patient_id")
+		if patientID == "" {
+			patientID = viewerID
+		}
+		if !shares.CanRead(viewerID, patientID) {
+			WriteError(w, r, "you do not have access to this patient's data", http.StatusForbidden)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, analytics.BuildDashboard(readings, patientID, time.Now(), locale.From(r.Context())))
+	}
+}
+
+// Handler to return the clinician export for the requester or a patient shared with them
+func clinicianExportHandler(s *store.Stores) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		viewerID := userIDFromRequest(r)
+		patientID := r.URL.Query().Get("patient_id")
+		if patientID == "" {
+			patientID = viewerID
+		}
+		if !s.Shares.CanRead(viewerID, patientID) {
+			WriteError(w, r, "you do not have access to this patient's data", http.StatusForbidden)
+			return
+		}
+
+		from, to, err := windowFromRequest(r, 90)
+		if err != nil {
+			WriteError(w, r, "invalid window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, analytics.BuildClinicianExport(s, patientID, from, to))
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Current API version, used as the path prefix for public endpoints
+const APIVersion = "v1"
+
+// Changelog Entry Struct
+type ChangelogEntry struct {
+	Version string   `json:"version"`
+	Date    string   `json:"date"`
+	Changes []string `json:"changes"`
+}
+
+// API changes, newest first
+var changelog = []ChangelogEntry{
+	{
+		Version: "v1",
+		Date:    "2026-10-16",
+		Changes: []string{
+			"All public endpoints are served under /v1.",
+			"Unversioned paths still work but are deprecated and send Deprecation, Sunset and Link headers.",
+			"GET /v1/openapi.json describes every flow endpoint; typed Go and TypeScript clients are generated from it under sdk/.",
+		},
+	},
+}
+
+// Helper function to prefix a route pattern's path with the API version
+func versioned(pattern string) string {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return "/" + APIVersion + pattern
+	}
+	return method + " /" + APIVersion + path
+}
+
+// Mark responses from a legacy unversioned route as deprecated,
+// pointing clients at the versioned successor
+func deprecated(successor string, sunset time.Time, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		if !sunset.IsZero() {
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		w.Header().Set("Link", "<"+successorPath(successor, r)+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Helper function to fill path wildcards in a successor pattern from the request
+func successorPath(pattern string, r *http.Request) string {
+	_, path, _ := strings.Cut(pattern, " ")
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			segments[i] = r.PathValue(strings.TrimSuffix(name, "}"))
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Handler to list API changes
+func changelogHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, changelog)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/rbac"
+	"diabeticai-advisor/internal/voice"
+)
+
+// Largest voice request accepted
+const maxVoiceBytes = 64 << 10
+
+// Register the Alexa and Google Assistant webhooks. They sit behind the usual
+// middleware, so the skill or action must send an API key bound to the patient
+// (e.g. ?api_key= in the endpoint URL). When alexaSkillID is set, Alexa
+// requests from other skills are refused.
+func RegisterVoice(m *Mux, alexaSkillID string) {
+	m.HandlePublic("POST /voice/alexa", "Alexa skill webhook: log a reading or ask what to eat", voiceHandler(m.Flows, voice.Alexa, alexaSkillID))
+	m.HandlePublic("POST /voice/google", "Google Assistant webhook: log a reading or ask what to eat", voiceHandler(m.Flows, voice.Google, ""))
+}
+
+// Handler to map a voice intent to its flow and speak a short answer.
+// Problems are spoken rather than returned as HTTP errors, so the assistant
+// tells the user what went wrong.
+func voiceHandler(flows *FlowRegistry, platform, skillID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxVoiceBytes))
+		if err != nil {
+			WriteError(w, r, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		parse := voice.ParseAlexa
+		if platform == voice.Google {
+			parse = voice.ParseGoogle
+		}
+		req, err := parse(body)
+		if err != nil {
+			WriteError(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if skillID != "" && req.SkillID != skillID {
+			WriteError(w, r, "unknown skill", http.StatusForbidden)
+			return
+		}
+
+		say := func(speech string, end bool) {
+			WriteJSON(w, http.StatusOK, voice.Respond(platform, req.SessionID, speech, end))
+		}
+		switch {
+		case req.Kind == voice.KindEnd:
+			w.WriteHeader(http.StatusOK)
+			return
+		case req.Kind == voice.KindLaunch, voice.IsHelp(req.Intent):
+			say(voice.Welcome, false)
+			return
+		case voice.IsStop(req.Intent):
+			say(voice.Goodbye, true)
+			return
+		}
+
+		intent, ok := voice.Intents[req.Intent]
+		if !ok {
+			say(voice.NotKnown, false)
+			return
+		}
+		flow, ok := flows.Action(intent.Flow)
+		if !ok || !flows.Enabled(intent.Flow) {
+			say("Sorry, that feature is unavailable right now.", true)
+			return
+		}
+		input, err := intent.Input(req.Slots)
+		if err != nil {
+			say(err.Error(), false)
+			return
+		}
+		data, _ := json.Marshal(input)
+
+		ctx := costs.WithUser(r.Context(), rbac.User(r.Context()))
+		out, err := flow.RunJSON(ctx, data, nil)
+		if err != nil {
+			log.Printf("Voice %s intent %s failed: %v", platform, req.Intent, err)
+			say("Sorry, something went wrong. Please try again later.", true)
+			return
+		}
+		say(intent.Speak(out, req.Slots), true)
+	}
+}
@@ -0,0 +1,230 @@
+// Package sessions keeps multi-turn conversation state in the shared store,
+// so any replica can continue a conversation started on another, or in a
+// SQLite database, so conversations also survive restarts.
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/store"
+)
+
+// Returned when a session does not exist or has expired
+var ErrNotFound = errors.New("session not found or expired")
+
+// Message Struct
+type Message struct {
+	Role      string    `json:"role" jsonschema:"description=user or model"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Session Struct
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Flow      string    `json:"flow"`
+	Messages  []Message `json:"messages"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Earlier messages folded into a summary to keep long conversations
+	// within the model's context; all messages are still kept
+	Summary    string `json:"summary,omitempty"`
+	Summarized int    `json:"summarized,omitempty" jsonschema:"description=Number of leading messages the summary covers"`
+}
+
+// Append a message to the session
+func (s *Session) Add(role, content string) {
+	s.Messages = append(s.Messages, Message{Role: role, Content: content, Timestamp: time.Now()})
+}
+
+// Messages since the summary
+func (s *Session) Recent() []Message {
+	return s.Messages[min(s.Summarized, len(s.Messages)):]
+}
+
+// Summary Struct
+//
+// A session in a list, without its messages.
+type Summary struct {
+	ID           string    `json:"id"`
+	Flow         string    `json:"flow"`
+	Messages     int       `json:"messages"`
+	LastQuestion string    `json:"last_question,omitempty" jsonschema:"description=The last thing the assistant asked"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Helper function to summarize a session for a list
+func (s *Session) summary(ttl time.Duration) Summary {
+	sum := Summary{ID: s.ID, Flow: s.Flow, Messages: len(s.Messages), UpdatedAt: s.UpdatedAt, ExpiresAt: s.UpdatedAt.Add(ttl)}
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role == "model" {
+			sum.LastQuestion = s.Messages[i].Content
+			break
+		}
+	}
+	return sum
+}
+
+// Where sessions are kept: the shared key-value store, or the database
+type backend interface {
+	get(ctx context.Context, id string) ([]byte, error)
+	save(ctx context.Context, s *Session, data []byte, ttl time.Duration) error
+	delete(ctx context.Context, id string) error
+	list(ctx context.Context, userID string) ([][]byte, error)
+}
+
+// Session store with an idle expiry
+type Store struct {
+	backend backend
+	ttl     time.Duration
+}
+
+// Create a session store in the shared key-value store. Sessions expire after ttl without activity.
+func NewStore(shared kv.Store, ttl time.Duration) *Store {
+	return &Store{backend: kvBackend{shared}, ttl: ttl}
+}
+
+// Create a session store in a SQLite database, so sessions survive restarts.
+// Sessions expire after ttl without activity.
+func NewSQLStore(db *sql.DB, ttl time.Duration) *Store {
+	return &Store{backend: sqlBackend{db}, ttl: ttl}
+}
+
+// Start a new session for a user and flow
+func (st *Store) Start(userID, flow string) *Session {
+	return &Session{ID: store.NewID(), UserID: userID, Flow: flow, Messages: []Message{}}
+}
+
+// Load a session, checking it belongs to the user
+func (st *Store) Get(ctx context.Context, id, userID string) (*Session, error) {
+	data, err := st.backend.get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if s.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+// List a user's open sessions, most recent first, so a conversation can be
+// resumed on another device
+func (st *Store) List(ctx context.Context, userID string) ([]Summary, error) {
+	found, err := st.backend.list(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	out := []Summary{}
+	for _, data := range found {
+		var s Session
+		if json.Unmarshal(data, &s) != nil || s.UserID != userID {
+			continue
+		}
+		out = append(out, s.summary(st.ttl))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	return out, nil
+}
+
+// Save a session, extending its expiry
+func (st *Store) Save(ctx context.Context, s *Session) error {
+	s.UpdatedAt = time.Now()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := st.backend.save(ctx, s, data, st.ttl); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Remove a session
+func (st *Store) Delete(ctx context.Context, id string) error {
+	return st.backend.delete(ctx, id)
+}
+
+// Sessions in the shared key-value store. Each user's session IDs are kept
+// under one key so they can be listed.
+type kvBackend struct {
+	kv kv.Store
+}
+
+func (b kvBackend) get(ctx context.Context, id string) ([]byte, error) {
+	data, err := b.kv.Get(ctx, "session:"+id)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (b kvBackend) save(ctx context.Context, s *Session, data []byte, ttl time.Duration) error {
+	if err := b.kv.Set(ctx, "session:"+s.ID, data, ttl); err != nil {
+		return err
+	}
+	ids := b.ids(ctx, s.UserID)
+	if slices.Contains(ids, s.ID) {
+		return nil
+	}
+	index, err := json.Marshal(append(ids, s.ID))
+	if err != nil {
+		return err
+	}
+	return b.kv.Set(ctx, "sessions:user:"+s.UserID, index, 0)
+}
+
+func (b kvBackend) delete(ctx context.Context, id string) error {
+	return b.kv.Delete(ctx, "session:"+id)
+}
+
+func (b kvBackend) list(ctx context.Context, userID string) ([][]byte, error) {
+	var found [][]byte
+	var live []string
+	ids := b.ids(ctx, userID)
+	for _, id := range ids {
+		data, err := b.get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, data)
+		live = append(live, id)
+	}
+
+	// Forget sessions that have expired or been closed
+	if len(live) < len(ids) {
+		if index, err := json.Marshal(live); err == nil {
+			b.kv.Set(ctx, "sessions:user:"+userID, index, 0)
+		}
+	}
+	return found, nil
+}
+
+// Helper function to read a user's session IDs
+func (b kvBackend) ids(ctx context.Context, userID string) []string {
+	var ids []string
+	if data, err := b.kv.Get(ctx, "sessions:user:"+userID); err == nil {
+		json.Unmarshal(data, &ids)
+	}
+	return ids
+}
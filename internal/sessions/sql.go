@@ -0,0 +1,60 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Sessions in the sessions table. Expired rows are skipped when read and
+// removed whenever a session is saved.
+type sqlBackend struct {
+	db *sql.DB
+}
+
+func (b sqlBackend) get(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRowContext(ctx, `SELECT data FROM sessions WHERE id = ? AND expires_at > ?`, id, time.Now().UnixMilli()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (b sqlBackend) save(ctx context.Context, s *Session, data []byte, ttl time.Duration) error {
+	now := time.Now()
+	expires := now.Add(ttl)
+	if ttl <= 0 {
+		expires = now.AddDate(100, 0, 0)
+	}
+	if _, err := b.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= ?`, now.UnixMilli()); err != nil {
+		return err
+	}
+	_, err := b.db.ExecContext(ctx, `INSERT INTO sessions (id, user_id, flow, data, updated_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at, expires_at = excluded.expires_at`,
+		s.ID, s.UserID, s.Flow, string(data), s.UpdatedAt.UnixMilli(), expires.UnixMilli())
+	return err
+}
+
+func (b sqlBackend) delete(ctx context.Context, id string) error {
+	_, err := b.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+func (b sqlBackend) list(ctx context.Context, userID string) ([][]byte, error) {
+	rows, err := b.db.QueryContext(ctx, `SELECT data FROM sessions WHERE user_id = ? AND expires_at > ? ORDER BY updated_at DESC`, userID, time.Now().UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var found [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		found = append(found, data)
+	}
+	return found, rows.Err()
+}
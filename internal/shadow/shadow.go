@@ -0,0 +1,156 @@
+// Package shadow runs candidate prompts and models next to live traffic. A
+// sample of generations is repeated with the candidate in the background; both
+// answers are stored and compared, and the candidate's is never returned.
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/replay"
+	"diabeticai-advisor/internal/requestid"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Config Struct
+type Config struct {
+	Model         string
+	PromptsDir    string
+	SamplePercent int
+	Flows         []string
+}
+
+// Runs a generation with a prompt, on the named model or the default when empty
+type Generator func(ctx context.Context, prompt, model string) (*ai.ModelResponse, error)
+
+// Where runs are stored, and the candidates they are made with
+var (
+	mu         sync.RWMutex
+	runs       *store.LogStore[store.ShadowRun]
+	config     Config
+	candidates map[string]string
+)
+
+// Context key marking a shadow generation, so it is never shadowed itself
+type key struct{}
+
+// Shadow a sample of traffic with the candidate model and the candidate
+// templates in cfg.PromptsDir. With neither set, nothing is shadowed.
+func Configure(s *store.LogStore[store.ShadowRun], cfg Config) error {
+	loaded := map[string]string{}
+	if cfg.PromptsDir != "" {
+		var err error
+		if loaded, err = prompts.ReadCandidates(cfg.PromptsDir); err != nil {
+			return fmt.Errorf("failed to load shadow prompts: %w", err)
+		}
+	}
+	if cfg.SamplePercent < 0 || cfg.SamplePercent > 100 {
+		return fmt.Errorf("shadow sample percent must be between 0 and 100")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	runs, config, candidates = s, cfg, loaded
+	return nil
+}
+
+// Report whether a candidate is configured
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return runs != nil && config.SamplePercent > 0 && (config.Model != "" || len(candidates) > 0)
+}
+
+// Repeat a sample of live generations with the candidate in the background.
+// The prompt is re-rendered with the candidate template it was built from, if
+// any; otherwise only the model changes. Shadow runs are skipped in replay mode,
+// since fixtures only hold live answers.
+func Sample(ctx context.Context, flow, userID, prompt string, live *ai.ModelResponse, generate Generator) {
+	if !Enabled() || ctx.Value(key{}) != nil || replay.Mode() == replay.Replay {
+		return
+	}
+	mu.RLock()
+	cfg, s := config, runs
+	mu.RUnlock()
+	if len(cfg.Flows) > 0 && !slices.Contains(cfg.Flows, flow) {
+		return
+	}
+
+	name, shadowPrompt := candidateFor(prompt)
+	if name == "" && cfg.Model == "" {
+		return
+	}
+	if rand.IntN(100) >= cfg.SamplePercent {
+		return
+	}
+
+	run := store.ShadowRun{
+		UserID:      userID,
+		Flow:        flow,
+		Prompt:      name,
+		RequestID:   requestid.From(ctx),
+		LiveModel:   costs.Model(ctx),
+		ShadowModel: cfg.Model,
+		LiveOutput:  live.Text(),
+	}
+	if run.ShadowModel == "" {
+		run.ShadowModel = run.LiveModel
+	}
+	if name != "" {
+		run.LiveVersion = prompts.Version(name)
+		run.ShadowVersion = prompts.TemplateVersion(candidate(name))
+	}
+
+	go func() {
+		ctx := context.WithValue(context.WithoutCancel(ctx), key{}, true)
+		ctx = costs.WithFlow(ctx, "shadow:"+flow, run.ShadowModel)
+		result, err := generate(ctx, shadowPrompt, cfg.Model)
+		if err != nil {
+			log.Printf("Shadow run failed flow=%s request_id=%s: %v", flow, run.RequestID, err)
+			run.Error = err.Error()
+		} else {
+			run.ShadowOutput = result.Text()
+			compare(&run)
+			var in, out int
+			if result.Usage != nil {
+				in, out = result.Usage.InputTokens, result.Usage.OutputTokens+result.Usage.ThoughtsTokens
+			}
+			costs.Record(ctx, flow, in, out)
+		}
+		store.Stamp(&run.UserID, &run.Timestamp)
+		s.Add(ctx, run)
+	}()
+}
+
+// Helper function to find the candidate template a prompt can be re-rendered with.
+// Returns the prompt unchanged and no name when there is none.
+func candidateFor(prompt string) (string, string) {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if rewritten, ok := prompts.Rewrite(name, prompt, candidates[name]); ok {
+			return name, rewritten
+		}
+	}
+	return "", prompt
+}
+
+// Helper function to look up a candidate template
+func candidate(name string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return candidates[name]
+}
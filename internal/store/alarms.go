@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alarm states. An active alarm repeats and escalates; a snoozed one waits
+// until its snooze ends; an acknowledged one stays quiet until readings recover.
+const (
+	AlarmActive       = "active"
+	AlarmSnoozed      = "snoozed"
+	AlarmAcknowledged = "acknowledged"
+)
+
+// Alarm Rule Struct
+//
+// A glucose level to alarm on, e.g. "below 70 for 15m" or "above 300".
+type AlarmRule struct {
+	ID            string `json:"id" jsonschema:"description=Name for the rule, e.g. low. Defaults to rule-1, rule-2, ..."`
+	Rule          string `json:"rule" jsonschema:"description=below or above a level in mg/dL, optionally held for a time, e.g. below 70 for 15m"`
+	EscalateAfter int    `json:"escalate_after_minutes,omitempty" jsonschema:"description=Alert emergency contacts if the alarm is not acknowledged or snoozed within this many minutes (default 15)"`
+}
+
+// Alarm Condition Struct
+//
+// A parsed alarm rule.
+type AlarmCondition struct {
+	Below bool
+	Level float64
+	For   time.Duration
+}
+
+// Parse the rule
+func (r AlarmRule) Condition() (AlarmCondition, error) {
+	fields := strings.Fields(strings.ToLower(r.Rule))
+	var c AlarmCondition
+	if len(fields) != 2 && len(fields) != 4 {
+		return c, fmt.Errorf("invalid alarm rule %q: use \"below 70\", \"above 300\" or \"below 70 for 15m\"", r.Rule)
+	}
+	switch fields[0] {
+	case "below":
+		c.Below = true
+	case "above":
+	default:
+		return c, fmt.Errorf("invalid alarm rule %q: start with below or above", r.Rule)
+	}
+	level, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "mg/dl"), 64)
+	if err != nil || level < 20 || level > 600 {
+		return c, fmt.Errorf("invalid alarm level %q: use mg/dL between 20 and 600", fields[1])
+	}
+	c.Level = level
+	if len(fields) == 4 {
+		d, err := time.ParseDuration(fields[3])
+		if fields[2] != "for" || err != nil || d < 0 || d > 4*time.Hour {
+			return c, fmt.Errorf("invalid alarm duration in %q: use for 15m, up to 4h", r.Rule)
+		}
+		c.For = d
+	}
+	return c, nil
+}
+
+// Report whether a reading is past the level
+func (c AlarmCondition) Past(value float64) bool {
+	if c.Below {
+		return value < c.Level
+	}
+	return value > c.Level
+}
+
+// Emergency Contact Struct
+type EmergencyContact struct {
+	Name   string `json:"name"`
+	Email  string `json:"email,omitempty" jsonschema:"description=Emailed when an alarm is escalated"`
+	UserID string `json:"user_id,omitempty" jsonschema:"description=App user, such as a caregiver, who gets an in-app alert when an alarm is escalated"`
+}
+
+// Alarm Settings Struct
+type AlarmSettings struct {
+	UserID   string             `json:"user_id"`
+	Rules    []AlarmRule        `json:"rules"`
+	Contacts []EmergencyContact `json:"contacts"`
+	Updated  time.Time          `json:"updated"`
+}
+
+// Check the settings are well formed, naming unnamed rules
+func (a *AlarmSettings) Validate() error {
+	ids := map[string]bool{}
+	for i := range a.Rules {
+		r := &a.Rules[i]
+		if r.ID == "" {
+			r.ID = fmt.Sprintf("rule-%d", i+1)
+		}
+		if ids[r.ID] {
+			return fmt.Errorf("duplicate rule id %q", r.ID)
+		}
+		ids[r.ID] = true
+		if _, err := r.Condition(); err != nil {
+			return err
+		}
+		if r.EscalateAfter < 0 || r.EscalateAfter > 240 {
+			return fmt.Errorf("escalate_after_minutes must be between 0 (the default) and 240")
+		}
+	}
+	for _, c := range a.Contacts {
+		if c.Email == "" && c.UserID == "" {
+			return fmt.Errorf("contact %q needs an email or a user_id", c.Name)
+		}
+		if c.Email != "" {
+			if _, err := mail.ParseAddress(c.Email); err != nil {
+				return fmt.Errorf("invalid email %q", c.Email)
+			}
+		}
+	}
+	return nil
+}
+
+// In-memory store of each user's alarm rules and emergency contacts
+type AlarmRuleStore struct {
+	mu       sync.RWMutex
+	settings map[string]AlarmSettings
+	kind     string
+	backend  Backend
+}
+
+// Create an empty alarm rule store
+func NewAlarmRuleStore() *AlarmRuleStore {
+	return &AlarmRuleStore{settings: make(map[string]AlarmSettings)}
+}
+
+// Return a user's alarm settings, empty if none are saved
+func (s *AlarmRuleStore) Get(userID string) AlarmSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.settings[userID]
+	if !ok {
+		return AlarmSettings{UserID: userID, Rules: []AlarmRule{}, Contacts: []EmergencyContact{}}
+	}
+	return a
+}
+
+// Replace a user's alarm settings
+func (s *AlarmRuleStore) Set(a AlarmSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.Rules == nil {
+		a.Rules = []AlarmRule{}
+	}
+	if a.Contacts == nil {
+		a.Contacts = []EmergencyContact{}
+	}
+	a.Updated = time.Now()
+	s.settings[a.UserID] = a
+	put(context.Background(), s.backend, s.kind, a.UserID, a)
+}
+
+// Load alarm settings from a backend and persist later changes to it
+func (s *AlarmRuleStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(userID string, a AlarmSettings) { s.settings[userID] = a })
+}
+
+// Return every user with alarm rules
+func (s *AlarmRuleStore) Users() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make([]string, 0, len(s.settings))
+	for userID, a := range s.settings {
+		if len(a.Rules) > 0 {
+			users = append(users, userID)
+		}
+	}
+	return users
+}
+
+// Alarm Struct
+//
+// A rule firing for a user, from when it is raised until readings recover.
+type Alarm struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	RuleID         string    `json:"rule_id"`
+	Rule           string    `json:"rule"`
+	State          string    `json:"state" jsonschema:"description=active, snoozed or acknowledged"`
+	Value          float64   `json:"value_mgdl" jsonschema:"description=Latest reading, in mg/dL"`
+	Since          time.Time `json:"since" jsonschema:"description=First reading in the run past the level"`
+	RaisedAt       time.Time `json:"raised_at"`
+	NotifiedAt     time.Time `json:"notified_at"`
+	SnoozedUntil   time.Time `json:"snoozed_until,omitempty"`
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitempty"`
+	EscalatedAt    time.Time `json:"escalated_at,omitempty"`
+}
+
+// In-memory store of firing alarms. Alarms are removed once readings recover.
+type AlarmStore struct {
+	mu      sync.RWMutex
+	alarms  map[string]Alarm
+	kind    string
+	backend Backend
+}
+
+// Create an empty alarm store
+func NewAlarmStore() *AlarmStore {
+	return &AlarmStore{alarms: make(map[string]Alarm)}
+}
+
+// Return a user's firing alarms, oldest first
+func (s *AlarmStore) Active(userID string) []Alarm {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := []Alarm{}
+	for _, a := range s.alarms {
+		if a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RaisedAt.Before(out[j].RaisedAt) })
+	return out
+}
+
+// Save an alarm, assigning an ID to a new one
+func (s *AlarmStore) Put(a Alarm) Alarm {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.ID == "" {
+		a.ID = NewID()
+	}
+	s.alarms[a.ID] = a
+	put(context.Background(), s.backend, s.kind, a.ID, a)
+	return a
+}
+
+// Remove an alarm once it has cleared
+func (s *AlarmStore) Clear(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.alarms, id)
+	remove(context.Background(), s.backend, s.kind, id)
+}
+
+// Acknowledge one of a user's alarms, stopping repeats and escalation until it clears
+func (s *AlarmStore) Acknowledge(userID, id string, now time.Time) (Alarm, bool) {
+	return s.update(userID, id, func(a *Alarm) {
+		a.State = AlarmAcknowledged
+		a.AcknowledgedAt = now
+	})
+}
+
+// Snooze one of a user's alarms until a time, after which it sounds again
+func (s *AlarmStore) Snooze(userID, id string, until time.Time) (Alarm, bool) {
+	return s.update(userID, id, func(a *Alarm) {
+		a.State = AlarmSnoozed
+		a.SnoozedUntil = until
+	})
+}
+
+// Change an alarm in place, so a concurrent acknowledgement isn't lost.
+// Reports false if the alarm has cleared.
+func (s *AlarmStore) Update(id string, fn func(a *Alarm)) (Alarm, bool) {
+	return s.update("", id, fn)
+}
+
+// Helper function to change one of a user's alarms, or any alarm when userID is empty
+func (s *AlarmStore) update(userID, id string, fn func(a *Alarm)) (Alarm, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.alarms[id]
+	if !ok || (userID != "" && a.UserID != userID) {
+		return Alarm{}, false
+	}
+	fn(&a)
+	s.alarms[id] = a
+	put(context.Background(), s.backend, s.kind, id, a)
+	return a, true
+}
+
+// Load alarms from a backend and persist later changes to it
+func (s *AlarmStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(id string, a Alarm) { s.alarms[id] = a })
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"diabeticai-advisor/internal/requestid"
+)
+
+// Backend is where stores persist what they hold, so it survives restarts.
+// Stores keep everything in memory for queries and write through to the
+// backend; Open loads it back at startup.
+//
+// Logs (readings, meals, doses, ...) are appended records; profiles
+// (preferences, schedules, programs, shares) are documents replaced whole.
+type Backend interface {
+	// Append a record to a user's log of a kind, such as "readings"
+	Append(ctx context.Context, kind, userID string, at time.Time, data []byte) error
+	// Call fn with every record of a kind, oldest first
+	Records(ctx context.Context, kind string, fn func(data []byte) error) error
+	// Save the document of a kind under a key, replacing any earlier one
+	Put(ctx context.Context, kind, key string, data []byte) error
+	// Remove the document of a kind under a key
+	Delete(ctx context.Context, kind, key string) error
+	// Call fn with every document of a kind
+	Documents(ctx context.Context, kind string, fn func(key string, data []byte) error) error
+}
+
+// Helper function to save a document, logging a failure. The in-memory copy
+// stays authoritative until the next restart, so callers carry on.
+func put(ctx context.Context, b Backend, kind, key string, v any) {
+	if b == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = b.Put(ctx, kind, key, data)
+	}
+	if err != nil {
+		log.Printf("store persist failed kind=%s request_id=%s: %v", kind, requestid.From(ctx), err)
+	}
+}
+
+// Helper function to remove a document, logging a failure
+func remove(ctx context.Context, b Backend, kind, key string) {
+	if b == nil {
+		return
+	}
+	if err := b.Delete(ctx, kind, key); err != nil {
+		log.Printf("store persist failed kind=%s request_id=%s: %v", kind, requestid.From(ctx), err)
+	}
+}
+
+// Helper function to read every document of a kind
+func loadDocuments[T any](ctx context.Context, b Backend, kind string, fn func(key string, v T)) error {
+	return b.Documents(ctx, kind, func(key string, data []byte) error {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("failed to decode %s %s: %w", kind, key, err)
+		}
+		fn(key, v)
+		return nil
+	})
+}
+
+// Attach a backend to every store and load what it holds. Writes from then on
+// are persisted. A nil backend keeps everything in memory only.
+func (s *Stores) Open(ctx context.Context, b Backend) error {
+	if b == nil {
+		return nil
+	}
+	stores := map[string]interface {
+		attach(ctx context.Context, b Backend, kind string) error
+	}{
+		"readings":      s.Readings,
+		"meals":         s.Meals,
+		"workouts":      s.Workouts,
+		"activity":      s.Activity,
+		"insulin":       s.Insulin,
+		"symptoms":      s.Symptoms,
+		"hypos":         s.Hypos,
+		"water":         s.Water,
+		"rollups":       s.Rollups,
+		"summaries":     s.Summaries,
+		"day_reviews":   s.DayReviews,
+		"labs":          s.Labs,
+		"evaluations":   s.Evaluations,
+		"shadows":       s.Shadows,
+		"usage":         s.Usage,
+		"reminders":     s.Reminders,
+		"shares":        s.Shares,
+		"preferences":   s.Preferences,
+		"notifications": s.Notifications,
+		"schedules":     s.Schedules,
+		"programs":      s.Programs,
+		"alarm_rules":   s.AlarmRules,
+		"alarms":        s.Alarms,
+	}
+	for kind, st := range stores {
+		if err := st.attach(ctx, b, kind); err != nil {
+			return fmt.Errorf("failed to load %s: %w", kind, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"cloud.google.com/go/auth/credentials"
+)
+
+// OAuth scope for Firestore
+const firestoreScope = "https://www.googleapis.com/auth/datastore"
+
+// Documents read per page when loading
+const firestorePageSize = 300
+
+// Backend in Cloud Firestore, over its REST API. Each kind has a records and a
+// documents collection under stores/{kind}. Records get generated IDs; documents
+// are named by a hash of their key, which is kept in a field since keys may hold
+// characters Firestore IDs can't.
+type FirestoreBackend struct {
+	base   string
+	client *http.Client
+	token  func(ctx context.Context) (string, error)
+}
+
+// A Firestore document as the REST API sends it
+type firestoreDoc struct {
+	Fields map[string]firestoreValue `json:"fields"`
+}
+
+// A Firestore field value; only the types this backend writes
+type firestoreValue struct {
+	String    *string `json:"stringValue,omitempty"`
+	Timestamp *string `json:"timestampValue,omitempty"`
+}
+
+// Helper function to build a string field
+func stringValue(s string) firestoreValue {
+	return firestoreValue{String: &s}
+}
+
+// Connect to a Firestore database ("(default)" when empty). Credentials come
+// from Application Default Credentials, which also supply the project when it is
+// empty. With FIRESTORE_EMULATOR_HOST set, the emulator is used without credentials.
+func NewFirestoreBackend(ctx context.Context, project, database string) (*FirestoreBackend, error) {
+	if database == "" {
+		database = "(default)"
+	}
+	fb := &FirestoreBackend{client: &http.Client{Timeout: 10 * time.Second}}
+
+	host := "https://firestore.googleapis.com"
+	if emulator := os.Getenv("FIRESTORE_EMULATOR_HOST"); emulator != "" {
+		host = "http://" + emulator
+		fb.token = func(context.Context) (string, error) { return "owner", nil }
+		if project == "" {
+			project = "demo-diabeticai"
+		}
+	} else {
+		creds, err := credentials.DetectDefault(&credentials.DetectOptions{Scopes: []string{firestoreScope}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Google credentials for Firestore: %w", err)
+		}
+		if project == "" {
+			if project, err = creds.ProjectID(ctx); err != nil || project == "" {
+				return nil, fmt.Errorf("set FIRESTORE_PROJECT: no project in the Google credentials")
+			}
+		}
+		fb.token = func(ctx context.Context) (string, error) {
+			t, err := creds.Token(ctx)
+			if err != nil {
+				return "", err
+			}
+			return t.Value, nil
+		}
+	}
+	fb.base = fmt.Sprintf("%s/v1/projects/%s/databases/%s/documents", host, url.PathEscape(project), url.PathEscape(database))
+
+	// Check the connection and credentials up front
+	if err := fb.list(ctx, "stores/ping/documents", url.Values{"pageSize": {"1"}}, func(firestoreDoc) error { return nil }); err != nil {
+		return nil, fmt.Errorf("failed to connect to firestore: %w", err)
+	}
+	return fb, nil
+}
+
+func (fb *FirestoreBackend) Append(ctx context.Context, kind, userID string, at time.Time, data []byte) error {
+	ts := at.UTC().Format(time.RFC3339Nano)
+	doc := firestoreDoc{Fields: map[string]firestoreValue{
+		"user_id": stringValue(userID),
+		"at":      {Timestamp: &ts},
+		"data":    stringValue(string(data)),
+	}}
+	return fb.do(ctx, http.MethodPost, fb.collection(kind, "records"), doc, nil)
+}
+
+func (fb *FirestoreBackend) Records(ctx context.Context, kind string, fn func(data []byte) error) error {
+	return fb.list(ctx, "stores/"+url.PathEscape(kind)+"/records", url.Values{"orderBy": {"at"}}, func(doc firestoreDoc) error {
+		if v := doc.Fields["data"].String; v != nil {
+			return fn([]byte(*v))
+		}
+		return nil
+	})
+}
+
+func (fb *FirestoreBackend) Put(ctx context.Context, kind, key string, data []byte) error {
+	doc := firestoreDoc{Fields: map[string]firestoreValue{
+		"key":  stringValue(key),
+		"data": stringValue(string(data)),
+	}}
+	return fb.do(ctx, http.MethodPatch, fb.document(kind, key), doc, nil)
+}
+
+func (fb *FirestoreBackend) Delete(ctx context.Context, kind, key string) error {
+	return fb.do(ctx, http.MethodDelete, fb.document(kind, key), nil, nil)
+}
+
+func (fb *FirestoreBackend) Documents(ctx context.Context, kind string, fn func(key string, data []byte) error) error {
+	return fb.list(ctx, "stores/"+url.PathEscape(kind)+"/documents", nil, func(doc firestoreDoc) error {
+		key, data := doc.Fields["key"].String, doc.Fields["data"].String
+		if key == nil || data == nil {
+			return nil
+		}
+		return fn(*key, []byte(*data))
+	})
+}
+
+// Helper function to build a collection URL under stores/{kind}
+func (fb *FirestoreBackend) collection(kind, name string) string {
+	return fb.base + "/stores/" + url.PathEscape(kind) + "/" + name
+}
+
+// Helper function to build a document URL from its key
+func (fb *FirestoreBackend) document(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fb.collection(kind, "documents") + "/" + hex.EncodeToString(sum[:16])
+}
+
+// Helper function to call fn with every document in a collection, page by page
+func (fb *FirestoreBackend) list(ctx context.Context, path string, query url.Values, fn func(firestoreDoc) error) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if query.Get("pageSize") == "" {
+		query.Set("pageSize", fmt.Sprint(firestorePageSize))
+	}
+	for {
+		var page struct {
+			Documents     []firestoreDoc `json:"documents"`
+			NextPageToken string         `json:"nextPageToken"`
+		}
+		if err := fb.do(ctx, http.MethodGet, fb.base+"/"+path+"?"+query.Encode(), nil, &page); err != nil {
+			return err
+		}
+		for _, doc := range page.Documents {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" || query.Get("pageSize") == "1" {
+			return nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// Helper function to make an authorized request, decoding the reply into out.
+// A missing document is not an error when deleting.
+func (fb *FirestoreBackend) do(ctx context.Context, method, rawURL string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return err
+	}
+	token, err := fb.token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a firestore token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := fb.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodDelete {
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error.Message == "" {
+			apiErr.Error.Message = resp.Status
+		}
+		return errors.New("firestore: " + apiErr.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Notification channels. Reminders are always queued in-app; other channels
+// are recorded on each reminder for a delivery integration to send.
+var Channels = []string{"in_app", "email", "sms", "push"}
+
+// Digest frequencies
+const (
+	DigestWeekly = "weekly"
+	DigestDaily  = "daily"
+	DigestOff    = "off"
+)
+
+// Quiet Hours Struct
+type QuietHours struct {
+	Start string `json:"start" jsonschema:"description=Start of quiet hours, HH:MM in your timezone"`
+	End   string `json:"end" jsonschema:"description=End of quiet hours, HH:MM in your timezone"`
+}
+
+// Notification Preferences Struct
+//
+// How and when a user is reminded. Zero values mean the defaults.
+type NotificationPreferences struct {
+	UserID     string      `json:"user_id"`
+	Channels   []string    `json:"channels" jsonschema:"description=Where reminders go: in_app, email, sms, push. Empty turns reminders off"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty" jsonschema:"description=Hold non-critical reminders during these hours. Critical reading alerts are always sent"`
+	LowAlert   float64     `json:"low_alert_mgdl,omitempty" jsonschema:"description=Alert on readings below this, in mg/dL (default 54)"`
+	HighAlert  float64     `json:"high_alert_mgdl,omitempty" jsonschema:"description=Alert on readings above this, in mg/dL (default 250)"`
+	Muted      []string    `json:"muted,omitempty" jsonschema:"description=Reminder types to stop, e.g. hydration"`
+	Digest     string      `json:"digest" jsonschema:"description=Summary frequency: weekly, daily or off"`
+	Monthly    bool        `json:"monthly_report,omitempty" jsonschema:"description=Email a monthly trend report. Needs the email channel and an email address"`
+	Email      string      `json:"email,omitempty" jsonschema:"description=Address for email reminders and reports"`
+	DayReview  string      `json:"day_review_at,omitempty" jsonschema:"description=Time for an automatic end-of-day review, HH:MM in your timezone. Empty turns it off"`
+	Updated    time.Time   `json:"updated"`
+}
+
+// Check the preferences are well formed
+func (p NotificationPreferences) Validate() error {
+	for _, c := range p.Channels {
+		if !slices.Contains(Channels, c) {
+			return fmt.Errorf("unknown channel %q", c)
+		}
+	}
+	if p.QuietHours != nil {
+		for _, hm := range []string{p.QuietHours.Start, p.QuietHours.End} {
+			if _, err := time.Parse("15:04", hm); err != nil {
+				return fmt.Errorf("quiet hours must be HH:MM, got %q", hm)
+			}
+		}
+	}
+	if p.LowAlert < 0 || p.HighAlert < 0 || (p.HighAlert > 0 && p.LowAlert >= p.HighAlert) {
+		return fmt.Errorf("low_alert_mgdl must be below high_alert_mgdl")
+	}
+	switch p.Digest {
+	case "", DigestWeekly, DigestDaily, DigestOff:
+	default:
+		return fmt.Errorf("digest must be weekly, daily or off")
+	}
+	if p.DayReview != "" {
+		if _, err := time.Parse("15:04", p.DayReview); err != nil {
+			return fmt.Errorf("day_review_at must be HH:MM, got %q", p.DayReview)
+		}
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			return fmt.Errorf("invalid email %q", p.Email)
+		}
+	}
+	if p.Monthly && !p.Emailable() {
+		return fmt.Errorf("monthly_report needs the email channel and an email address")
+	}
+	return nil
+}
+
+// Report whether email can be sent to the user
+func (p NotificationPreferences) Emailable() bool {
+	return p.Email != "" && slices.Contains(p.Channels, "email")
+}
+
+// Report whether a local time falls in quiet hours. Quiet hours may span midnight.
+func (p NotificationPreferences) Quiet(local time.Time) bool {
+	if p.QuietHours == nil {
+		return false
+	}
+	start, _ := time.Parse("15:04", p.QuietHours.Start)
+	end, _ := time.Parse("15:04", p.QuietHours.End)
+	now := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from <= to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// In-memory store of notification preferences
+type NotificationStore struct {
+	mu      sync.RWMutex
+	prefs   map[string]NotificationPreferences
+	kind    string
+	backend Backend
+}
+
+// Create an empty notification preference store
+func NewNotificationStore() *NotificationStore {
+	return &NotificationStore{prefs: make(map[string]NotificationPreferences)}
+}
+
+// Return a user's preferences, or the defaults if none are saved
+func (s *NotificationStore) Get(userID string) NotificationPreferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.prefs[userID]
+	if !ok {
+		p = NotificationPreferences{UserID: userID, Channels: []string{"in_app"}}
+	}
+	if p.Digest == "" {
+		p.Digest = DigestWeekly
+	}
+	return p
+}
+
+// Save a user's preferences
+func (s *NotificationStore) Set(p NotificationPreferences) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.Channels == nil {
+		p.Channels = []string{}
+	}
+	p.Updated = time.Now()
+	s.prefs[p.UserID] = p
+	put(context.Background(), s.backend, s.kind, p.UserID, p)
+}
+
+// Remove a user's preferences, restoring the defaults
+func (s *NotificationStore) Delete(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.prefs, userID)
+	remove(context.Background(), s.backend, s.kind, userID)
+}
+
+// Load notification preferences from a backend and persist later changes to it
+func (s *NotificationStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(userID string, p NotificationPreferences) { s.prefs[userID] = p })
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Preferences Struct
+//
+// Display settings for a user. Empty fields fall back to the defaults for Locale.
+type Preferences struct {
+	UserID   string    `json:"user_id"`
+	Locale   string    `json:"locale,omitempty" jsonschema:"description=Locale such as en-US, en-GB or de-DE"`
+	Units    string    `json:"units,omitempty" jsonschema:"description=Glucose units: mg/dL or mmol/L"`
+	Clock    string    `json:"clock,omitempty" jsonschema:"description=Clock format: 12h or 24h"`
+	Timezone string    `json:"timezone,omitempty" jsonschema:"description=IANA time zone, e.g. Africa/Nairobi"`
+	Updated  time.Time `json:"updated"`
+}
+
+// In-memory store of user preferences
+type PreferenceStore struct {
+	mu      sync.RWMutex
+	prefs   map[string]Preferences
+	kind    string
+	backend Backend
+}
+
+// Create an empty preference store
+func NewPreferenceStore() *PreferenceStore {
+	return &PreferenceStore{prefs: make(map[string]Preferences)}
+}
+
+// Return a user's preferences, or empty ones if none are saved
+func (s *PreferenceStore) Get(userID string) Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.prefs[userID]
+	if !ok {
+		p.UserID = userID
+	}
+	return p
+}
+
+// Save a user's preferences
+func (s *PreferenceStore) Set(p Preferences) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.Updated = time.Now()
+	s.prefs[p.UserID] = p
+	put(context.Background(), s.backend, s.kind, p.UserID, p)
+}
+
+// Load preferences from a backend and persist later changes to it
+func (s *PreferenceStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(userID string, p Preferences) { s.prefs[userID] = p })
+}
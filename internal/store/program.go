@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Program Session Struct
+//
+// One planned workout in a program week. Completed is set once a logged
+// workout in that week is matched to it.
+type ProgramSession struct {
+	Day             int        `json:"day" jsonschema:"description=Day of the program week, 1 to 7"`
+	Type            string     `json:"type"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Intensity       string     `json:"intensity"`
+	Completed       *time.Time `json:"completed,omitempty"`
+}
+
+// Program Week Struct
+type ProgramWeek struct {
+	Number     int              `json:"number"`
+	Start      time.Time        `json:"start"`
+	Level      int              `json:"level" jsonschema:"description=Training level, 1 (easiest) to 5"`
+	Sessions   []ProgramSession `json:"sessions"`
+	Adjustment string           `json:"adjustment,omitempty" jsonschema:"description=Why the level changed from the week before"`
+	Guidance   string           `json:"guidance,omitempty"`
+	Adherence  float64          `json:"adherence" jsonschema:"description=Percent of planned sessions completed"`
+	AvgDrop    float64          `json:"avg_drop,omitempty" jsonschema:"description=Average blood sugar drop across completed sessions"`
+	Lows       int              `json:"lows,omitempty" jsonschema:"description=Completed sessions followed by a reading below 70 mg/dL"`
+}
+
+// End of the program week
+func (w ProgramWeek) End() time.Time { return w.Start.AddDate(0, 0, 7) }
+
+// Exercise Program Struct
+type ExerciseProgram struct {
+	UserID          string        `json:"user_id"`
+	FitnessLevel    string        `json:"fitness_level"`
+	PreferredType   string        `json:"preferred_type"`
+	Goal            string        `json:"goal,omitempty"`
+	TimeAvailable   int           `json:"time_available"`
+	SessionsPerWeek int           `json:"sessions_per_week"`
+	TotalWeeks      int           `json:"total_weeks"`
+	Weeks           []ProgramWeek `json:"weeks"`
+	Created         time.Time     `json:"created"`
+	Updated         time.Time     `json:"updated"`
+}
+
+// The week in progress, nil for a program with no weeks
+func (p *ExerciseProgram) Current() *ProgramWeek {
+	if len(p.Weeks) == 0 {
+		return nil
+	}
+	return &p.Weeks[len(p.Weeks)-1]
+}
+
+// In-memory store of each user's multi-week exercise program
+type ProgramStore struct {
+	mu       sync.RWMutex
+	programs map[string]ExerciseProgram
+	kind     string
+	backend  Backend
+}
+
+// Create an empty program store
+func NewProgramStore() *ProgramStore {
+	return &ProgramStore{programs: make(map[string]ExerciseProgram)}
+}
+
+// Return a user's program and whether one exists
+func (s *ProgramStore) Get(userID string) (ExerciseProgram, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	program, ok := s.programs[userID]
+	return program, ok
+}
+
+// Replace a user's program
+func (s *ProgramStore) Set(program ExerciseProgram) ExerciseProgram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	program.Updated = time.Now()
+	s.programs[program.UserID] = program
+	put(context.Background(), s.backend, s.kind, program.UserID, program)
+	return program
+}
+
+// Remove a user's program
+func (s *ProgramStore) Delete(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.programs, userID)
+	remove(context.Background(), s.backend, s.kind, userID)
+}
+
+// Load programs from a backend and persist later changes to it
+func (s *ProgramStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(userID string, program ExerciseProgram) { s.programs[userID] = program })
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/icd10"
+)
+
+// Glucose Reading Struct
+type GlucoseReading struct {
+	UserID     string    `json:"user_id"`
+	Value      float64   `json:"value" jsonschema:"description=Blood sugar reading in mg/dL"`
+	MealTiming string    `json:"meal_timing,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func (r GlucoseReading) Owner() string   { return r.UserID }
+func (r GlucoseReading) Time() time.Time { return r.Timestamp }
+
+// Readings store, keyed by user
+type ReadingStore = LogStore[GlucoseReading]
+
+// Meal Log Struct
+type MealLog struct {
+	UserID      string    `json:"user_id"`
+	MealType    string    `json:"meal_type,omitempty" jsonschema:"description=Type of meal: breakfast, lunch, dinner, snack"`
+	Description string    `json:"description" jsonschema:"description=What was eaten"`
+	Foods       []string  `json:"foods,omitempty" jsonschema:"description=Individual food items (optional)"`
+	Carbs       float64   `json:"carbs,omitempty" jsonschema:"description=Estimated carbohydrates in grams (optional)"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func (m MealLog) Owner() string   { return m.UserID }
+func (m MealLog) Time() time.Time { return m.Timestamp }
+
+func (m MealLog) redacted(r Redactor) MealLog {
+	m.Description = r("description", m.Description)
+	return m
+}
+
+// Helper function to list the foods in a meal, falling back to the description
+func (m MealLog) FoodItems() []string {
+	items := m.Foods
+	if len(items) == 0 {
+		items = strings.FieldsFunc(m.Description, func(r rune) bool {
+			return r == ',' || r == ';' || r == '+'
+		})
+	}
+
+	var out []string
+	for _, item := range items {
+		for _, part := range strings.Split(item, " and ") {
+			if food := strings.ToLower(strings.TrimSpace(part)); food != "" {
+				out = append(out, food)
+			}
+		}
+	}
+	return out
+}
+
+// Workout Log Struct
+type WorkoutLog struct {
+	UserID          string    `json:"user_id"`
+	Type            string    `json:"type" jsonschema:"description=Exercise type: cardio, strength, yoga, walking"`
+	DurationMinutes int       `json:"duration_minutes" jsonschema:"description=Workout length in minutes"`
+	Intensity       string    `json:"intensity,omitempty" jsonschema:"description=Intensity: light, moderate, vigorous (optional)"`
+	AvgHeartRate    int       `json:"avg_heart_rate,omitempty" jsonschema:"description=Average heart rate in bpm, from a wearable"`
+	Source          string    `json:"source,omitempty" jsonschema:"description=Wearable or bridge that sent the workout, e.g. fitbit"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func (w WorkoutLog) Owner() string   { return w.UserID }
+func (w WorkoutLog) Time() time.Time { return w.Timestamp }
+
+// Activity Sample Struct
+//
+// Steps over an interval, or a heart rate reading, from a wearable.
+type ActivitySample struct {
+	UserID    string    `json:"user_id"`
+	Source    string    `json:"source"`
+	Steps     int       `json:"steps,omitempty"`
+	Minutes   int       `json:"minutes,omitempty" jsonschema:"description=Length of the interval the steps were counted over"`
+	HeartRate int       `json:"heart_rate_bpm,omitempty"`
+	Timestamp time.Time `json:"timestamp" jsonschema:"description=Start of the interval, or when the heart rate was measured"`
+}
+
+func (a ActivitySample) Owner() string   { return a.UserID }
+func (a ActivitySample) Time() time.Time { return a.Timestamp }
+
+// Insulin Dose Struct
+type InsulinDose struct {
+	UserID      string    `json:"user_id"`
+	Units       float64   `json:"units" jsonschema:"description=Units of insulin taken"`
+	InsulinType string    `json:"insulin_type" jsonschema:"description=Insulin type: ultra_rapid, rapid, short, long"`
+	Note        string    `json:"note,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func (d InsulinDose) Owner() string   { return d.UserID }
+func (d InsulinDose) Time() time.Time { return d.Timestamp }
+
+func (d InsulinDose) redacted(r Redactor) InsulinDose {
+	d.Note = r("note", d.Note)
+	return d
+}
+
+// Symptom Check Struct
+type SymptomCheck struct {
+	UserID     string       `json:"user_id"`
+	Symptoms   string       `json:"symptoms"`
+	Duration   string       `json:"duration,omitempty"`
+	Urgency    string       `json:"urgency"`
+	Assessment string       `json:"assessment"`
+	ICD10      []icd10.Code `json:"icd10_suggestions,omitempty"`
+	Timestamp  time.Time    `json:"timestamp"`
+}
+
+func (c SymptomCheck) Owner() string   { return c.UserID }
+func (c SymptomCheck) Time() time.Time { return c.Timestamp }
+
+func (c SymptomCheck) redacted(r Redactor) SymptomCheck {
+	c.Symptoms = r("symptoms", c.Symptoms)
+	c.Duration = r("duration", c.Duration)
+	c.Assessment = r("assessment", c.Assessment)
+	return c
+}
+
+// Water Log Struct
+type WaterLog struct {
+	UserID      string    `json:"user_id"`
+	Milliliters float64   `json:"ml" jsonschema:"description=Water drunk in millilitres"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func (w WaterLog) Owner() string   { return w.UserID }
+func (w WaterLog) Time() time.Time { return w.Timestamp }
+
+// Daily Rollup Struct
+//
+// One user's glucose and hydration totals for a calendar day, built by the nightly job.
+type DailyRollup struct {
+	UserID      string    `json:"user_id"`
+	Date        string    `json:"date"`
+	Readings    int       `json:"readings"`
+	Mean        float64   `json:"mean"`
+	TimeInRange float64   `json:"time_in_range"`
+	TimeBelow   float64   `json:"time_below"`
+	TimeAbove   float64   `json:"time_above"`
+	CV          float64   `json:"cv"`
+	WaterML     float64   `json:"water_ml"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func (r DailyRollup) Owner() string   { return r.UserID }
+func (r DailyRollup) Time() time.Time { return r.Timestamp }
+
+// Summary Struct
+//
+// Output of a scheduled summary flow, kept as the flow returned it.
+type Summary struct {
+	UserID    string          `json:"user_id"`
+	Kind      string          `json:"kind"`
+	Output    json.RawMessage `json:"output"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+func (s Summary) Owner() string   { return s.UserID }
+func (s Summary) Time() time.Time { return s.Timestamp }
+
+// Day Review Struct
+//
+// An end-of-day reflection and the intention it set for the next day.
+type DayReview struct {
+	UserID     string    `json:"user_id"`
+	Date       string    `json:"date" jsonschema:"description=Day reviewed, YYYY-MM-DD in your timezone"`
+	Reflection string    `json:"reflection"`
+	Wins       string    `json:"wins"`
+	Intention  string    `json:"intention" jsonschema:"description=One concrete intention for the next day"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func (d DayReview) Owner() string   { return d.UserID }
+func (d DayReview) Time() time.Time { return d.Timestamp }
+
+// LabAppointment Struct
+//
+// An upcoming lab test with a reminder the night before. Logged by the time
+// the reminder is due.
+type LabAppointment struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	Test         string    `json:"test"`
+	At           time.Time `json:"at"`
+	FastingHours int       `json:"fasting_hours"`
+	RemindAt     time.Time `json:"remind_at"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func (l LabAppointment) Owner() string   { return l.UserID }
+func (l LabAppointment) Time() time.Time { return l.RemindAt }
+
+// Suspected causes of a hypo
+var HypoCauses = []string{"missed_meal", "delayed_meal", "exercise", "too_much_insulin", "insulin_timing", "alcohol", "illness", "unknown"}
+
+// Hypo Event Struct
+type HypoEvent struct {
+	UserID       string    `json:"user_id"`
+	LowestBG     float64   `json:"lowest_bg" jsonschema:"description=Lowest blood sugar during the event in mg/dL"`
+	Treatment    string    `json:"treatment,omitempty" jsonschema:"description=What was taken, e.g. 15 g glucose tablets"`
+	TreatedCarbs float64   `json:"treated_carbs,omitempty" jsonschema:"description=Grams of fast-acting carbs taken (optional)"`
+	Cause        string    `json:"cause,omitempty" jsonschema:"description=Suspected cause: missed_meal, delayed_meal, exercise, too_much_insulin, insulin_timing, alcohol, illness, unknown"`
+	NeededHelp   bool      `json:"needed_help,omitempty" jsonschema:"description=Needed someone else's help to treat it"`
+	Note         string    `json:"note,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func (h HypoEvent) Owner() string   { return h.UserID }
+func (h HypoEvent) Time() time.Time { return h.Timestamp }
+
+func (h HypoEvent) redacted(r Redactor) HypoEvent {
+	h.Note = r("note", h.Note)
+	return h
+}
+
+// Evaluation Struct
+//
+// A second model's grading of one flow response against the clinical rubric.
+type Evaluation struct {
+	UserID    string          `json:"user_id"`
+	Flow      string          `json:"flow"`
+	RequestID string          `json:"request_id"`
+	Criteria  []EvalCriterion `json:"criteria"`
+	Score     float64         `json:"score" jsonschema:"description=Share of criteria passed, 0 to 1"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Eval Criterion Struct
+type EvalCriterion struct {
+	Name   string `json:"name"`
+	Pass   bool   `json:"pass"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (e Evaluation) Owner() string   { return e.UserID }
+func (e Evaluation) Time() time.Time { return e.Timestamp }
+
+// Shadow Run Struct
+//
+// A live generation next to the same request answered by a candidate prompt
+// or model, and how the two compare. The candidate's answer is never returned.
+type ShadowRun struct {
+	UserID        string    `json:"user_id"`
+	Flow          string    `json:"flow"`
+	Prompt        string    `json:"prompt,omitempty" jsonschema:"description=Template with a candidate, empty when only the model changed"`
+	RequestID     string    `json:"request_id"`
+	LiveModel     string    `json:"live_model"`
+	ShadowModel   string    `json:"shadow_model"`
+	LiveVersion   string    `json:"live_version,omitempty"`
+	ShadowVersion string    `json:"shadow_version,omitempty"`
+	LiveOutput    string    `json:"live_output"`
+	ShadowOutput  string    `json:"shadow_output,omitempty"`
+	Similarity    float64   `json:"similarity" jsonschema:"description=Word overlap of the two answers, 0 to 1"`
+	LengthChange  float64   `json:"length_change" jsonschema:"description=Change in answer length, percent"`
+	SafetyAdded   []string  `json:"safety_added,omitempty" jsonschema:"description=Safety phrases only the candidate used"`
+	SafetyDropped []string  `json:"safety_dropped,omitempty" jsonschema:"description=Safety phrases the candidate left out"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func (r ShadowRun) Owner() string   { return r.UserID }
+func (r ShadowRun) Time() time.Time { return r.Timestamp }
+
+// Reminder Struct
+type Reminder struct {
+	UserID   string    `json:"user_id"`
+	Type     string    `json:"type"`
+	Message  string    `json:"message"`
+	Channels []string  `json:"channels,omitempty"`
+	DueAt    time.Time `json:"due_at"`
+}
+
+func (r Reminder) Owner() string   { return r.UserID }
+func (r Reminder) Time() time.Time { return r.DueAt }
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduled Dose Struct
+//
+// A daily medication reminder from a confirmed schedule.
+type ScheduledDose struct {
+	Medication  string `json:"medication"`
+	Dose        string `json:"dose,omitempty"`
+	Time        string `json:"time" jsonschema:"description=Time of day, HH:MM in your timezone"`
+	Instruction string `json:"instruction" jsonschema:"description=How to take it, e.g. with breakfast"`
+}
+
+// Medication Schedule Struct
+type MedicationSchedule struct {
+	UserID    string          `json:"user_id"`
+	Doses     []ScheduledDose `json:"doses"`
+	Confirmed time.Time       `json:"confirmed"`
+}
+
+// In-memory store of each user's confirmed medication schedule
+type ScheduleStore struct {
+	mu        sync.RWMutex
+	schedules map[string]MedicationSchedule
+	kind      string
+	backend   Backend
+}
+
+// Create an empty schedule store
+func NewScheduleStore() *ScheduleStore {
+	return &ScheduleStore{schedules: make(map[string]MedicationSchedule)}
+}
+
+// Return a user's schedule, empty if none is confirmed
+func (s *ScheduleStore) Get(userID string) MedicationSchedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sched, ok := s.schedules[userID]
+	if !ok {
+		return MedicationSchedule{UserID: userID, Doses: []ScheduledDose{}}
+	}
+	return sched
+}
+
+// Replace a user's schedule
+func (s *ScheduleStore) Set(userID string, doses []ScheduledDose) MedicationSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sched := MedicationSchedule{UserID: userID, Doses: doses, Confirmed: time.Now()}
+	s.schedules[userID] = sched
+	put(context.Background(), s.backend, s.kind, userID, sched)
+	return sched
+}
+
+// Load schedules from a backend and persist later changes to it
+func (s *ScheduleStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(userID string, sched MedicationSchedule) { s.schedules[userID] = sched })
+}
+
+// Return every user with a confirmed schedule
+func (s *ScheduleStore) Users() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make([]string, 0, len(s.schedules))
+	for userID := range s.schedules {
+		users = append(users, userID)
+	}
+	return users
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Share Grant Struct
+type ShareGrant struct {
+	ID           string    `json:"id"`
+	OwnerID      string    `json:"owner_id"`
+	GranteeID    string    `json:"grantee_id"`
+	Relationship string    `json:"relationship,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// In-memory store of read-access grants from patients to caregivers
+type ShareStore struct {
+	mu      sync.RWMutex
+	grants  map[string]ShareGrant
+	kind    string
+	backend Backend
+}
+
+// Create an empty share store
+func NewShareStore() *ShareStore {
+	return &ShareStore{grants: make(map[string]ShareGrant)}
+}
+
+// Grant a caregiver read access to an owner's data
+func (s *ShareStore) Grant(ownerID, granteeID, relationship string) ShareGrant {
+	grant := ShareGrant{
+		ID:           NewID(),
+		OwnerID:      ownerID,
+		GranteeID:    granteeID,
+		Relationship: relationship,
+		CreatedAt:    time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants[grant.ID] = grant
+	put(context.Background(), s.backend, s.kind, grant.ID, grant)
+	return grant
+}
+
+// Revoke a grant; only its owner may do so
+func (s *ShareStore) Revoke(ownerID, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grant, ok := s.grants[id]
+	if !ok || grant.OwnerID != ownerID {
+		return false
+	}
+	delete(s.grants, id)
+	remove(context.Background(), s.backend, s.kind, id)
+	return true
+}
+
+// Load grants from a backend and persist later changes to it
+func (s *ShareStore) attach(ctx context.Context, b Backend, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	return loadDocuments(ctx, b, kind, func(id string, grant ShareGrant) { s.grants[id] = grant })
+}
+
+// List grants a user has given and received
+func (s *ShareStore) List(userID string) (given, received []ShareGrant) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	given, received = []ShareGrant{}, []ShareGrant{}
+	for _, grant := range s.grants {
+		if grant.OwnerID == userID {
+			given = append(given, grant)
+		}
+		if grant.GranteeID == userID {
+			received = append(received, grant)
+		}
+	}
+	return given, received
+}
+
+// Check whether a viewer may read a patient's data
+func (s *ShareStore) CanRead(viewerID, patientID string) bool {
+	if viewerID == patientID {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, grant := range s.grants {
+		if grant.OwnerID == patientID && grant.GranteeID == viewerID {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to generate a random identifier
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
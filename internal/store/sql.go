@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Backend in the records and documents tables of a SQLite database (see package db)
+type SQLBackend struct {
+	db *sql.DB
+}
+
+// Create a backend in an open database
+func NewSQLBackend(db *sql.DB) *SQLBackend {
+	return &SQLBackend{db: db}
+}
+
+func (b *SQLBackend) Append(ctx context.Context, kind, userID string, at time.Time, data []byte) error {
+	_, err := b.db.ExecContext(ctx, `INSERT INTO records (kind, user_id, at, data) VALUES (?, ?, ?, ?)`, kind, userID, at.UnixMilli(), string(data))
+	return err
+}
+
+func (b *SQLBackend) Records(ctx context.Context, kind string, fn func(data []byte) error) error {
+	rows, err := b.db.QueryContext(ctx, `SELECT data FROM records WHERE kind = ? ORDER BY at, id`, kind)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (b *SQLBackend) Put(ctx context.Context, kind, key string, data []byte) error {
+	_, err := b.db.ExecContext(ctx, `INSERT INTO documents (kind, key, data) VALUES (?, ?, ?)
+		ON CONFLICT (kind, key) DO UPDATE SET data = excluded.data`, kind, key, string(data))
+	return err
+}
+
+func (b *SQLBackend) Delete(ctx context.Context, kind, key string) error {
+	_, err := b.db.ExecContext(ctx, `DELETE FROM documents WHERE kind = ? AND key = ?`, kind, key)
+	return err
+}
+
+func (b *SQLBackend) Documents(ctx context.Context, kind string, fn func(key string, data []byte) error) error {
+	rows, err := b.db.QueryContext(ctx, `SELECT key, data FROM documents WHERE kind = ?`, kind)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var data []byte
+		if err := rows.Scan(&key, &data); err != nil {
+			return err
+		}
+		if err := fn(key, data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
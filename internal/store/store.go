@@ -0,0 +1,247 @@
+// Package store holds the per-user logs, profiles and sharing grants. They are
+// kept in memory and, with a Backend, persisted to a database.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"diabeticai-advisor/internal/requestid"
+)
+
+// Record is any user-owned, timestamped entry kept in a LogStore
+type Record interface {
+	Owner() string
+	Time() time.Time
+}
+
+// In-memory per-user log of records, keyed by user
+type LogStore[T Record] struct {
+	mu      sync.RWMutex
+	entries map[string][]T
+	redact  func(T) T
+	kind    string
+	backend Backend
+}
+
+// Create an empty log store
+func NewLogStore[T Record]() *LogStore[T] {
+	return &LogStore[T]{entries: make(map[string][]T)}
+}
+
+// Redactor removes personal details from a named free-text field
+type Redactor func(field, text string) string
+
+// Create an empty log store that redacts each record's free text before storing it
+func newRedactedLogStore[T Record](r Redactor, redact func(T, Redactor) T) *LogStore[T] {
+	s := NewLogStore[T]()
+	s.redact = func(v T) T { return redact(v, r) }
+	return s
+}
+
+// Load a log's records from a backend and persist later writes to it
+func (s *LogStore[T]) attach(ctx context.Context, b Backend, kind string) error {
+	var loaded []T
+	err := b.Records(ctx, kind, func(data []byte) error {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("failed to decode record: %w", err)
+		}
+		loaded = append(loaded, v)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind, s.backend = kind, b
+	for _, v := range loaded {
+		s.entries[v.Owner()] = append(s.entries[v.Owner()], v)
+	}
+	for _, list := range s.entries {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time().Before(list[j].Time())
+		})
+	}
+	return nil
+}
+
+// Helper function to write a record through to the backend, logging a failure.
+// Callers hold the lock, so records reach the backend in the order they were added.
+func (s *LogStore[T]) persist(ctx context.Context, v T) {
+	if s.backend == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = s.backend.Append(ctx, s.kind, v.Owner(), v.Time(), data)
+	}
+	if err != nil {
+		log.Printf("store persist failed kind=%s user=%s request_id=%s: %v", s.kind, v.Owner(), requestid.From(ctx), err)
+	}
+}
+
+// Add a record, keeping each user's records sorted by time.
+// The write is logged with the request ID from ctx.
+func (s *LogStore[T]) Add(ctx context.Context, v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Printf("store write type=%T user=%s request_id=%s", v, v.Owner(), requestid.From(ctx))
+	if s.redact != nil {
+		v = s.redact(v)
+	}
+	s.persist(ctx, v)
+
+	list := append(s.entries[v.Owner()], v)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Time().Before(list[j].Time())
+	})
+	s.entries[v.Owner()] = list
+}
+
+// Add many records at once, sorting each affected user's log a single time.
+// The write is logged once with the record count.
+func (s *LogStore[T]) AddAll(ctx context.Context, vs []T) {
+	if len(vs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Printf("store write type=%T count=%d request_id=%s", vs[0], len(vs), requestid.From(ctx))
+
+	touched := map[string]bool{}
+	for _, v := range vs {
+		if s.redact != nil {
+			v = s.redact(v)
+		}
+		s.persist(ctx, v)
+		s.entries[v.Owner()] = append(s.entries[v.Owner()], v)
+		touched[v.Owner()] = true
+	}
+	for owner := range touched {
+		list := s.entries[owner]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time().Before(list[j].Time())
+		})
+	}
+}
+
+// Return a user's records within [from, to)
+func (s *LogStore[T]) Range(userID string, from, to time.Time) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []T
+	for _, v := range s.entries[userID] {
+		if v.Time().Before(from) || !v.Time().Before(to) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Return a user's most recent record
+func (s *LogStore[T]) Latest(userID string) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var zero T
+	list := s.entries[userID]
+	if len(list) == 0 {
+		return zero, false
+	}
+	return list[len(list)-1], true
+}
+
+// Return every user with at least one record
+func (s *LogStore[T]) Users() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]string, 0, len(s.entries))
+	for userID := range s.entries {
+		users = append(users, userID)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Default user when no user ID is supplied
+const DefaultUserID = "default"
+
+// Helper function to fill in a missing owner and timestamp
+func Stamp(userID *string, ts *time.Time) {
+	if *userID == "" {
+		*userID = DefaultUserID
+	}
+	if ts.IsZero() {
+		*ts = time.Now()
+	}
+}
+
+// Stores Struct
+type Stores struct {
+	Readings      *ReadingStore
+	Meals         *LogStore[MealLog]
+	Workouts      *LogStore[WorkoutLog]
+	Activity      *LogStore[ActivitySample]
+	Insulin       *LogStore[InsulinDose]
+	Symptoms      *LogStore[SymptomCheck]
+	Hypos         *LogStore[HypoEvent]
+	Water         *LogStore[WaterLog]
+	Rollups       *LogStore[DailyRollup]
+	Summaries     *LogStore[Summary]
+	DayReviews    *LogStore[DayReview]
+	Labs          *LogStore[LabAppointment]
+	Evaluations   *LogStore[Evaluation]
+	Shadows       *LogStore[ShadowRun]
+	Usage         *LogStore[ModelUsage]
+	Reminders     *LogStore[Reminder]
+	Shares        *ShareStore
+	Preferences   *PreferenceStore
+	Notifications *NotificationStore
+	Schedules     *ScheduleStore
+	Programs      *ProgramStore
+	AlarmRules    *AlarmRuleStore
+	Alarms        *AlarmStore
+}
+
+// Create empty stores for every log. Free-text fields pass through redact before they are stored.
+func New(redact Redactor) *Stores {
+	if redact == nil {
+		redact = func(field, text string) string { return text }
+	}
+	return &Stores{
+		Readings:      NewLogStore[GlucoseReading](),
+		Meals:         newRedactedLogStore(redact, MealLog.redacted),
+		Workouts:      NewLogStore[WorkoutLog](),
+		Activity:      NewLogStore[ActivitySample](),
+		Insulin:       newRedactedLogStore(redact, InsulinDose.redacted),
+		Symptoms:      newRedactedLogStore(redact, SymptomCheck.redacted),
+		Hypos:         newRedactedLogStore(redact, HypoEvent.redacted),
+		Water:         NewLogStore[WaterLog](),
+		Rollups:       NewLogStore[DailyRollup](),
+		Summaries:     NewLogStore[Summary](),
+		DayReviews:    NewLogStore[DayReview](),
+		Labs:          NewLogStore[LabAppointment](),
+		Evaluations:   NewLogStore[Evaluation](),
+		Shadows:       NewLogStore[ShadowRun](),
+		Usage:         NewLogStore[ModelUsage](),
+		Reminders:     NewLogStore[Reminder](),
+		Shares:        NewShareStore(),
+		Preferences:   NewPreferenceStore(),
+		Notifications: NewNotificationStore(),
+		Schedules:     NewScheduleStore(),
+		Programs:      NewProgramStore(),
+		AlarmRules:    NewAlarmRuleStore(),
+		Alarms:        NewAlarmStore(),
+	}
+}
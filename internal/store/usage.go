@@ -0,0 +1,21 @@
+package store
+
+import "time"
+
+// Model Usage Struct
+//
+// Tokens used by one generation and what they cost.
+type ModelUsage struct {
+	UserID       string    `json:"user_id"`
+	Flow         string    `json:"flow"`
+	Model        string    `json:"model"`
+	RequestID    string    `json:"request_id,omitempty"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens" jsonschema:"description=Output tokens, including thinking tokens"`
+	Cost         float64   `json:"cost"`
+	Priced       bool      `json:"priced" jsonschema:"description=False when the model has no price, so cost is 0"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func (u ModelUsage) Owner() string   { return u.UserID }
+func (u ModelUsage) Time() time.Time { return u.Timestamp }
@@ -0,0 +1,263 @@
+// Package thresholds holds the clinical cutoffs used across the advisor:
+// glucose ranges by population, ketone tiers and exercise safety limits.
+// They live in versioned tables with effective dates, so a guideline change
+// is a reviewed data change rather than edits to scattered constants.
+package thresholds
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Populations with their own glucose ranges
+const (
+	Adult      = "adult"
+	Child      = "child"
+	OlderAdult = "older_adult"
+	Pregnancy  = "pregnancy"
+)
+
+// Range Struct
+//
+// Glucose bands in mg/dL, and the share of readings (percent) each band should stay within.
+type Range struct {
+	VeryLow      float64 `json:"very_low"`
+	Low          float64 `json:"low"`
+	High         float64 `json:"high"`
+	VeryHigh     float64 `json:"very_high"`
+	InRangeGoal  float64 `json:"in_range_goal" jsonschema:"description=Minimum percent of readings in range"`
+	BelowGoal    float64 `json:"below_goal" jsonschema:"description=Maximum percent of readings below low"`
+	VeryLowGoal  float64 `json:"very_low_goal" jsonschema:"description=Maximum percent of readings below very low"`
+	AboveGoal    float64 `json:"above_goal" jsonschema:"description=Maximum percent of readings above high"`
+	VeryHighGoal float64 `json:"very_high_goal" jsonschema:"description=Maximum percent of readings above very high"`
+}
+
+// Ketones Struct
+//
+// Blood ketone cutoffs in mmol/L for the small, moderate and large tiers.
+type Ketones struct {
+	BloodSmall    float64 `json:"blood_small"`
+	BloodModerate float64 `json:"blood_moderate"`
+	BloodLarge    float64 `json:"blood_large"`
+}
+
+// Exercise Struct
+//
+// Blood sugar limits in mg/dL for starting exercise.
+type Exercise struct {
+	SnackBelow float64 `json:"snack_below" jsonschema:"description=Eat a snack before exercising below this"`
+	DelayAbove float64 `json:"delay_above" jsonschema:"description=Delay exercise and check ketones above this"`
+}
+
+// HypoRisk Struct
+//
+// Blood sugar in mg/dL and insulin on board in units that raise the risk of a low.
+type HypoRisk struct {
+	NearLow    float64 `json:"near_low" jsonschema:"description=Moderate risk below this, high with near_low_iob on board"`
+	NearLowIOB float64 `json:"near_low_iob"`
+	Watch      float64 `json:"watch" jsonschema:"description=Moderate risk below this with watch_iob on board"`
+	WatchIOB   float64 `json:"watch_iob"`
+}
+
+// Fasting Struct
+//
+// Limits for fasting with diabetes: when to break a fast, and the share of
+// recent readings (percent) that makes fasting risky.
+type Fasting struct {
+	BreakAbove      float64 `json:"break_above" jsonschema:"description=Break the fast above this (mg/dL)"`
+	MaxTimeBelow    float64 `json:"max_time_below" jsonschema:"description=Recent readings below low, in percent, that make fasting risky"`
+	MaxTimeVeryHigh float64 `json:"max_time_very_high" jsonschema:"description=Recent readings above very high, in percent, that make fasting risky"`
+}
+
+// Table Struct
+//
+// One version of the clinical thresholds, in force from its effective date.
+type Table struct {
+	Version          string           `json:"version"`
+	Effective        string           `json:"effective" jsonschema:"description=Date the table takes effect (YYYY-MM-DD)"`
+	Sources          []string         `json:"sources,omitempty"`
+	Populations      map[string]Range `json:"populations"`
+	Ketones          Ketones          `json:"ketones"`
+	HighBGAction     float64          `json:"high_bg_action" jsonschema:"description=Readings above this get the high blood sugar action plan (mg/dL)"`
+	HighBGCallDoctor float64          `json:"high_bg_call_doctor" jsonschema:"description=Readings above this need a call to the doctor now (mg/dL)"`
+	CorrectionMinBG  float64          `json:"correction_min_bg" jsonschema:"description=Lowest reading before a bolus that counts as a correction when estimating the correction factor (mg/dL)"`
+	HypoRisk         HypoRisk         `json:"hypo_risk"`
+	Exercise         Exercise         `json:"exercise"`
+	Fasting          Fasting          `json:"fasting"`
+	effective        time.Time
+}
+
+// Built-in tables, reviewed alongside the code
+//
+//go:embed thresholds.json
+var builtin []byte
+
+// Tables in force, oldest first, and the built-in ones a file is merged into
+var (
+	mu     sync.RWMutex
+	tables []Table
+	base   []Table
+)
+
+func init() {
+	loaded, err := parse(builtin)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in thresholds: %v", err))
+	}
+	tables, base = loaded, loaded
+}
+
+// Load extra tables from a JSON file in the built-in format. A table with the
+// same version as a built-in one replaces it; others are added, so a new
+// guideline can be staged ahead of its effective date. Loading again replaces
+// the tables from the previous load.
+func Load(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read thresholds: %w", err)
+	}
+	extra, err := parse(data)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	merged := append([]Table{}, base...)
+	for _, t := range extra {
+		if i := slices.IndexFunc(merged, func(m Table) bool { return m.Version == t.Version }); i >= 0 {
+			merged[i] = t
+		} else {
+			merged = append(merged, t)
+		}
+	}
+	sortTables(merged)
+	tables = merged
+	return nil
+}
+
+// Helper function to decode and validate a thresholds file
+func parse(data []byte) ([]Table, error) {
+	var file struct {
+		Tables []Table `json:"tables"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode thresholds: %w", err)
+	}
+	if len(file.Tables) == 0 {
+		return nil, fmt.Errorf("thresholds file has no tables")
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Tables {
+		t := &file.Tables[i]
+		if t.Version == "" || seen[t.Version] {
+			return nil, fmt.Errorf("thresholds table %d: missing or duplicate version %q", i, t.Version)
+		}
+		seen[t.Version] = true
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("thresholds %s: %w", t.Version, err)
+		}
+	}
+	sortTables(file.Tables)
+	return file.Tables, nil
+}
+
+// Helper function to check a table is complete and its cutoffs are in order
+func (t *Table) validate() error {
+	effective, err := time.Parse(time.DateOnly, t.Effective)
+	if err != nil {
+		return fmt.Errorf("invalid effective date %q", t.Effective)
+	}
+	t.effective = effective
+
+	if _, ok := t.Populations[Adult]; !ok {
+		return fmt.Errorf("missing %s glucose range", Adult)
+	}
+	for name, r := range t.Populations {
+		if !(0 < r.VeryLow && r.VeryLow < r.Low && r.Low < r.High && r.High < r.VeryHigh) {
+			return fmt.Errorf("%s glucose range must increase from very_low to very_high", name)
+		}
+	}
+	k := t.Ketones
+	if !(0 < k.BloodSmall && k.BloodSmall < k.BloodModerate && k.BloodModerate < k.BloodLarge) {
+		return fmt.Errorf("ketone cutoffs must increase from small to large")
+	}
+	if !(0 < t.HighBGAction && t.HighBGAction < t.HighBGCallDoctor) {
+		return fmt.Errorf("high_bg_action must be positive and below high_bg_call_doctor")
+	}
+	if t.CorrectionMinBG <= 0 {
+		return fmt.Errorf("correction_min_bg must be positive")
+	}
+	h := t.HypoRisk
+	if !(0 < h.NearLow && h.NearLow < h.Watch && 0 < h.NearLowIOB && h.NearLowIOB <= h.WatchIOB) {
+		return fmt.Errorf("hypo_risk near_low must be below watch, and near_low_iob positive and at most watch_iob")
+	}
+	if !(0 < t.Exercise.SnackBelow && t.Exercise.SnackBelow < t.Exercise.DelayAbove) {
+		return fmt.Errorf("exercise snack_below must be positive and below delay_above")
+	}
+	fast := t.Fasting
+	if fast.BreakAbove <= 0 || fast.MaxTimeBelow <= 0 || fast.MaxTimeBelow > 100 || fast.MaxTimeVeryHigh <= 0 || fast.MaxTimeVeryHigh > 100 {
+		return fmt.Errorf("fasting break_above must be positive, and max_time_below and max_time_very_high between 0 and 100")
+	}
+	return nil
+}
+
+func sortTables(ts []Table) {
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].effective.Before(ts[j].effective) })
+}
+
+// Return the table in force at a time: the one with the latest effective date
+// not after it. Before the first effective date, the oldest table applies.
+func Active(at time.Time) Table {
+	mu.RLock()
+	defer mu.RUnlock()
+	active := tables[0]
+	for _, t := range tables[1:] {
+		if !t.effective.After(at) {
+			active = t
+		}
+	}
+	return active
+}
+
+// Return the table in force now
+func Current() Table {
+	return Active(time.Now())
+}
+
+// Return every table, oldest first
+func All() []Table {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Table{}, tables...)
+}
+
+// Return the glucose range for a population, falling back to the adult range
+func (t Table) Range(population string) Range {
+	if r, ok := t.Populations[population]; ok {
+		return r
+	}
+	return t.Populations[Adult]
+}
+
+// Report whether a population has its own glucose range
+func (t Table) HasPopulation(population string) bool {
+	_, ok := t.Populations[population]
+	return ok
+}
+
+// Return the adult glucose range from the table in force now
+func Ranges() Range {
+	return Current().Range(Adult)
+}
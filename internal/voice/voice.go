@@ -0,0 +1,255 @@
+// Package voice maps Alexa and Google Assistant intents to flows and turns
+// flow output into short answers that read well aloud.
+package voice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Voice platforms
+const (
+	Alexa  = "alexa"
+	Google = "google"
+)
+
+// Longest answer spoken, in characters. Longer text is cut at a sentence.
+const maxSpeech = 300
+
+// Request Struct
+//
+// A voice request reduced to what the intents need.
+type Request struct {
+	Platform  string
+	Kind      string
+	Intent    string
+	Slots     map[string]string
+	SessionID string
+	SkillID   string
+}
+
+// Request kinds
+const (
+	KindLaunch = "launch"
+	KindIntent = "intent"
+	KindEnd    = "end"
+)
+
+// Intent Struct
+//
+// A flow an intent runs, how to build its input from the slots, and what to say with its output.
+type Intent struct {
+	Flow  string
+	Input func(slots map[string]string) (any, error)
+	Speak func(out json.RawMessage, slots map[string]string) string
+}
+
+// Intents by name. Skills and actions should define these intents and slots.
+var Intents = map[string]Intent{
+	"LogBloodSugar": {
+		Flow: "bloodSugarInterpreter",
+		Input: func(slots map[string]string) (any, error) {
+			reading, err := strconv.ParseFloat(strings.TrimSpace(slots["reading"]), 64)
+			if err != nil || reading <= 0 {
+				return nil, errors.New("I didn't catch the reading. Try saying: log my blood sugar of one forty five.")
+			}
+			return map[string]any{"reading": reading, "meal_timing": slots["timing"], "meal_type": slots["meal"]}, nil
+		},
+		Speak: func(out json.RawMessage, slots map[string]string) string {
+			var o struct {
+				Status         string `json:"status"`
+				Recommendation string `json:"recommendation"`
+			}
+			json.Unmarshal(out, &o)
+			return fmt.Sprintf("Logged %s. That's %s. %s", slots["reading"], o.Status, FirstSentences(o.Recommendation))
+		},
+	},
+	"MealSuggestion": {
+		Flow: "mealPlanner",
+		Input: func(slots map[string]string) (any, error) {
+			return map[string]any{"diet_type": slots["diet"]}, nil
+		},
+		Speak: func(out json.RawMessage, slots map[string]string) string {
+			var sections map[string]any
+			json.Unmarshal(out, &sections)
+			meal := strings.ToLower(slots["meal"])
+			if meal == "snack" {
+				meal = "snacks"
+			}
+			text, _ := sections[meal].(string)
+			if text == "" {
+				meal = "dinner"
+				text, _ = sections[meal].(string)
+			}
+			return fmt.Sprintf("For %s, try this. %s", meal, FirstSentences(text))
+		},
+	},
+}
+
+// What to say on launch and when asked for help
+const (
+	Welcome  = "Welcome to Diabetes Advisor. You can say: log my blood sugar of one forty five, or: what should I eat for dinner?"
+	Goodbye  = "Goodbye. Take care."
+	NotKnown = "Sorry, I can't help with that yet. You can log a blood sugar reading or ask what to eat."
+)
+
+// Built-in intents that end the session or ask for help
+var (
+	stopIntents = []string{"AMAZON.StopIntent", "AMAZON.CancelIntent", "actions.intent.CANCEL", "Stop"}
+	helpIntents = []string{"AMAZON.HelpIntent", "Help"}
+)
+
+// Report whether an intent asks to stop
+func IsStop(intent string) bool { return slices.Contains(stopIntents, intent) }
+
+// Report whether an intent asks for help
+func IsHelp(intent string) bool { return slices.Contains(helpIntents, intent) }
+
+// Alexa request body
+type alexaRequest struct {
+	Session struct {
+		SessionID   string `json:"sessionId"`
+		Application struct {
+			ApplicationID string `json:"applicationId"`
+		} `json:"application"`
+	} `json:"session"`
+	Request struct {
+		Type   string `json:"type"`
+		Intent struct {
+			Name  string `json:"name"`
+			Slots map[string]struct {
+				Value string `json:"value"`
+			} `json:"slots"`
+		} `json:"intent"`
+	} `json:"request"`
+}
+
+// Decode an Alexa skill request
+func ParseAlexa(body []byte) (Request, error) {
+	var in alexaRequest
+	if err := json.Unmarshal(body, &in); err != nil {
+		return Request{}, fmt.Errorf("failed to decode Alexa request: %w", err)
+	}
+	req := Request{Platform: Alexa, SessionID: in.Session.SessionID, SkillID: in.Session.Application.ApplicationID, Slots: map[string]string{}}
+	switch in.Request.Type {
+	case "LaunchRequest":
+		req.Kind = KindLaunch
+	case "SessionEndedRequest":
+		req.Kind = KindEnd
+	case "IntentRequest":
+		req.Kind, req.Intent = KindIntent, in.Request.Intent.Name
+		for name, slot := range in.Request.Intent.Slots {
+			if slot.Value != "" {
+				req.Slots[name] = slot.Value
+			}
+		}
+	default:
+		return Request{}, fmt.Errorf("unsupported Alexa request type %q", in.Request.Type)
+	}
+	return req, nil
+}
+
+// Google Assistant (Actions Builder) webhook body
+type googleRequest struct {
+	Handler struct {
+		Name string `json:"name"`
+	} `json:"handler"`
+	Intent struct {
+		Name   string `json:"name"`
+		Params map[string]struct {
+			Original string `json:"original"`
+			Resolved any    `json:"resolved"`
+		} `json:"params"`
+	} `json:"intent"`
+	Session struct {
+		ID string `json:"id"`
+	} `json:"session"`
+}
+
+// Decode a Google Assistant webhook request. The main invocation starts the
+// conversation; otherwise the intent is matched by name.
+func ParseGoogle(body []byte) (Request, error) {
+	var in googleRequest
+	if err := json.Unmarshal(body, &in); err != nil {
+		return Request{}, fmt.Errorf("failed to decode Google Assistant request: %w", err)
+	}
+	req := Request{Platform: Google, Kind: KindIntent, Intent: in.Intent.Name, SessionID: in.Session.ID, Slots: map[string]string{}}
+	if in.Intent.Name == "actions.intent.MAIN" || (in.Intent.Name == "" && in.Handler.Name == "main") {
+		req.Kind = KindLaunch
+	}
+	for name, p := range in.Intent.Params {
+		switch v := p.Resolved.(type) {
+		case string:
+			req.Slots[name] = v
+		case float64:
+			req.Slots[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			req.Slots[name] = p.Original
+		}
+	}
+	return req, nil
+}
+
+// Render the response body for a platform. End closes the session.
+func Respond(platform, sessionID, speech string, end bool) any {
+	ssml := "<speak>" + escape(speech) + "</speak>"
+	if platform == Google {
+		resp := map[string]any{
+			"session": map[string]any{"id": sessionID},
+			"prompt":  map[string]any{"firstSimple": map[string]string{"speech": ssml, "text": speech}},
+		}
+		if end {
+			resp["scene"] = map[string]any{"next": map[string]string{"name": "actions.scene.END_CONVERSATION"}}
+		}
+		return resp
+	}
+	return map[string]any{
+		"version": "1.0",
+		"response": map[string]any{
+			"outputSpeech":     map[string]string{"type": "SSML", "ssml": ssml},
+			"shouldEndSession": end,
+		},
+	}
+}
+
+// Markdown that reads badly aloud: list markers, then emphasis and headings
+var (
+	listMarker = regexp.MustCompile(`(?m)^\s*(?:[-•*]|\d+\.)\s+`)
+	markup     = regexp.MustCompile("[*_#>`]+")
+)
+
+// Sentence ends, keeping the punctuation
+var sentenceEnd = regexp.MustCompile(`[.!?](\s|$)`)
+
+// Strip formatting from generated text and keep the sentences that fit in a short answer
+func FirstSentences(text string) string {
+	text = markup.ReplaceAllString(listMarker.ReplaceAllString(text, ""), "")
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= maxSpeech {
+		return text
+	}
+	cut := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		if loc[0]+1 > maxSpeech {
+			break
+		}
+		cut = loc[0] + 1
+	}
+	if cut == 0 {
+		if i := strings.LastIndex(text[:maxSpeech], " "); i > 0 {
+			return text[:i] + "."
+		}
+		return text[:maxSpeech]
+	}
+	return text[:cut]
+}
+
+// Helper function to escape text for SSML
+func escape(s string) string {
+	return strings.NewReplacer("&", "and", "<", "", ">", "").Replace(s)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/alarms"
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/careteam"
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/disclaimer"
+	"diabeticai-advisor/internal/email"
+	"diabeticai-advisor/internal/jobs"
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/locale"
+	"diabeticai-advisor/internal/reminders"
+	"diabeticai-advisor/internal/store"
+
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Background jobs and their schedules
+func scheduleJobs(sched *jobs.Scheduler, g *genkit.Genkit, stores *store.Stores, claims kv.Store, bot *careteam.Bot, mailer *email.Sender, disclaimers *disclaimer.Set) error {
+	if err := sched.Add("reminders", "every 5m", func(ctx context.Context) error {
+		if n := reminders.Dispatch(ctx, stores, claims, time.Now()); n > 0 {
+			log.Printf("Queued %d reminder(s)", n)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Glucose alarms, checked each minute against the latest CGM readings
+	if err := sched.Add("glucose-alarms", "every 1m", func(ctx context.Context) error {
+		res, err := alarms.Check(ctx, stores, mailer, time.Now())
+		if res.Raised+res.Repeated+res.Escalated > 0 {
+			log.Printf("Alarms: %d raised, %d repeated, %d escalated", res.Raised, res.Repeated, res.Escalated)
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := sched.Add("stats-rollup", "daily 00:15", func(ctx context.Context) error {
+		yesterday := time.Now().AddDate(0, 0, -1)
+		for _, userID := range stores.Readings.Users() {
+			rollup := analytics.BuildDailyRollup(stores, userID, yesterday)
+			stores.Rollups.Add(ctx, rollup)
+
+			// Users who asked for a daily digest get the rollup as their summary
+			if stores.Notifications.Get(userID).Digest == store.DigestDaily {
+				out, _ := json.Marshal(rollup)
+				summary := store.Summary{UserID: userID, Kind: "daily", Output: out}
+				store.Stamp(&summary.UserID, &summary.Timestamp)
+				stores.Summaries.Add(ctx, summary)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if bot.Enabled() {
+		if err := sched.Add("care-team-alerts", "every 5m", func(ctx context.Context) error {
+			n, err := bot.PostAlerts(ctx, claims, time.Now())
+			if n > 0 {
+				log.Printf("Posted %d care team alert(s)", n)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := sched.Add("care-team-digest", "daily 08:00", func(ctx context.Context) error {
+			return bot.PostDigests(ctx, time.Now())
+		}); err != nil {
+			return err
+		}
+	}
+
+	// End-of-day reviews at each opted-in user's chosen local time, unless
+	// they already reviewed the day themselves
+	if err := sched.Add("day-review", "every 15m", func(ctx context.Context) error {
+		flow := lookupFlow(g, "dayReview")
+		if flow == nil {
+			return fmt.Errorf("dayReview flow is not registered")
+		}
+
+		failed := 0
+		for _, userID := range stores.Readings.Users() {
+			notify := stores.Notifications.Get(userID)
+			if notify.DayReview == "" {
+				continue
+			}
+			prefs, _ := locale.Resolve(stores.Preferences.Get(userID))
+			local := time.Now().In(prefs.Location)
+			at, _ := time.Parse("15:04", notify.DayReview)
+			if local.Hour()*60+local.Minute() < at.Hour()*60+at.Minute() || reviewedToday(stores, userID, local) {
+				continue
+			}
+			date := local.Format("2006-01-02")
+			key := "day-review:" + userID + ":" + date
+			if won, err := kv.Claim(ctx, claims, key, 24*time.Hour); err != nil || !won {
+				continue
+			}
+
+			input, _ := json.Marshal(map[string]string{"user_id": userID, "date": date})
+			out, err := flow.RunJSON(costs.WithUser(locale.With(ctx, prefs), userID), input, nil)
+			if err != nil {
+				log.Printf("Day review failed for %s: %v", userID, err)
+				kv.Release(ctx, claims, key)
+				failed++
+				continue
+			}
+			var review struct {
+				Intention string `json:"intention"`
+			}
+			json.Unmarshal(out, &review)
+			if len(notify.Channels) > 0 && !slices.Contains(notify.Muted, "day_review") {
+				stores.Reminders.Add(ctx, store.Reminder{
+					UserID:   userID,
+					Type:     "day_review",
+					Message:  "Your day in review is ready. Tomorrow's intention: " + review.Intention,
+					Channels: notify.Channels,
+					DueAt:    time.Now(),
+				})
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to generate %d day reviews", failed)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Monthly trend report for users who opted in, stored as a summary and
+	// emailed when SMTP is configured
+	if err := sched.Add("monthly-report", "monthly 1 07:00", func(ctx context.Context) error {
+		trends := lookupFlow(g, "glucoseTrends")
+		failed := 0
+		for _, userID := range stores.Readings.Users() {
+			notify := stores.Notifications.Get(userID)
+			if !notify.Monthly || !notify.Emailable() {
+				continue
+			}
+			prefs, _ := locale.Resolve(stores.Preferences.Get(userID))
+			now := time.Now()
+			if prefs.Location != nil {
+				now = now.In(prefs.Location)
+			}
+			thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+			report := analytics.BuildMonthlyReport(stores, userID, thisMonth.AddDate(0, -1, 0))
+
+			// Top insights from the trend analysis over the month's days
+			if trends != nil && report.Stats.Count > 0 {
+				days := int(thisMonth.Sub(report.Stats.From).Hours()/24 + 0.5)
+				input, _ := json.Marshal(map[string]any{"user_id": userID, "days": days})
+				out, err := trends.RunJSON(costs.WithUser(locale.With(ctx, prefs), userID), input, nil)
+				if err != nil {
+					log.Printf("Monthly report insights failed for %s: %v", userID, err)
+				} else {
+					report.Insights = topInsights(out)
+				}
+			}
+
+			out, _ := json.Marshal(report)
+			summary := store.Summary{UserID: userID, Kind: "monthly", Output: out}
+			store.Stamp(&summary.UserID, &summary.Timestamp)
+			stores.Summaries.Add(ctx, summary)
+
+			if !mailer.Enabled() {
+				continue
+			}
+			footer, _ := disclaimers.For("monthlyReport", prefs.Locale)
+			subject, html, err := email.RenderMonthly(report, prefs, footer)
+			if err == nil {
+				err = mailer.Send(notify.Email, subject, html)
+			}
+			if err != nil {
+				log.Printf("Monthly report failed for %s: %v", userID, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to email %d monthly reports", failed)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return sched.Add("weekly-summary", "weekly mon 07:00", func(ctx context.Context) error {
+		flow := lookupFlow(g, "weeklySummary")
+		if flow == nil {
+			return fmt.Errorf("weeklySummary flow is not registered")
+		}
+
+		failed := 0
+		for _, userID := range stores.Readings.Users() {
+			if stores.Notifications.Get(userID).Digest != store.DigestWeekly {
+				continue
+			}
+			input, _ := json.Marshal(map[string]string{"user_id": userID})
+			prefs, _ := locale.Resolve(stores.Preferences.Get(userID))
+			out, err := flow.RunJSON(costs.WithUser(locale.With(ctx, prefs), userID), input, nil)
+			if err != nil {
+				log.Printf("Weekly summary failed for %s: %v", userID, err)
+				failed++
+				continue
+			}
+			summary := store.Summary{UserID: userID, Kind: "weekly", Output: out}
+			store.Stamp(&summary.UserID, &summary.Timestamp)
+			stores.Summaries.Add(ctx, summary)
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to generate %d weekly summaries", failed)
+		}
+		return nil
+	})
+}
+
+// Insights kept from each of the trend flow's sections
+const insightsPerSection = 2
+
+// Bullet or number starting a list item
+var listMarker = regexp.MustCompile(`^\s*(?:[-•*]|\d+\.)\s+`)
+
+// Helper function to take the first points of the trend flow's patterns and suggestions
+func topInsights(out json.RawMessage) []string {
+	var trends struct {
+		Patterns    string `json:"patterns"`
+		Suggestions string `json:"suggestions"`
+	}
+	if err := json.Unmarshal(out, &trends); err != nil {
+		return nil
+	}
+	var insights []string
+	for _, section := range []string{trends.Patterns, trends.Suggestions} {
+		n := 0
+		for _, line := range strings.Split(section, "\n") {
+			line = strings.TrimSpace(strings.ReplaceAll(listMarker.ReplaceAllString(line, ""), "**", ""))
+			if line == "" || strings.HasSuffix(line, ":") || n == insightsPerSection {
+				continue
+			}
+			insights = append(insights, line)
+			n++
+		}
+	}
+	return insights
+}
+
+// Helper function to check whether a user has reviewed the local day already
+func reviewedToday(stores *store.Stores, userID string, local time.Time) bool {
+	date := local.Format("2006-01-02")
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	for _, r := range stores.DayReviews.Range(userID, midnight, local.Add(time.Second)) {
+		if r.Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to find a registered flow by name
+func lookupFlow(g *genkit.Genkit, name string) api.Action {
+	for _, flow := range genkit.ListFlows(g) {
+		if flow.Name() == name {
+			return flow
+		}
+	}
+	return nil
+}
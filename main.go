@@ -3,442 +3,404 @@ package main
 // Import the required packages
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"flag"
 	"log"
-	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"diabeticai-advisor/internal/analytics"
+	"diabeticai-advisor/internal/careteam"
+	"diabeticai-advisor/internal/config"
+	"diabeticai-advisor/internal/costs"
+	"diabeticai-advisor/internal/db"
+	"diabeticai-advisor/internal/disclaimer"
+	"diabeticai-advisor/internal/email"
+	"diabeticai-advisor/internal/fda"
+	"diabeticai-advisor/internal/flows"
+	"diabeticai-advisor/internal/guidelines"
+	"diabeticai-advisor/internal/jobs"
+	"diabeticai-advisor/internal/kv"
+	"diabeticai-advisor/internal/nutrition"
+	"diabeticai-advisor/internal/prompts"
+	"diabeticai-advisor/internal/redact"
+	"diabeticai-advisor/internal/replay"
+	"diabeticai-advisor/internal/safety"
+	"diabeticai-advisor/internal/sdkgen"
+	"diabeticai-advisor/internal/server"
+	"diabeticai-advisor/internal/sessions"
+	"diabeticai-advisor/internal/shadow"
+	"diabeticai-advisor/internal/store"
+	"diabeticai-advisor/internal/thresholds"
 
 	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/firebase/genkit/go/plugins/googlegenai"
-	"github.com/firebase/genkit/go/plugins/server"
+	"github.com/firebase/genkit/go/plugins/ollama"
 )
 
-// Define Input and Output Structures for each flow
-
-// BloodSugar Input Struct
-type BloodSugarInput struct {
-	Reading    float64 `json:"reading" jsonschema:"description=Blood sugar reading in mg/dL"`
-	MealTiming string  `json:"meal_timing" jsonschema:"description=Timing: fasting, before_meal, after_meal"`
-	MealType   string  `json:"meal_type" jsonschema:"description=Type of meal: breakfast, lunch, dinner, snack"`
-}
-
-// BloodSugar Output Struct
-type BloodSugarOutput struct {
-	Status         string `json:"status" jsonschema:"description=Status: normal, high, low, critical"`
-	Interpretation string `json:"interpretation" jsonschema:"description=Detailed interpretation"`
-	Recommendation string `json:"recommendation" jsonschema:"description=Immediate recommendations"`
-}
-
-// MealPlan Input Struct
-type MealPlanInput struct {
-	DietType     string  `json:"diet_type" jsonschema:"description=Diet preference: vegetarian, non_vegetarian, vegan"`
-	Allergies    string  `json:"allergies" jsonschema:"description=Any food allergies or restrictions"`
-	CalorieLimit float64 `json:"calorie_limit" jsonschema:"description=Daily calorie limit (optional)"`
-}
-
-// MealPlan Output Struct
-type MealPlanOutput struct {
-	Breakfast string `json:"breakfast" jsonschema:"description=Breakfast suggestions"`
-	Lunch     string `json:"lunch" jsonschema:"description=Lunch suggestions"`
-	Dinner    string `json:"dinner" jsonschema:"description=Dinner suggestions"`
-	Snacks    string `json:"snacks" jsonschema:"description=Healthy snack options"`
-}
-
-// Symptom Input Struct
-type SymptomInput struct {
-	Symptoms    string `json:"symptoms" jsonschema:"description=Describe symptoms you're experiencing"`
-	Duration    string `json:"duration" jsonschema:"description=How long symptoms have been present"`
-	CurrentMeds string `json:"current_meds" jsonschema:"description=Current medications (optional)"`
-}
-
-// Symptom Output Struct
-type SymptomOutput struct {
-	Urgency    string `json:"urgency" jsonschema:"description=Urgency level: emergency, urgent, routine"`
-	Assessment string `json:"assessment" jsonschema:"description=Symptom assessment"`
-	NextSteps  string `json:"next_steps" jsonschema:"description=Recommended next steps"`
-}
-
-// Exercise Input Struct
-type ExerciseInput struct {
-	FitnessLevel  string  `json:"fitness_level" jsonschema:"description=Fitness level: beginner, intermediate, advanced"`
-	TimeAvailable int     `json:"time_available" jsonschema:"description=Minutes available for exercise"`
-	CurrentBG     float64 `json:"current_bg" jsonschema:"description=Current blood glucose level (optional)"`
-	PreferredType string  `json:"preferred_type" jsonschema:"description=Exercise preference: cardio, strength, yoga, walking"`
-}
-
-// Exercise Output Struct
-type ExerciseOutput struct {
-	SafetyCheck    string `json:"safety_check" jsonschema:"description=Safety considerations based on BG"`
-	Recommendation string `json:"recommendation" jsonschema:"description=Exercise recommendations"`
-	Duration       string `json:"duration" jsonschema:"description=Recommended duration and intensity"`
-	Precautions    string `json:"precautions" jsonschema:"description=Important precautions"`
-}
+// Declare main function
+func main() {
 
-// Medication Input Struct
-type MedicationInput struct {
-	MedicationName string `json:"medication_name" jsonschema:"description=Name of medication"`
-	Purpose        string `json:"purpose" jsonschema:"description=Purpose of inquiry (dosage, timing, side_effects, interactions)"`
-}
+	// Parse command-line flags
+	dev := flag.Bool("dev", false, "load the env file and reload prompts and config when they change")
+	selftest := flag.Bool("selftest", false, "run a welcome generation and sample flows against the model, then exit")
+	sdk := flag.String("sdk", "", "generate the Go and TypeScript clients into this directory, then exit")
+	flag.Parse()
 
-// Medication Output Struct
-type MedicationOutput struct {
-	Information string `json:"information" jsonschema:"description=Medication information"`
-	Reminder    string `json:"reminder" jsonschema:"description=Important reminders"`
-	Disclaimer  string `json:"disclaimer" jsonschema:"description=Medical disclaimer"`
-}
+	// Create a blank context
+	ctx := context.Background()
 
-// Helper function to split text into sections
-func splitIntoSections(text string, numSections int) []string {
-	sections := make([]string, numSections)
-	if text == "" || numSections <= 0 {
-		return sections
+	// In dev mode, read settings from the env file first
+	if *dev {
+		if err := config.LoadEnvFile(envFilePath()); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	parts := strings.Split(text, "\n\n")
-
-	for i := 0; i < numSections && i < len(parts); i++ {
-		sections[i] = strings.TrimSpace(parts[i])
+	// Load configuration from the environment
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	return sections
-}
+	// Load insulin action curves
+	if err := analytics.LoadInsulinCurves(cfg.InsulinCurves); err != nil {
+		log.Fatalf("Invalid INSULIN_CURVES: %v", err)
+	}
 
-// Helper function to parse meal sections
-func parseMealSections(text string) map[string]string {
-	return map[string]string{
-		"breakfast": extractSection(text, "BREAKFAST"),
-		"lunch":     extractSection(text, "LUNCH"),
-		"dinner":    extractSection(text, "DINNER"),
-		"snacks":    extractSection(text, "SNACKS"),
+	// Load clinical threshold tables
+	if err := thresholds.Load(cfg.ThresholdsFile); err != nil {
+		log.Fatalf("Invalid THRESHOLDS_FILE: %v", err)
+	}
+	active := thresholds.Current()
+	log.Printf("Clinical thresholds %s in effect since %s", active.Version, active.Effective)
+
+	// Load prompt overrides
+	if n, err := prompts.LoadDir(cfg.PromptsDir); err != nil {
+		log.Fatal(err)
+	} else if n > 0 {
+		log.Printf("Loaded %d prompt override(s) from %s", n, cfg.PromptsDir)
 	}
-}
 
-// Helper function to extract section from text
-func extractSection(text, keyword string) string {
-	if text == "" {
-		return "No information available."
+	// Record or replay model responses
+	if err := replay.Configure(cfg.ReplayMode, cfg.ReplayDir); err != nil {
+		log.Fatalf("Invalid REPLAY_MODE: %v", err)
+	} else if cfg.ReplayMode != "" {
+		log.Printf("Model responses: %s (%s)", cfg.ReplayMode, cfg.ReplayDir)
 	}
 
-	textUpper := strings.ToUpper(text)
-	keywordUpper := strings.ToUpper(keyword)
+	// Initialize Google's AI plugin with the Key
+	plugin := &googlegenai.GoogleAI{
+		APIKey: cfg.GeminiAPIKey,
+	}
+	plugins := []api.Plugin{plugin}
 
-	start := strings.Index(textUpper, keywordUpper)
-	if start == -1 {
-		return "No information available."
+	// A local Ollama server embeds guideline passages in self-hosted deployments
+	var local *ollama.Ollama
+	if cfg.GuidelineEmbedder == "ollama" {
+		local = &ollama.Ollama{ServerAddress: cfg.OllamaAddress}
+		plugins = append(plugins, local)
 	}
 
-	// Move past the keyword
-	content := text[start+len(keywordUpper):]
+	// Initialize Genkit
+	g := genkit.Init(ctx,
+		genkit.WithPlugins(plugins...),
+		genkit.WithDefaultModel(cfg.Model),
+	)
 
-	// Stop at the next section header
-	for _, next := range []string{"BREAKFAST", "LUNCH", "DINNER", "SNACKS"} {
-		if next == keywordUpper {
-			continue
-		}
-		if idx := strings.Index(strings.ToUpper(content), next); idx != -1 {
-			content = content[:idx]
-			break
+	// Guideline passages for grounding advice, when an embedder is configured
+	var embedder ai.Embedder
+	switch cfg.GuidelineEmbedder {
+	case "googleai":
+		if embedder, err = plugin.DefineEmbedder(g, cfg.EmbedModel, nil); err != nil {
+			log.Fatal(err)
 		}
+	case "ollama":
+		embedder = local.DefineEmbedder(g, cfg.OllamaAddress, cfg.EmbedModel, nil)
 	}
-
-	clean := strings.TrimSpace(strings.Trim(content, ":-"))
-	if clean == "" {
-		return "No information available."
+	guides, err := guidelines.New(embedder)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if guides.Enabled() {
+		log.Printf("Grounding advice in guideline passages embedded with %s (%s)", cfg.EmbedModel, guides.Embedder())
 	}
 
-	return clean
-}
-
-// Helper function to check for keywords
-func containsKeywords(text string, keywords []string) bool {
-	if text == "" || len(keywords) == 0 {
-		return false
+	// Shared stores, redacting personal details from free text before it is kept
+	redactor, err := redact.New(cfg.RedactFields)
+	if err != nil {
+		log.Fatalf("Invalid REDACT_FIELDS: %v", err)
 	}
+	stores := store.New(redactor.Field)
 
-	textLower := strings.ToLower(text)
+	// Database for stores and conversations, when DATABASE_PATH is set
+	database, err := db.Open(cfg.DatabasePath)
+	if err != nil {
+		log.Fatalf("Invalid DATABASE_PATH: %v", err)
+	}
+	if database != nil {
+		defer database.Close()
+	}
 
-	for _, keyword := range keywords {
-		if keyword == "" {
-			continue
-		}
-		if strings.Contains(textLower, strings.ToLower(keyword)) {
-			return true
+	// Persist stores so readings, logs and profiles survive restarts
+	var backend store.Backend
+	switch cfg.Storage {
+	case "sqlite":
+		backend = store.NewSQLBackend(database)
+	case "firestore":
+		if backend, err = store.NewFirestoreBackend(ctx, cfg.FirestoreProject, cfg.FirestoreDatabase); err != nil {
+			log.Fatal(err)
 		}
 	}
-
-	return false
-}
-
-// Declare main function
-func main() {
-
-	// Create a blank context
-	ctx := context.Background()
-
-	// Load the Google's AI API Key environment variable
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		log.Fatal("GEMINI API KEY environment variable is missing!")
+	if err := stores.Open(ctx, backend); err != nil {
+		log.Fatalf("Failed to load %s storage: %v", cfg.Storage, err)
 	}
+	log.Printf("Storing readings, logs and profiles in %s", cfg.Storage)
 
-	// Initialize Google's AI plugin with the Key
-	plugin := &googlegenai.GoogleAI{
-		APIKey: apiKey,
+	// Model cost accounting
+	if err := costs.LoadPricing(cfg.ModelPrices); err != nil {
+		log.Fatalf("Invalid MODEL_PRICES: %v", err)
+	}
+	costs.Configure(stores.Usage, cfg.Model, cfg.CostCurrency)
+
+	// Shadow runs of candidate prompts and models, never returned to users
+	if err := shadow.Configure(stores.Shadows, shadow.Config{
+		Model:         cfg.ShadowModel,
+		PromptsDir:    cfg.ShadowPromptsDir,
+		SamplePercent: cfg.ShadowSamplePct,
+		Flows:         cfg.ShadowFlows,
+	}); err != nil {
+		log.Fatalf("Invalid shadow mode settings: %v", err)
 	}
 
-	// Initialize Genkit
-	g := genkit.Init(ctx,
-		genkit.WithPlugins(plugin),
-		genkit.WithDefaultModel("googleai/gemini-2.5-flash"),
-	)
+	// Content safety filter on generated answers
+	if err := safety.Configure(safety.Config{
+		Mode:          cfg.SafetyFilter,
+		Action:        cfg.SafetyAction,
+		BlocklistFile: cfg.SafetyBlocklist,
+		Model:         cfg.SafetyModel,
+	}); err != nil {
+		log.Fatalf("Invalid safety filter settings: %v", err)
+	}
 
-	// Welcome Message
-	fmt.Println("=== DiabetesAI Advisor Initializing ===")
-	response, err := genkit.Generate(ctx, g,
-		ai.WithPrompt("Generate a warm welcome, encouraging welcome message for diabetes patients using this AI health advisor. Keep it under 50 words."),
-	)
+	// Nutrition lookups
+	fdcCache, err := nutrition.NewCache(cfg.FDCCacheFile)
 	if err != nil {
-		log.Printf("Error generating welcome: %v", err)
-	} else {
-		fmt.Println("\n" + response.Text())
+		log.Fatal(err)
 	}
+	foods := nutrition.NewClient(cfg.FDCAPIKey, fdcCache)
 
-	// Flow 1: Blood Sugar Interpreter
-	bloodSugarFlow := genkit.DefineFlow(g, "bloodSugarInterpreter", func(ctx context.Context, input *BloodSugarInput) (*BloodSugarOutput, error) {
-		prompt := fmt.Sprintf(`You are a diabetes care advisor. Analyze this blood sugar reading:
-		
-Reading: %.1f mg/dL
-Timing: %s
-Meal: %s
-
-Provide:
-1. Status (normal/high/low/critical)
-2. Clear interpretation in simple terms
-3. Immediate actionable recommendations
-
-Guidelines:
-- Fasting: 70-100 normal, 100-126 pre-diabetes, >126 diabetes concern
-- Before meal: 70-130 normal
-- 2 hours after meal: <180 normal
-- <70 is low (hypoglycemia)
-- >250 requires immediate attention
-
-Be supportive and clear.`, input.Reading, input.MealTiming, input.MealType)
-
-		result, err := genkit.Generate(ctx, g, ai.WithPrompt(prompt))
-		if err != nil {
-			return nil, fmt.Errorf("failed to interpret blood sugar: %w", err)
-		}
-
-		// Determine status based on reading
-		status := "normal"
-		if input.Reading < 70 {
-			status = "low"
-		} else if input.Reading > 250 {
-			status = "critical"
-		} else if input.Reading > 180 {
-			status = "high"
-		}
-
-		text := result.Text()
-		parts := splitIntoSections(text, 3)
-
-		return &BloodSugarOutput{
-			Status:         status,
-			Interpretation: parts[0],
-			Recommendation: parts[1],
-		}, nil
-	})
-
-	// Flow 2: Meal Planner
-	mealPlanFlow := genkit.DefineFlow(g, "mealPlanner", func(ctx context.Context, input *MealPlanInput) (*MealPlanOutput, error) {
-		calorieInfo := ""
-		if input.CalorieLimit > 0 {
-			calorieInfo = fmt.Sprintf("Target daily calories: %.0f", input.CalorieLimit)
-		}
+	// FDA drug label lookups
+	labels, err := fda.NewClient(cfg.OpenFDAAPIKey, cfg.LabelCacheFile)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		prompt := fmt.Sprintf(`Create a diabetes-friendly meal plan:
+	// Shared state for sessions, response caching and rate limits (Redis when REDIS_URL is set)
+	shared, err := kv.Open(cfg.RedisURL)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-Diet Type: %s
-Allergies/Restrictions: %s
-%s
+	// Set up HTTP server with access control
+	keys, err := server.NewAPIKeyStore(cfg.APIKeys)
+	if err != nil {
+		log.Fatalf("Invalid API_KEYS: %v", err)
+	}
+	limiter := server.NewRateLimiter(cfg.RateLimitPerMinute, shared)
+	mux := server.NewMux(keys, limiter)
+	mux.Cache = server.NewResponseCache(shared, cfg.ResponseCacheTTL)
+	mux.Preferences = stores.Preferences
+	mux.Shares = stores.Shares
+	if mux.Disclaimers, err = disclaimer.Load(cfg.DisclaimersFile, cfg.DefaultLocale); err != nil {
+		log.Fatal(err)
+	}
+	mux.Sunset = cfg.LegacySunset
+	mux.Compress = cfg.CompressResponses
+
+	// Request body limits, larger for the photo and import uploads
+	mux.MaxBodyBytes = cfg.MaxBodyBytes
+	mux.LimitBody("POST /symptoms", cfg.MaxUploadBytes)
+	mux.LimitBody("POST /import", cfg.MaxImportBytes)
+	if err := mux.Use(cfg.Middleware...); err != nil {
+		log.Fatalf("Invalid MIDDLEWARE: %v", err)
+	}
 
-For each meal, provide:
-- Specific food items
-- Approximate portion sizes
-- Why it's good for blood sugar control
+	// Conversations, in the database when DATABASE_PATH is set so they survive restarts
+	conversations := sessions.NewStore(shared, cfg.SessionTTL)
+	if database != nil {
+		conversations = sessions.NewSQLStore(database, cfg.SessionTTL)
+	}
 
-Focus on:
-- Low glycemic index foods
-- Balanced macros (protein, healthy fats, complex carbs)
-- High fiber content
-- Foods that prevent blood sugar spikes
+	// Register flows, data endpoints and admin endpoints
+	deps := flows.Deps{
+		Stores:     stores,
+		Nutrition:  foods,
+		Products:   nutrition.NewOpenFoodFacts(),
+		Labels:     labels,
+		Sessions:   conversations,
+		Guidelines: guides,
+		Redact:     redactor.Field,
+		Evaluator: &flows.Evaluator{
+			Evaluations:   stores.Evaluations,
+			Model:         cfg.EvalModel,
+			SamplePercent: cfg.EvalSamplePercent,
+			AlertPercent:  cfg.EvalAlertPercent,
+		},
+		ChatTokenBudget:  cfg.ChatTokenBudget,
+		ChatSummaryModel: cfg.ChatSummaryModel,
+	}
+	for _, flow := range flows.All(deps) {
+		flow.Register(g, mux)
+	}
+	server.RegisterData(mux, stores)
+	server.RegisterNutrition(mux, foods)
+	server.RegisterSessions(mux, conversations)
+	server.RegisterVoice(mux, cfg.AlexaSkillID)
+	server.RegisterAdmin(mux, cfg.AdminAPIKey)
+	server.RegisterEvaluations(mux, stores.Evaluations, cfg.EvalAlertPercent, cfg.AdminAPIKey)
+	server.RegisterCosts(mux, stores.Usage, cfg.AdminAPIKey)
+	server.RegisterShadow(mux, stores.Shadows, cfg.AdminAPIKey)
+
+	// Care team bot for Slack and Discord
+	bot, err := careteam.New(careteam.Config{
+		TeamID:            cfg.CareTeamID,
+		Staff:             cfg.CareTeamStaff,
+		SlackWebhookURL:   cfg.SlackWebhookURL,
+		SlackSigningKey:   cfg.SlackSigningSecret,
+		DiscordWebhookURL: cfg.DiscordWebhookURL,
+		DiscordPublicKey:  cfg.DiscordPublicKey,
+	}, stores)
+	if err != nil {
+		log.Fatalf("Invalid CARE_TEAM_STAFF: %v", err)
+	}
+	server.RegisterCareTeam(mux, bot)
+	server.RegisterIngest(mux, stores, cfg.IngestSecrets)
 
-Format:
-BREAKFAST: [meal details]
-LUNCH: [meal details]
-DINNER: [meal details]
-SNACKS: [snack options]`, input.DietType, input.Allergies, calorieInfo)
+	// Background jobs
+	sched, err := jobs.New(cfg.JobsStateFile, shared)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mailer, err := email.NewSender(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := scheduleJobs(sched, g, stores, shared, bot, mailer, mux.Disclaimers); err != nil {
+		log.Fatal(err)
+	}
+	server.RegisterJobs(mux, sched, cfg.AdminAPIKey)
 
-		result, err := genkit.Generate(ctx, g, ai.WithPrompt(prompt))
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate meal plan: %w", err)
+	// Generate the client SDKs from the registered flows instead of serving
+	if *sdk != "" {
+		doc, err := json.MarshalIndent(mux.OpenAPI(), "", "  ")
+		if err == nil {
+			err = sdkgen.Write(*sdk, append(doc, '\n'))
 		}
-
-		text := result.Text()
-		sections := parseMealSections(text)
-
-		return &MealPlanOutput{
-			Breakfast: sections["breakfast"],
-			Lunch:     sections["lunch"],
-			Dinner:    sections["dinner"],
-			Snacks:    sections["snacks"],
-		}, nil
-	})
-
-	// Flow 3: Symptom Checker
-	symptomFlow := genkit.DefineFlow(g, "symptomChecker", func(ctx context.Context, input *SymptomInput) (*SymptomOutput, error) {
-		prompt := fmt.Sprintf(`You are a diabetes health advisor. Assess these symptoms:
-
-Symptoms: %s
-Duration: %s
-Current Medications: %s
-
-Determine:
-1. URGENCY LEVEL: 
-   - EMERGENCY (call 911): Severe symptoms like chest pain, loss of consciousness, extreme confusion
-   - URGENT (contact doctor today): Persistent high BG, signs of infection, concerning symptoms
-   - ROUTINE (monitor and schedule appointment): Mild symptoms
-
-2. ASSESSMENT: What these symptoms might indicate
-
-3. NEXT STEPS: Specific actions to take
-
-Be clear about when to seek immediate medical help. Always err on the side of caution.`, input.Symptoms, input.Duration, input.CurrentMeds)
-
-		result, err := genkit.Generate(ctx, g, ai.WithPrompt(prompt))
 		if err != nil {
-			return nil, fmt.Errorf("failed to check symptoms: %w", err)
+			log.Fatalf("Failed to generate SDKs: %v", err)
 		}
+		log.Printf("Generated SDKs in %s", *sdk)
+		return
+	}
 
-		text := result.Text()
-
-		// Determine urgency from response
-		urgency := "routine"
-		if containsKeywords(text, []string{"emergency", "911", "immediate", "urgent care"}) {
-			urgency = "emergency"
-		} else if containsKeywords(text, []string{"urgent", "contact doctor", "today"}) {
-			urgency = "urgent"
+	// Run the self-test instead of serving
+	if *selftest {
+		if failed := runSelfTest(ctx, g); failed > 0 {
+			log.Fatalf("Self-test failed: %d check(s)", failed)
 		}
+		log.Println("Self-test passed")
+		return
+	}
 
-		parts := splitIntoSections(text, 3)
-
-		return &SymptomOutput{
-			Urgency:    urgency,
-			Assessment: parts[0],
-			NextSteps:  parts[1],
-		}, nil
-	})
-
-	// Flow 4: Exercise Advisor
-	exerciseFlow := genkit.DefineFlow(g, "exerciseAdvisor", func(ctx context.Context, input *ExerciseInput) (*ExerciseOutput, error) {
-		bgInfo := ""
-		if input.CurrentBG > 0 {
-			bgInfo = fmt.Sprintf("Current Blood Glucose: %.1f mg/dL", input.CurrentBG)
+	// On Cloud Run an instance may be stopped between requests, so jobs run
+	// when Cloud Scheduler calls POST /jobs/{name}/run instead of on a timer
+	if cfg.Serverless() {
+		log.Println("Cloud Run mode: background jobs run when triggered at POST /jobs/{name}/run")
+		for _, job := range sched.List() {
+			log.Printf("  %-18s %-18s cron %q", job.Name, job.Schedule, job.Cron)
 		}
-
-		prompt := fmt.Sprintf(`Create a diabetes-safe exercise plan:
-
-Fitness Level: %s
-Time Available: %d minutes
-%s
-Preferred Exercise: %s
-
-Provide:
-1. SAFETY CHECK: Is it safe to exercise now based on BG? (BG 100-250 is generally safe, <100 eat snack first, >250 delay exercise)
-2. EXERCISE PLAN: Specific exercises with sets/reps or duration
-3. DURATION & INTENSITY: How to structure the workout
-4. PRECAUTIONS: Important safety tips
-
-Remember:
-- Exercise lowers blood sugar
-- Stay hydrated
-- Have fast-acting carbs nearby
-- Stop if feeling dizzy or unwell`, input.FitnessLevel, input.TimeAvailable, bgInfo, input.PreferredType)
-
-		result, err := genkit.Generate(ctx, g, ai.WithPrompt(prompt))
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate exercise plan: %w", err)
+		if cfg.Storage == "memory" {
+			log.Println("Warning: STORAGE=memory loses data whenever an instance stops; set STORAGE=firestore")
 		}
+	} else {
+		go sched.Start(ctx)
+	}
 
-		text := result.Text()
-		parts := splitIntoSections(text, 4)
-
-		return &ExerciseOutput{
-			SafetyCheck:    parts[0],
-			Recommendation: parts[1],
-			Duration:       parts[2],
-			Precautions:    parts[3],
-		}, nil
-	})
-
-	// Flow 5: Medication Info
-	medicationFlow := genkit.DefineFlow(g, "medicationInfo", func(ctx context.Context, input *MedicationInput) (*MedicationOutput, error) {
-		prompt := fmt.Sprintf(`Provide general information about diabetes medication:
-
-Medication: %s
-Question about: %s
-
-Provide helpful general information, but:
-1. DO NOT prescribe or change dosages
-2. Emphasize consulting with healthcare provider
-3. Mention common considerations
-4. Include important safety information
-
-Always include a clear disclaimer that this is educational information only.`, input.MedicationName, input.Purpose)
-
-		result, err := genkit.Generate(ctx, g, ai.WithPrompt(prompt))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get medication info: %w", err)
+	// Reload prompts and config on change in dev mode
+	if *dev {
+		watched := []string{cfg.PromptsDir, cfg.EnvFile}
+		if cfg.ThresholdsFile != "" {
+			watched = append(watched, cfg.ThresholdsFile)
 		}
-
-		disclaimer := "⚠️ IMPORTANT: This is educational information only. Always consult your healthcare provider before starting, stopping, or changing any medication. This AI advisor cannot replace professional medical advice."
-
-		return &MedicationOutput{
-			Information: result.Text(),
-			Reminder:    "Set reminders on your phone for medication times. Never skip doses without consulting your doctor.",
-			Disclaimer:  disclaimer,
-		}, nil
-	})
-
-	// Set up HTTP server
-	mux := http.NewServeMux()
-	mux.HandleFunc("POST /bloodSugar", genkit.Handler(bloodSugarFlow))
-	mux.HandleFunc("POST /mealPlan", genkit.Handler(mealPlanFlow))
-	mux.HandleFunc("POST /symptoms", genkit.Handler(symptomFlow))
-	mux.HandleFunc("POST /exercise", genkit.Handler(exerciseFlow))
-	mux.HandleFunc("POST /medication", genkit.Handler(medicationFlow))
-
-	// Determine port (Cloud Run compatible)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+		go config.Watch(ctx, watched, time.Second, func(path string) {
+			reload(path, cfg, limiter)
+		})
+		log.Printf("Dev mode: watching %s for changes", strings.Join(watched, ", "))
+
+		// Try-it console for the flows, without authentication
+		server.RegisterConsole(mux)
+		log.Printf("Dev mode: try the flows at http://localhost:%s/console", cfg.Port)
 	}
 
-	addr := "0.0.0.0:" + port
-
 	// Print server info
+	addr := cfg.Addr()
 	log.Println("=== DiabetesAI Advisor Server Starting ===")
 	log.Printf("Server listening on %s\n", addr)
 	log.Println("Available Endpoints:")
-	log.Println("  POST /bloodSugar   - Interpret blood sugar readings")
-	log.Println("  POST /mealPlan     - Get diabetes-friendly meal plans")
-	log.Println("  POST /symptoms     - Check symptoms and get guidance")
-	log.Println("  POST /exercise     - Get safe exercise recommendations")
-	log.Println("  POST /medication   - Get medication information")
+	for _, route := range mux.Routes() {
+		method, path, _ := strings.Cut(route.Pattern, " ")
+		log.Printf("  %-6s %-20s - %s", method, path, route.Description)
+	}
+	if cfg.AdminAPIKey != "" {
+		log.Println("Admin API enabled under /admin")
+	}
 
 	// Start the server
 	log.Fatal(server.Start(ctx, addr, mux))
 }
+
+// Helper function to find the env file before config is loaded
+func envFilePath() string {
+	if path := os.Getenv("ENV_FILE"); path != "" {
+		return path
+	}
+	return ".env"
+}
+
+// Helper function to apply a changed prompts directory or env file.
+// Only settings that can change at runtime are applied; the rest need a restart.
+func reload(path string, cfg *config.Config, limiter *server.RateLimiter) {
+	if path == cfg.PromptsDir {
+		n, err := prompts.LoadDir(path)
+		if err != nil {
+			log.Printf("Prompt reload failed, keeping previous prompts: %v", err)
+			return
+		}
+		log.Printf("Reloaded %d prompt override(s)", n)
+		return
+	}
+	if path == cfg.ThresholdsFile {
+		if err := thresholds.Load(path); err != nil {
+			log.Printf("Thresholds reload failed, keeping previous tables: %v", err)
+			return
+		}
+		log.Printf("Reloaded thresholds; %s is in effect", thresholds.Current().Version)
+		return
+	}
+
+	if err := config.LoadEnvFile(path); err != nil {
+		log.Printf("Config reload failed: %v", err)
+		return
+	}
+	next, err := config.Load()
+	if err != nil {
+		log.Printf("Config reload failed: %v", err)
+		return
+	}
+	limiter.SetLimit(next.RateLimitPerMinute)
+	log.Printf("Reloaded config from %s (rate limit %d/min)", path, next.RateLimitPerMinute)
+}
@@ -0,0 +1,3 @@
+module github.com/Narokwe/diabeticai-advisor/sdk/go
+
+go 1.22
@@ -0,0 +1,1247 @@
+// Code generated by diabeticai-advisor --sdk. DO NOT EDIT.
+
+// Typed client for the DiabetesAI Advisor API (v1).
+
+export interface AdvisorClientOptions {
+  /** Server URL, such as https://advisor.example.com */
+  baseUrl: string;
+  /** Sent as the X-API-Key header */
+  apiKey?: string;
+  /** Defaults to the global fetch */
+  fetch?: typeof fetch;
+}
+
+/** The error envelope every endpoint returns. message is safe to show to users. */
+export interface ErrorBody {
+  code: string;
+  message: string;
+  retryable: boolean;
+  retry_after_seconds?: number;
+  request_id?: string;
+}
+
+export class AdvisorError extends Error {
+  readonly status: number;
+  readonly code: string;
+  readonly retryable: boolean;
+  readonly retryAfterSeconds?: number;
+  readonly requestId?: string;
+
+  constructor(status: number, body: ErrorBody) {
+    super(body.message);
+    this.name = "AdvisorError";
+    this.status = status;
+    this.code = body.code;
+    this.retryable = body.retryable;
+    this.retryAfterSeconds = body.retry_after_seconds;
+    this.requestId = body.request_id;
+  }
+}
+
+export class AdvisorClient {
+  private readonly baseUrl: string;
+  private readonly apiKey?: string;
+  private readonly fetchFn: typeof fetch;
+
+  constructor(options: AdvisorClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.apiKey = options.apiKey;
+    this.fetchFn = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  /** Sends a flow request in its {"data": ...} envelope and unwraps the {"result": ...} reply */
+  private async call<I, O>(method: string, path: string, input: I, init?: RequestInit): Promise<O> {
+    const headers: Record<string, string> = {
+      "Content-Type": "application/json",
+      Accept: "application/json",
+    };
+    if (this.apiKey) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    const resp = await this.fetchFn(this.baseUrl + path, {
+      ...init,
+      method,
+      headers: { ...headers, ...(init?.headers as Record<string, string> | undefined) },
+      body: JSON.stringify({ data: input }),
+    });
+    const body = await resp.json().catch(() => undefined);
+    if (!resp.ok) {
+      throw new AdvisorError(resp.status, body?.error ?? { code: "internal", message: resp.statusText, retryable: false });
+    }
+    return body.result as O;
+  }
+
+  /** Interpret blood sugar readings */
+  bloodSugarInterpreter(input: BloodSugarInterpreterInput, init?: RequestInit): Promise<BloodSugarInterpreterOutput> {
+    return this.call("POST", "/v1/bloodSugar", input, init);
+  }
+
+  /** Chat about diabetes; long conversations are summarized to stay within limits */
+  chat(input: ChatInput, init?: RequestInit): Promise<ChatOutput> {
+    return this.call("POST", "/v1/chat", input, init);
+  }
+
+  /** Reflect on the day and set one intention for tomorrow */
+  dayReview(input: DayReviewInput, init?: RequestInit): Promise<DayReviewOutput> {
+    return this.call("POST", "/v1/dayReview", input, init);
+  }
+
+  /** Get safe exercise recommendations */
+  exerciseAdvisor(input: ExerciseAdvisorInput, init?: RequestInit): Promise<ExerciseAdvisorOutput> {
+    return this.call("POST", "/v1/exercise", input, init);
+  }
+
+  /** Start or advance a multi-week exercise program that adapts to adherence and blood sugar */
+  exerciseProgram(input: ExerciseProgramInput, init?: RequestInit): Promise<ExerciseProgramOutput> {
+    return this.call("POST", "/v1/exercise/program", input, init);
+  }
+
+  /** Learn how exercise affects your blood sugar */
+  exerciseResponse(input: ExerciseResponseInput, init?: RequestInit): Promise<ExerciseResponseOutput> {
+    return this.call("POST", "/v1/exerciseResponse", input, init);
+  }
+
+  /** Safety guidance for intermittent fasting */
+  fastingAdvisor(input: FastingAdvisorInput, init?: RequestInit): Promise<FastingAdvisorOutput> {
+    return this.call("POST", "/v1/fastingAdvisor", input, init);
+  }
+
+  /** Project blood glucose over the next 1-4 hours */
+  glucoseForecast(input: GlucoseForecastInput, init?: RequestInit): Promise<GlucoseForecastOutput> {
+    return this.call("POST", "/v1/glucoseForecast", input, init);
+  }
+
+  /** Analyze time in range and variability */
+  glucoseTrends(input: GlucoseTrendsInput, init?: RequestInit): Promise<GlucoseTrendsOutput> {
+    return this.call("POST", "/v1/glucoseTrends", input, init);
+  }
+
+  /** Action plan for readings above 250 mg/dL */
+  highBGAction(input: HighBGActionInput, init?: RequestInit): Promise<HighBGActionOutput> {
+    return this.call("POST", "/v1/highBGAction", input, init);
+  }
+
+  /** Review logged hypo events for recurring causes and prevention */
+  hypoReview(input: HypoReviewInput, init?: RequestInit): Promise<HypoReviewOutput> {
+    return this.call("POST", "/v1/hypoReview", input, init);
+  }
+
+  /** Assess low blood sugar risk with insulin on board */
+  hypoRisk(input: HypoRiskInput, init?: RequestInit): Promise<HypoRiskOutput> {
+    return this.call("POST", "/v1/hypoRisk", input, init);
+  }
+
+  /** Estimate carb ratio and correction factor for your clinician */
+  icrEstimator(input: IcrEstimatorInput, init?: RequestInit): Promise<IcrEstimatorOutput> {
+    return this.call("POST", "/v1/icrEstimator", input, init);
+  }
+
+  /** Keep or discard guidance for insulin after opening, heat, cold or travel */
+  insulinStorage(input: InsulinStorageInput, init?: RequestInit): Promise<InsulinStorageOutput> {
+    return this.call("POST", "/v1/insulinStorage", input, init);
+  }
+
+  /** Get ready for a lab test, with an optional reminder the evening before */
+  labPrep(input: LabPrepInput, init?: RequestInit): Promise<LabPrepOutput> {
+    return this.call("POST", "/v1/labPrep", input, init);
+  }
+
+  /** Find foods that spike your blood sugar */
+  mealCorrelation(input: MealCorrelationInput, init?: RequestInit): Promise<MealCorrelationOutput> {
+    return this.call("POST", "/v1/mealCorrelation", input, init);
+  }
+
+  /** Get diabetes-friendly meal plans */
+  mealPlanner(input: MealPlannerInput, init?: RequestInit): Promise<MealPlannerOutput> {
+    return this.call("POST", "/v1/mealPlan", input, init);
+  }
+
+  /** Build a daily medication timetable from food and timing rules */
+  medSchedule(input: MedScheduleInput, init?: RequestInit): Promise<MedScheduleOutput> {
+    return this.call("POST", "/v1/medSchedule", input, init);
+  }
+
+  /** Lower-cost drugs in the same class to raise with your prescriber */
+  medicationAlternatives(input: MedicationAlternativesInput, init?: RequestInit): Promise<MedicationAlternativesOutput> {
+    return this.call("POST", "/v1/medicationAlternatives", input, init);
+  }
+
+  /** Get medication information */
+  medicationInfo(input: MedicationInfoInput, init?: RequestInit): Promise<MedicationInfoOutput> {
+    return this.call("POST", "/v1/medication", input, init);
+  }
+
+  /** Expand a meal plan line into a full recipe with nutrition */
+  recipe(input: RecipeInput, init?: RequestInit): Promise<RecipeOutput> {
+    return this.call("POST", "/v1/recipe", input, init);
+  }
+
+  /** Check symptoms and get guidance */
+  symptomChecker(input: SymptomCheckerInput, init?: RequestInit): Promise<SymptomCheckerOutput> {
+    return this.call("POST", "/v1/symptoms", input, init);
+  }
+
+  /** Summarize the past week */
+  weeklySummary(input: WeeklySummaryInput, init?: RequestInit): Promise<WeeklySummaryOutput> {
+    return this.call("POST", "/v1/weeklySummary", input, init);
+  }
+}
+
+export interface BloodSugarInterpreterInput {
+  /** Timing: fasting */
+  meal_timing: string;
+  /** Type of meal: breakfast */
+  meal_type: string;
+  /** Blood sugar reading in mg/dL */
+  reading: number;
+  /** User identifier used to store the reading (optional) */
+  user_id?: string;
+}
+
+export interface BloodSugarInterpreterOutput {
+  /** Detailed interpretation */
+  interpretation: string;
+  /** Immediate recommendations */
+  recommendation: string;
+  /** Status: normal */
+  status: string;
+}
+
+export interface ChatInput {
+  /** Your message */
+  message: string;
+  /** Conversation to continue; leave out to start a new one */
+  session_id?: string;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface ChatOutput {
+  /** Estimated tokens of conversation sent to the model */
+  context_tokens: number;
+  reply: string;
+  /** Send back with your next message to continue the conversation */
+  session_id: string;
+  /** Older messages were summarized on this turn to stay within the token budget */
+  summarized?: boolean;
+}
+
+export interface DayReviewInput {
+  /** Day to review */
+  date?: string;
+  /** Scheduled medications you missed today. Send an empty list if you took them all (optional) */
+  missed_doses?: string[];
+  /** Anything about your day you want to reflect on (optional) */
+  note?: string;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface DayReviewOutput {
+  /** What was logged during the day */
+  day: {
+    carbs: number;
+    date: string;
+    hypos: number;
+    insulin_doses: number;
+    meals: number;
+    /** Percent of scheduled doses taken */
+    medication_adherence?: number;
+    missed_doses?: string[];
+    /** Doses a day in your confirmed medication schedule */
+    scheduled_doses: number;
+    stats: {
+      count: number;
+      cv: number;
+      from: string;
+      /** Glucose management indicator: the A1C (%) the mean glucose predicts */
+      gmi?: number;
+      mean: number;
+      population: string;
+      /** Glucose bands and goals the percentages are measured against */
+      ranges: {
+        /** Maximum percent of readings above high */
+        above_goal: number;
+        /** Maximum percent of readings below low */
+        below_goal: number;
+        high: number;
+        /** Minimum percent of readings in range */
+        in_range_goal: number;
+        low: number;
+        very_high: number;
+        /** Maximum percent of readings above very high */
+        very_high_goal: number;
+        very_low: number;
+        /** Maximum percent of readings below very low */
+        very_low_goal: number;
+      };
+      sd: number;
+      target_range: string;
+      thresholds_version: string;
+      time_above_range: number;
+      time_below_range: number;
+      time_in_range: number;
+      time_very_high: number;
+      time_very_low: number;
+      to: string;
+    };
+    steps: number;
+    water_ml: number;
+    workout_minutes: number;
+  };
+  /** One concrete intention for tomorrow */
+  intention: string;
+  /** A walk through the day */
+  reflection: string;
+  /** What went well */
+  wins: string;
+}
+
+export interface ExerciseAdvisorInput {
+  /** Current blood glucose level (optional) */
+  current_bg: number;
+  /** Fitness level: beginner */
+  fitness_level: string;
+  /** Exercise preference: cardio */
+  preferred_type: string;
+  /** Minutes available for exercise */
+  time_available: number;
+  /** User identifier for personal exercise history (optional) */
+  user_id?: string;
+}
+
+export interface ExerciseAdvisorOutput {
+  /** Recommended duration and intensity */
+  duration: string;
+  /** Important precautions */
+  precautions: string;
+  /** Exercise recommendations */
+  recommendation: string;
+  /** Safety considerations based on BG */
+  safety_check: string;
+}
+
+export interface ExerciseProgramInput {
+  /** Fitness level: beginner */
+  fitness_level?: string;
+  /** What you want from the program */
+  goal?: string;
+  /** Exercise preference: cardio */
+  preferred_type?: string;
+  /** Replace the current program with a new one */
+  restart?: boolean;
+  /** Sessions per week */
+  sessions_per_week?: number;
+  /** Minutes available per session (default 30) */
+  time_available?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+  /** Program length in weeks */
+  weeks?: number;
+}
+
+export interface ExerciseProgramOutput {
+  program: {
+    created: string;
+    fitness_level: string;
+    goal?: string;
+    preferred_type: string;
+    sessions_per_week: number;
+    time_available: number;
+    total_weeks: number;
+    updated: string;
+    user_id: string;
+    weeks: ({
+      /** Percent of planned sessions completed */
+      adherence: number;
+      /** Why the level changed from the week before */
+      adjustment?: string;
+      /** Average blood sugar drop across completed sessions */
+      avg_drop?: number;
+      guidance?: string;
+      /** Training level */
+      level: number;
+      /** Completed sessions followed by a reading below 70 mg/dL */
+      lows?: number;
+      number: number;
+      sessions: ({
+        completed?: string;
+        /** Day of the program week */
+        day: number;
+        duration_minutes: number;
+        intensity: string;
+        type: string;
+      })[];
+      start: string;
+    })[];
+  };
+  /** started */
+  status: string;
+  /** The week in progress */
+  week?: {
+    /** Percent of planned sessions completed */
+    adherence: number;
+    /** Why the level changed from the week before */
+    adjustment?: string;
+    /** Average blood sugar drop across completed sessions */
+    avg_drop?: number;
+    guidance?: string;
+    /** Training level */
+    level: number;
+    /** Completed sessions followed by a reading below 70 mg/dL */
+    lows?: number;
+    number: number;
+    sessions: ({
+      completed?: string;
+      /** Day of the program week */
+      day: number;
+      duration_minutes: number;
+      intensity: string;
+      type: string;
+    })[];
+    start: string;
+  };
+}
+
+export interface ExerciseResponseInput {
+  /** Number of days to analyze (default 90) */
+  days?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface ExerciseResponseOutput {
+  /** How your body typically responds to each exercise type */
+  insights: string;
+  /** Glucose response by exercise type */
+  patterns: ({
+    avg_drop: number;
+    avg_nadir: number;
+    lows: number;
+    max_drop: number;
+    sessions: number;
+    type: string;
+  })[];
+  /** Personalized precautions */
+  precautions: string;
+  /** Workouts with surrounding readings */
+  sessions_analyzed: number;
+}
+
+export interface FastingAdvisorInput {
+  /** Current diabetes medications */
+  medications: string;
+  /** Fasting protocol */
+  protocol: string;
+  /** User identifier for recent readings (optional) */
+  user_id?: string;
+}
+
+export interface FastingAdvisorOutput {
+  /** Red flags for breaking the fast immediately */
+  break_fast_if: string[];
+  /** How your medications behave during fasting */
+  medication_notes: string;
+  /** When to check blood sugar */
+  monitoring_schedule: string[];
+  /** Medication and glucose risks found */
+  risk_factors: string[];
+  /** Fasting risk: low */
+  risk_level: string;
+  /** How to approach the fast safely */
+  safety_guidance: string;
+  /** Metrics for the past 14 days */
+  stats: {
+    count: number;
+    cv: number;
+    from: string;
+    /** Glucose management indicator: the A1C (%) the mean glucose predicts */
+    gmi?: number;
+    mean: number;
+    population: string;
+    /** Glucose bands and goals the percentages are measured against */
+    ranges: {
+      /** Maximum percent of readings above high */
+      above_goal: number;
+      /** Maximum percent of readings below low */
+      below_goal: number;
+      high: number;
+      /** Minimum percent of readings in range */
+      in_range_goal: number;
+      low: number;
+      very_high: number;
+      /** Maximum percent of readings above very high */
+      very_high_goal: number;
+      very_low: number;
+      /** Maximum percent of readings below very low */
+      very_low_goal: number;
+    };
+    sd: number;
+    target_range: string;
+    thresholds_version: string;
+    time_above_range: number;
+    time_below_range: number;
+    time_in_range: number;
+    time_very_high: number;
+    time_very_low: number;
+    to: string;
+  };
+}
+
+export interface GlucoseForecastInput {
+  /** Carb ratio in grams per unit (optional */
+  carb_ratio?: number;
+  /** Current blood glucose in mg/dL (optional when a reading was logged in the last 15 minutes) */
+  current_bg?: number;
+  /** Hours to project */
+  hours?: number;
+  /** Correction factor in mg/dL per unit (optional */
+  isf?: number;
+  /** Exercise planned in the forecast window (optional) */
+  planned_activity?: {
+    /** How long it lasts in minutes */
+    duration_minutes: number;
+    /** Intensity: light */
+    intensity?: string;
+    /** Minutes from now until it starts */
+    start_in_minutes?: number;
+    /** Exercise type */
+    type?: string;
+  };
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface GlucoseForecastOutput {
+  /** When to check again and what to do */
+  actions: string;
+  /** What pushes it up or down */
+  drivers: string;
+  /** Projected blood glucose every 15 minutes with confidence bands */
+  forecast: {
+    /** Carb ratio used */
+    carb_ratio: number;
+    /** Grams of logged carbs not yet absorbed */
+    carbs_on_board: number;
+    /** First time the projection rises above the high threshold */
+    high_risk_at?: string;
+    highest: {
+      /** Projected blood glucose in mg/dL */
+      bg: number;
+      /** Upper edge of the confidence band in mg/dL */
+      high: number;
+      /** Lower edge of the confidence band in mg/dL */
+      low: number;
+      /** Minutes from now */
+      minutes: number;
+      time: string;
+    };
+    insulin_on_board: number;
+    /** Correction factor used */
+    isf: number;
+    /** First time the band dips below the low threshold */
+    low_risk_at?: string;
+    lowest: {
+      /** Projected blood glucose in mg/dL */
+      bg: number;
+      /** Upper edge of the confidence band in mg/dL */
+      high: number;
+      /** Lower edge of the confidence band in mg/dL */
+      low: number;
+      /** Minutes from now */
+      minutes: number;
+      time: string;
+    };
+    points: ({
+      /** Projected blood glucose in mg/dL */
+      bg: number;
+      /** Upper edge of the confidence band in mg/dL */
+      high: number;
+      /** Lower edge of the confidence band in mg/dL */
+      low: number;
+      /** Minutes from now */
+      minutes: number;
+      time: string;
+    })[];
+    /** Where the ratios came from: input */
+    ratio_source: string;
+    start_bg: number;
+    /** Recent rate of change from the last 30 minutes of readings */
+    trend_mgdl_per_min: number;
+  };
+  /** Where blood sugar is heading */
+  outlook: string;
+}
+
+export interface GlucoseTrendsInput {
+  /** Number of days to analyze (default 14) */
+  days?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface GlucoseTrendsOutput {
+  /** Patterns observed in the readings */
+  patterns: string;
+  /** Time-in-range and variability metrics */
+  stats: {
+    count: number;
+    cv: number;
+    from: string;
+    /** Glucose management indicator: the A1C (%) the mean glucose predicts */
+    gmi?: number;
+    mean: number;
+    population: string;
+    /** Glucose bands and goals the percentages are measured against */
+    ranges: {
+      /** Maximum percent of readings above high */
+      above_goal: number;
+      /** Maximum percent of readings below low */
+      below_goal: number;
+      high: number;
+      /** Minimum percent of readings in range */
+      in_range_goal: number;
+      low: number;
+      very_high: number;
+      /** Maximum percent of readings above very high */
+      very_high_goal: number;
+      very_low: number;
+      /** Maximum percent of readings below very low */
+      very_low_goal: number;
+    };
+    sd: number;
+    target_range: string;
+    thresholds_version: string;
+    time_above_range: number;
+    time_below_range: number;
+    time_in_range: number;
+    time_very_high: number;
+    time_very_low: number;
+    to: string;
+  };
+  /** Suggestions to improve control */
+  suggestions: string;
+}
+
+export interface HighBGActionInput {
+  /** Blood ketones in mmol/L (optional) */
+  blood_ketones?: number;
+  /** Able to drink and keep fluids down */
+  can_keep_fluids_down?: boolean;
+  /** Feeling ill */
+  ill?: boolean;
+  /** Urine ketone result: negative */
+  ketones?: string;
+  /** Country or region */
+  location?: string;
+  /** Missed an insulin or diabetes medication dose */
+  missed_dose?: boolean;
+  /** Blood sugar reading in mg/dL */
+  reading: number;
+  /** User identifier (optional) */
+  user_id?: string;
+  /** Has been vomiting */
+  vomiting?: boolean;
+}
+
+export interface HighBGActionOutput {
+  /** When to call your doctor */
+  call_doctor_if: string[];
+  /** Local emergency numbers and ER guidance */
+  emergency_resources?: {
+    /** Direct ambulance line where it differs from the emergency number */
+    ambulance_number?: string;
+    country?: string;
+    emergency_number: string;
+    er_guidance: string;
+    /** Diabetes support lines. These are not for emergencies */
+    hotlines?: ({
+      hours?: string;
+      name: string;
+      phone: string;
+    })[];
+    /** False when the location was not recognised and generic guidance is returned */
+    matched: boolean;
+  };
+  /** Escalation: monitor */
+  escalation: string;
+  /** Supportive explanation of the plan */
+  explanation: string;
+  /** When to go to the emergency room */
+  go_to_er_if: string[];
+  /** Unanswered questions that could change the plan */
+  questions: string[];
+  /** Stepwise action plan */
+  steps: string[];
+}
+
+export interface HypoReviewInput {
+  /** Days of events to review (default 90) */
+  days?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface HypoReviewOutput {
+  /** Points to discuss with your doctor */
+  doctor_points: string;
+  /** Counts of causes */
+  patterns: {
+    /** Events within 12 hours of a logged workout */
+    after_exercise: number;
+    /** Events within 4 hours of a logged insulin dose */
+    after_insulin: number;
+    average_lowest: number;
+    by_cause: Record<string, number>;
+    /** Events by time of day: overnight */
+    by_period: Record<string, number>;
+    events: number;
+    needed_help: number;
+    /** Events with no meal logged in the previous 4 hours */
+    no_recent_meal: number;
+    /** Events treated with more than 30 g of carbs */
+    overtreated: number;
+    /** Patterns seen in at least 2 events and a third of them */
+    recurring: string[];
+    /** Events below 54 mg/dL */
+    severe: number;
+    /** Low reading episodes with no hypo event logged */
+    unlogged_lows: number;
+  };
+  /** Habits that target the patterns */
+  prevention: string;
+  /** True when an event was severe or needed help */
+  see_doctor_soon: boolean;
+  /** Recurring causes in plain language */
+  summary: string;
+}
+
+export interface HypoRiskInput {
+  /** Current blood glucose in mg/dL */
+  current_bg: number;
+  /** Activity planned in the next few hours (optional) */
+  planned_activity?: string;
+  /** Trend: rising */
+  trend?: string;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface HypoRiskOutput {
+  /** Why the risk is at this level */
+  assessment: string;
+  /** Active insulin in units */
+  insulin_on_board: number;
+  /** Steps to prevent a low */
+  prevention: string;
+  /** Steps */
+  recent_activity: {
+    latest_heart_rate_bpm?: number;
+    /** Steps in the last 3 hours */
+    recent_steps: number;
+    /** Workout minutes in the last 3 hours */
+    recent_workout_minutes: number;
+    samples: number;
+    steps_today: number;
+  };
+  /** Risk level: low */
+  risk_level: string;
+}
+
+export interface IcrEstimatorInput {
+  /** Number of days to analyze (default 14) */
+  days?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface IcrEstimatorOutput {
+  /** Questions to raise with your clinician */
+  discussion_points: string;
+  /** Deterministic carb ratio and correction factor estimates */
+  estimates: {
+    carb_ratio_observed?: number;
+    carb_ratio_rule?: number;
+    confidence: string;
+    correction_events: number;
+    correction_factor_observed?: number;
+    correction_factor_rule?: number;
+    days: number;
+    meal_events: number;
+    total_daily_dose?: number;
+  };
+  /** What the numbers mean and how they were derived */
+  explanation: string;
+}
+
+export interface InsulinStorageInput {
+  /** normal */
+  appearance?: string;
+  /** Days since first use or since it came out of the fridge */
+  days_out?: number;
+  /** vial or pen (default pen; cartridges count as pens) */
+  form?: string;
+  /** True if it froze or was stored against an ice pack */
+  frozen?: boolean;
+  /** How long it was at that temperature (optional) */
+  hours?: number;
+  /** Insulin name or brand */
+  insulin: string;
+  /** True once the insulin is in use */
+  opened?: boolean;
+  /** C or F (default C) */
+  temp_unit?: string;
+  /** Highest (or lowest) temperature it was exposed to (optional) */
+  temperature?: number;
+  /** True to include travel storage rules */
+  traveling?: boolean;
+  /** Days you need it for */
+  will_be_out_days?: number;
+}
+
+export interface InsulinStorageOutput {
+  /** Days left at room temperature */
+  days_left?: number;
+  /** Plain-language explanation of the verdict */
+  explanation: string;
+  form: string;
+  limits: ({
+    limit: string;
+    rule: string;
+    source: string;
+  })[];
+  product: {
+    /** True when the insulin is normally cloudy after mixing */
+    cloudy: boolean;
+    /** rapid */
+    kind: string;
+    /** False when the insulin was not recognised and generic limits are used */
+    matched: boolean;
+    name: string;
+    /** Days a pen or cartridge lasts once opened or out of the fridge */
+    pen_days: number;
+    /** Days a vial lasts once opened or out of the fridge */
+    vial_days: number;
+  };
+  reasons: string[];
+  travel_tips?: string[];
+  /** keep */
+  verdict: string;
+}
+
+export interface LabPrepInput {
+  /** Appointment time */
+  at: string;
+  /** Hours of fasting your doctor or lab asked for (default from the test) */
+  fasting_hours?: number;
+  /** Current medications */
+  medications?: string;
+  /** Glucose ranges to use: adult (default) */
+  population?: string;
+  /** Send a reminder the evening before */
+  remind?: boolean;
+  /** Reminder time the evening before */
+  remind_at?: string;
+  /** Lab test */
+  test: string;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface LabPrepOutput {
+  appointment: string;
+  /** When to stop eating */
+  fast_from?: string;
+  /** Hours of fasting before the test; 0 when none is needed or it isn't known */
+  fasting_hours: number;
+  /** Where the fasting window comes from: input */
+  fasting_source: string;
+  hydration: string;
+  /** Questions for your doctor about medication timing */
+  medication_questions: string;
+  /** What to do before the test */
+  preparation: string;
+  /** When the reminder will be sent */
+  reminder_at?: string;
+  /** Why the reminder may not reach you */
+  reminder_note?: string;
+  /** Risks from your medications while fasting */
+  safety_notes?: string[];
+  test: string;
+}
+
+export interface MealCorrelationInput {
+  /** Number of days to analyze (default 30) */
+  days?: number;
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface MealCorrelationOutput {
+  /** Foods or patterns consistently followed by spikes */
+  insights: string;
+  /** Meals with follow-up readings */
+  meals_analyzed: number;
+  /** Glucose response by food and meal type */
+  patterns: ({
+    avg_peak: number;
+    avg_rise?: number;
+    food: string;
+    meals: number;
+    spike_rate: number;
+    spikes: number;
+  })[];
+  /** Practical swaps and adjustments */
+  suggestions: string;
+}
+
+export interface MealPlannerInput {
+  /** Any food allergies or restrictions */
+  allergies: string;
+  /** Daily calorie limit (optional) */
+  calorie_limit: number;
+  /** Preferred cuisine */
+  cuisine?: string;
+  /** Diet preference: vegetarian */
+  diet_type: string;
+  /** The other people you cook for and what they like (optional) */
+  household?: ({
+    /** Who they are */
+    member: string;
+    /** Likes */
+    preferences?: string;
+  })[];
+  /** People eating together */
+  household_size?: number;
+  /** Where you live */
+  region?: string;
+}
+
+export interface MealPlannerOutput {
+  /** Breakfast suggestions */
+  breakfast: string;
+  /** Dinner suggestions */
+  dinner: string;
+  /** Each shared dish and how to adapt it for the member with diabetes */
+  dishes?: ({
+    /** What everyone eats */
+    dish: string;
+    /** breakfast */
+    meal: string;
+    /** Portion and carb swaps for the member with diabetes */
+    modification: string;
+  })[];
+  /** Lunch suggestions */
+  lunch: string;
+  /** USDA nutrient values per 100 g for the main foods in the plan */
+  nutrition?: ({
+    calories: number;
+    carbs: number;
+    description: string;
+    fat: number;
+    fdc_id: number;
+    fiber: number;
+    glycemic_index?: number;
+    protein: number;
+    query: string;
+    source: string;
+    sugars?: number;
+  })[];
+  /** People the shared plan feeds */
+  servings?: number;
+  /** Healthy snack options */
+  snacks: string;
+}
+
+export interface MedScheduleInput {
+  /** Bedtime HH:MM (default 22:30) */
+  bed?: string;
+  /** Breakfast time HH:MM (default 07:30) */
+  breakfast?: string;
+  /** schedule_id of a proposed schedule to save as daily reminders */
+  confirm?: string;
+  /** Dinner time HH:MM (default 19:00) */
+  dinner?: string;
+  /** Lunch time HH:MM (default 12:30) */
+  lunch?: string;
+  /** Your full medication list */
+  medications: ({
+    /** Dose as prescribed */
+    dose?: string;
+    /** Medication name */
+    name: string;
+    /** Doses per day as prescribed */
+    times_per_day?: number;
+  })[];
+  /** User identifier (optional) */
+  user_id?: string;
+  /** Wake time HH:MM (default 07:00) */
+  wake?: string;
+}
+
+export interface MedScheduleOutput {
+  /** True once the schedule is saved as daily reminders */
+  confirmed: boolean;
+  doses: ({
+    dose?: string;
+    /** How to take it */
+    instruction: string;
+    medication: string;
+    /** Time of day */
+    time: string;
+  })[];
+  /** Spacing */
+  notes?: string;
+  /** Send back as confirm to save this schedule */
+  schedule_id?: string;
+  timings?: ({
+    label_text?: string;
+    medication: string;
+    /** with_meal */
+    rule: string;
+    /** label when read from the FDA label */
+    source: string;
+  })[];
+}
+
+export interface MedicationAlternativesInput {
+  /** Country where you fill prescriptions (default US) */
+  country?: string;
+  /** cash_pay */
+  coverage?: string;
+  /** Medication you take now */
+  medication: string;
+}
+
+export interface MedicationAlternativesOutput {
+  /** Other drugs in the same class */
+  alternatives: ({
+    brands?: string[];
+    /** Sold as a generic or biosimilar in many countries */
+    generic: boolean;
+    /** What the FDA label says about hypoglycemia */
+    hypoglycemia_risk?: string;
+    /** Facts quoted from the FDA drug label */
+    label_facts?: ({
+      section: string;
+      source: string;
+      text: string;
+    })[];
+    name: string;
+    note?: string;
+  })[];
+  class: string;
+  cost_tips: string[];
+  disclaimer: string;
+  /** Options and questions to raise with your prescriber */
+  discussion: string;
+  /** True when FDA label data was found for the drugs */
+  grounded: boolean;
+  medication: string;
+}
+
+export interface MedicationInfoInput {
+  /** Name of medication */
+  medication_name: string;
+  /** Purpose of inquiry (dosage */
+  purpose: string;
+}
+
+export interface MedicationInfoOutput {
+  /** True when the information was generated from FDA label data */
+  grounded: boolean;
+  /** What the FDA label says about hypoglycemia */
+  hypoglycemia_risk?: string;
+  /** Medication information generated by the model */
+  information: string;
+  /** Facts quoted from the FDA drug label */
+  label_facts: ({
+    section: string;
+    source: string;
+    text: string;
+  })[];
+  /** Important reminders */
+  reminder: string;
+}
+
+export interface RecipeInput {
+  /** Any food allergies or restrictions (optional) */
+  allergies?: string;
+  /** Diet preference: vegetarian */
+  diet_type?: string;
+  /** A meal line from the meal plan */
+  meal: string;
+  /** Number of servings (default 1) */
+  servings?: number;
+}
+
+export interface RecipeOutput {
+  cook_minutes: number;
+  ingredients: ({
+    /** Weight for all servings in grams */
+    grams?: number;
+    name: string;
+    /** Household measure */
+    quantity?: string;
+  })[];
+  /** Carbs */
+  per_serving: {
+    calories: number;
+    carbs: number;
+    fat: number;
+    fiber: number;
+    protein: number;
+    /** usda when computed from FoodData Central */
+    source: string;
+  };
+  prep_minutes: number;
+  servings: number;
+  steps: string[];
+  title: string;
+}
+
+export interface SymptomCheckerInput {
+  /** Blood ketones in mmol/L (optional) */
+  blood_ketones?: number;
+  /** Current blood glucose in mg/dL (optional) */
+  current_bg?: number;
+  /** Current medications (optional) */
+  current_meds?: string;
+  /** How long symptoms have been present */
+  duration?: string;
+  /** Fruity-smelling breath */
+  fruity_breath?: boolean;
+  /** Photo of a foot wound */
+  image?: string;
+  /** What the photo shows: foot_wound */
+  image_site?: string;
+  /** Urine ketone result: negative */
+  ketones?: string;
+  /** Country or region */
+  location?: string;
+  /** Feeling nauseous */
+  nausea?: boolean;
+  /** Session from a needs_more_info response. Send only the answered fields; earlier ones are kept */
+  session_id?: string;
+  /** Add candidate ICD-10 codes for the clinician export */
+  suggest_icd10?: boolean;
+  /** Describe symptoms you're experiencing (required on the first call) */
+  symptoms?: string;
+  /** User identifier used to keep the check for the clinician export (optional) */
+  user_id?: string;
+  /** Has been vomiting */
+  vomiting?: boolean;
+}
+
+export interface SymptomCheckerOutput {
+  /** Symptom assessment */
+  assessment?: string;
+  /** Deterministic DKA screening result */
+  dka_screen: {
+    /** Whether DKA screening criteria were met */
+    positive: boolean;
+    /** Criteria that were met */
+    reasons?: string[];
+  };
+  /** Local emergency numbers and ER guidance */
+  emergency_resources?: {
+    /** Direct ambulance line where it differs from the emergency number */
+    ambulance_number?: string;
+    country?: string;
+    emergency_number: string;
+    er_guidance: string;
+    /** Diabetes support lines. These are not for emergencies */
+    hotlines?: ({
+      hours?: string;
+      name: string;
+      phone: string;
+    })[];
+    /** False when the location was not recognised and generic guidance is returned */
+    matched: boolean;
+  };
+  /** Candidate ICD-10 codes for clinician review. Suggestions only */
+  icd10_suggestions?: ({
+    code: string;
+    description: string;
+    /** Where the code came from: lookup or model */
+    source: string;
+    suggestion: boolean;
+  })[];
+  /** Recommended next steps */
+  next_steps?: string;
+  /** Visual assessment of the photo and the escalation it calls for */
+  photo_triage?: {
+    /** What the photo shows */
+    description?: string;
+    /** Escalation the photo calls for: none */
+    escalation: string;
+    /** Visible signs */
+    findings: string[];
+    /** Escalation rules that fired */
+    reasons?: string[];
+    /** Visual severity: mild */
+    severity: string;
+    /** What the photo shows: foot_wound */
+    site: string;
+  };
+  /** Details needed before urgency can be judged */
+  questions?: ({
+    /** Input field the answer goes in */
+    field: string;
+    question: string;
+  })[];
+  /** Send back with the answers to continue the check */
+  session_id?: string;
+  /** complete */
+  status: string;
+  /** Urgency level: emergency */
+  urgency?: string;
+}
+
+export interface WeeklySummaryInput {
+  /** User identifier (optional) */
+  user_id?: string;
+}
+
+export interface WeeklySummaryOutput {
+  /** Areas to focus on next week */
+  focus_areas: string;
+  /** Metrics for the past 7 days */
+  stats: {
+    count: number;
+    cv: number;
+    from: string;
+    /** Glucose management indicator: the A1C (%) the mean glucose predicts */
+    gmi?: number;
+    mean: number;
+    population: string;
+    /** Glucose bands and goals the percentages are measured against */
+    ranges: {
+      /** Maximum percent of readings above high */
+      above_goal: number;
+      /** Maximum percent of readings below low */
+      below_goal: number;
+      high: number;
+      /** Minimum percent of readings in range */
+      in_range_goal: number;
+      low: number;
+      very_high: number;
+      /** Maximum percent of readings above very high */
+      very_high_goal: number;
+      very_low: number;
+      /** Maximum percent of readings below very low */
+      very_low_goal: number;
+    };
+    sd: number;
+    target_range: string;
+    thresholds_version: string;
+    time_above_range: number;
+    time_below_range: number;
+    time_in_range: number;
+    time_very_high: number;
+    time_very_low: number;
+    to: string;
+  };
+  /** Summary of the week */
+  summary: string;
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"diabeticai-advisor/internal/replay"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Sample requests run by --selftest
+var selfTests = []struct {
+	flow  string
+	input string
+}{
+	{"bloodSugarInterpreter", `{"user_id": "selftest", "reading": 145, "meal_timing": "after_meal", "meal_type": "lunch"}`},
+	{"mealPlanner", `{"diet_type": "vegetarian", "allergies": "none"}`},
+	{"symptomChecker", `{"symptoms": "increased thirst and frequent urination", "duration": "2 days", "current_bg": 210}`},
+}
+
+// Helper function to check the model connection and a few flows end to end.
+// Returns the number of failed checks.
+func runSelfTest(ctx context.Context, g *genkit.Genkit) int {
+	failed := 0
+
+	// Welcome Message
+	fmt.Println("=== DiabetesAI Advisor Self-Test ===")
+	welcome := "Generate a warm welcome, encouraging welcome message for diabetes patients using this AI health advisor. Keep it under 50 words."
+	response, err := replay.Do(welcome, func() (*ai.ModelResponse, error) {
+		return genkit.Generate(ctx, g, ai.WithPrompt(welcome))
+	})
+	if err != nil {
+		log.Printf("Error generating welcome: %v", err)
+		failed++
+	} else {
+		fmt.Println("\n" + response.Text())
+	}
+
+	flowsByName := make(map[string]api.Action)
+	for _, flow := range genkit.ListFlows(g) {
+		flowsByName[flow.Name()] = flow
+	}
+
+	for _, test := range selfTests {
+		flow, ok := flowsByName[test.flow]
+		if !ok {
+			log.Printf("FAIL %s: flow not registered", test.flow)
+			failed++
+			continue
+		}
+		out, err := flow.RunJSON(ctx, json.RawMessage(test.input), nil)
+		if err != nil {
+			log.Printf("FAIL %s: %v", test.flow, err)
+			failed++
+			continue
+		}
+		log.Printf("ok   %s: %s", test.flow, out)
+	}
+
+	return failed
+}